@@ -20,6 +20,65 @@ func CalleesOf(caller *Node) map[*Node]bool {
 	return callees
 }
 
+// CallersOf returns a new set containing all direct callers of the
+// callee node.
+//
+func CallersOf(callee *Node) map[*Node]bool {
+	callers := make(map[*Node]bool)
+	for _, e := range callee.In {
+		callers[e.Caller] = true
+	}
+	return callers
+}
+
+// Reachable returns the set of functions reachable in graph g from
+// the roots, including the roots themselves.
+//
+func Reachable(g *Graph, roots []*ssa.Function) map[*ssa.Function]bool {
+	seen := make(map[*ssa.Function]bool)
+	var queue []*Node
+	for _, fn := range roots {
+		if n := g.Nodes[fn]; n != nil && !seen[fn] {
+			seen[fn] = true
+			queue = append(queue, n)
+		}
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, e := range n.Out {
+			if !seen[e.Callee.Func] {
+				seen[e.Callee.Func] = true
+				queue = append(queue, e.Callee)
+			}
+		}
+	}
+	for _, fn := range roots {
+		seen[fn] = true
+	}
+	return seen
+}
+
+// MainRoots returns the entry points of the main packages among pkgs:
+// their main and init functions. They are the usual roots of whole
+// program analyses such as RTA.
+//
+func MainRoots(pkgs []*ssa.Package) []*ssa.Function {
+	var roots []*ssa.Function
+	for _, pkg := range pkgs {
+		if pkg == nil || pkg.Pkg.Name() != "main" {
+			continue
+		}
+		if fn := pkg.Func("main"); fn != nil {
+			roots = append(roots, fn)
+		}
+		if fn := pkg.Func("init"); fn != nil {
+			roots = append(roots, fn)
+		}
+	}
+	return roots
+}
+
 // GraphVisitEdges visits all the edges in graph g in depth-first order.
 // The edge function is called for each edge in postorder.  If it
 // returns non-nil, visitation stops and GraphVisitEdges returns that
@@ -0,0 +1,79 @@
+package callgraph_test
+
+import (
+	"go/parser"
+	"reflect"
+	"sort"
+	"testing"
+
+	"golang.org/x/tools/go/loader"
+	"honnef.co/go/tools/callgraph"
+	"honnef.co/go/tools/callgraph/cha"
+	"honnef.co/go/tools/ssa"
+	"honnef.co/go/tools/ssa/ssautil"
+)
+
+const input = `package main
+
+type T int
+func (T) f() { g() }
+func (T) unused() {}
+
+type I interface{ f() }
+
+func main() {
+	var i I = T(0)
+	i.f()
+}
+
+func g() {}
+func dead() { g() }
+`
+
+func TestReachable(t *testing.T) {
+	conf := loader.Config{ParserMode: parser.ParseComments}
+	f, err := conf.ParseFile("main.go", input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.CreateFromFiles("main", f)
+	iprog, err := conf.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prog := ssautil.CreateProgram(iprog, 0)
+	prog.Build()
+	pkg := prog.Package(iprog.Created[0].Pkg)
+
+	roots := callgraph.MainRoots([]*ssa.Package{pkg})
+	var names []string
+	for _, fn := range roots {
+		names = append(names, fn.Name())
+	}
+	if want := []string{"main", "init"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got roots %v, want %v", names, want)
+	}
+
+	cg := cha.CallGraph(prog)
+	names = nil
+	for fn := range callgraph.Reachable(cg, roots) {
+		if fn.Pkg == pkg {
+			names = append(names, fn.RelString(pkg.Pkg))
+		}
+	}
+	sort.Strings(names)
+	want := []string{"(T).f", "g", "init", "main"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("got reachable functions %v, want %v", names, want)
+	}
+
+	callers := callgraph.CallersOf(cg.Nodes[pkg.Func("g")])
+	names = nil
+	for n := range callers {
+		names = append(names, n.Func.RelString(pkg.Pkg))
+	}
+	sort.Strings(names)
+	if want := []string{"(T).f", "dead"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got callers of g %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,174 @@
+// allocs lists the patterns that cause the most heap allocations in
+// packages, to help with performance triage. Allocations inside of
+// loops are counted separately, as they tend to matter most.
+package main // import "honnef.co/go/tools/cmd/allocs"
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/build"
+	"os"
+	"sort"
+
+	"github.com/kisielk/gotool"
+	"golang.org/x/tools/go/buildutil"
+	"golang.org/x/tools/go/loader"
+	"honnef.co/go/tools/functions"
+	"honnef.co/go/tools/ssa"
+	"honnef.co/go/tools/ssa/ssautil"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [flags] <packages>\n\n", os.Args[0])
+	fmt.Fprintln(os.Stderr, "Flags:")
+	flag.PrintDefaults()
+}
+
+// A pattern is a kind of allocation and how often it occurs in a
+// package.
+type pattern struct {
+	Kind   string `json:"kind"`
+	Count  int    `json:"count"`
+	InLoop int    `json:"in_loop"`
+}
+
+// A site is a single allocation.
+type site struct {
+	Position string `json:"position"`
+	Function string `json:"function"`
+	Kind     string `json:"kind"`
+	InLoop   bool   `json:"in_loop"`
+}
+
+type report struct {
+	Package  string    `json:"package"`
+	Count    int       `json:"count"`
+	InLoop   int       `json:"in_loop"`
+	Patterns []pattern `json:"patterns"`
+	Sites    []site    `json:"sites,omitempty"`
+}
+
+func main() {
+	var tags buildutil.TagsFlag
+	flag.Var(&tags, "tags", "List of build tags")
+	tests := flag.Bool("tests", false, "Include tests")
+	n := flag.Int("n", 5, "Print only the `n` most common patterns of each package (0 prints all)")
+	loops := flag.Bool("loops", false, "Only consider allocations inside of loops")
+	verbose := flag.Bool("v", false, "Print the position of each allocation")
+	asJSON := flag.Bool("json", false, "Print results as JSON")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	ctx := build.Default
+	ctx.BuildTags = tags
+	conf := &loader.Config{
+		Build:      &ctx,
+		ImportPkgs: map[string]bool{},
+	}
+	for _, path := range gotool.ImportPaths(flag.Args()) {
+		conf.ImportPkgs[path] = *tests
+	}
+	lprog, err := conf.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	prog := ssautil.CreateProgram(lprog, 0)
+	prog.Build()
+
+	reports := map[*ssa.Package]*report{}
+	for _, info := range lprog.InitialPackages() {
+		pkg := prog.Package(info.Pkg)
+		reports[pkg] = &report{Package: pkg.Pkg.Path()}
+	}
+	for fn := range ssautil.AllFunctions(prog) {
+		r := reports[fn.Pkg]
+		if r == nil || fn.Synthetic != "" {
+			continue
+		}
+		for _, alloc := range functions.Allocations(fn) {
+			if *loops && !alloc.InLoop {
+				continue
+			}
+			r.add(alloc)
+			if *verbose {
+				r.Sites = append(r.Sites, site{
+					Position: prog.Fset.Position(alloc.Instr.Pos()).String(),
+					Function: fn.RelString(fn.Pkg.Pkg),
+					Kind:     alloc.Kind,
+					InLoop:   alloc.InLoop,
+				})
+			}
+		}
+	}
+
+	var out []*report
+	for _, r := range reports {
+		sort.Slice(r.Patterns, func(i, j int) bool {
+			if r.Patterns[i].Count != r.Patterns[j].Count {
+				return r.Patterns[i].Count > r.Patterns[j].Count
+			}
+			return r.Patterns[i].Kind < r.Patterns[j].Kind
+		})
+		if *n > 0 && len(r.Patterns) > *n {
+			r.Patterns = r.Patterns[:*n]
+		}
+		sort.Slice(r.Sites, func(i, j int) bool {
+			return r.Sites[i].Position < r.Sites[j].Position
+		})
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Package < out[j].Package
+	})
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	if *asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		enc.Encode(out)
+		return
+	}
+	for _, r := range out {
+		fmt.Fprintf(w, "%s: %d allocations, %d in loops\n", r.Package, r.Count, r.InLoop)
+		for _, p := range r.Patterns {
+			fmt.Fprintf(w, "\t%6d %6d in loops  %s\n", p.Count, p.InLoop, p.Kind)
+		}
+		for _, s := range r.Sites {
+			loop := ""
+			if s.InLoop {
+				loop = " in loop"
+			}
+			fmt.Fprintf(w, "%s: %s%s in %s\n", s.Position, s.Kind, loop, s.Function)
+		}
+	}
+}
+
+func (r *report) add(alloc functions.Allocation) {
+	r.Count++
+	if alloc.InLoop {
+		r.InLoop++
+	}
+	for i := range r.Patterns {
+		p := &r.Patterns[i]
+		if p.Kind == alloc.Kind {
+			p.Count++
+			if alloc.InLoop {
+				p.InLoop++
+			}
+			return
+		}
+	}
+	p := pattern{Kind: alloc.Kind, Count: 1}
+	if alloc.InLoop {
+		p.InLoop = 1
+	}
+	r.Patterns = append(r.Patterns, p)
+}
@@ -0,0 +1,283 @@
+// apidiff reports changes to the exported API of Go packages between
+// two versions, such as removed identifiers, changed signatures and
+// methods added to interfaces, and exits with a non-zero status if
+// any of them break compatibility.
+//
+// The versions are either two directories, or a git revision and the
+// working tree.
+package main // import "honnef.co/go/tools/cmd/apidiff"
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/build"
+	"go/types"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/buildutil"
+	"golang.org/x/tools/go/loader"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [flags] <old dir> <new dir>\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s [flags] -rev <revision> <dir>\n\n", os.Args[0])
+	fmt.Fprintln(os.Stderr, "Directories ending in /... compare all packages below them.")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	flag.PrintDefaults()
+}
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	var tags buildutil.TagsFlag
+	flag.Var(&tags, "tags", "List of build tags")
+	rev := flag.String("rev", "", "Compare the working tree with the git `revision`")
+	all := flag.Bool("all", false, "Print compatible changes, too")
+	asJSON := flag.Bool("json", false, "Print results as JSON")
+	flag.Usage = usage
+	flag.Parse()
+
+	var oldDir, newDir string
+	switch {
+	case *rev != "" && flag.NArg() == 1:
+		newDir = flag.Arg(0)
+		dir, err := checkout(*rev, newDir)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		defer os.RemoveAll(dir)
+		oldDir = dir
+		if strings.HasSuffix(newDir, "/...") {
+			oldDir += "/..."
+		}
+	case *rev == "" && flag.NArg() == 2:
+		oldDir, newDir = flag.Arg(0), flag.Arg(1)
+	default:
+		flag.Usage()
+		return 2
+	}
+
+	ctx := build.Default
+	ctx.BuildTags = tags
+	pkgs, err := packageDirs(oldDir, newDir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	type result struct {
+		Package string   `json:"package"`
+		Changes []change `json:"changes"`
+	}
+	var results []result
+	breaking := false
+	for _, rel := range pkgs {
+		var changes []change
+		oldPkg, oerr := load(&ctx, filepath.Join(strings.TrimSuffix(oldDir, "..."), rel), rel)
+		newPkg, nerr := load(&ctx, filepath.Join(strings.TrimSuffix(newDir, "..."), rel), rel)
+		switch {
+		case oerr == errNoPackage && nerr == errNoPackage:
+			continue
+		case oerr == errNoPackage:
+			changes = []change{{Object: rel, Message: "added package"}}
+		case nerr == errNoPackage:
+			changes = []change{{Object: rel, Message: "removed package", Breaking: true}}
+		case oerr != nil:
+			fmt.Fprintln(os.Stderr, oerr)
+			return 2
+		case nerr != nil:
+			fmt.Fprintln(os.Stderr, nerr)
+			return 2
+		default:
+			d := &differ{old: oldPkg, new: newPkg}
+			changes = d.diff()
+		}
+		var shown []change
+		for _, c := range changes {
+			if c.Breaking {
+				breaking = true
+			}
+			if c.Breaking || *all {
+				shown = append(shown, c)
+			}
+		}
+		if len(shown) > 0 {
+			results = append(results, result{Package: rel, Changes: shown})
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "\t")
+		enc.Encode(results)
+	} else {
+		for _, r := range results {
+			for _, c := range r.Changes {
+				prefix := "-"
+				if !c.Breaking {
+					prefix = "+"
+				}
+				fmt.Printf("%s %s: %s: %s\n", prefix, r.Package, c.Object, c.Message)
+			}
+		}
+	}
+	if breaking {
+		return 1
+	}
+	return 0
+}
+
+var errNoPackage = errors.New("no Go package")
+
+// packageDirs returns the directories, relative to oldDir and newDir,
+// that contain packages to compare. Unless the directories end in
+// /..., this is only the directories themselves.
+func packageDirs(oldDir, newDir string) ([]string, error) {
+	recursive := strings.HasSuffix(oldDir, "/...")
+	if recursive != strings.HasSuffix(newDir, "/...") {
+		return nil, fmt.Errorf("either both or neither of the directories must end in /...")
+	}
+	if !recursive {
+		return []string{"."}, nil
+	}
+	seen := map[string]bool{}
+	for _, root := range []string{oldDir, newDir} {
+		root = filepath.Clean(strings.TrimSuffix(root, "..."))
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				return nil
+			}
+			name := info.Name()
+			if path != root && (name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			if name == "internal" {
+				// Internal packages have no public API.
+				return filepath.SkipDir
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			seen[rel] = true
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	var dirs []string
+	for dir := range seen {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// load type-checks the package in dir, naming it path.
+func load(ctx *build.Context, dir, path string) (*types.Package, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	bpkg, err := ctx.ImportDir(dir, 0)
+	if err != nil {
+		if _, ok := err.(*build.NoGoError); ok || os.IsNotExist(err) {
+			return nil, errNoPackage
+		}
+		return nil, err
+	}
+	if bpkg.Name == "main" {
+		return nil, errNoPackage
+	}
+	var files []string
+	for _, f := range bpkg.GoFiles {
+		files = append(files, filepath.Join(dir, f))
+	}
+	conf := &loader.Config{Build: ctx}
+	conf.CreateFromFilenames(path, files...)
+	lprog, err := conf.Load()
+	if err != nil {
+		return nil, err
+	}
+	return lprog.Created[0].Pkg, nil
+}
+
+// checkout extracts dir, a directory of a git repository, as of the
+// revision rev into a temporary directory, which the caller must
+// remove. Subdirectories are only extracted if dir ends in /....
+func checkout(rev, dir string) (string, error) {
+	recursive := strings.HasSuffix(dir, "/...")
+	dir = filepath.Clean(strings.TrimSuffix(dir, "..."))
+	cmd := exec.Command("git", "rev-parse", "--show-prefix")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("couldn't find git repository of %s: %s", dir, err)
+	}
+	prefix := strings.TrimSpace(string(out))
+	tree := rev
+	if prefix != "" {
+		tree += ":" + strings.TrimSuffix(prefix, "/")
+	}
+	cmd = exec.Command("git", "archive", "--format=tar", tree)
+	cmd.Dir = dir
+	cmd.Stderr = os.Stderr
+	out, err = cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("couldn't read %s at %s: %s", dir, rev, err)
+	}
+
+	tmp, err := ioutil.TempDir("", "apidiff")
+	if err != nil {
+		return "", err
+	}
+	tr := tar.NewReader(bytes.NewReader(out))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			os.RemoveAll(tmp)
+			return "", err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if !recursive && strings.Contains(hdr.Name, "/") {
+			continue
+		}
+		path := filepath.Join(tmp, filepath.FromSlash(hdr.Name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			os.RemoveAll(tmp)
+			return "", err
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			os.RemoveAll(tmp)
+			return "", err
+		}
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			os.RemoveAll(tmp)
+			return "", err
+		}
+	}
+	return tmp, nil
+}
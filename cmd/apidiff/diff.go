@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+)
+
+// A change is a difference between the exported APIs of two versions
+// of a package.
+type change struct {
+	Object   string `json:"object"`
+	Message  string `json:"message"`
+	Breaking bool   `json:"breaking"`
+}
+
+type differ struct {
+	old, new *types.Package
+	changes  []change
+}
+
+func (d *differ) report(obj, format string, args ...interface{}) {
+	d.changes = append(d.changes, change{Object: obj, Message: fmt.Sprintf(format, args...), Breaking: true})
+}
+
+func (d *differ) compatible(obj, format string, args ...interface{}) {
+	d.changes = append(d.changes, change{Object: obj, Message: fmt.Sprintf(format, args...), Breaking: false})
+}
+
+// typeString formats T so that types of the packages being compared
+// print the same in both versions. Parameter names are omitted, as
+// renaming parameters doesn't affect compatibility.
+func (d *differ) typeString(T types.Type) string {
+	if sig, ok := T.(*types.Signature); ok {
+		T = types.NewSignature(nil, unnamed(sig.Params()), unnamed(sig.Results()), sig.Variadic())
+	}
+	return types.TypeString(T, func(pkg *types.Package) string {
+		if pkg == d.old || pkg == d.new {
+			return ""
+		}
+		return pkg.Path()
+	})
+}
+
+func unnamed(tuple *types.Tuple) *types.Tuple {
+	vars := make([]*types.Var, tuple.Len())
+	for i := range vars {
+		vars[i] = types.NewParam(tuple.At(i).Pos(), nil, "", tuple.At(i).Type())
+	}
+	return types.NewTuple(vars...)
+}
+
+func kind(obj types.Object) string {
+	switch obj := obj.(type) {
+	case *types.Const:
+		return "constant"
+	case *types.Var:
+		return "variable"
+	case *types.Func:
+		return "function"
+	case *types.TypeName:
+		if obj.IsAlias() {
+			return "type alias"
+		}
+		return "type"
+	}
+	return "object"
+}
+
+// diff compares the exported package-level objects of d.old and
+// d.new.
+func (d *differ) diff() []change {
+	oscope, nscope := d.old.Scope(), d.new.Scope()
+	for _, name := range oscope.Names() {
+		o := oscope.Lookup(name)
+		if !o.Exported() {
+			continue
+		}
+		n := nscope.Lookup(name)
+		if n == nil || !n.Exported() {
+			d.report(name, "removed")
+			continue
+		}
+		if kind(o) != kind(n) {
+			d.report(name, "changed from %s to %s", kind(o), kind(n))
+			continue
+		}
+		switch o := o.(type) {
+		case *types.Const, *types.Var, *types.Func:
+			ot, nt := d.typeString(o.Type()), d.typeString(n.Type())
+			if ot != nt {
+				d.report(name, "changed type from %s to %s", ot, nt)
+			}
+		case *types.TypeName:
+			if o.IsAlias() {
+				ot, nt := d.typeString(o.Type()), d.typeString(n.Type())
+				if ot != nt {
+					d.report(name, "changed from alias of %s to alias of %s", ot, nt)
+				}
+				continue
+			}
+			d.diffType(name, o.Type(), n.Type())
+		}
+	}
+	for _, name := range nscope.Names() {
+		n := nscope.Lookup(name)
+		if o := oscope.Lookup(name); n.Exported() && (o == nil || !o.Exported()) {
+			d.compatible(name, "added")
+		}
+	}
+	sort.SliceStable(d.changes, func(i, j int) bool {
+		return d.changes[i].Object < d.changes[j].Object
+	})
+	return d.changes
+}
+
+// diffType compares the named types o and n.
+func (d *differ) diffType(name string, o, n types.Type) {
+	switch ou := o.Underlying().(type) {
+	case *types.Struct:
+		nu, ok := n.Underlying().(*types.Struct)
+		if !ok {
+			d.report(name, "changed from struct to %s", d.typeString(n.Underlying()))
+			return
+		}
+		d.diffStruct(name, ou, nu)
+	case *types.Interface:
+		nu, ok := n.Underlying().(*types.Interface)
+		if !ok {
+			d.report(name, "changed from interface to %s", d.typeString(n.Underlying()))
+			return
+		}
+		d.diffInterface(name, ou, nu)
+	default:
+		ot, nt := d.typeString(ou), d.typeString(n.Underlying())
+		if ot != nt {
+			d.report(name, "changed underlying type from %s to %s", ot, nt)
+		}
+	}
+	if _, ok := o.Underlying().(*types.Interface); !ok {
+		d.diffMethods(name, o, n)
+	}
+}
+
+func (d *differ) diffStruct(name string, o, n *types.Struct) {
+	fields := map[string]*types.Var{}
+	for i := 0; i < n.NumFields(); i++ {
+		fields[n.Field(i).Name()] = n.Field(i)
+	}
+	for i := 0; i < o.NumFields(); i++ {
+		of := o.Field(i)
+		if !of.Exported() {
+			continue
+		}
+		obj := name + "." + of.Name()
+		nf, ok := fields[of.Name()]
+		if !ok || !nf.Exported() {
+			d.report(obj, "removed field")
+			continue
+		}
+		ot, nt := d.typeString(of.Type()), d.typeString(nf.Type())
+		if ot != nt {
+			d.report(obj, "changed field type from %s to %s", ot, nt)
+		}
+	}
+}
+
+func (d *differ) diffInterface(name string, o, n *types.Interface) {
+	// Other packages can only implement interfaces that don't have
+	// unexported methods.
+	implementable := true
+	for i := 0; i < o.NumMethods(); i++ {
+		if !o.Method(i).Exported() {
+			implementable = false
+		}
+	}
+	methods := map[string]*types.Func{}
+	for i := 0; i < o.NumMethods(); i++ {
+		methods[o.Method(i).Name()] = o.Method(i)
+	}
+	for i := 0; i < n.NumMethods(); i++ {
+		nm := n.Method(i)
+		obj := name + "." + nm.Name()
+		om, ok := methods[nm.Name()]
+		if !ok {
+			if nm.Exported() && implementable {
+				d.report(obj, "added method, which existing implementations lack")
+			} else if !nm.Exported() && implementable {
+				d.report(name, "added unexported method, which other packages can't implement")
+			}
+			continue
+		}
+		delete(methods, nm.Name())
+		if !nm.Exported() {
+			continue
+		}
+		ot, nt := d.typeString(om.Type()), d.typeString(nm.Type())
+		if ot != nt {
+			d.report(obj, "changed method signature from %s to %s", ot, nt)
+		}
+	}
+	for mname, om := range methods {
+		if om.Exported() {
+			d.report(name+"."+mname, "removed method")
+		}
+	}
+}
+
+// diffMethods compares the exported methods of the named types o and
+// n, including promoted ones.
+func (d *differ) diffMethods(name string, o, n types.Type) {
+	optrs := types.NewMethodSet(types.NewPointer(o))
+	nptrs := types.NewMethodSet(types.NewPointer(n))
+	nvals := types.NewMethodSet(n)
+	ovals := types.NewMethodSet(o)
+	for i := 0; i < optrs.Len(); i++ {
+		om := optrs.At(i).Obj()
+		if !om.Exported() {
+			continue
+		}
+		obj := name + "." + om.Name()
+		sel := nptrs.Lookup(om.Pkg(), om.Name())
+		if sel == nil {
+			d.report(obj, "removed method")
+			continue
+		}
+		ot, nt := d.typeString(om.Type()), d.typeString(sel.Obj().Type())
+		if ot != nt {
+			d.report(obj, "changed method signature from %s to %s", ot, nt)
+		}
+		if ovals.Lookup(om.Pkg(), om.Name()) != nil && nvals.Lookup(om.Pkg(), om.Name()) == nil {
+			d.report(obj, "changed receiver from value to pointer")
+		}
+	}
+}
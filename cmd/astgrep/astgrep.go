@@ -0,0 +1,161 @@
+// astgrep searches Go code for syntax patterns, such as
+// json.Unmarshal($x, $y), optionally constrained by type information.
+// See the documentation of honnef.co/go/tools/pattern for the syntax
+// of patterns and constraints.
+package main // import "honnef.co/go/tools/cmd/astgrep"
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/kisielk/gotool"
+	"golang.org/x/tools/go/buildutil"
+	"golang.org/x/tools/go/loader"
+	"honnef.co/go/tools/pattern"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [flags] <pattern> [packages]\n\n", os.Args[0])
+	fmt.Fprintln(os.Stderr, "Packages default to ./....")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	flag.PrintDefaults()
+}
+
+type stringsFlag []string
+
+func (f *stringsFlag) String() string { return strings.Join(*f, ", ") }
+
+func (f *stringsFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
+
+type result struct {
+	Position string            `json:"position"`
+	End      string            `json:"end"`
+	Text     string            `json:"text"`
+	Bindings map[string]string `json:"bindings,omitempty"`
+
+	pos token.Position
+}
+
+func main() {
+	var tags buildutil.TagsFlag
+	var where stringsFlag
+	flag.Var(&tags, "tags", "List of build tags")
+	flag.Var(&where, "where", "Constrain a wildcard, as in '$y: !kind(pointer)'. Can be repeated.")
+	tests := flag.Bool("tests", false, "Include tests")
+	asJSON := flag.Bool("json", false, "Print results as JSON, including the code bound to wildcards")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	p, err := pattern.Parse(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	for _, c := range where {
+		if err := p.Where(c); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+	args := flag.Args()[1:]
+	if len(args) == 0 {
+		args = []string{"./..."}
+	}
+
+	ctx := build.Default
+	ctx.BuildTags = tags
+	conf := &loader.Config{
+		Build:       &ctx,
+		ImportPkgs:  map[string]bool{},
+		AllowErrors: true,
+	}
+	// Searching code doesn't need it to be free of type errors.
+	conf.TypeChecker.Error = func(error) {}
+	for _, path := range gotool.ImportPaths(args) {
+		conf.ImportPkgs[path] = *tests
+	}
+	lprog, err := conf.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	sources := map[string][]byte{}
+	text := func(n ast.Node) string {
+		pos, end := lprog.Fset.Position(n.Pos()), lprog.Fset.Position(n.End())
+		src, ok := sources[pos.Filename]
+		if !ok {
+			src, _ = ioutil.ReadFile(pos.Filename)
+			sources[pos.Filename] = src
+		}
+		if !pos.IsValid() || end.Offset > len(src) || pos.Offset > end.Offset {
+			return ""
+		}
+		return string(src[pos.Offset:end.Offset])
+	}
+
+	var results []result
+	for _, info := range lprog.InitialPackages() {
+		for _, f := range info.Files {
+			for _, m := range p.Find(f, info.Pkg, &info.Info) {
+				pos := lprog.Fset.Position(m.Node.Pos())
+				r := result{
+					Position: pos.String(),
+					End:      lprog.Fset.Position(m.Node.End()).String(),
+					Text:     text(m.Node),
+					pos:      pos,
+				}
+				if len(m.Bindings) > 0 {
+					r.Bindings = map[string]string{}
+					for name, n := range m.Bindings {
+						r.Bindings[name] = text(n)
+					}
+				}
+				results = append(results, r)
+			}
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		pi, pj := results[i].pos, results[j].pos
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		return pi.Offset < pj.Offset
+	})
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	if *asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		enc.Encode(results)
+	} else {
+		for _, r := range results {
+			line := r.Text
+			if i := strings.IndexByte(line, '\n'); i != -1 {
+				line = line[:i]
+			}
+			fmt.Fprintf(w, "%s: %s\n", r.Position, line)
+		}
+	}
+	if len(results) == 0 {
+		w.Flush()
+		os.Exit(1)
+	}
+}
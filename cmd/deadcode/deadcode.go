@@ -0,0 +1,190 @@
+// deadcode reports functions and methods that can't be reached from
+// the entry points of a program.
+//
+// Unlike unused, which considers a function used as soon as it is
+// referred to, deadcode builds a call graph of the whole program with
+// rapid type analysis, starting at the main and init functions of the
+// main packages, and optionally at the tests. Functions that are only
+// called by other dead functions are dead, too.
+package main // import "honnef.co/go/tools/cmd/deadcode"
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/build"
+	"go/types"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kisielk/gotool"
+	"golang.org/x/tools/go/buildutil"
+	"golang.org/x/tools/go/loader"
+	"honnef.co/go/tools/callgraph"
+	"honnef.co/go/tools/callgraph/rta"
+	"honnef.co/go/tools/ssa"
+	"honnef.co/go/tools/ssa/ssautil"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [flags] <packages>\n\n", os.Args[0])
+	fmt.Fprintln(os.Stderr, "Flags:")
+	flag.PrintDefaults()
+}
+
+type deadFunc struct {
+	Position string `json:"position"`
+	Package  string `json:"package"`
+	Name     string `json:"name"`
+}
+
+func main() {
+	var tags buildutil.TagsFlag
+	flag.Var(&tags, "tags", "List of build tags")
+	tests := flag.Bool("tests", false, "Treat the tests of the packages as entry points, too")
+	filter := flag.String("filter", "", "Only report functions in packages whose import paths match the `regexp` (default: all packages outside of the standard library and vendor directories)")
+	asJSON := flag.Bool("json", false, "Print results as JSON")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	var re *regexp.Regexp
+	if *filter != "" {
+		var err error
+		re, err = regexp.Compile(*filter)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "invalid -filter:", err)
+			os.Exit(2)
+		}
+	}
+
+	ctx := build.Default
+	ctx.BuildTags = tags
+	conf := &loader.Config{
+		Build:      &ctx,
+		ImportPkgs: map[string]bool{},
+	}
+	for _, path := range gotool.ImportPaths(flag.Args()) {
+		conf.ImportPkgs[path] = *tests
+	}
+	lprog, err := conf.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	prog := ssautil.CreateProgram(lprog, 0)
+	prog.Build()
+
+	var initial []*ssa.Package
+	for _, info := range lprog.InitialPackages() {
+		initial = append(initial, prog.Package(info.Pkg))
+	}
+	roots := callgraph.MainRoots(initial)
+	if *tests {
+		roots = append(roots, testRoots(initial)...)
+	}
+	if len(roots) == 0 {
+		fmt.Fprintln(os.Stderr, "no entry points: none of the packages is a main package; use -tests to start at tests")
+		os.Exit(1)
+	}
+	res := rta.Analyze(roots, false)
+
+	report := func(pkg *types.Package) bool {
+		if re != nil {
+			return re.MatchString(pkg.Path())
+		}
+		if strings.Contains(pkg.Path(), "/vendor/") || strings.HasPrefix(pkg.Path(), "vendor/") {
+			return false
+		}
+		bpkg, err := ctx.Import(pkg.Path(), "", build.FindOnly)
+		return err == nil && !bpkg.Goroot
+	}
+	reported := map[*types.Package]bool{}
+
+	var dead []deadFunc
+	for fn := range ssautil.AllFunctions(prog) {
+		if _, ok := res.Reachable[fn]; ok {
+			continue
+		}
+		// Anonymous functions are dead with their parents, and
+		// synthetic ones have no source to delete.
+		if fn.Pkg == nil || fn.Synthetic != "" || fn.Parent() != nil || !fn.Pos().IsValid() {
+			continue
+		}
+		if fn.Name() == "init" && fn.Signature.Recv() == nil {
+			continue
+		}
+		pkg := fn.Pkg.Pkg
+		r, ok := reported[pkg]
+		if !ok {
+			r = report(pkg)
+			reported[pkg] = r
+		}
+		if !r {
+			continue
+		}
+		dead = append(dead, deadFunc{
+			Position: prog.Fset.Position(fn.Pos()).String(),
+			Package:  pkg.Path(),
+			Name:     fn.RelString(pkg),
+		})
+	}
+	sort.Slice(dead, func(i, j int) bool {
+		if dead[i].Package != dead[j].Package {
+			return dead[i].Package < dead[j].Package
+		}
+		return dead[i].Position < dead[j].Position
+	})
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	if *asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		enc.Encode(dead)
+		return
+	}
+	for _, fn := range dead {
+		fmt.Fprintf(w, "%s: unreachable func: %s\n", fn.Position, fn.Name)
+	}
+}
+
+// testRoots returns the init functions of the packages and the
+// functions that go test calls: tests, benchmarks and examples.
+func testRoots(pkgs []*ssa.Package) []*ssa.Function {
+	var roots []*ssa.Function
+	for _, pkg := range pkgs {
+		if pkg == nil {
+			continue
+		}
+		for _, m := range pkg.Members {
+			fn, ok := m.(*ssa.Function)
+			if !ok {
+				continue
+			}
+			if fn.Name() == "init" || isTestFunc(fn) {
+				roots = append(roots, fn)
+			}
+		}
+	}
+	return roots
+}
+
+func isTestFunc(fn *ssa.Function) bool {
+	f := fn.Prog.Fset.File(fn.Pos())
+	if f == nil || !strings.HasSuffix(f.Name(), "_test.go") {
+		return false
+	}
+	for _, prefix := range []string{"Test", "Benchmark", "Example"} {
+		if strings.HasPrefix(fn.Name(), prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,320 @@
+// docjson prints the documentation of Go packages as JSON, for doc
+// sites and other tools that would otherwise have to scrape the HTML
+// of godoc.
+package main // import "honnef.co/go/tools/cmd/docjson"
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/doc"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kisielk/gotool"
+	"golang.org/x/tools/go/buildutil"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [flags] [packages]\n\n", os.Args[0])
+	fmt.Fprintln(os.Stderr, "Packages default to ./....")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	flag.PrintDefaults()
+}
+
+type Package struct {
+	ImportPath string     `json:"import_path"`
+	Name       string     `json:"name"`
+	Doc        string     `json:"doc"`
+	Synopsis   string     `json:"synopsis"`
+	Position   string     `json:"position"`
+	Consts     []*Value   `json:"consts,omitempty"`
+	Vars       []*Value   `json:"vars,omitempty"`
+	Funcs      []*Func    `json:"funcs,omitempty"`
+	Types      []*Type    `json:"types,omitempty"`
+	Examples   []*Example `json:"examples,omitempty"`
+	Notes      []*Note    `json:"notes,omitempty"`
+}
+
+// A Value is a declaration of one or more constants or variables.
+type Value struct {
+	Names    []string `json:"names"`
+	Doc      string   `json:"doc"`
+	Decl     string   `json:"decl"`
+	Position string   `json:"position"`
+}
+
+type Func struct {
+	Name string `json:"name"`
+	// Recv is the receiver of methods, as in *T.
+	Recv     string     `json:"recv,omitempty"`
+	Doc      string     `json:"doc"`
+	Decl     string     `json:"decl"`
+	Position string     `json:"position"`
+	Examples []*Example `json:"examples,omitempty"`
+}
+
+type Type struct {
+	Name     string     `json:"name"`
+	Doc      string     `json:"doc"`
+	Decl     string     `json:"decl"`
+	Position string     `json:"position"`
+	Consts   []*Value   `json:"consts,omitempty"`
+	Vars     []*Value   `json:"vars,omitempty"`
+	Funcs    []*Func    `json:"funcs,omitempty"`
+	Methods  []*Func    `json:"methods,omitempty"`
+	Examples []*Example `json:"examples,omitempty"`
+}
+
+type Example struct {
+	// Name is the part of the example function's name after the
+	// documented identifier, as in "Reader_suffix" for
+	// ExampleReader_suffix.
+	Name   string `json:"name"`
+	Doc    string `json:"doc"`
+	Code   string `json:"code"`
+	Output string `json:"output,omitempty"`
+	// Unordered reports whether the lines of the output may appear
+	// in any order.
+	Unordered bool `json:"unordered,omitempty"`
+}
+
+// A Note is a marked comment, such as BUG(who): or TODO(who):.
+type Note struct {
+	Marker   string `json:"marker"`
+	UID      string `json:"uid"`
+	Body     string `json:"body"`
+	Position string `json:"position"`
+}
+
+func main() {
+	var tags buildutil.TagsFlag
+	flag.Var(&tags, "tags", "List of build tags")
+	unexported := flag.Bool("unexported", false, "Include unexported declarations")
+	flag.Usage = usage
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		args = []string{"./..."}
+	}
+	ctx := build.Default
+	ctx.BuildTags = tags
+	wd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	var mode doc.Mode
+	if *unexported {
+		mode = doc.AllDecls | doc.AllMethods
+	}
+
+	failed := false
+	out := []*Package{}
+	for _, path := range gotool.ImportPaths(args) {
+		bpkg, err := ctx.Import(path, wd, 0)
+		if err != nil {
+			if _, ok := err.(*build.NoGoError); !ok {
+				fmt.Fprintln(os.Stderr, err)
+				failed = true
+			}
+			continue
+		}
+		pkg, err := document(bpkg, mode)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed = true
+			continue
+		}
+		out = append(out, pkg)
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	enc.Encode(out)
+	w.Flush()
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// document parses the files of bpkg and extracts its documentation.
+func document(bpkg *build.Package, mode doc.Mode) (*Package, error) {
+	fset := token.NewFileSet()
+	parse := func(names []string) ([]*ast.File, error) {
+		var files []*ast.File
+		for _, name := range names {
+			f, err := parser.ParseFile(fset, filepath.Join(bpkg.Dir, name), nil, parser.ParseComments)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, f)
+		}
+		return files, nil
+	}
+	files, err := parse(append(append([]string(nil), bpkg.GoFiles...), bpkg.CgoFiles...))
+	if err != nil {
+		return nil, err
+	}
+	testFiles, err := parse(append(append([]string(nil), bpkg.TestGoFiles...), bpkg.XTestGoFiles...))
+	if err != nil {
+		return nil, err
+	}
+	astPkg := &ast.Package{Name: bpkg.Name, Files: map[string]*ast.File{}}
+	for _, f := range files {
+		astPkg.Files[fset.File(f.Pos()).Name()] = f
+	}
+	// doc.New takes ownership of the AST and removes the bodies of
+	// functions.
+	dpkg := doc.New(astPkg, bpkg.ImportPath, mode)
+
+	e := &exporter{fset: fset, examples: map[string][]*Example{}}
+	for _, ex := range doc.Examples(testFiles...) {
+		e.addExample(ex)
+	}
+	pkg := &Package{
+		ImportPath: bpkg.ImportPath,
+		Name:       dpkg.Name,
+		Doc:        dpkg.Doc,
+		Synopsis:   doc.Synopsis(dpkg.Doc),
+		Consts:     e.values(dpkg.Consts),
+		Vars:       e.values(dpkg.Vars),
+		Funcs:      e.funcs(dpkg.Funcs),
+		Examples:   e.examples[""],
+	}
+	if len(files) > 0 {
+		// The position of the package is that of its first file.
+		pkg.Position = e.position(files[0].Package)
+	}
+	for _, t := range dpkg.Types {
+		pkg.Types = append(pkg.Types, &Type{
+			Name:     t.Name,
+			Doc:      t.Doc,
+			Decl:     e.render(t.Decl),
+			Position: e.position(t.Decl.Pos()),
+			Consts:   e.values(t.Consts),
+			Vars:     e.values(t.Vars),
+			Funcs:    e.funcs(t.Funcs),
+			Methods:  e.funcs(t.Methods),
+			Examples: e.examples[t.Name],
+		})
+	}
+	var markers []string
+	for marker := range dpkg.Notes {
+		markers = append(markers, marker)
+	}
+	sort.Strings(markers)
+	for _, marker := range markers {
+		for _, n := range dpkg.Notes[marker] {
+			pkg.Notes = append(pkg.Notes, &Note{
+				Marker:   marker,
+				UID:      n.UID,
+				Body:     n.Body,
+				Position: e.position(n.Pos),
+			})
+		}
+	}
+	return pkg, nil
+}
+
+type exporter struct {
+	fset *token.FileSet
+	// examples maps the documented identifiers, such as Reader or
+	// Reader_Read, to their examples. Examples of the package are
+	// keyed by the empty string.
+	examples map[string][]*Example
+}
+
+func (e *exporter) addExample(ex *doc.Example) {
+	var code string
+	switch {
+	case ex.Play != nil:
+		code = e.render(ex.Play)
+	default:
+		code = e.render(&printer.CommentedNode{Node: ex.Code, Comments: ex.Comments})
+		if _, ok := ex.Code.(*ast.BlockStmt); ok {
+			code = unblock(code)
+		}
+	}
+	// The name of ExampleT_M_suffix is "T_M_suffix"; the suffix
+	// starts with a lowercase letter.
+	id, suffix := ex.Name, ""
+	if i := strings.LastIndex(id, "_"); i != -1 && i+1 < len(id) && !ast.IsExported(id[i+1:]) {
+		id, suffix = id[:i], id[i+1:]
+	}
+	e.examples[id] = append(e.examples[id], &Example{
+		Name:      suffix,
+		Doc:       ex.Doc,
+		Code:      code,
+		Output:    ex.Output,
+		Unordered: ex.Unordered,
+	})
+}
+
+func (e *exporter) values(vs []*doc.Value) []*Value {
+	var out []*Value
+	for _, v := range vs {
+		out = append(out, &Value{
+			Names:    v.Names,
+			Doc:      v.Doc,
+			Decl:     e.render(v.Decl),
+			Position: e.position(v.Decl.Pos()),
+		})
+	}
+	return out
+}
+
+func (e *exporter) funcs(fns []*doc.Func) []*Func {
+	var out []*Func
+	for _, fn := range fns {
+		id := fn.Name
+		if fn.Recv != "" {
+			id = strings.TrimPrefix(fn.Recv, "*") + "_" + fn.Name
+		}
+		out = append(out, &Func{
+			Name:     fn.Name,
+			Recv:     fn.Recv,
+			Doc:      fn.Doc,
+			Decl:     e.render(fn.Decl),
+			Position: e.position(fn.Decl.Pos()),
+			Examples: e.examples[id],
+		})
+	}
+	return out
+}
+
+func (e *exporter) position(pos token.Pos) string {
+	return e.fset.Position(pos).String()
+}
+
+func (e *exporter) render(node interface{}) string {
+	var buf bytes.Buffer
+	// Format like gofmt does.
+	cfg := &printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+	cfg.Fprint(&buf, e.fset, node)
+	return buf.String()
+}
+
+// unblock removes the braces around and the indentation of a block,
+// as godoc shows the code of examples.
+func unblock(code string) string {
+	code = strings.TrimSpace(code)
+	code = strings.TrimPrefix(code, "{")
+	code = strings.TrimSuffix(code, "}")
+	lines := strings.Split(strings.Trim(code, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(line, "\t")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,224 @@
+// implements lists the types that implement an interface, or the
+// interfaces that a type implements.
+package main // import "honnef.co/go/tools/cmd/implements"
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/build"
+	"go/types"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/kisielk/gotool"
+	"golang.org/x/tools/go/buildutil"
+	"golang.org/x/tools/go/loader"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [flags] <type> [packages]\n\n", os.Args[0])
+	fmt.Fprintln(os.Stderr, "The type is named by its package's import path and its name, as in io.Reader or (*net/http.Request),")
+	fmt.Fprintln(os.Stderr, "or is a predeclared type such as error.")
+	fmt.Fprintln(os.Stderr, "Packages default to ./....")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	flag.PrintDefaults()
+}
+
+type match struct {
+	Position string `json:"position"`
+	Name     string `json:"name"`
+	// Kind is either "interface" or "type".
+	Kind string `json:"kind"`
+	// Pointer reports whether only the pointer to the type
+	// implements the interface.
+	Pointer bool `json:"pointer"`
+}
+
+func main() {
+	var tags buildutil.TagsFlag
+	flag.Var(&tags, "tags", "List of build tags")
+	tests := flag.Bool("tests", false, "Include tests")
+	asJSON := flag.Bool("json", false, "Print results as JSON")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	path, name, ok := parseType(flag.Arg(0))
+	if !ok {
+		fmt.Fprintf(os.Stderr, "invalid type %q\n", flag.Arg(0))
+		os.Exit(2)
+	}
+	args := flag.Args()[1:]
+	if len(args) == 0 {
+		args = []string{"./..."}
+	}
+
+	ctx := build.Default
+	ctx.BuildTags = tags
+	conf := &loader.Config{
+		Build:      &ctx,
+		ImportPkgs: map[string]bool{},
+	}
+	for _, path := range gotool.ImportPaths(args) {
+		conf.ImportPkgs[path] = *tests
+	}
+	if _, ok := conf.ImportPkgs[path]; !ok && path != "" {
+		conf.Import(path)
+	}
+	lprog, err := conf.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	scope := types.Universe
+	if path != "" {
+		pkg := lprog.Package(path)
+		if pkg == nil {
+			fmt.Fprintf(os.Stderr, "couldn't find package %s\n", path)
+			os.Exit(1)
+		}
+		scope = pkg.Pkg.Scope()
+	}
+	obj, ok := scope.Lookup(name).(*types.TypeName)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "%s.%s isn't a type\n", path, name)
+		os.Exit(1)
+	}
+	matches := implementations(lprog, obj)
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	if *asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		enc.Encode(matches)
+		return
+	}
+	for _, m := range matches {
+		if m.Pointer {
+			fmt.Fprintf(w, "%s: %s %s (pointer)\n", m.Position, m.Kind, m.Name)
+		} else {
+			fmt.Fprintf(w, "%s: %s %s\n", m.Position, m.Kind, m.Name)
+		}
+	}
+}
+
+// implementations returns the types that implement obj, if it is an
+// interface, or else the interfaces that obj implements, sorted by
+// name.
+func implementations(lprog *loader.Program, obj *types.TypeName) []match {
+	T := obj.Type()
+
+	// Implementations are looked for among the types of the packages
+	// named on the command line, interfaces among those of all
+	// packages, including dependencies such as the standard library.
+	var candidates []*loader.PackageInfo
+	if types.IsInterface(T) {
+		candidates = lprog.InitialPackages()
+	} else {
+		for _, info := range lprog.AllPackages {
+			candidates = append(candidates, info)
+		}
+	}
+	f := &finder{ms: &typeutil.MethodSetCache{}}
+	var matches []match
+	seen := map[*types.TypeName]bool{}
+	for _, info := range candidates {
+		scope := info.Pkg.Scope()
+		for _, n := range scope.Names() {
+			cand, ok := scope.Lookup(n).(*types.TypeName)
+			if !ok || cand == obj || seen[cand] {
+				continue
+			}
+			seen[cand] = true
+			var impl types.Type
+			if types.IsInterface(T) {
+				impl = f.implements(cand.Type(), T)
+			} else {
+				impl = f.implements(T, cand.Type())
+			}
+			if impl == nil {
+				continue
+			}
+			_, ptr := impl.(*types.Pointer)
+			kind := "type"
+			if types.IsInterface(cand.Type()) {
+				kind = "interface"
+			}
+			matches = append(matches, match{
+				Position: lprog.Fset.Position(cand.Pos()).String(),
+				Name:     types.TypeString(cand.Type(), nil),
+				Kind:     kind,
+				Pointer:  ptr,
+			})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Name < matches[j].Name
+	})
+	return matches
+}
+
+// parseType splits a type name such as (*net/http.Request) into its
+// package path and name. Predeclared types, such as error, have an
+// empty path.
+func parseType(s string) (path, name string, ok bool) {
+	s = strings.TrimPrefix(strings.TrimSuffix(s, ")"), "(")
+	s = strings.TrimPrefix(s, "*")
+	if _, ok := types.Universe.Lookup(s).(*types.TypeName); ok {
+		return "", s, true
+	}
+	i := strings.LastIndex(s, ".")
+	if i <= 0 || i == len(s)-1 || strings.LastIndex(s, "/") > i {
+		return "", "", false
+	}
+	return s[:i], s[i+1:], true
+}
+
+type finder struct {
+	ms *typeutil.MethodSetCache
+}
+
+// implements returns T or *T, whichever implements the interface I,
+// or nil if neither does. Empty interfaces and interfaces identical
+// to T are skipped, as they aren't interesting.
+func (f *finder) implements(T, I types.Type) types.Type {
+	iface, ok := I.Underlying().(*types.Interface)
+	if !ok || iface.NumMethods() == 0 {
+		return nil
+	}
+	if types.IsInterface(T) {
+		if types.Identical(T.Underlying(), iface) || !f.hasMethods(T, iface) {
+			return nil
+		}
+		return T
+	}
+	if f.hasMethods(T, iface) {
+		return T
+	}
+	if ptr := types.NewPointer(T); f.hasMethods(ptr, iface) {
+		return ptr
+	}
+	return nil
+}
+
+// hasMethods reports whether the method set of T contains all methods
+// of iface.
+func (f *finder) hasMethods(T types.Type, iface *types.Interface) bool {
+	mset := f.ms.MethodSet(T)
+	for i := 0; i < iface.NumMethods(); i++ {
+		m := iface.Method(i)
+		sel := mset.Lookup(m.Pkg(), m.Name())
+		if sel == nil || !types.Identical(sel.Type(), m.Type()) {
+			return false
+		}
+	}
+	return true
+}
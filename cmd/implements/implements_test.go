@@ -0,0 +1,74 @@
+package main
+
+import (
+	"go/types"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/go/loader"
+)
+
+func TestParseType(t *testing.T) {
+	tests := []struct {
+		in         string
+		path, name string
+		ok         bool
+	}{
+		{"io.Reader", "io", "Reader", true},
+		{"(*net/http.Request)", "net/http", "Request", true},
+		{"error", "", "error", true},
+		{"(*error)", "", "error", true},
+		{"errors", "", "", false},
+		{"io.", "", "", false},
+		{"example.com/pkg", "", "", false},
+	}
+	for _, tt := range tests {
+		path, name, ok := parseType(tt.in)
+		if path != tt.path || name != tt.name || ok != tt.ok {
+			t.Errorf("parseType(%q) = %q, %q, %t, want %q, %q, %t", tt.in, path, name, ok, tt.path, tt.name, tt.ok)
+		}
+	}
+}
+
+func TestImplementationsOfError(t *testing.T) {
+	const src = `package pkg
+
+type E struct{}
+
+func (E) Error() string { return "" }
+
+type P struct{}
+
+func (*P) Error() string { return "" }
+
+type I interface {
+	Error() string
+	Code() int
+}
+
+type N struct{}
+`
+	conf := &loader.Config{}
+	f, err := conf.ParseFile("pkg.go", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.CreateFromFiles("pkg", f)
+	lprog, err := conf.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, m := range implementations(lprog, types.Universe.Lookup("error").(*types.TypeName)) {
+		s := m.Kind + " " + m.Name
+		if m.Pointer {
+			s += " (pointer)"
+		}
+		got = append(got, s)
+	}
+	want := []string{"type pkg.E", "interface pkg.I", "type pkg.P (pointer)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,256 @@
+// importgraph prints the import graph of a set of packages as DOT,
+// GraphML or JSON, for visualizing and reviewing the architecture of
+// a code base.
+package main // import "honnef.co/go/tools/cmd/importgraph"
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"go/build"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/kisielk/gotool"
+	"golang.org/x/tools/go/buildutil"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [flags] [packages]\n\n", os.Args[0])
+	fmt.Fprintln(os.Stderr, "Packages default to ./....")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	flag.PrintDefaults()
+}
+
+type stringsFlag []string
+
+func (f *stringsFlag) String() string { return strings.Join(*f, ", ") }
+
+func (f *stringsFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
+
+// A node is a package, or a group of packages sharing an import path
+// prefix.
+type node struct {
+	Name    string   `json:"name"`
+	Imports []string `json:"imports"`
+	FanIn   int      `json:"fan_in"`
+	FanOut  int      `json:"fan_out"`
+	// Highlight reports whether the fan-in or fan-out of the node
+	// exceeds the threshold.
+	Highlight bool `json:"highlight,omitempty"`
+}
+
+func main() {
+	var tags buildutil.TagsFlag
+	var collapse stringsFlag
+	flag.Var(&tags, "tags", "List of build tags")
+	flag.Var(&collapse, "collapse", "Collapse the packages below the import path `prefix` into one node. Can be repeated.")
+	tests := flag.Bool("tests", false, "Include the imports of tests")
+	format := flag.String("format", "dot", "Output `format`: dot, graphml or json")
+	maxFanIn := flag.Int("fan-in", 0, "Highlight packages imported by more than `n` packages (0 disables)")
+	maxFanOut := flag.Int("fan-out", 0, "Highlight packages importing more than `n` packages (0 disables)")
+	flag.Usage = usage
+	flag.Parse()
+
+	var write func(io.Writer, []*node) error
+	switch *format {
+	case "dot":
+		write = writeDOT
+	case "graphml":
+		write = writeGraphML
+	case "json":
+		write = writeJSON
+	default:
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		os.Exit(2)
+	}
+	args := flag.Args()
+	if len(args) == 0 {
+		args = []string{"./..."}
+	}
+
+	ctx := build.Default
+	ctx.BuildTags = tags
+	wd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// Only imports among the packages are part of the graph.
+	var pkgs []*build.Package
+	known := map[string]bool{}
+	for _, path := range gotool.ImportPaths(args) {
+		bpkg, err := ctx.Import(path, wd, 0)
+		if err != nil {
+			if _, ok := err.(*build.NoGoError); !ok {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			continue
+		}
+		if !known[bpkg.ImportPath] {
+			known[bpkg.ImportPath] = true
+			pkgs = append(pkgs, bpkg)
+		}
+	}
+	name := func(path string) string {
+		for _, prefix := range collapse {
+			prefix = strings.TrimSuffix(prefix, "/")
+			if path == prefix || strings.HasPrefix(path, prefix+"/") {
+				return prefix
+			}
+		}
+		return path
+	}
+	edges := map[string]map[string]bool{}
+	for _, bpkg := range pkgs {
+		from := name(bpkg.ImportPath)
+		if edges[from] == nil {
+			edges[from] = map[string]bool{}
+		}
+		imports := bpkg.Imports
+		if *tests {
+			imports = append(append(imports[:len(imports):len(imports)], bpkg.TestImports...), bpkg.XTestImports...)
+		}
+		for _, imp := range imports {
+			if imp == "C" {
+				continue
+			}
+			// Resolve vendored imports to their canonical paths.
+			dep, err := ctx.Import(imp, bpkg.Dir, build.FindOnly)
+			if err != nil || !known[dep.ImportPath] {
+				continue
+			}
+			if to := name(dep.ImportPath); to != from {
+				edges[from][to] = true
+			}
+		}
+	}
+
+	nodes := map[string]*node{}
+	var sorted []*node
+	for from := range edges {
+		n := &node{Name: from, Imports: []string{}}
+		nodes[from] = n
+		sorted = append(sorted, n)
+	}
+	for from, tos := range edges {
+		for to := range tos {
+			nodes[from].Imports = append(nodes[from].Imports, to)
+			nodes[from].FanOut++
+			nodes[to].FanIn++
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	for _, n := range sorted {
+		sort.Strings(n.Imports)
+		n.Highlight = (*maxFanIn > 0 && n.FanIn > *maxFanIn) || (*maxFanOut > 0 && n.FanOut > *maxFanOut)
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	if err := write(w, sorted); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func writeDOT(w io.Writer, nodes []*node) error {
+	fmt.Fprintln(w, "digraph imports {")
+	for _, n := range nodes {
+		attrs := fmt.Sprintf("tooltip=\"fan-in %d, fan-out %d\"", n.FanIn, n.FanOut)
+		if n.Highlight {
+			attrs += ", color=red, style=filled, fillcolor=\"#ffdddd\""
+		}
+		fmt.Fprintf(w, "\t%q [%s];\n", n.Name, attrs)
+	}
+	for _, n := range nodes {
+		for _, imp := range n.Imports {
+			fmt.Fprintf(w, "\t%q -> %q;\n", n.Name, imp)
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+func writeJSON(w io.Writer, nodes []*node) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(nodes)
+}
+
+type graphML struct {
+	XMLName xml.Name    `xml:"graphml"`
+	XMLNS   string      `xml:"xmlns,attr"`
+	Keys    []graphKey  `xml:"key"`
+	Graph   graphMLBody `xml:"graph"`
+}
+
+type graphKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLBody struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+func writeGraphML(w io.Writer, nodes []*node) error {
+	g := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphKey{
+			{"fan_in", "node", "fan_in", "int"},
+			{"fan_out", "node", "fan_out", "int"},
+			{"highlight", "node", "highlight", "boolean"},
+		},
+		Graph: graphMLBody{ID: "imports", EdgeDefault: "directed"},
+	}
+	for _, n := range nodes {
+		g.Graph.Nodes = append(g.Graph.Nodes, graphMLNode{
+			ID: n.Name,
+			Data: []graphMLData{
+				{"fan_in", fmt.Sprint(n.FanIn)},
+				{"fan_out", fmt.Sprint(n.FanOut)},
+				{"highlight", fmt.Sprint(n.Highlight)},
+			},
+		})
+		for _, imp := range n.Imports {
+			g.Graph.Edges = append(g.Graph.Edges, graphMLEdge{n.Name, imp})
+		}
+	}
+	io.WriteString(w, xml.Header)
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if err := enc.Encode(g); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
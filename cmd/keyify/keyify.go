@@ -1,4 +1,5 @@
-// keyify transforms unkeyed struct literals into a keyed ones.
+// keyify transforms unkeyed struct literals into a keyed ones, or
+// vice versa.
 package main
 
 import (
@@ -9,12 +10,17 @@ import (
 	"go/ast"
 	"go/build"
 	"go/constant"
+	"go/parser"
 	"go/printer"
 	"go/token"
 	"go/types"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
+
+	"honnef.co/go/tools/internal/diff"
+	"honnef.co/go/tools/lint"
 
 	"golang.org/x/tools/go/ast/astutil"
 	"golang.org/x/tools/go/buildutil"
@@ -27,18 +33,26 @@ var (
 	fJSON      bool
 	fMinify    bool
 	fModified  bool
+	fUnkeyify  bool
+	fDryRun    bool
+	fFill      bool
+	fArchive   bool
 )
 
 func init() {
-	flag.BoolVar(&fRecursive, "r", false, "keyify struct initializers recursively")
+	flag.BoolVar(&fRecursive, "r", false, "keyify nested struct initializers, such as elements of slices and maps, as well")
 	flag.BoolVar(&fOneLine, "o", false, "print new struct initializer on a single line")
-	flag.BoolVar(&fJSON, "json", false, "print new struct initializer as JSON")
+	flag.BoolVar(&fJSON, "json", false, "print new struct initializer as JSON or, when converting files or packages, the edits as a JSON list")
 	flag.BoolVar(&fMinify, "m", false, "omit fields that are set to their zero value")
+	flag.BoolVar(&fFill, "z", false, "fill in fields that are missing from keyed struct initializers with their zero values")
+	flag.BoolVar(&fUnkeyify, "u", false, "turn keyed struct initializers that set all fields in declaration order into unkeyed ones instead")
+	flag.BoolVar(&fDryRun, "dry-run", false, "print a diff of the changes instead of writing them or printing the new initializer")
 	flag.BoolVar(&fModified, "modified", false, "read an archive of modified files from standard input")
+	flag.BoolVar(&fArchive, "archive", false, "write the changed files to standard output as an archive, in the format read by -modified, instead of writing them or printing the new initializer")
 }
 
 func usage() {
-	fmt.Printf("Usage: %s [flags] <position>\n\n", os.Args[0])
+	fmt.Printf("Usage: %s [flags] <position|file|package>\n\n", os.Args[0])
 	flag.PrintDefaults()
 }
 
@@ -50,22 +64,14 @@ func main() {
 		flag.Usage()
 		os.Exit(2)
 	}
-	pos := flag.Args()[0]
-	name, start, _, err := parsePos(pos)
-	if err != nil {
-		log.Fatal(err)
-	}
-	eval, err := filepath.EvalSymlinks(name)
-	if err != nil {
-		log.Fatal(err)
+	if fMinify && fFill {
+		log.Fatal("-m and -z can't be combined")
 	}
-	name, err = filepath.Abs(eval)
-	if err != nil {
-		log.Fatal(err)
+	if fArchive && (fJSON || fDryRun) {
+		log.Fatal("-archive can't be combined with -json or -dry-run")
 	}
-	cwd, err := os.Getwd()
-	if err != nil {
-		log.Fatal(err)
+	if fJSON && fDryRun {
+		log.Fatal("-json and -dry-run can't be combined")
 	}
 	ctx := &build.Default
 	if fModified {
@@ -75,21 +81,25 @@ func main() {
 		}
 		ctx = buildutil.OverlayContext(ctx, overlay)
 	}
-	bpkg, err := buildutil.ContainingPackage(ctx, cwd, name)
+	pos := flag.Args()[0]
+	if !strings.Contains(pos, ":#") {
+		rewrite(ctx, pos)
+		return
+	}
+	name, start, _, err := parsePos(pos)
 	if err != nil {
 		log.Fatal(err)
 	}
-	conf := &loader.Config{
-		Build: ctx,
-	}
-	conf.TypeCheckFuncBodies = func(s string) bool {
-		return s == bpkg.ImportPath || s == bpkg.ImportPath+"_test"
+	name = absPath(name)
+	cwd, err := os.Getwd()
+	if err != nil {
+		log.Fatal(err)
 	}
-	conf.ImportWithTests(bpkg.ImportPath)
-	lprog, err := conf.Load()
+	bpkg, err := buildutil.ContainingPackage(ctx, cwd, name)
 	if err != nil {
 		log.Fatal(err)
 	}
+	lprog := load(ctx, bpkg)
 	var tf *token.File
 	var af *ast.File
 	pkg := lprog.InitialPackages()[0]
@@ -101,6 +111,9 @@ func main() {
 			break
 		}
 	}
+	if tf == nil {
+		log.Fatalf("%s isn't part of package %s", name, bpkg.ImportPath)
+	}
 	tstart, tend, err := fileOffsetToPos(tf, start, start)
 	if err != nil {
 		log.Fatal(err)
@@ -116,79 +129,429 @@ func main() {
 	if complit == nil {
 		log.Fatal("no composite literal found near point")
 	}
-	if len(complit.Elts) == 0 {
-		printComplit(complit, complit, lprog.Fset, lprog.Fset)
+	k := &keyifier{pkg: pkg, recursive: fRecursive, oneLine: fOneLine}
+	_, isStruct := pkg.TypeOf(complit).Underlying().(*types.Struct)
+	var replacement string
+	switch {
+	case len(complit.Elts) == 0 && !fFill:
+		replacement = printExpr(lprog.Fset, complit)
+	case k.convertible(complit):
+		replacement = k.print(k.convert(complit))
+	case k.recursive && k.needsChange(complit):
+		replacement = k.print(k.expr(complit))
+	case !isStruct:
+		log.Fatal("not a struct initialiser")
 		return
-	}
-	if _, ok := complit.Elts[0].(*ast.KeyValueExpr); ok {
+	case fUnkeyify && isKeyed(complit):
+		log.Fatal("struct initialiser doesn't set all fields in declaration order")
+		return
+	default:
+		// the literal already has the desired form
 		lit := complit
 		if fOneLine {
 			lit = copyExpr(complit, 1).(*ast.CompositeLit)
 		}
-		printComplit(complit, lit, lprog.Fset, lprog.Fset)
-		return
+		replacement = printExpr(lprog.Fset, lit)
 	}
-	_, ok := pkg.TypeOf(complit.Type).Underlying().(*types.Struct)
-	if !ok {
-		log.Fatal("not a struct initialiser")
+	if fArchive || fDryRun {
+		src, err := readFile(ctx, name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		out, _, err := lint.ApplyFixes(lprog.Fset, src, []*lint.Fix{lint.Replace(complit, replacement)})
+		if err != nil {
+			log.Fatal(err)
+		}
+		if fArchive {
+			writeArchive(os.Stdout, name, out)
+		} else {
+			short := relName(name)
+			os.Stdout.Write(diff.Unified("a/"+short, "b/"+short, src, out))
+		}
 		return
 	}
+	printComplit(complit, replacement, lprog.Fset)
+}
 
-	newComplit, lines := keyify(pkg, complit)
-	newFset := token.NewFileSet()
-	newFile := newFset.AddFile("", -1, lines)
+// absPath returns the absolute path of name, with symlinks resolved.
+func absPath(name string) string {
+	eval, err := filepath.EvalSymlinks(name)
+	if err != nil {
+		log.Fatal(err)
+	}
+	name, err = filepath.Abs(eval)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return name
+}
+
+// load loads bpkg and its tests.
+func load(ctx *build.Context, bpkg *build.Package) *loader.Program {
+	conf := &loader.Config{
+		Build: ctx,
+	}
+	conf.TypeCheckFuncBodies = func(s string) bool {
+		return s == bpkg.ImportPath || s == bpkg.ImportPath+"_test"
+	}
+	conf.ImportWithTests(bpkg.ImportPath)
+	lprog, err := conf.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return lprog
+}
+
+// A keyifier builds new composite literals. Their positions don't
+// refer to a file, but denote the line that each part of a literal
+// will be printed on.
+type keyifier struct {
+	pkg       *loader.PackageInfo
+	recursive bool      // convert nested literals
+	oneLine   bool      // print literals on a single line
+	namedOnly bool      // only convert literals of named types
+	line      token.Pos // the last line that has been used
+}
+
+// print formats expr, which has been built by k.
+func (k *keyifier) print(expr ast.Expr) string {
+	fset := token.NewFileSet()
+	lines := int(k.line) + 1
+	file := fset.AddFile("", -1, lines)
 	for i := 1; i <= lines; i++ {
-		newFile.AddLine(i)
+		file.AddLine(i)
 	}
-	printComplit(complit, newComplit, lprog.Fset, newFset)
+	return printExpr(fset, expr)
 }
 
-func keyify(
-	pkg *loader.PackageInfo,
-	complit *ast.CompositeLit,
-) (*ast.CompositeLit, int) {
-	var calcPos func(int) token.Pos
-	if fOneLine {
-		calcPos = func(int) token.Pos { return token.Pos(1) }
-	} else {
-		calcPos = func(i int) token.Pos { return token.Pos(2 + i) }
+// next returns the position of the next line.
+func (k *keyifier) next() token.Pos {
+	if k.oneLine {
+		return 1
 	}
+	k.line++
+	return k.line
+}
 
-	st, _ := pkg.TypeOf(complit.Type).Underlying().(*types.Struct)
-	newComplit := &ast.CompositeLit{
-		Type:   complit.Type,
-		Lbrace: 1,
-		Rbrace: token.Pos(st.NumFields() + 2),
+// cur returns the position of the current line.
+func (k *keyifier) cur() token.Pos {
+	if k.line == 0 {
+		return k.next()
+	}
+	return k.line
+}
+
+// convertible reports whether complit is a struct literal that can be
+// converted: an unkeyed one, or in unkeyify mode, a keyed one that
+// sets all fields in declaration order.
+func (k *keyifier) convertible(complit *ast.CompositeLit) bool {
+	typ := k.pkg.TypeOf(complit)
+	if typ == nil {
+		return false
+	}
+	st, ok := typ.Underlying().(*types.Struct)
+	if !ok {
+		return false
 	}
-	if fOneLine {
-		newComplit.Rbrace = 1
+	if _, ok := typ.(*types.Named); k.namedOnly && !ok {
+		return false
+	}
+	if !fUnkeyify {
+		return !isKeyed(complit) || k.keyedChanges(complit, st)
+	}
+	if len(complit.Elts) == 0 || len(complit.Elts) != st.NumFields() {
+		return false
+	}
+	for i, elt := range complit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			return false
+		}
+		if key, ok := kv.Key.(*ast.Ident); !ok || key.Name != st.Field(i).Name() {
+			return false
+		}
+	}
+	return true
+}
+
+// keyedChanges reports whether keyifying complit, a keyed literal,
+// changes it, because fields are missing and -z is set, or because
+// fields are set to their zero values and -m is set.
+func (k *keyifier) keyedChanges(complit *ast.CompositeLit, st *types.Struct) bool {
+	values := map[string]ast.Expr{}
+	for _, elt := range complit.Elts {
+		kv := elt.(*ast.KeyValueExpr)
+		if key, ok := kv.Key.(*ast.Ident); ok {
+			values[key.Name] = kv.Value
+		}
 	}
-	numLines := 2 + st.NumFields()
-	n := 0
 	for i := 0; i < st.NumFields(); i++ {
 		field := st.Field(i)
-		val := complit.Elts[i]
-		if fRecursive {
-			if val2, ok := val.(*ast.CompositeLit); ok {
-				if _, ok := pkg.TypeOf(val2.Type).Underlying().(*types.Struct); ok {
-					var lines int
-					numLines += lines
-					val, lines = keyify(pkg, val2)
+		val, ok := values[field.Name()]
+		if !ok && fFill && k.settable(field) {
+			return true
+		}
+		if ok && k.omit(val, field.Type()) {
+			return true
+		}
+	}
+	return false
+}
+
+// settable reports whether field may be set by a literal in k's
+// package.
+func (k *keyifier) settable(field *types.Var) bool {
+	return field.Exported() || field.Pkg() == k.pkg.Pkg
+}
+
+// omit reports whether val, the value of a field of type typ, should
+// be omitted because it is the zero value.
+func (k *keyifier) omit(val ast.Expr, typ types.Type) bool {
+	if !fMinify {
+		return false
+	}
+	_, isIface := typ.Underlying().(*types.Interface)
+	return isNil(val, k.pkg) || (!isIface && isZero(val, k.pkg))
+}
+
+func (k *keyifier) convert(complit *ast.CompositeLit) *ast.CompositeLit {
+	if fUnkeyify {
+		return k.unkeyify(complit)
+	}
+	return k.keyify(complit)
+}
+
+func (k *keyifier) unkeyify(complit *ast.CompositeLit) *ast.CompositeLit {
+	newComplit := &ast.CompositeLit{
+		Type:   copyExpr(complit.Type, k.cur()),
+		Lbrace: k.cur(),
+	}
+	for _, elt := range complit.Elts {
+		newComplit.Elts = append(newComplit.Elts, k.expr(elt.(*ast.KeyValueExpr).Value))
+	}
+	newComplit.Rbrace = k.cur()
+	return newComplit
+}
+
+func (k *keyifier) keyify(complit *ast.CompositeLit) *ast.CompositeLit {
+	st, _ := k.pkg.TypeOf(complit).Underlying().(*types.Struct)
+	newComplit := &ast.CompositeLit{
+		Type:   copyExpr(complit.Type, k.cur()),
+		Lbrace: k.cur(),
+	}
+	values := make([]ast.Expr, st.NumFields())
+	if isKeyed(complit) {
+		for _, elt := range complit.Elts {
+			kv := elt.(*ast.KeyValueExpr)
+			for i := 0; i < st.NumFields(); i++ {
+				if key, ok := kv.Key.(*ast.Ident); ok && key.Name == st.Field(i).Name() {
+					values[i] = kv.Value
 				}
 			}
 		}
-		_, isIface := st.Field(i).Type().Underlying().(*types.Interface)
-		if fMinify && (isNil(val, pkg) || (!isIface && isZero(val, pkg))) {
+	} else {
+		copy(values, complit.Elts)
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		val := values[i]
+		if val == nil && (!fFill || !k.settable(field)) {
+			continue
+		}
+		if val != nil && k.omit(val, field.Type()) {
 			continue
 		}
+		pos := k.next()
 		elt := &ast.KeyValueExpr{
-			Key:   &ast.Ident{NamePos: calcPos(n), Name: field.Name()},
-			Value: copyExpr(val, calcPos(n)),
+			Key: &ast.Ident{NamePos: pos, Name: field.Name()},
+		}
+		if val == nil {
+			elt.Value = k.zero(field.Type())
+		} else {
+			elt.Value = k.expr(val)
 		}
 		newComplit.Elts = append(newComplit.Elts, elt)
-		n++
 	}
-	return newComplit, numLines
+	newComplit.Rbrace = k.next()
+	return newComplit
+}
+
+// zero returns an expression for the zero value of typ.
+func (k *keyifier) zero(typ types.Type) ast.Expr {
+	switch u := typ.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return &ast.Ident{Name: "false"}
+		case u.Info()&types.IsString != 0:
+			return &ast.BasicLit{Kind: token.STRING, Value: `""`}
+		case u.Kind() == types.UnsafePointer:
+			return &ast.Ident{Name: "nil"}
+		default:
+			return &ast.BasicLit{Kind: token.INT, Value: "0"}
+		}
+	case *types.Struct, *types.Array:
+		qualifier := func(pkg *types.Package) string {
+			if pkg == k.pkg.Pkg {
+				return ""
+			}
+			return pkg.Name()
+		}
+		texpr, err := parser.ParseExpr(types.TypeString(typ, qualifier))
+		if err != nil {
+			log.Fatalf("couldn't build zero value of %s: %s", typ, err)
+		}
+		movePos(texpr, k.cur())
+		return &ast.CompositeLit{Type: texpr}
+	default:
+		return &ast.Ident{Name: "nil"}
+	}
+}
+
+// movePos moves all of texpr, a type expression, to the line denoted
+// by pos, so that it gets printed on a single line.
+func movePos(texpr ast.Expr, pos token.Pos) {
+	ast.Inspect(texpr, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.Ident:
+			node.NamePos = pos
+		case *ast.BasicLit:
+			node.ValuePos = pos
+		case *ast.StarExpr:
+			node.Star = pos
+		case *ast.ArrayType:
+			node.Lbrack = pos
+		case *ast.MapType:
+			node.Map = pos
+		case *ast.ChanType:
+			node.Begin = pos
+			node.Arrow = pos
+		case *ast.FuncType:
+			node.Func = pos
+		case *ast.StructType:
+			node.Struct = pos
+		case *ast.InterfaceType:
+			node.Interface = pos
+		case *ast.FieldList:
+			node.Opening = pos
+			node.Closing = pos
+		case *ast.Ellipsis:
+			node.Ellipsis = pos
+		}
+		return true
+	})
+}
+
+// expr returns a copy of expr. In recursive mode, convertible struct
+// literals nested in expr are converted, and the literals that contain
+// them are printed with one element per line.
+func (k *keyifier) expr(expr ast.Expr) ast.Expr {
+	if !k.recursive || !k.needsChange(expr) {
+		return copyExpr(expr, k.cur())
+	}
+	switch expr := expr.(type) {
+	case *ast.ParenExpr:
+		cp := *expr
+		cp.Lparen = 0
+		cp.X = k.expr(cp.X)
+		cp.Rparen = 0
+		return &cp
+	case *ast.UnaryExpr:
+		cp := *expr
+		cp.OpPos = 0
+		cp.X = k.expr(cp.X)
+		return &cp
+	case *ast.CompositeLit:
+		if k.convertible(expr) {
+			return k.convert(expr)
+		}
+		newComplit := &ast.CompositeLit{
+			Type:   copyExpr(expr.Type, k.cur()),
+			Lbrace: k.cur(),
+		}
+		for _, elt := range expr.Elts {
+			pos := k.next()
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				key := copyExpr(kv.Key, pos)
+				setPos(key, pos)
+				newComplit.Elts = append(newComplit.Elts, &ast.KeyValueExpr{
+					Key:   key,
+					Value: k.expr(kv.Value),
+				})
+			} else {
+				val := k.expr(elt)
+				setPos(val, pos)
+				newComplit.Elts = append(newComplit.Elts, val)
+			}
+		}
+		newComplit.Rbrace = k.next()
+		return newComplit
+	default:
+		return copyExpr(expr, k.cur())
+	}
+}
+
+// needsChange reports whether expr contains convertible struct
+// literals.
+func (k *keyifier) needsChange(expr ast.Expr) bool {
+	found := false
+	ast.Inspect(expr, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CompositeLit:
+			if k.convertible(node) {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// isKeyed reports whether complit is empty or uses keys.
+func isKeyed(complit *ast.CompositeLit) bool {
+	if len(complit.Elts) == 0 {
+		return true
+	}
+	_, ok := complit.Elts[0].(*ast.KeyValueExpr)
+	return ok
+}
+
+// setPos moves the start of expr, which has been copied with
+// copyExpr, to pos, so that it gets printed on the line denoted by
+// pos.
+func setPos(expr ast.Expr, pos token.Pos) {
+	switch expr := expr.(type) {
+	case *ast.BasicLit:
+		expr.ValuePos = pos
+	case *ast.Ident:
+		expr.NamePos = pos
+	case *ast.UnaryExpr:
+		expr.OpPos = pos
+	case *ast.StarExpr:
+		expr.Star = pos
+	case *ast.ParenExpr:
+		expr.Lparen = pos
+	case *ast.CompositeLit:
+		if expr.Type != nil {
+			setPos(expr.Type, pos)
+		} else {
+			expr.Lbrace = pos
+		}
+	case *ast.SelectorExpr:
+		setPos(expr.X, pos)
+	case *ast.CallExpr:
+		setPos(expr.Fun, pos)
+	case *ast.IndexExpr:
+		setPos(expr.X, pos)
+	case *ast.BinaryExpr:
+		setPos(expr.X, pos)
+	case *ast.ArrayType:
+		expr.Lbrack = pos
+	case *ast.MapType:
+		expr.Map = pos
+	}
 }
 
 func isNil(val ast.Expr, pkg *loader.PackageInfo) bool {
@@ -242,10 +605,14 @@ func isZero(val ast.Expr, pkg *loader.PackageInfo) bool {
 	return false
 }
 
-func printComplit(oldlit, newlit *ast.CompositeLit, oldfset, newfset *token.FileSet) {
+func printExpr(fset *token.FileSet, expr ast.Expr) string {
 	buf := &bytes.Buffer{}
 	cfg := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
-	_ = cfg.Fprint(buf, newfset, newlit)
+	_ = cfg.Fprint(buf, fset, expr)
+	return buf.String()
+}
+
+func printComplit(oldlit *ast.CompositeLit, replacement string, oldfset *token.FileSet) {
 	if fJSON {
 		output := struct {
 			Start       int    `json:"start"`
@@ -254,11 +621,11 @@ func printComplit(oldlit, newlit *ast.CompositeLit, oldfset, newfset *token.File
 		}{
 			oldfset.Position(oldlit.Pos()).Offset,
 			oldfset.Position(oldlit.End()).Offset,
-			buf.String(),
+			replacement,
 		}
 		_ = json.NewEncoder(os.Stdout).Encode(output)
 	} else {
-		fmt.Println(buf.String())
+		fmt.Println(replacement)
 	}
 }
 
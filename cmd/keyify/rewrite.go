@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"honnef.co/go/tools/internal/diff"
+	"honnef.co/go/tools/lint"
+
+	"golang.org/x/tools/go/buildutil"
+	"golang.org/x/tools/go/loader"
+)
+
+// An edit replaces the bytes between Start and End of File with
+// Replacement.
+type edit struct {
+	File        string `json:"file"`
+	Start       int    `json:"start"`
+	End         int    `json:"end"`
+	Replacement string `json:"replacement"`
+}
+
+// relName returns name relative to the current directory, if it is
+// inside of it.
+func relName(name string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return name
+	}
+	if rel, err := filepath.Rel(cwd, name); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return name
+}
+
+// rewrite converts all literals of named struct types in arg, which is
+// either a file or a package, and writes the changed files or, in
+// dry-run, archive and JSON modes, prints a diff, the contents of the
+// changed files or the edits.
+func rewrite(ctx *build.Context, arg string) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		log.Fatal(err)
+	}
+	var bpkg *build.Package
+	var only string
+	if strings.HasSuffix(arg, ".go") {
+		only = absPath(arg)
+		bpkg, err = buildutil.ContainingPackage(ctx, cwd, only)
+	} else {
+		bpkg, err = ctx.Import(arg, cwd, 0)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	lprog := load(ctx, bpkg)
+	edits := []edit{}
+	for _, pkg := range lprog.InitialPackages() {
+		for _, f := range pkg.Files {
+			name := lprog.Fset.File(f.Pos()).Name()
+			if only != "" && name != only {
+				continue
+			}
+			if isGenerated(f) {
+				continue
+			}
+			fixes := rewriteFile(lprog.Fset, pkg, f)
+			if len(fixes) == 0 {
+				continue
+			}
+			if fJSON {
+				tf := lprog.Fset.File(f.Pos())
+				for _, fix := range fixes {
+					for _, e := range fix.Edits {
+						edits = append(edits, edit{name, tf.Offset(e.Pos), tf.Offset(e.End), e.NewText})
+					}
+				}
+				continue
+			}
+			src, err := readFile(ctx, name)
+			if err != nil {
+				log.Fatal(err)
+			}
+			out, _, err := lint.ApplyFixes(lprog.Fset, src, fixes)
+			if err != nil {
+				log.Fatalf("couldn't rewrite %s: %s", name, err)
+			}
+			if fArchive {
+				writeArchive(os.Stdout, name, out)
+				continue
+			}
+			if fDryRun {
+				short := relName(name)
+				os.Stdout.Write(diff.Unified("a/"+short, "b/"+short, src, out))
+				continue
+			}
+			fi, err := os.Stat(name)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if err := ioutil.WriteFile(name, out, fi.Mode()); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+	if fJSON {
+		json.NewEncoder(os.Stdout).Encode(edits)
+	}
+}
+
+// rewriteFile returns fixes that convert the outermost convertible
+// literals in f, and the literals nested in them. Literals that fit on
+// a single line are kept on a single line.
+func rewriteFile(fset *token.FileSet, pkg *loader.PackageInfo, f *ast.File) []*lint.Fix {
+	var fixes []*lint.Fix
+	ast.Inspect(f, func(node ast.Node) bool {
+		complit, ok := node.(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+		k := &keyifier{
+			pkg:       pkg,
+			recursive: true,
+			oneLine:   fOneLine || isSingleLine(fset, complit),
+			namedOnly: true,
+		}
+		if !k.convertible(complit) {
+			return true
+		}
+		fixes = append(fixes, lint.Replace(complit, k.print(k.convert(complit))))
+		return false
+	})
+	return fixes
+}
+
+func isSingleLine(fset *token.FileSet, node ast.Node) bool {
+	return fset.Position(node.Pos()).Line == fset.Position(node.End()).Line
+}
+
+func isGenerated(f *ast.File) bool {
+	if len(f.Comments) == 0 {
+		return false
+	}
+	text := f.Comments[0].Text()
+	return strings.Contains(text, "Code generated by") || strings.Contains(text, "DO NOT EDIT")
+}
+
+// writeArchive writes the file name with the given contents to w in
+// the archive format of buildutil.ParseOverlayArchive.
+func writeArchive(w io.Writer, name string, contents []byte) {
+	fmt.Fprintf(w, "%s\n%d\n", name, len(contents))
+	w.Write(contents)
+}
+
+// readFile returns the contents of the file name, as seen by ctx.
+func readFile(ctx *build.Context, name string) ([]byte, error) {
+	if ctx.OpenFile == nil {
+		return ioutil.ReadFile(name)
+	}
+	rc, err := ctx.OpenFile(name)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
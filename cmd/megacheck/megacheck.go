@@ -11,26 +11,6 @@ import (
 	"honnef.co/go/tools/unused"
 )
 
-type Checker struct {
-	Checkers []lint.Checker
-}
-
-func (c *Checker) Init(prog *lint.Program) {
-	for _, cc := range c.Checkers {
-		cc.Init(prog)
-	}
-}
-
-func (c *Checker) Funcs() map[string]lint.Func {
-	fns := map[string]lint.Func{}
-	for _, cc := range c.Checkers {
-		for k, v := range cc.Funcs() {
-			fns[k] = v
-		}
-	}
-	return fns
-}
-
 func main() {
 	var flags struct {
 		staticcheck struct {
@@ -48,9 +28,12 @@ func main() {
 			functions    bool
 			types        bool
 			variables    bool
+			writeOnly    bool
+			params       bool
 			debug        string
 			wholeProgram bool
 			reflection   bool
+			strictIfaces bool
 		}
 	}
 	fs := lintutil.FlagSet("megacheck")
@@ -76,13 +59,19 @@ func main() {
 		"unused.types", true, "Report unused types")
 	fs.BoolVar(&flags.unused.variables,
 		"unused.vars", true, "Report unused variables")
+	fs.BoolVar(&flags.unused.writeOnly,
+		"unused.writeonly", false, "Report fields and package-level variables that are written to but never read")
+	fs.BoolVar(&flags.unused.params,
+		"unused.params", false, "Report unused function parameters and named results that are never assigned")
 	fs.BoolVar(&flags.unused.wholeProgram,
 		"unused.exported", false, "Treat arguments as a program and report unused exported identifiers")
 	fs.BoolVar(&flags.unused.reflection, "unused.reflect", true, "Consider identifiers as used when it's likely they'll be accessed via reflection")
+	fs.BoolVar(&flags.unused.strictIfaces,
+		"unused.strict-interfaces", false, "Only consider methods used for implementing interfaces if the interfaces are used themselves")
 
 	fs.Parse(os.Args[1:])
 
-	c := &Checker{}
+	c := &lint.MultiChecker{}
 
 	if flags.staticcheck.enabled {
 		sac := staticcheck.NewChecker()
@@ -113,9 +102,16 @@ func main() {
 		if flags.unused.variables {
 			mode |= unused.CheckVariables
 		}
+		if flags.unused.writeOnly {
+			mode |= unused.CheckWriteOnly
+		}
+		if flags.unused.params {
+			mode |= unused.CheckParams
+		}
 		uc := unused.NewChecker(mode)
 		uc.WholeProgram = flags.unused.wholeProgram
 		uc.ConsiderReflection = flags.unused.reflection
+		uc.StrictInterfaces = flags.unused.strictIfaces
 		c.Checkers = append(c.Checkers, unused.NewLintChecker(uc))
 	}
 
@@ -0,0 +1,83 @@
+// quickfix runs staticcheck, gosimple and stylecheck and applies all
+// of their suggested fixes in one pass.
+package main // import "honnef.co/go/tools/cmd/quickfix"
+
+import (
+	"flag"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+
+	"honnef.co/go/tools/lint"
+	"honnef.co/go/tools/lint/lintutil"
+	"honnef.co/go/tools/simple"
+	"honnef.co/go/tools/staticcheck"
+	"honnef.co/go/tools/stylecheck"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	fs := lintutil.FlagSet("quickfix")
+	verbose := fs.Bool("v", false, "List every applied fix")
+	fs.Parse(os.Args[1:])
+	diffOnly := fs.Lookup("diff").Value.(flag.Getter).Get().(bool)
+
+	c := &lint.MultiChecker{
+		Checkers: []lint.Checker{
+			staticcheck.NewChecker(),
+			simple.NewChecker(),
+			stylecheck.NewChecker(),
+		},
+	}
+	ps, lprog, err := lintutil.Lint(c, fs.Args(), lintutil.FlagOptions(fs))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	// Problems are sorted by position. Of overlapping fixes, the one
+	// starting first, or the first one reported at the same position,
+	// is applied, so that runs on the same code agree.
+	applied, skipped, changed, err := lintutil.FixProblems(ps, lprog.Fset, diffOnly)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	for _, p := range skipped {
+		fmt.Fprintf(os.Stderr, "%s: skipped: %s (conflicts with another fix)\n", position(lprog.Fset, p.Position), p.Text)
+	}
+	if *verbose {
+		for _, p := range applied {
+			fmt.Fprintf(os.Stderr, "%s: applied: %s\n", position(lprog.Fset, p.Position), p.Text)
+		}
+	}
+	unfixable := 0
+	for _, p := range ps {
+		if p.Fix == nil || len(p.Fix.Edits) == 0 {
+			unfixable++
+		}
+	}
+	fmt.Fprintf(os.Stderr, "applied %d fixes to %d files, skipped %d conflicting fixes; %d problems have no fix\n",
+		len(applied), changed, len(skipped), unfixable)
+	return 0
+}
+
+func position(fset *token.FileSet, pos token.Pos) string {
+	p := fset.Position(pos)
+	return fmt.Sprintf("%s:%d:%d", shortPath(p.Filename), p.Line, p.Column)
+}
+
+func shortPath(path string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(cwd, path); err == nil && len(rel) < len(path) {
+		return rel
+	}
+	return path
+}
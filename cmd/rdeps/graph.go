@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/build"
+	"io"
+	"sort"
+	"sync"
+
+	"golang.org/x/tools/go/buildutil"
+)
+
+// An importKind describes which files of a package import another
+// package.
+type importKind int
+
+const (
+	importPackage importKind = 1 << iota // the package's own files
+	importTest                           // its _test.go files, including external tests
+)
+
+// A reverseGraph maps import paths to the packages that import them.
+type reverseGraph map[string]map[string]importKind
+
+func (g reverseGraph) add(from, to string, kind importKind) {
+	if to == "C" {
+		// "C" is fake
+		return
+	}
+	if g[to] == nil {
+		g[to] = map[string]importKind{}
+	}
+	g[to][from] |= kind
+}
+
+// addPackage adds the imports of bpkg, whose import path is path, to
+// g. resolve returns the canonical import path of an import.
+func (g reverseGraph) addPackage(path string, bpkg *build.Package, resolve func(string) string) {
+	for _, imp := range bpkg.Imports {
+		g.add(path, resolve(imp), importPackage)
+	}
+	for _, imp := range bpkg.TestImports {
+		g.add(path, resolve(imp), importTest)
+	}
+	for _, imp := range bpkg.XTestImports {
+		g.add(path, resolve(imp), importTest)
+	}
+}
+
+// gopathImportGraph returns the reverse import graph of all packages
+// in the source directories of ctx, and the errors encountered while
+// reading packages. Like importgraph.Build, but it records which
+// imports only occur in tests.
+func gopathImportGraph(ctx *build.Context) (reverseGraph, map[string]error) {
+	g := reverseGraph{}
+	errs := map[string]error{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sema := make(chan struct{}, 20) // I/O concurrency limiting semaphore
+	buildutil.ForEachPackage(ctx, func(path string, err error) {
+		if err != nil {
+			mu.Lock()
+			errs[path] = err
+			mu.Unlock()
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sema <- struct{}{}
+			defer func() { <-sema }()
+
+			bpkg, err := ctx.Import(path, "", 0)
+			if err != nil {
+				if _, ok := err.(*build.NoGoError); !ok {
+					mu.Lock()
+					errs[path] = err
+					mu.Unlock()
+				}
+				// Even in error cases, Import usually returns a package.
+				if bpkg == nil {
+					return
+				}
+			}
+			// Resolve vendored imports.
+			memo := map[string]string{}
+			resolve := func(imp string) string {
+				canon, ok := memo[imp]
+				if !ok {
+					canon = imp
+					if bpkg2, _ := ctx.Import(imp, bpkg.Dir, build.FindOnly); bpkg2 != nil {
+						canon = bpkg2.ImportPath
+					}
+					memo[imp] = canon
+				}
+				return canon
+			}
+			local := reverseGraph{}
+			local.addPackage(path, bpkg, resolve)
+
+			mu.Lock()
+			defer mu.Unlock()
+			for to, from := range local {
+				for from, kind := range from {
+					g.add(from, to, kind)
+				}
+			}
+		}()
+	})
+	wg.Wait()
+	return g, errs
+}
+
+// An edge is an import of the package To by the package From. Test is
+// true if only the tests of From import To.
+type edge struct {
+	From string
+	To   string
+	Test bool
+}
+
+// sortEdges sorts edges and removes duplicates.
+func sortEdges(edges []edge) []edge {
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].To != edges[j].To {
+			return edges[i].To < edges[j].To
+		}
+		return edges[i].From < edges[j].From
+	})
+	var out []edge
+	for i, e := range edges {
+		if i > 0 && e == edges[i-1] {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// writeDOT writes the reverse dependencies of targets as a graph in
+// the DOT language of Graphviz. Edges point from importing to imported
+// packages; targets are drawn in bold, imports by tests are dashed.
+func writeDOT(w io.Writer, targets []string, edges []edge) {
+	fmt.Fprintln(w, "digraph {")
+	for _, target := range targets {
+		fmt.Fprintf(w, "\t%q [style = bold]\n", target)
+	}
+	for _, e := range sortEdges(edges) {
+		if e.Test {
+			fmt.Fprintf(w, "\t%q -> %q [style = dashed, label = \"test\"]\n", e.From, e.To)
+		} else {
+			fmt.Fprintf(w, "\t%q -> %q\n", e.From, e.To)
+		}
+	}
+	fmt.Fprintln(w, "}")
+}
+
+type jsonImporter struct {
+	Package string `json:"package"`
+	Test    bool   `json:"test,omitempty"`
+}
+
+// writeJSON writes the reverse dependencies of targets as a JSON
+// object that maps each package to the packages importing it.
+func writeJSON(w io.Writer, targets []string, edges []edge) {
+	out := map[string][]jsonImporter{}
+	for _, target := range targets {
+		out[target] = []jsonImporter{}
+	}
+	for _, e := range sortEdges(edges) {
+		if out[e.To] == nil {
+			out[e.To] = []jsonImporter{}
+		}
+		out[e.To] = append(out[e.To], jsonImporter{e.From, e.Test})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	enc.Encode(out)
+}
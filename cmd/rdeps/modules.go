@@ -0,0 +1,186 @@
+package main
+
+import (
+	"errors"
+	"go/build"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"honnef.co/go/tools/internal/gomod"
+)
+
+// A module is a Go module on disk.
+type module struct {
+	path string // module path
+	dir  string // root directory
+}
+
+// readModule returns the module whose root directory is dir.
+func readModule(dir string) (module, error) {
+	modPath, err := gomod.ModulePath(dir)
+	if err != nil {
+		return module{}, err
+	}
+	return module{path: modPath, dir: dir}, nil
+}
+
+// workspaceModules returns the modules that make up the workspace
+// containing dir: the modules of the closest go.work file, or else
+// the closest module.
+func workspaceModules(dir string) ([]module, error) {
+	roots, err := gomod.WorkspaceRoots(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var mods []module
+	for _, root := range roots {
+		mod, err := readModule(root)
+		if err != nil {
+			return nil, err
+		}
+		mods = append(mods, mod)
+	}
+	return mods, nil
+}
+
+// modCacheDir returns the location of the module cache.
+func modCacheDir() (string, error) {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir, nil
+	}
+	out, err := exec.Command("go", "env", "GOMODCACHE").Output()
+	if err == nil && len(strings.TrimSpace(string(out))) > 0 {
+		return strings.TrimSpace(string(out)), nil
+	}
+	gopath := filepath.SplitList(build.Default.GOPATH)
+	if len(gopath) == 0 {
+		return "", errors.New("couldn't determine the location of the module cache")
+	}
+	return filepath.Join(gopath[0], "pkg", "mod"), nil
+}
+
+// unescapeModulePath reverses the escaping of upper-case letters in
+// the module cache, where "!x" stands for "X".
+func unescapeModulePath(p string) string {
+	var b strings.Builder
+	bang := false
+	for _, r := range p {
+		switch {
+		case r == '!':
+			bang = true
+		case bang:
+			b.WriteRune(unicode.ToUpper(r))
+			bang = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// modCacheModules returns all modules in the module cache. Different
+// versions of a module are different modules with the same path.
+func modCacheModules() ([]module, error) {
+	root, err := modCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	var mods []module
+	err = filepath.Walk(root, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && p == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !fi.IsDir() {
+			return nil
+		}
+		if p == filepath.Join(root, "cache") {
+			return filepath.SkipDir
+		}
+		name := fi.Name()
+		i := strings.LastIndex(name, "@")
+		if i < 0 {
+			return nil
+		}
+		rel, err := filepath.Rel(root, filepath.Join(filepath.Dir(p), name[:i]))
+		if err != nil {
+			return err
+		}
+		mods = append(mods, module{
+			path: unescapeModulePath(filepath.ToSlash(rel)),
+			dir:  p,
+		})
+		return filepath.SkipDir
+	})
+	return mods, err
+}
+
+// moduleImportGraph returns the reverse import graph of all packages in
+// mods, and the errors encountered while reading packages. Directories
+// that the go command ignores, as well as nested modules, are skipped.
+func moduleImportGraph(ctx *build.Context, mods []module) (reverse reverseGraph, errs map[string]error) {
+	reverse = reverseGraph{}
+	errs = map[string]error{}
+	for _, mod := range mods {
+		filepath.Walk(mod.dir, func(p string, fi os.FileInfo, err error) error {
+			if err != nil {
+				errs[p] = err
+				return nil
+			}
+			if !fi.IsDir() {
+				return nil
+			}
+			name := fi.Name()
+			if p != mod.dir {
+				if name == "testdata" || name == "vendor" ||
+					strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+					return filepath.SkipDir
+				}
+				if _, err := os.Stat(filepath.Join(p, "go.mod")); err == nil {
+					return filepath.SkipDir
+				}
+			}
+			bpkg, err := ctx.ImportDir(p, 0)
+			if err != nil {
+				if _, ok := err.(*build.NoGoError); !ok {
+					errs[p] = err
+				}
+				if bpkg == nil || len(bpkg.Imports) == 0 {
+					return nil
+				}
+			}
+			rel, err := filepath.Rel(mod.dir, p)
+			if err != nil {
+				return nil
+			}
+			reverse.addPackage(path.Join(mod.path, filepath.ToSlash(rel)), bpkg, func(imp string) string { return imp })
+			return nil
+		})
+	}
+	return reverse, errs
+}
+
+// moduleImportPath returns the import path of the package in dir,
+// using the innermost of mods that contains dir.
+func moduleImportPath(dir string, mods []module) (string, bool) {
+	best := ""
+	bestLen := -1
+	for _, mod := range mods {
+		rel, err := filepath.Rel(mod.dir, dir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if len(mod.dir) > bestLen {
+			best = path.Join(mod.path, filepath.ToSlash(rel))
+			bestLen = len(mod.dir)
+		}
+	}
+	return best, bestLen >= 0
+}
@@ -1,8 +1,13 @@
 // rdeps scans GOPATH for all reverse dependencies of a set of Go
-// packages.
+// packages. Inside a module, it scans the module, or all modules of
+// the go.work workspace, and optionally the module cache instead.
 //
-// rdeps will not sort its output, and the order of the output is
-// undefined. Pipe its output through sort if you need stable output.
+// Only the files matching the build tags and the target platform,
+// which can be set with -tags, -goos and -goarch, are considered.
+//
+// rdeps prints reverse dependencies in the order it finds them,
+// closest ones first. Pipe its output through sort if you need sorted
+// output.
 package main
 
 import (
@@ -10,11 +15,17 @@ import (
 	"flag"
 	"fmt"
 	"go/build"
+	"go/types"
 	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"honnef.co/go/tools/internal/gomod"
 
 	"github.com/kisielk/gotool"
 	"golang.org/x/tools/go/buildutil"
-	"golang.org/x/tools/refactor/importgraph"
 )
 
 func main() {
@@ -22,10 +33,43 @@ func main() {
 	flag.Var(&tags, "tags", "List of build tags")
 	stdin := flag.Bool("stdin", false, "Read packages from stdin instead of the command line")
 	recursive := flag.Bool("r", false, "Print reverse dependencies recursively")
+	modcache := flag.Bool("modcache", false, "In module mode, also scan the module cache")
+	format := flag.String("format", "list", "Output format: list, dot or json")
+	depth := flag.Int("depth", 0, "Print reverse dependencies up to `n` imports away; implies -r")
+	why := flag.Bool("why", false, "Print an import chain from each reverse dependency to the package")
+	tests := flag.Bool("test", false, "Include packages that only import the package in their tests")
+	goos := flag.String("goos", build.Default.GOOS, "Only consider files for the operating system `os`")
+	goarch := flag.String("goarch", build.Default.GOARCH, "Only consider files for the architecture `arch`")
 	flag.Parse()
 
+	if *format != "list" && *format != "dot" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "unsupported format %q\n", *format)
+		os.Exit(2)
+	}
+	if *why && *format != "list" {
+		fmt.Fprintln(os.Stderr, "-why can only be used with -format list")
+		os.Exit(2)
+	}
+	limit := 1
+	if *recursive {
+		limit = 0
+	}
+	if *depth > 0 {
+		limit = *depth
+	}
+
+	if types.SizesFor("gc", *goarch) == nil {
+		fmt.Fprintf(os.Stderr, "unknown architecture %q\n", *goarch)
+		os.Exit(2)
+	}
 	ctx := build.Default
 	ctx.BuildTags = tags
+	ctx.GOOS = *goos
+	ctx.GOARCH = *goarch
+	if os.Getenv("CGO_ENABLED") == "" && (ctx.GOOS != runtime.GOOS || ctx.GOARCH != runtime.GOARCH) {
+		// Like the go command, don't use cgo when cross-compiling.
+		ctx.CgoEnabled = false
+	}
 	var args []string
 	if *stdin {
 		s := bufio.NewScanner(os.Stdin)
@@ -44,33 +88,121 @@ func main() {
 		os.Exit(1)
 	}
 	pkgs := gotool.ImportPaths(args)
-	for i, pkg := range pkgs {
-		bpkg, err := ctx.Import(pkg, wd, build.FindOnly)
+
+	var reverse reverseGraph
+	var errors map[string]error
+	if gomod.Enabled(wd) {
+		mods, err := workspaceModules(wd)
 		if err != nil {
-			continue
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		for i, pkg := range pkgs {
+			if !build.IsLocalImport(pkg) {
+				continue
+			}
+			if path, ok := moduleImportPath(filepath.Join(wd, pkg), mods); ok {
+				pkgs[i] = path
+			}
+		}
+		if *modcache {
+			cached, err := modCacheModules()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			mods = append(mods, cached...)
 		}
-		pkgs[i] = bpkg.ImportPath
+		reverse, errors = moduleImportGraph(&ctx, mods)
+	} else {
+		for i, pkg := range pkgs {
+			bpkg, err := ctx.Import(pkg, wd, build.FindOnly)
+			if err != nil {
+				continue
+			}
+			pkgs[i] = bpkg.ImportPath
+		}
+		reverse, errors = gopathImportGraph(&ctx)
 	}
-	_, reverse, errors := importgraph.Build(&ctx)
-	_ = errors
 
-	seen := map[string]bool{}
-	var printRDeps func(pkg string)
-	printRDeps = func(pkg string) {
-		for rdep := range reverse[pkg] {
-			if seen[rdep] {
+	// Reverse dependencies are visited breadth-first, so that the
+	// import chains printed by -why are as short as possible.
+	type item struct {
+		pkg   string
+		depth int
+	}
+	isTarget := map[string]bool{}
+	var queue []item
+	for _, pkg := range pkgs {
+		isTarget[pkg] = true
+		queue = append(queue, item{pkg, 0})
+	}
+	var found []string
+	// testOnly records the reverse dependencies that only import
+	// packages in their tests. Test files can't be imported, so they
+	// have no reverse dependencies of their own.
+	testOnly := map[string]bool{}
+	queued := map[string]bool{}
+	// next maps reverse dependencies to the package they import on
+	// the way to a target.
+	next := map[string]string{}
+	var edges []edge
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if limit > 0 && cur.depth >= limit {
+			continue
+		}
+		var rdeps []string
+		for rdep := range reverse[cur.pkg] {
+			rdeps = append(rdeps, rdep)
+		}
+		sort.Strings(rdeps)
+		for _, rdep := range rdeps {
+			test := reverse[cur.pkg][rdep]&importPackage == 0
+			if test && !*tests {
 				continue
 			}
-			seen[rdep] = true
-			fmt.Println(rdep)
-			if *recursive {
-				printRDeps(rdep)
+			edges = append(edges, edge{From: rdep, To: cur.pkg, Test: test})
+			if _, ok := testOnly[rdep]; !ok {
+				found = append(found, rdep)
+				testOnly[rdep] = test
+				next[rdep] = cur.pkg
+			} else if testOnly[rdep] && !test {
+				testOnly[rdep] = false
+				next[rdep] = cur.pkg
+			}
+			if !test && !queued[rdep] {
+				queued[rdep] = true
+				queue = append(queue, item{rdep, cur.depth + 1})
 			}
 		}
 	}
 
-	for _, pkg := range pkgs {
-		printRDeps(pkg)
+	if *format == "list" {
+		for _, rdep := range found {
+			if testOnly[rdep] {
+				fmt.Printf("%s (test)\n", rdep)
+			} else {
+				fmt.Println(rdep)
+			}
+			if *why {
+				chain := []string{rdep}
+				for pkg := next[rdep]; ; pkg = next[pkg] {
+					chain = append(chain, pkg)
+					if isTarget[pkg] {
+						break
+					}
+				}
+				fmt.Printf("\t%s\n", strings.Join(chain, " -> "))
+			}
+		}
+	}
+	switch *format {
+	case "dot":
+		writeDOT(os.Stdout, pkgs, edges)
+	case "json":
+		writeJSON(os.Stdout, pkgs, edges)
 	}
 	for pkg, err := range errors {
 		fmt.Fprintf(os.Stderr, "error in package %s: %s\n", pkg, err)
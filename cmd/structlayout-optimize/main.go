@@ -3,30 +3,43 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
+	"honnef.co/go/tools/internal/diff"
 	st "honnef.co/go/tools/structlayout"
 )
 
 var (
 	fJSON    bool
 	fRecurse bool
+	fMinimal bool
+	fWrite   bool
+	fDiff    bool
 )
 
 func init() {
 	flag.BoolVar(&fJSON, "json", false, "Format data as JSON")
 	flag.BoolVar(&fRecurse, "r", false, "Break up structs and reorder their fields freely")
+	flag.BoolVar(&fMinimal, "minimal", false, "Keep groups of fields together and move as few fields as possible")
+	flag.BoolVar(&fWrite, "w", false, "Reorder the fields in the struct's source code")
+	flag.BoolVar(&fDiff, "d", false, "Print a diff of reordering the fields in the struct's source code")
 }
 
 func main() {
 	log.SetFlags(0)
 	flag.Parse()
+	if (fWrite || fDiff) && fRecurse {
+		log.Fatal("-w and -d cannot be used with -r")
+	}
 
 	var in []st.Field
 	if err := json.NewDecoder(os.Stdin).Decode(&in); err != nil {
@@ -45,9 +58,43 @@ func main() {
 		}
 		fields = append(fields, field)
 	}
-	optimize(fields)
+	if fMinimal {
+		fields = optimizeMinimal(fields)
+	} else {
+		optimize(fields)
+	}
 	fields = pad(fields)
 
+	if fWrite || fDiff {
+		name, src, out, err := rewrite(fields)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if bytes.Equal(src, out) {
+			// The fields are in the optimal order already.
+			return
+		}
+		if fDiff {
+			short := name
+			if cwd, err := os.Getwd(); err == nil {
+				if rel, err := filepath.Rel(cwd, name); err == nil && !strings.HasPrefix(rel, "..") {
+					short = rel
+				}
+			}
+			os.Stdout.Write(diff.Unified("a/"+short, "b/"+short, src, out))
+		}
+		if fWrite {
+			fi, err := os.Stat(name)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if err := ioutil.WriteFile(name, out, fi.Mode()); err != nil {
+				log.Fatal(err)
+			}
+		}
+		return
+	}
+
 	if fJSON {
 		json.NewEncoder(os.Stdout).Encode(fields)
 	} else {
@@ -57,40 +104,40 @@ func main() {
 	}
 }
 
+// combine merges the fields of nested structs into single fields, so
+// that only the top-level fields get reordered.
 func combine(fields []st.Field) []st.Field {
 	new := st.Field{}
 	cur := ""
 	var out []st.Field
-	wasPad := true
+	flush := func() {
+		// The size of a struct is a multiple of its alignment.
+		new.Size = align(new.End-new.Start, new.Align)
+		new.End = new.Start + new.Size
+		out = append(out, new)
+	}
 	for _, field := range fields {
-		var prefix string
 		if field.IsPadding {
-			wasPad = true
 			continue
 		}
 		p := strings.Split(field.Name, ".")
-		prefix = strings.Join(p[:2], ".")
-		if field.Align > new.Align {
-			new.Align = field.Align
-		}
-		if !wasPad {
-			new.End = field.Start
-			new.Size = new.End - new.Start
-		}
+		prefix := strings.Join(p[:2], ".")
 		if prefix != cur {
 			if cur != "" {
-				out = append(out, new)
+				flush()
 			}
 			cur = prefix
 			new = field
 			new.Name = prefix
-		} else {
-			new.Type = "struct"
+			continue
+		}
+		new.Type = "struct"
+		new.End = field.End
+		if field.Align > new.Align {
+			new.Align = field.Align
 		}
-		wasPad = false
 	}
-	new.Size = new.End - new.Start
-	out = append(out, new)
+	flush()
 	return out
 }
 
@@ -98,6 +145,85 @@ func optimize(fields []st.Field) {
 	sort.Sort(&byAlignAndSize{fields})
 }
 
+// maxPermute is the largest number of groups whose orders
+// optimizeMinimal tries exhaustively.
+const maxPermute = 8
+
+// optimizeMinimal returns a reordering of fields that has as little
+// padding as possible while keeping consecutive fields of the same
+// group together and moving as few fields as possible. Fields without
+// a group form groups of their own.
+func optimizeMinimal(fields []st.Field) []st.Field {
+	var groups [][]st.Field
+	for i, field := range fields {
+		if i > 0 && field.Group != 0 && field.Group == fields[i-1].Group {
+			groups[len(groups)-1] = append(groups[len(groups)-1], field)
+			continue
+		}
+		groups = append(groups, []st.Field{field})
+	}
+
+	best := fields
+	bestSize := paddedSize(fields)
+	bestMoved := 0
+	try := func(order []int) {
+		var out []st.Field
+		for _, i := range order {
+			out = append(out, groups[i]...)
+		}
+		size := paddedSize(out)
+		moved := 0
+		for i := range out {
+			if out[i].Name != fields[i].Name {
+				moved++
+			}
+		}
+		if size < bestSize || (size == bestSize && moved < bestMoved) {
+			best, bestSize, bestMoved = out, size, moved
+		}
+	}
+
+	order := make([]int, len(groups))
+	for i := range order {
+		order[i] = i
+	}
+	if len(groups) <= maxPermute {
+		permute(order, 0, try)
+	} else {
+		// Too many groups to try them all; order the groups like
+		// optimize orders fields, keeping them stable otherwise.
+		sort.SliceStable(order, func(i, j int) bool {
+			a, b := groups[order[i]][0], groups[order[j]][0]
+			return a.Align > b.Align
+		})
+		try(order)
+	}
+	return best
+}
+
+// permute calls fn with all permutations of order[k:].
+func permute(order []int, k int, fn func([]int)) {
+	if k == len(order) {
+		fn(order)
+		return
+	}
+	for i := k; i < len(order); i++ {
+		order[k], order[i] = order[i], order[k]
+		permute(order, k+1, fn)
+		order[k], order[i] = order[i], order[k]
+	}
+}
+
+// paddedSize returns the size of a struct made of fields, including
+// all padding.
+func paddedSize(fields []st.Field) int64 {
+	out := pad(fields)
+	if len(out) == 0 {
+		return 0
+	}
+	return out[len(out)-1].End
+}
+
 func pad(fields []st.Field) []st.Field {
 	if len(fields) == 0 {
 		return nil
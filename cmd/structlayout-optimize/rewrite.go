@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+	st "honnef.co/go/tools/structlayout"
+)
+
+// A chunk is the source code of a field declaration, including the
+// comments and blank lines preceding it.
+type chunk struct {
+	pos  string
+	text string
+}
+
+// posFile returns the file name of pos, a position of the form
+// file:line:column.
+func posFile(pos string) string {
+	for i := 0; i < 2; i++ {
+		j := strings.LastIndex(pos, ":")
+		if j < 0 {
+			return ""
+		}
+		pos = pos[:j]
+	}
+	return pos
+}
+
+// rewrite reorders the fields in the declaration of the struct that
+// fields, the output of structlayout, describe, to match the order of
+// fields. It returns the name of the file declaring the struct and its
+// old and new contents, the latter formatted. Doc comments, line
+// comments and tags move with their fields. If the order of the
+// fields doesn't change, the file is returned as is.
+func rewrite(fields []st.Field) (name string, src, out []byte, err error) {
+	var order []string
+	for _, field := range fields {
+		if field.IsPadding {
+			continue
+		}
+		if field.Pos == "" {
+			return "", nil, nil, errors.New("input has no source positions; it has to be produced by structlayout for a single type")
+		}
+		order = append(order, field.Pos)
+	}
+	name = posFile(order[0])
+	src, err = ioutil.ReadFile(name)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	var styp *ast.StructType
+	ast.Inspect(f, func(node ast.Node) bool {
+		if styp != nil {
+			return false
+		}
+		if node, ok := node.(*ast.StructType); ok {
+			for _, field := range node.Fields.List {
+				for _, pos := range fieldPositions(fset, field) {
+					if pos == order[0] {
+						styp = node
+						return false
+					}
+				}
+			}
+		}
+		return true
+	})
+	if styp == nil {
+		return "", nil, nil, fmt.Errorf("couldn't find the struct declaration in %s; has the file changed?", name)
+	}
+
+	chunks, tail, err := splitFields(fset, src, styp)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if len(chunks) != len(order) {
+		return "", nil, nil, fmt.Errorf("the struct declaration in %s doesn't match the layout; has the file changed?", name)
+	}
+	unchanged := true
+	for i, c := range chunks {
+		if c.pos != order[i] {
+			unchanged = false
+			break
+		}
+	}
+	if unchanged {
+		return name, src, src, nil
+	}
+	byPos := map[string]string{}
+	for _, c := range chunks {
+		byPos[c.pos] = c.text
+	}
+	var body bytes.Buffer
+	for i, pos := range order {
+		text, ok := byPos[pos]
+		if !ok {
+			return "", nil, nil, fmt.Errorf("the struct declaration in %s doesn't match the layout; has the file changed?", name)
+		}
+		switch {
+		case i == 0:
+			text = "\n" + trimBlankLines(text)
+		case pos == chunks[0].pos:
+			// The first field starts a group of fields.
+			text = "\n" + text
+		}
+		body.WriteString(text)
+	}
+	body.WriteString(tail)
+
+	fix := lint.ReplaceRange(styp.Fields.Opening+1, styp.Fields.Closing, body.String())
+	out, _, err = lint.ApplyFixes(fset, src, []*lint.Fix{fix})
+	if err != nil {
+		return "", nil, nil, err
+	}
+	out, err = format.Source(out)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return name, src, out, nil
+}
+
+// trimBlankLines removes the blank lines at the start of text, keeping
+// the indentation of its first non-blank line.
+func trimBlankLines(text string) string {
+	for {
+		i := strings.IndexByte(text, '\n')
+		if i < 0 || strings.TrimSpace(text[:i]) != "" {
+			return text
+		}
+		text = text[i+1:]
+	}
+}
+
+// fieldPositions returns the positions that structlayout records for
+// the fields declared by field: the positions of their names, or of
+// the type of an embedded field.
+func fieldPositions(fset *token.FileSet, field *ast.Field) []string {
+	if len(field.Names) == 0 {
+		return []string{fset.Position(field.Pos()).String()}
+	}
+	var out []string
+	for _, name := range field.Names {
+		out = append(out, fset.Position(name.Pos()).String())
+	}
+	return out
+}
+
+// splitFields splits the body of styp into the source code of its
+// fields, and the source code following the last field. Declarations
+// of several fields, such as a, b int, are split into one declaration
+// per field.
+func splitFields(fset *token.FileSet, src []byte, styp *ast.StructType) (chunks []chunk, tail string, err error) {
+	tf := fset.File(styp.Pos())
+	off := tf.Offset
+	lineEnd := func(pos token.Pos) int {
+		if i := bytes.IndexByte(src[off(pos):], '\n'); i >= 0 {
+			return off(pos) + i
+		}
+		return len(src)
+	}
+
+	start := off(styp.Fields.Opening) + 1
+	end := off(styp.Fields.Closing)
+	for _, field := range styp.Fields.List {
+		if off(field.Pos()) < start {
+			return nil, "", errors.New("can't reorder fields that share a line")
+		}
+		last := field.End()
+		if field.Comment != nil {
+			last = field.Comment.End()
+		}
+		e := lineEnd(last)
+		if e > end {
+			return nil, "", errors.New("can't reorder fields that share a line")
+		}
+		positions := fieldPositions(fset, field)
+		if len(field.Names) <= 1 {
+			chunks = append(chunks, chunk{positions[0], string(src[start:e])})
+			start = e
+			continue
+		}
+		names := field.Names
+		lineStart := bytes.LastIndexByte(src[:off(names[0].Pos())], '\n') + 1
+		indent := string(src[lineStart:off(names[0].Pos())])
+		if strings.TrimSpace(indent) != "" {
+			// A comment precedes the names on their line.
+			indent = ""
+		}
+		rest := string(src[off(names[len(names)-1].End()):e])
+		chunks = append(chunks, chunk{positions[0], string(src[start:off(names[0].Pos())]) + names[0].Name + rest})
+		typ := string(src[off(field.Type.Pos()):off(field.End())])
+		for i, name := range names[1:] {
+			chunks = append(chunks, chunk{positions[i+1], "\n" + indent + name.Name + " " + typ})
+		}
+		start = e
+	}
+	return chunks, string(src[start:end]), nil
+}
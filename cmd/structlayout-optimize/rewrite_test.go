@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"testing"
+
+	st "honnef.co/go/tools/structlayout"
+)
+
+// layout returns the fields of the struct declared in file, ordered as
+// in names, with the positions structlayout would record for them.
+func layout(t *testing.T, file string, names []string) []st.Field {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	positions := map[string]string{}
+	ast.Inspect(f, func(node ast.Node) bool {
+		if field, ok := node.(*ast.Field); ok {
+			for i, pos := range fieldPositions(fset, field) {
+				positions[field.Names[i].Name] = pos
+			}
+		}
+		return true
+	})
+	var fields []st.Field
+	for _, name := range names {
+		fields = append(fields, st.Field{Name: "T." + name, Pos: positions[name]})
+	}
+	return fields
+}
+
+func TestRewrite(t *testing.T) {
+	fields := layout(t, "testdata/fields.go", []string{"b", "g", "c", "e", "f", "a", "d"})
+	_, _, out, err := rewrite(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ioutil.ReadFile("testdata/fields.go.golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, want) {
+		t.Errorf("got:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestRewriteUnchanged(t *testing.T) {
+	// The file isn't formatted, but keeping the order of its fields
+	// mustn't change it.
+	fields := layout(t, "testdata/unchanged.go", []string{"a", "b", "c"})
+	_, src, out, err := rewrite(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, src) {
+		t.Errorf("got:\n%s\nwant:\n%s", out, src)
+	}
+}
@@ -0,0 +1,12 @@
+package pkg
+
+type T struct {
+	// a is first.
+	a bool `json:"a"`
+	b, c int64 // b and c
+
+	d    bool
+	e, f int32 `json:"ef"`
+	// g is last.
+	g int64
+}
@@ -0,0 +1,6 @@
+package pkg
+
+type T struct {
+	a int64
+	b, c   int32 // b and c
+}
@@ -1,5 +1,5 @@
 // structlayout-pretty formats the output of structlayout with ASCII
-// art.
+// art, or as an SVG graphic or HTML page.
 package main
 
 import (
@@ -13,10 +13,16 @@ import (
 	st "honnef.co/go/tools/structlayout"
 )
 
-var fVerbose bool
+var (
+	fVerbose   bool
+	fFormat    string
+	fCacheLine int64
+)
 
 func init() {
 	flag.BoolVar(&fVerbose, "v", false, "Do not compact consecutive bytes of fields")
+	flag.StringVar(&fFormat, "format", "text", "Output format: text, svg or html")
+	flag.Int64Var(&fCacheLine, "cache-line", 64, "Size of cache lines in bytes, for svg and html output")
 }
 
 func main() {
@@ -30,6 +36,22 @@ func main() {
 	if len(fields) == 0 {
 		return
 	}
+	if fCacheLine <= 0 {
+		log.Fatal("-cache-line must be positive")
+	}
+	switch fFormat {
+	case "text":
+		printText(fields)
+	case "svg":
+		writeSVG(os.Stdout, fields, fCacheLine)
+	case "html":
+		writeHTML(os.Stdout, fields, fCacheLine)
+	default:
+		log.Fatalf("unsupported format %q", fFormat)
+	}
+}
+
+func printText(fields []st.Field) {
 	max := fields[len(fields)-1].End
 	maxLength := len(fmt.Sprintf("%d", max))
 	padding := strings.Repeat(" ", maxLength+2)
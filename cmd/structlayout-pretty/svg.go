@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	st "honnef.co/go/tools/structlayout"
+)
+
+const (
+	byteWidth   = 12 // width of a byte in pixels
+	rowHeight   = 36 // height of a cache line in pixels
+	rowGap      = 12 // vertical space between cache lines
+	leftMargin  = 64 // space for offsets to the left of cache lines
+	topMargin   = 32
+	legendLine  = 20 // height of a line of the legend
+	paddingFill = "#e06666"
+)
+
+// fieldFills are the colors of fields, used in turn.
+var fieldFills = []string{"#6fa8dc", "#93c47d", "#ffd966", "#8e7cc3", "#76a5af", "#f6b26b"}
+
+// structName returns the name of the struct that fields belong to.
+func structName(fields []st.Field) string {
+	for _, field := range fields {
+		if field.IsPadding {
+			continue
+		}
+		if i := strings.Index(field.Name, "."); i >= 0 {
+			return field.Name[:i]
+		}
+		return field.Name
+	}
+	return ""
+}
+
+// fieldLabel returns the description of field used in tooltips and
+// the legend.
+func fieldLabel(field st.Field) string {
+	if field.IsPadding {
+		return fmt.Sprintf("padding: %d-%d (size %d)", field.Start, field.End, field.Size)
+	}
+	return fmt.Sprintf("%s %s: %d-%d (size %d, align %d)", field.Name, field.Type, field.Start, field.End, field.Size, field.Align)
+}
+
+// writeSVG draws fields to scale as an SVG graphic, with one row per
+// cache line of lineSize bytes. Fields that straddle cache lines are
+// split across rows.
+func writeSVG(w io.Writer, fields []st.Field, lineSize int64) {
+	size := fields[len(fields)-1].End
+	lines := (size + lineSize - 1) / lineSize
+	if lines == 0 {
+		lines = 1
+	}
+	rowsHeight := lines * (rowHeight + rowGap)
+	width := leftMargin + lineSize*byteWidth + 16
+	height := topMargin + rowsHeight + int64(len(fields))*legendLine + 16
+
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="monospace" font-size="12">`+"\n", width, height)
+	fmt.Fprintf(w, `<text x="%d" y="%d" font-size="14">%s: %d bytes</text>`+"\n", leftMargin, topMargin-12, html.EscapeString(structName(fields)), size)
+
+	for i := int64(0); i < lines; i++ {
+		y := topMargin + i*(rowHeight+rowGap)
+		fmt.Fprintf(w, `<text x="%d" y="%d" text-anchor="end">%d</text>`+"\n", leftMargin-8, y+rowHeight/2+4, i*lineSize)
+		fmt.Fprintf(w, `<rect x="%d" y="%d" width="%d" height="%d" fill="none" stroke="#cccccc"/>`+"\n",
+			leftMargin, y, lineSize*byteWidth, rowHeight)
+	}
+
+	fill := 0
+	for _, field := range fields {
+		color := paddingFill
+		if !field.IsPadding {
+			color = fieldFills[fill%len(fieldFills)]
+			fill++
+		}
+		title := html.EscapeString(fieldLabel(field))
+		for start := field.Start; start < field.End; {
+			line := start / lineSize
+			end := (line + 1) * lineSize
+			if field.End < end {
+				end = field.End
+			}
+			x := leftMargin + (start%lineSize)*byteWidth
+			y := topMargin + line*(rowHeight+rowGap)
+			rw := (end - start) * byteWidth
+			fmt.Fprintf(w, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s" stroke="#444444"><title>%s</title></rect>`+"\n",
+				x, y, rw, rowHeight, color, title)
+			name := field.Name
+			if i := strings.LastIndex(name, "."); i >= 0 {
+				name = name[i+1:]
+			}
+			if !field.IsPadding && int64(len(name))*7+4 <= rw {
+				fmt.Fprintf(w, `<text x="%d" y="%d" text-anchor="middle" pointer-events="none">%s</text>`+"\n",
+					x+rw/2, y+rowHeight/2+4, html.EscapeString(name))
+			}
+			start = end
+		}
+	}
+
+	// Cache line boundaries
+	for i := int64(1); i < lines; i++ {
+		y := topMargin + i*(rowHeight+rowGap) - rowGap/2
+		fmt.Fprintf(w, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#cc0000" stroke-dasharray="4,3"/>`+"\n",
+			leftMargin-48, y, leftMargin+lineSize*byteWidth, y)
+	}
+
+	fill = 0
+	for i, field := range fields {
+		color := paddingFill
+		if !field.IsPadding {
+			color = fieldFills[fill%len(fieldFills)]
+			fill++
+		}
+		y := topMargin + rowsHeight + int64(i)*legendLine
+		fmt.Fprintf(w, `<rect x="%d" y="%d" width="12" height="12" fill="%s" stroke="#444444"/>`+"\n", leftMargin, y, color)
+		fmt.Fprintf(w, `<text x="%d" y="%d">%s</text>`+"\n", leftMargin+20, y+10, html.EscapeString(fieldLabel(field)))
+	}
+	fmt.Fprintln(w, "</svg>")
+}
+
+// writeHTML writes a self-contained HTML page showing the SVG graphic
+// of fields, followed by a table of the fields.
+func writeHTML(w io.Writer, fields []st.Field, lineSize int64) {
+	name := html.EscapeString(structName(fields))
+	var padding int64
+	for _, field := range fields {
+		if field.IsPadding {
+			padding += field.Size
+		}
+	}
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Layout of %s</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; font-family: monospace; }
+td, th { border: 1px solid #cccccc; padding: 2px 8px; text-align: left; }
+tr.padding { background: %s; }
+</style>
+</head>
+<body>
+<h1>%s</h1>
+<p>%d bytes, %d bytes of padding, %d-byte cache lines.</p>
+`, name, paddingFill, name, fields[len(fields)-1].End, padding, lineSize)
+	writeSVG(w, fields, lineSize)
+	fmt.Fprintln(w, "<table>")
+	fmt.Fprintln(w, "<tr><th>Field</th><th>Type</th><th>Start</th><th>End</th><th>Size</th><th>Align</th></tr>")
+	for _, field := range fields {
+		if field.IsPadding {
+			fmt.Fprintf(w, `<tr class="padding"><td>padding</td><td></td><td>%d</td><td>%d</td><td>%d</td><td></td></tr>`+"\n",
+				field.Start, field.End, field.Size)
+			continue
+		}
+		fmt.Fprintf(w, "<tr><td>%s</td><td>%s</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td></tr>\n",
+			html.EscapeString(field.Name), html.EscapeString(field.Type), field.Start, field.End, field.Size, field.Align)
+	}
+	fmt.Fprintln(w, "</table>")
+	fmt.Fprintln(w, "</body>")
+	fmt.Fprintln(w, "</html>")
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/build"
+	"go/types"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"honnef.co/go/tools/gcsizes"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// parseArchs parses the value of the -arch flag.
+func parseArchs(s string) ([]string, error) {
+	if s == "" {
+		return []string{build.Default.GOARCH}, nil
+	}
+	var archs []string
+	for _, arch := range strings.Split(s, ",") {
+		arch = strings.TrimSpace(arch)
+		if types.SizesFor("gc", arch) == nil {
+			return nil, fmt.Errorf("unknown architecture %q", arch)
+		}
+		archs = append(archs, arch)
+	}
+	return archs, nil
+}
+
+// A fieldLayout is the position of a field on one architecture.
+type fieldLayout struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+	Size  int64 `json:"size"`
+	Align int64 `json:"align"`
+}
+
+// An archField is a field and its layouts on several architectures.
+type archField struct {
+	Name    string                 `json:"name"`
+	Type    string                 `json:"type"`
+	Layouts map[string]fieldLayout `json:"layouts"`
+	Differs bool                   `json:"differs"`
+}
+
+// An archComparison is the layout of a struct type on several
+// architectures. Padding isn't listed, but follows from the offsets.
+type archComparison struct {
+	Package string           `json:"package,omitempty"`
+	Name    string           `json:"name"`
+	Archs   []string         `json:"archs"`
+	Sizes   map[string]int64 `json:"sizes"`
+	Differs bool             `json:"differs"`
+	Fields  []archField      `json:"fields"`
+}
+
+// compareArchs returns the layouts of typ, a struct type called name,
+// on archs.
+func compareArchs(archs []string, typ types.Type, name string) archComparison {
+	c := archComparison{
+		Name:  name,
+		Archs: archs,
+		Sizes: map[string]int64{},
+	}
+	index := map[string]int{}
+	for _, arch := range archs {
+		s := gcsizes.ForArch(arch)
+		c.Sizes[arch] = s.Sizeof(typ)
+		for _, field := range sizes(s, typ.Underlying().(*types.Struct), name, 0, nil) {
+			if field.IsPadding {
+				continue
+			}
+			i, ok := index[field.Name]
+			if !ok {
+				i = len(c.Fields)
+				index[field.Name] = i
+				c.Fields = append(c.Fields, archField{
+					Name:    field.Name,
+					Type:    field.Type,
+					Layouts: map[string]fieldLayout{},
+				})
+			}
+			c.Fields[i].Layouts[arch] = fieldLayout{
+				Start: field.Start,
+				End:   field.End,
+				Size:  field.Size,
+				Align: field.Align,
+			}
+		}
+	}
+	for _, arch := range archs[1:] {
+		if c.Sizes[arch] != c.Sizes[archs[0]] {
+			c.Differs = true
+		}
+	}
+	for i := range c.Fields {
+		field := &c.Fields[i]
+		for _, arch := range archs[1:] {
+			if field.Layouts[arch] != field.Layouts[archs[0]] {
+				field.Differs = true
+				c.Differs = true
+			}
+		}
+	}
+	return c
+}
+
+// emitComparison prints c as a table with a column per architecture.
+// Fields whose layouts differ are marked.
+func emitComparison(c archComparison) {
+	var sizes []string
+	for _, arch := range c.Archs {
+		sizes = append(sizes, fmt.Sprintf("%s %d", arch, c.Sizes[arch]))
+	}
+	name := c.Name
+	if c.Package != "" {
+		name = c.Package + "." + name
+	}
+	fmt.Printf("%s: %s bytes\n", name, strings.Join(sizes, ", "))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "\tfield\ttype\t%s\t\n", strings.Join(c.Archs, "\t"))
+	for _, field := range c.Fields {
+		var cols []string
+		for _, arch := range c.Archs {
+			l := field.Layouts[arch]
+			cols = append(cols, fmt.Sprintf("%d-%d (size %d, align %d)", l.Start, l.End, l.Size, l.Align))
+		}
+		mark := ""
+		if field.Differs {
+			mark = "differs"
+		}
+		fmt.Fprintf(w, "\t%s\t%s\t%s\t%s\n", field.Name, field.Type, strings.Join(cols, "\t"), mark)
+	}
+	w.Flush()
+}
+
+// compareReport prints the layouts on archs of all struct types
+// declared in pkgs.
+func compareReport(conf loader.Config, pkgs []string, archs []string) {
+	for _, pkg := range pkgs {
+		conf.Import(pkg)
+	}
+	lprog, err := conf.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	out := []archComparison{}
+	for _, pkg := range lprog.InitialPackages() {
+		for _, named := range structTypes(pkg.Pkg) {
+			c := compareArchs(archs, named, named.Obj().Name())
+			c.Package = pkg.Pkg.Path()
+			out = append(out, c)
+		}
+	}
+	if fJSON {
+		json.NewEncoder(os.Stdout).Encode(out)
+		return
+	}
+	for _, c := range out {
+		emitComparison(c)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+)
+
+// readBaseline reads the layouts stored in the file name, as printed
+// by -json, keyed by the qualified names of the types.
+func readBaseline(name string) (map[string]structReport, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	out := map[string]structReport{}
+	dec := json.NewDecoder(f)
+	for {
+		var r structReport
+		if err := dec.Decode(&r); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("couldn't read baseline %s: %s", name, err)
+		}
+		out[r.Package+"."+r.Name] = r
+	}
+	return out, nil
+}
+
+// checkBaseline compares the total padding of reports with that of
+// the baseline stored in the file name. If it grew, it prints the
+// struct types whose padding grew and exits with status 1.
+func checkBaseline(reports []structReport, name string) {
+	baseline, err := readBaseline(name)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var before, after int64
+	for _, r := range baseline {
+		before += r.Padding
+	}
+	type growth struct {
+		name          string
+		before, after int64
+	}
+	var grown []growth
+	for _, r := range reports {
+		after += r.Padding
+		old := baseline[r.Package+"."+r.Name]
+		if r.Padding > old.Padding {
+			grown = append(grown, growth{r.Package + "." + r.Name, old.Padding, r.Padding})
+		}
+	}
+	if after <= before {
+		fmt.Printf("total padding: %d bytes (baseline: %d bytes)\n", after, before)
+		return
+	}
+	sort.Slice(grown, func(i, j int) bool {
+		return grown[i].after-grown[i].before > grown[j].after-grown[j].before
+	})
+	fmt.Printf("total padding grew from %d to %d bytes\n", before, after)
+	for _, g := range grown {
+		fmt.Printf("\t%s: padding grew from %d to %d bytes\n", g.name, g.before, g.after)
+	}
+	os.Exit(1)
+}
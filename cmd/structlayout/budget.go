@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"honnef.co/go/tools/gcsizes"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// A violation is a struct type that exceeds the layout budget.
+type violation struct {
+	Package string `json:"package"`
+	Name    string `json:"name"`
+	Arch    string `json:"arch,omitempty"`
+	Problem string `json:"problem"`
+}
+
+// hasBudget reports whether any budget flags were set.
+func hasBudget() bool {
+	return fMaxPadding >= 0 || fMaxSize >= 0 || fStraddle
+}
+
+// violations returns the ways in which r exceeds the budget set by the
+// -max-padding, -max-size and -straddle flags.
+func violations(r structReport) []string {
+	var out []string
+	if fMaxPadding >= 0 && r.Padding > fMaxPadding {
+		out = append(out, fmt.Sprintf("%d bytes of padding exceed the maximum of %d", r.Padding, fMaxPadding))
+	}
+	if fMaxSize >= 0 && r.Size > fMaxSize {
+		out = append(out, fmt.Sprintf("size of %d bytes exceeds the maximum of %d", r.Size, fMaxSize))
+	}
+	if fStraddle {
+		// Fields larger than a cache line necessarily straddle
+		// cache lines.
+		for _, field := range r.Fields {
+			if field.IsPadding || field.Size == 0 || field.Size > fCacheLine {
+				continue
+			}
+			if field.Start/fCacheLine != (field.End-1)/fCacheLine {
+				out = append(out, fmt.Sprintf("field %s (%d-%d) straddles the cache line boundary at %d",
+					field.Name, field.Start, field.End, (field.End-1)/fCacheLine*fCacheLine))
+			}
+		}
+	}
+	return out
+}
+
+// checkBudget prints the violations of the budget by reports, which
+// were computed for archs, and exits with a non-zero status if there
+// are any.
+func checkBudget(reports [][]structReport, archs []string) {
+	out := []violation{}
+	for i, rs := range reports {
+		for _, r := range rs {
+			for _, problem := range violations(r) {
+				v := violation{Package: r.Package, Name: r.Name, Problem: problem}
+				if len(archs) > 1 {
+					v.Arch = archs[i]
+				}
+				out = append(out, v)
+			}
+		}
+	}
+	if fJSON {
+		json.NewEncoder(os.Stdout).Encode(out)
+	} else {
+		for _, v := range out {
+			name := v.Name
+			if v.Package != "" {
+				name = v.Package + "." + name
+			}
+			if v.Arch != "" {
+				name += " (" + v.Arch + ")"
+			}
+			fmt.Printf("%s: %s\n", name, v.Problem)
+		}
+	}
+	if len(out) > 0 {
+		os.Exit(1)
+	}
+}
+
+// budgetReport checks the layouts of all struct types declared in
+// pkgs, on archs, against the budget.
+func budgetReport(conf loader.Config, pkgs []string, archs []string) {
+	for _, pkg := range pkgs {
+		conf.Import(pkg)
+	}
+	lprog, err := conf.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	var reports [][]structReport
+	for _, arch := range archs {
+		s := gcsizes.ForArch(arch)
+		var rs []structReport
+		for _, pkg := range lprog.InitialPackages() {
+			rs = append(rs, packageReports(pkg.Pkg, s)...)
+		}
+		reports = append(reports, rs)
+	}
+	checkBudget(reports, archs)
+}
@@ -1,39 +1,90 @@
 // structlayout displays the layout (field sizes and padding) of structs.
+//
+// Given a package and the name of a type, it displays the layout of
+// that type. Given only a package or a pattern such as ./..., it
+// displays the layouts of all struct types in the matching packages,
+// sorted by the amount of padding.
 package main
 
 import (
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"go/ast"
 	"go/build"
+	"go/token"
 	"go/types"
 	"log"
 	"os"
+	"strings"
 
 	"honnef.co/go/tools/gcsizes"
 	st "honnef.co/go/tools/structlayout"
 
+	"github.com/kisielk/gotool"
+
 	"golang.org/x/tools/go/loader"
 )
 
-var fJSON bool
+var (
+	fJSON       bool
+	fArch       string
+	fMaxPadding int64
+	fMaxSize    int64
+	fStraddle   bool
+	fCacheLine  int64
+	fBaseline   string
+)
 
 func init() {
 	flag.BoolVar(&fJSON, "json", false, "Format data as JSON")
+	flag.StringVar(&fArch, "arch", "", "Comma-separated list of architectures to compute layouts for, comparing them if there are several (default: $GOARCH)")
+	flag.Int64Var(&fMaxPadding, "max-padding", -1, "Report structs with more than `n` bytes of padding and exit with status 1")
+	flag.Int64Var(&fMaxSize, "max-size", -1, "Report structs larger than `n` bytes and exit with status 1")
+	flag.BoolVar(&fStraddle, "straddle", false, "Report structs with fields that straddle cache lines although they'd fit into one, and exit with status 1")
+	flag.Int64Var(&fCacheLine, "cache-line", 64, "Size of cache lines in bytes, for -straddle")
+	flag.StringVar(&fBaseline, "baseline", "", "Exit with status 1 if the total padding of all structs grew past the baseline stored in `file` by -json")
 }
 
 func main() {
 	log.SetFlags(0)
 	flag.Parse()
 
-	if len(flag.Args()) != 2 {
+	if len(flag.Args()) == 0 {
 		flag.Usage()
 		os.Exit(1)
 	}
 
+	archs, err := parseArchs(fArch)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if fCacheLine <= 0 {
+		log.Fatal("-cache-line must be positive")
+	}
 	conf := loader.Config{
 		Build: &build.Default,
 	}
+	if fBaseline != "" && (len(flag.Args()) != 1 || len(archs) > 1 || hasBudget()) {
+		log.Fatal("-baseline can only be used with a single architecture and without budgets, when reporting on packages")
+	}
+	if len(flag.Args()) == 1 {
+		if hasBudget() {
+			budgetReport(conf, gotool.ImportPaths(flag.Args()), archs)
+			return
+		}
+		if len(archs) > 1 {
+			compareReport(conf, gotool.ImportPaths(flag.Args()), archs)
+			return
+		}
+		report(conf, gotool.ImportPaths(flag.Args()), gcsizes.ForArch(archs[0]))
+		return
+	}
+	if len(flag.Args()) != 2 {
+		flag.Usage()
+		os.Exit(1)
+	}
 
 	var pkg string
 	var typName string
@@ -45,19 +96,37 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	var typ types.Type
-	obj := lprog.Package(pkg).Pkg.Scope().Lookup(typName)
-	if obj == nil {
-		log.Fatal("couldn't find type")
+	typ, err := lookupType(lprog, lprog.Package(pkg).Pkg, typName)
+	if err != nil {
+		log.Fatal(err)
 	}
-	typ = obj.Type()
 
 	st, ok := typ.Underlying().(*types.Struct)
 	if !ok {
 		log.Fatal("identifier is not a struct type")
 	}
 
-	fields := sizes(st, typ.(*types.Named).Obj().Name(), 0, nil)
+	if hasBudget() {
+		var reports [][]structReport
+		for _, arch := range archs {
+			r := newReport(gcsizes.ForArch(arch), pkg, typeName(typ), typ)
+			reports = append(reports, []structReport{r})
+		}
+		checkBudget(reports, archs)
+		return
+	}
+	if len(archs) > 1 {
+		c := compareArchs(archs, typ, typeName(typ))
+		if fJSON {
+			json.NewEncoder(os.Stdout).Encode(c)
+		} else {
+			emitComparison(c)
+		}
+		return
+	}
+
+	fields := sizes(gcsizes.ForArch(archs[0]), st, typeName(typ), 0, nil)
+	setDecls(fields, typeName(typ), fieldDecls(lprog, typ.(*types.Named).Obj()))
 	if fJSON {
 		emitJSON(fields)
 	} else {
@@ -65,6 +134,136 @@ func main() {
 	}
 }
 
+// lookupType returns the type called name in pkg. The name of a
+// generic type has to be followed by type arguments, as in
+// Pair[int,string]; type arguments may refer to the packages imported
+// by the file that declares the type.
+func lookupType(lprog *loader.Program, pkg *types.Package, name string) (types.Type, error) {
+	base := name
+	if i := strings.Index(name, "["); i >= 0 {
+		base = name[:i]
+	}
+	obj, ok := pkg.Scope().Lookup(base).(*types.TypeName)
+	if !ok {
+		return nil, errors.New("couldn't find type")
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok || named.TypeParams().Len() == 0 {
+		if base != name {
+			return nil, fmt.Errorf("%s is not a generic type", base)
+		}
+		return obj.Type(), nil
+	}
+	if base == name {
+		return nil, fmt.Errorf("%s is a generic type and needs type arguments, such as %s[%s]",
+			name, name, strings.TrimSuffix(strings.Repeat("int,", named.TypeParams().Len()), ","))
+	}
+	tv, err := types.Eval(lprog.Fset, pkg, obj.Pos(), name)
+	if err != nil {
+		return nil, err
+	}
+	if !tv.IsType() {
+		return nil, fmt.Errorf("%s is not a type", name)
+	}
+	return tv.Type, nil
+}
+
+// typeName returns the name of typ, including its type arguments,
+// without qualifying it with its package. It doesn't contain spaces,
+// so that it can be used as part of field names.
+func typeName(typ types.Type) string {
+	named := typ.(*types.Named)
+	return strings.Replace(types.TypeString(typ, types.RelativeTo(named.Obj().Pkg())), ", ", ",", -1)
+}
+
+// A fieldDecl describes the declaration of a field.
+type fieldDecl struct {
+	group int
+	pos   token.Position
+}
+
+// fieldDecls returns the declarations of the fields of obj, a struct
+// type, keyed by field name. Fields that are separated by blank lines
+// in the source belong to different groups.
+func fieldDecls(lprog *loader.Program, obj *types.TypeName) map[string]fieldDecl {
+	decls := map[string]fieldDecl{}
+	_, path, _ := lprog.PathEnclosingInterval(obj.Pos(), obj.Pos())
+	var spec *ast.TypeSpec
+	for _, node := range path {
+		if node, ok := node.(*ast.TypeSpec); ok {
+			spec = node
+			break
+		}
+	}
+	if spec == nil {
+		return decls
+	}
+	styp, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return decls
+	}
+	line := func(pos token.Pos) int { return lprog.Fset.Position(pos).Line }
+	group := 0
+	prevEnd := -1
+	for _, field := range styp.Fields.List {
+		start := field.Pos()
+		if field.Doc != nil {
+			start = field.Doc.Pos()
+		}
+		if prevEnd == -1 || line(start) > prevEnd+1 {
+			group++
+		}
+		prevEnd = line(field.End())
+		if field.Comment != nil {
+			prevEnd = line(field.Comment.End())
+		}
+		if len(field.Names) == 0 {
+			decls[embeddedName(field.Type)] = fieldDecl{group, lprog.Fset.Position(field.Pos())}
+		}
+		for _, name := range field.Names {
+			decls[name.Name] = fieldDecl{group, lprog.Fset.Position(name.Pos())}
+		}
+	}
+	return decls
+}
+
+// embeddedName returns the name of the embedded field of type expr.
+func embeddedName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name
+	case *ast.SelectorExpr:
+		return expr.Sel.Name
+	case *ast.StarExpr:
+		return embeddedName(expr.X)
+	case *ast.IndexExpr:
+		return embeddedName(expr.X)
+	case *ast.IndexListExpr:
+		return embeddedName(expr.X)
+	default:
+		return ""
+	}
+}
+
+// setDecls sets the groups and positions of fields, the layout of the
+// struct called prefix. Fields of nested structs belong to the group,
+// and have the position, of the top-level field.
+func setDecls(fields []st.Field, prefix string, decls map[string]fieldDecl) {
+	for i := range fields {
+		if fields[i].IsPadding {
+			continue
+		}
+		name := strings.TrimPrefix(fields[i].Name, prefix+".")
+		if j := strings.Index(name, "."); j >= 0 {
+			name = name[:j]
+		}
+		if decl, ok := decls[name]; ok {
+			fields[i].Group = decl.group
+			fields[i].Pos = decl.pos.String()
+		}
+	}
+}
+
 func emitJSON(fields []st.Field) {
 	if fields == nil {
 		fields = []st.Field{}
@@ -77,8 +276,7 @@ func emitText(fields []st.Field) {
 		fmt.Println(field)
 	}
 }
-func sizes(typ *types.Struct, prefix string, base int64, out []st.Field) []st.Field {
-	s := gcsizes.ForArch(build.Default.GOARCH)
+func sizes(s *gcsizes.Sizes, typ *types.Struct, prefix string, base int64, out []st.Field) []st.Field {
 	n := typ.NumFields()
 	var fields []*types.Var
 	for i := 0; i < n; i++ {
@@ -103,7 +301,7 @@ func sizes(typ *types.Struct, prefix string, base int64, out []st.Field) []st.Fi
 		}
 		size := s.Sizeof(field.Type())
 		if typ2, ok := field.Type().Underlying().(*types.Struct); ok && typ2.NumFields() != 0 {
-			out = sizes(typ2, prefix+"."+field.Name(), pos, out)
+			out = sizes(s, typ2, prefix+"."+field.Name(), pos, out)
 		} else {
 			out = append(out, st.Field{
 				Name:  prefix + "." + field.Name(),
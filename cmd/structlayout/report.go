@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"log"
+	"os"
+	"sort"
+
+	"honnef.co/go/tools/gcsizes"
+	st "honnef.co/go/tools/structlayout"
+
+	"golang.org/x/tools/go/loader"
+)
+
+// A structReport is the layout of a struct type and the amount of
+// padding in it.
+type structReport struct {
+	Package string     `json:"package"`
+	Name    string     `json:"name"`
+	Size    int64      `json:"size"`
+	Padding int64      `json:"padding"`
+	Fields  []st.Field `json:"fields"`
+}
+
+// report prints the layouts of all struct types declared in pkgs,
+// using sizes s, sorted by the amount of padding, largest first. As
+// JSON, the layouts are printed one object per line, sorted by package
+// and name, so that they can be processed as a stream and stored as a
+// baseline for -baseline.
+func report(conf loader.Config, pkgs []string, s *gcsizes.Sizes) {
+	for _, pkg := range pkgs {
+		conf.Import(pkg)
+	}
+	lprog, err := conf.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	pkgInfos := lprog.InitialPackages()
+	sort.Slice(pkgInfos, func(i, j int) bool {
+		return pkgInfos[i].Pkg.Path() < pkgInfos[j].Pkg.Path()
+	})
+	var reports []structReport
+	enc := json.NewEncoder(os.Stdout)
+	for _, pkg := range pkgInfos {
+		rs := packageReports(pkg.Pkg, s)
+		if fJSON && fBaseline == "" {
+			for _, r := range rs {
+				enc.Encode(r)
+			}
+			continue
+		}
+		reports = append(reports, rs...)
+	}
+	if fBaseline != "" {
+		checkBaseline(reports, fBaseline)
+		return
+	}
+	if fJSON {
+		return
+	}
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].Padding > reports[j].Padding
+	})
+	for _, r := range reports {
+		fmt.Printf("%s.%s: %d bytes, %d bytes of padding\n", r.Package, r.Name, r.Size, r.Padding)
+		for _, field := range r.Fields {
+			fmt.Printf("\t%s\n", field)
+		}
+	}
+}
+
+// structTypes returns the struct types declared at the package level
+// of pkg, sorted by name. Generic types are skipped, as their layouts
+// depend on their type arguments.
+func structTypes(pkg *types.Package) []*types.Named {
+	var out []*types.Named
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		obj, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || obj.IsAlias() {
+			continue
+		}
+		named, ok := obj.Type().(*types.Named)
+		if !ok || named.TypeParams().Len() > 0 {
+			continue
+		}
+		st, ok := named.Underlying().(*types.Struct)
+		if !ok || st.NumFields() == 0 {
+			continue
+		}
+		out = append(out, named)
+	}
+	return out
+}
+
+// packageReports returns the layouts of the struct types declared at
+// the package level of pkg, using sizes s, sorted by name.
+func packageReports(pkg *types.Package, s *gcsizes.Sizes) []structReport {
+	var out []structReport
+	for _, named := range structTypes(pkg) {
+		out = append(out, newReport(s, pkg.Path(), named.Obj().Name(), named))
+	}
+	return out
+}
+
+// newReport returns the layout of typ, a struct type called name
+// declared in the package with path pkg, using sizes s.
+func newReport(s *gcsizes.Sizes, pkg, name string, typ types.Type) structReport {
+	fields := sizes(s, typ.Underlying().(*types.Struct), name, 0, nil)
+	r := structReport{
+		Package: pkg,
+		Name:    name,
+		Size:    s.Sizeof(typ),
+		Fields:  fields,
+	}
+	for _, field := range fields {
+		if field.IsPadding {
+			r.Padding += field.Size
+		}
+	}
+	return r
+}
@@ -0,0 +1,234 @@
+// symsearch searches the declarations of Go packages by name and
+// prints their kinds, signatures and positions.
+package main // import "honnef.co/go/tools/cmd/symsearch"
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/kisielk/gotool"
+	"golang.org/x/tools/go/buildutil"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [flags] <query> [packages]\n\n", os.Args[0])
+	fmt.Fprintln(os.Stderr, "The query is a regular expression matched against names such as Reader or Buffer.WriteString.")
+	fmt.Fprintln(os.Stderr, "Packages default to ./....")
+	fmt.Fprintln(os.Stderr, "Flags:")
+	flag.PrintDefaults()
+}
+
+type symbol struct {
+	Position  string `json:"position"`
+	Package   string `json:"package"`
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	Signature string `json:"signature"`
+
+	pos token.Position
+}
+
+func main() {
+	var tags buildutil.TagsFlag
+	flag.Var(&tags, "tags", "List of build tags")
+	tests := flag.Bool("tests", false, "Include tests")
+	exported := flag.Bool("exported", false, "Only search exported declarations")
+	asJSON := flag.Bool("json", false, "Print results as JSON")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	query := flag.Arg(0)
+	// Like in many editors, queries without uppercase letters are
+	// case-insensitive.
+	if strings.IndexFunc(query, unicode.IsUpper) == -1 {
+		query = "(?i)" + query
+	}
+	re, err := regexp.Compile(query)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	args := flag.Args()[1:]
+	if len(args) == 0 {
+		args = []string{"./..."}
+	}
+
+	ctx := build.Default
+	ctx.BuildTags = tags
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fset := token.NewFileSet()
+	var syms []symbol
+	for _, path := range gotool.ImportPaths(args) {
+		bpkg, err := ctx.Import(path, cwd, 0)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		}
+		files := append([]string(nil), bpkg.GoFiles...)
+		files = append(files, bpkg.CgoFiles...)
+		if *tests {
+			files = append(files, bpkg.TestGoFiles...)
+			files = append(files, bpkg.XTestGoFiles...)
+		}
+		for _, name := range files {
+			f, err := parser.ParseFile(fset, filepath.Join(bpkg.Dir, name), nil, 0)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				continue
+			}
+			for _, sym := range declarations(fset, f) {
+				if *exported && !ast.IsExported(sym.Name[strings.LastIndex(sym.Name, ".")+1:]) {
+					continue
+				}
+				if !re.MatchString(sym.Name) {
+					continue
+				}
+				sym.Package = bpkg.ImportPath
+				syms = append(syms, sym)
+			}
+		}
+	}
+	sort.SliceStable(syms, func(i, j int) bool {
+		pi, pj := syms[i].pos, syms[j].pos
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		return pi.Offset < pj.Offset
+	})
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	if *asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		enc.Encode(syms)
+	} else {
+		for _, sym := range syms {
+			fmt.Fprintf(w, "%s: %s %s.%s: %s\n", sym.Position, sym.Kind, sym.Package, sym.Name, sym.Signature)
+		}
+	}
+	if len(syms) == 0 {
+		w.Flush()
+		os.Exit(1)
+	}
+}
+
+// declarations returns the package-level declarations of f, as well as
+// the methods and fields of its types. Methods and fields are named
+// after their types, as in Buffer.WriteString.
+func declarations(fset *token.FileSet, f *ast.File) []symbol {
+	var out []symbol
+	add := func(id *ast.Ident, name, kind string, sig ast.Node) {
+		if id.Name == "_" {
+			return
+		}
+		pos := fset.Position(id.Pos())
+		sym := symbol{
+			Position: pos.String(),
+			Name:     name,
+			Kind:     kind,
+			pos:      pos,
+		}
+		if sig != nil {
+			sym.Signature = render(fset, sig)
+		}
+		out = append(out, sym)
+	}
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			sig := *decl
+			sig.Doc = nil
+			sig.Body = nil
+			if decl.Recv == nil || len(decl.Recv.List) == 0 {
+				add(decl.Name, decl.Name.Name, "func", &sig)
+			} else {
+				recv := receiverName(decl.Recv.List[0].Type)
+				add(decl.Name, recv+"."+decl.Name.Name, "method", &sig)
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					add(spec.Name, spec.Name.Name, "type", typeSignature(spec))
+					var fields *ast.FieldList
+					kind := "field"
+					switch T := spec.Type.(type) {
+					case *ast.StructType:
+						fields = T.Fields
+					case *ast.InterfaceType:
+						fields, kind = T.Methods, "method"
+					}
+					if fields == nil {
+						continue
+					}
+					for _, field := range fields.List {
+						for _, name := range field.Names {
+							add(name, spec.Name.Name+"."+name.Name, kind, field.Type)
+						}
+					}
+				case *ast.ValueSpec:
+					for _, name := range spec.Names {
+						var sig ast.Node
+						if spec.Type != nil {
+							sig = spec.Type
+						}
+						add(name, name.Name, decl.Tok.String(), sig)
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// typeSignature returns the type of spec, abbreviating struct and
+// interface types to their keywords.
+func typeSignature(spec *ast.TypeSpec) ast.Node {
+	switch spec.Type.(type) {
+	case *ast.StructType:
+		return ast.NewIdent("struct")
+	case *ast.InterfaceType:
+		return ast.NewIdent("interface")
+	}
+	return spec.Type
+}
+
+func receiverName(T ast.Expr) string {
+	if star, ok := T.(*ast.StarExpr); ok {
+		T = star.X
+	}
+	if id, ok := T.(*ast.Ident); ok {
+		return id.Name
+	}
+	return "?"
+}
+
+func render(fset *token.FileSet, n ast.Node) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, fset, n)
+	// Signatures are printed on one line.
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
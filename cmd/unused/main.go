@@ -3,9 +3,12 @@
 package main // import "honnef.co/go/tools/cmd/unused"
 
 import (
+	"flag"
 	"log"
 	"os"
 
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/lint"
 	"honnef.co/go/tools/lint/lintutil"
 	"honnef.co/go/tools/unused"
 )
@@ -16,9 +19,17 @@ var (
 	fFunctions    bool
 	fTypes        bool
 	fVariables    bool
+	fWriteOnly    bool
+	fParams       bool
 	fDebug        string
+	fGraph        string
+	fGraphFormat  string
 	fWholeProgram bool
 	fReflection   bool
+	fStrictIfaces bool
+	fJSON         bool
+	fWorkspace    bool
+	fVariants     string
 )
 
 func newChecker(mode unused.CheckMode) *unused.Checker {
@@ -31,9 +42,18 @@ func newChecker(mode unused.CheckMode) *unused.Checker {
 		}
 		checker.Debug = debug
 	}
+	if fGraph != "" {
+		graph, err := os.Create(fGraph)
+		if err != nil {
+			log.Fatal("couldn't open graph file:", err)
+		}
+		checker.Graph = graph
+		checker.GraphFormat = fGraphFormat
+	}
 
 	checker.WholeProgram = fWholeProgram
 	checker.ConsiderReflection = fReflection
+	checker.StrictInterfaces = fStrictIfaces
 	return checker
 }
 
@@ -46,11 +66,36 @@ func main() {
 	fs.BoolVar(&fFunctions, "funcs", true, "Report unused functions and methods")
 	fs.BoolVar(&fTypes, "types", true, "Report unused types")
 	fs.BoolVar(&fVariables, "vars", true, "Report unused variables")
+	fs.BoolVar(&fWriteOnly, "writeonly", false, "Report fields and package-level variables that are written to but never read")
+	fs.BoolVar(&fParams, "params", false, "Report unused function parameters and named results that are never assigned")
 	fs.StringVar(&fDebug, "debug", "", "Write a debug graph to `file`. Existing files will be overwritten.")
+	fs.StringVar(&fGraph, "graph", "", "Write the graph of declarations and the declarations they use to `file`. Existing files will be overwritten.")
+	fs.StringVar(&fGraphFormat, "graph-format", "dot", "Format of the -graph file, either dot or json")
 	fs.BoolVar(&fWholeProgram, "exported", false, "Treat arguments as a program and report unused exported identifiers")
 	fs.BoolVar(&fReflection, "reflect", true, "Consider identifiers as used when it's likely they'll be accessed via reflection")
+	fs.BoolVar(&fStrictIfaces, "strict-interfaces", false, "Only consider methods used for implementing interfaces if the interfaces are used themselves")
+	fs.BoolVar(&fWorkspace, "workspace", false, "Treat all packages of the current module or go.work workspace as a program and report unused exported identifiers")
+	fs.StringVar(&fVariants, "variants", "", "Whitespace-separated list of build `variants` of the form GOOS/GOARCH[,tag...]. Only identifiers that are unused in all variants are reported.")
+	fs.BoolVar(&fJSON, "json", false, "Print results as JSON, including why each identifier is considered unused")
 	fs.Parse(os.Args[1:])
 
+	if fGraphFormat != "dot" && fGraphFormat != "json" {
+		log.Fatalf("unsupported graph format %q", fGraphFormat)
+	}
+
+	pkgs := fs.Args()
+	if fWorkspace {
+		if len(pkgs) > 0 {
+			log.Fatal("-workspace doesn't accept package arguments")
+		}
+		var err error
+		pkgs, err = workspacePackages()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fWholeProgram = true
+	}
+
 	var mode unused.CheckMode
 	if fConstants {
 		mode |= unused.CheckConstants
@@ -67,8 +112,51 @@ func main() {
 	if fVariables {
 		mode |= unused.CheckVariables
 	}
+	if fWriteOnly {
+		mode |= unused.CheckWriteOnly
+	}
+	if fParams {
+		mode |= unused.CheckParams
+	}
+
+	if fVariants != "" {
+		variants, err := lintutil.ParseVariants(fVariants)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if fJSON {
+			log.Fatal("-json can't be combined with -variants")
+		}
+		if fGraph != "" {
+			log.Fatal("-graph can't be combined with -variants")
+		}
+		newLintChecker := func() lint.Checker {
+			return unused.NewLintChecker(newChecker(mode))
+		}
+		lintutil.ProcessVariants(newLintChecker, fs, pkgs, variants)
+		return
+	}
 
 	checker := newChecker(mode)
 	l := unused.NewLintChecker(checker)
-	lintutil.ProcessFlagSet(l, fs)
+	if fJSON {
+		printJSON(l, fs, pkgs)
+		return
+	}
+	lintutil.ProcessPackages(l, fs, pkgs)
+}
+
+func printJSON(l *unused.LintChecker, fs *flag.FlagSet, pkgs []string) {
+	ps, lprog, err := lintutil.Lint(l, pkgs, lintutil.FlagOptions(fs))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := l.WriteJSON(os.Stdout, lprog.Fset, ps); err != nil {
+		log.Fatal(err)
+	}
+	for _, p := range ps {
+		if p.Severity != config.SeverityWarning {
+			os.Exit(1)
+		}
+	}
 }
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"honnef.co/go/tools/internal/gomod"
+)
+
+// workspacePackages returns the directories of all packages in the
+// workspace containing the current directory, relative to it.
+// Directories that the go command ignores, as well as nested modules
+// that aren't part of the workspace, are skipped.
+func workspacePackages() ([]string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	roots, err := gomod.WorkspaceRoots(wd)
+	if err != nil {
+		return nil, err
+	}
+	isRoot := map[string]bool{}
+	for _, root := range roots {
+		isRoot[root] = true
+	}
+
+	var pkgs []string
+	seen := map[string]bool{}
+	for _, root := range roots {
+		err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if fi.IsDir() {
+				name := fi.Name()
+				if path != root && (name == "testdata" || name == "vendor" ||
+					strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+					return filepath.SkipDir
+				}
+				if !isRoot[path] {
+					if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+						return filepath.SkipDir
+					}
+				}
+				return nil
+			}
+			if !strings.HasSuffix(path, ".go") {
+				return nil
+			}
+			if dir := filepath.Dir(path); !seen[dir] {
+				seen[dir] = true
+				pkgs = append(pkgs, dir)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i, pkg := range pkgs {
+		rel, err := filepath.Rel(wd, pkg)
+		if err != nil {
+			return nil, err
+		}
+		if rel != "." && !strings.HasPrefix(rel, "..") {
+			rel = "." + string(filepath.Separator) + rel
+		}
+		pkgs[i] = rel
+	}
+	return pkgs, nil
+}
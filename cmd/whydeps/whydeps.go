@@ -0,0 +1,260 @@
+// whydeps explains why packages depend on another package by printing
+// the import chains that lead from them to it. It is the forward
+// counterpart of rdeps.
+//
+// Given a single package, it prints all import chains from it to the
+// target package, or the shortest ones with -k. Given a pattern such
+// as ./..., it answers which of the matching packages pull in the
+// target package, and how.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"go/build"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kisielk/gotool"
+	"golang.org/x/tools/go/buildutil"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [flags] <packages> <target>\n\n", os.Args[0])
+	fmt.Fprintln(os.Stderr, "Flags:")
+	flag.PrintDefaults()
+}
+
+func main() {
+	var tags buildutil.TagsFlag
+	flag.Var(&tags, "tags", "List of build tags")
+	k := flag.Int("k", 0, "Print only the `n` shortest import chains of each package (0 prints all)")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	ctx := build.Default
+	ctx.BuildTags = tags
+	wd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	g := &graph{
+		ctx:      &ctx,
+		imports:  map[string][]string{},
+		goroot:   map[string]bool{},
+		dirs:     map[string]string{},
+		resolved: map[[2]string]string{},
+	}
+	target, err := g.resolve(flag.Arg(flag.NArg()-1), wd)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	// The standard library doesn't import packages outside of it.
+	g.skipStdlib = !g.goroot[target]
+
+	var starts []string
+	for _, pkg := range gotool.ImportPaths(flag.Args()[:flag.NArg()-1]) {
+		path, err := g.resolve(pkg, wd)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		}
+		starts = append(starts, path)
+	}
+	for _, start := range starts {
+		g.load(start)
+	}
+
+	reaches := map[string]bool{}
+	var visit func(pkg string) bool
+	visit = func(pkg string) bool {
+		if r, ok := reaches[pkg]; ok {
+			return r
+		}
+		// Guard against import cycles in invalid code.
+		reaches[pkg] = false
+		r := pkg == target
+		for _, imp := range g.imports[pkg] {
+			if visit(imp) {
+				r = true
+			}
+		}
+		reaches[pkg] = r
+		return r
+	}
+
+	found := false
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	for _, start := range starts {
+		if start == target || !visit(start) {
+			continue
+		}
+		found = true
+		printChains(w, g, start, target, reaches, *k)
+	}
+	if !found {
+		w.Flush()
+		fmt.Fprintf(os.Stderr, "%s isn't imported by any of the packages\n", target)
+		os.Exit(1)
+	}
+}
+
+// printChains prints the import chains from start to target, or the k
+// shortest ones if k > 0. reaches reports whether a package leads to
+// target.
+func printChains(w *bufio.Writer, g *graph, start, target string, reaches map[string]bool, k int) {
+	if k > 0 {
+		// Chains are found breadth-first, shortest ones first.
+		queue := [][]string{{start}}
+		for n := 0; len(queue) > 0 && n < k; {
+			chain := queue[0]
+			queue = queue[1:]
+			last := chain[len(chain)-1]
+			if last == target {
+				fmt.Fprintln(w, strings.Join(chain, " -> "))
+				n++
+				continue
+			}
+			for _, imp := range g.imports[last] {
+				if reaches[imp] {
+					next := make([]string, len(chain), len(chain)+1)
+					copy(next, chain)
+					queue = append(queue, append(next, imp))
+				}
+			}
+		}
+		return
+	}
+
+	var chain []string
+	var walk func(pkg string)
+	walk = func(pkg string) {
+		chain = append(chain, pkg)
+		if pkg == target {
+			fmt.Fprintln(w, strings.Join(chain, " -> "))
+		} else {
+			for _, imp := range g.imports[pkg] {
+				if reaches[imp] {
+					walk(imp)
+				}
+			}
+		}
+		chain = chain[:len(chain)-1]
+	}
+	walk(start)
+}
+
+// A graph is the import graph of the packages reachable from a set of
+// packages.
+type graph struct {
+	ctx        *build.Context
+	skipStdlib bool                 // don't look at the imports of the standard library
+	imports    map[string][]string  // sorted canonical import paths
+	goroot     map[string]bool      // packages in the standard library
+	dirs       map[string]string    // directories of packages
+	resolved   map[[2]string]string // canonical import paths of imports from directories
+}
+
+// resolve returns the canonical import path of the package that path,
+// an import path or a relative path, refers to from the directory dir.
+func (g *graph) resolve(path, dir string) (string, error) {
+	key := [2]string{path, dir}
+	if canon, ok := g.resolved[key]; ok {
+		return canon, nil
+	}
+	bpkg, err := g.ctx.Import(path, dir, build.FindOnly)
+	if err != nil {
+		return "", err
+	}
+	canon := bpkg.ImportPath
+	if build.IsLocalImport(canon) || strings.HasPrefix(canon, "_/") {
+		// Outside of GOPATH, packages named by relative paths don't
+		// know their import paths. Derive them from the module.
+		if p, ok := moduleImportPath(bpkg.Dir); ok {
+			canon = p
+		}
+	}
+	g.resolved[key] = canon
+	g.goroot[canon] = bpkg.Goroot
+	g.dirs[canon] = bpkg.Dir
+	return canon, nil
+}
+
+// load adds pkg, which has been resolved, and all packages it imports
+// to the graph.
+func (g *graph) load(pkg string) {
+	if _, ok := g.imports[pkg]; ok {
+		return
+	}
+	g.imports[pkg] = nil
+	if g.goroot[pkg] && g.skipStdlib {
+		return
+	}
+	bpkg, err := g.ctx.ImportDir(g.dirs[pkg], 0)
+	if err != nil {
+		if _, ok := err.(*build.NoGoError); !ok {
+			fmt.Fprintf(os.Stderr, "error in package %s: %s\n", pkg, err)
+		}
+		if bpkg == nil {
+			return
+		}
+	}
+	var imports []string
+	for _, imp := range bpkg.Imports {
+		if imp == "C" {
+			// "C" is fake
+			continue
+		}
+		canon, err := g.resolve(imp, bpkg.Dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error in package %s: %s\n", pkg, err)
+			continue
+		}
+		imports = append(imports, canon)
+	}
+	sort.Strings(imports)
+	g.imports[pkg] = imports
+	for _, imp := range imports {
+		g.load(imp)
+	}
+}
+
+// moduleImportPath returns the import path of the package in dir,
+// derived from the path of the module containing it.
+func moduleImportPath(dir string) (string, bool) {
+	for root := dir; ; {
+		if data, err := ioutil.ReadFile(filepath.Join(root, "go.mod")); err == nil {
+			sc := bufio.NewScanner(strings.NewReader(string(data)))
+			for sc.Scan() {
+				line := strings.TrimSpace(sc.Text())
+				if strings.HasPrefix(line, "module ") {
+					mod := strings.Trim(strings.TrimSpace(line[len("module "):]), `"`)
+					rel, err := filepath.Rel(root, dir)
+					if err != nil {
+						return "", false
+					}
+					return path.Join(mod, filepath.ToSlash(rel)), true
+				}
+			}
+			return "", false
+		}
+		parent := filepath.Dir(root)
+		if parent == root {
+			return "", false
+		}
+		root = parent
+	}
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Severities of problems.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// An Override changes the configuration of checks for files matching
+// any of its path patterns. Patterns are relative to the directory of
+// the configuration file and may use ** to match any number of
+// directories, as in "pkg/api/**".
+type Override struct {
+	Paths    []string          `toml:"paths"`
+	Checks   []string          `toml:"checks"`
+	Severity map[string]string `toml:"severity"`
+}
+
+func validateSeverity(m map[string]string) error {
+	for check, sev := range m {
+		if sev != SeverityError && sev != SeverityWarning {
+			return fmt.Errorf("invalid severity %q for %s, must be %q or %q", sev, check, SeverityError, SeverityWarning)
+		}
+	}
+	return nil
+}
+
+func mergeSeverity(parent, child map[string]string) map[string]string {
+	if len(child) == 0 {
+		return parent
+	}
+	out := map[string]string{}
+	for k, v := range parent {
+		out[k] = v
+	}
+	for k, v := range child {
+		out[k] = v
+	}
+	return out
+}
+
+// matchPath reports whether the slash-separated name matches pattern,
+// where ** matches zero or more path elements.
+func matchPath(pattern, name string) bool {
+	return matchElems(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchElems(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchElems(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if m, _ := path.Match(pattern[0], name[0]); !m {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+func (o Override) matches(filename string) bool {
+	if abs, err := filepath.Abs(filename); err == nil {
+		filename = abs
+	}
+	filename = filepath.ToSlash(filename)
+	for _, p := range o.Paths {
+		if matchPath(p, filename) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchCheck(pattern, check string) bool {
+	if pattern == "all" {
+		return true
+	}
+	m, _ := path.Match(pattern, check)
+	return m
+}
+
+// Enabled reports whether check is enabled for the file filename.
+func (c Config) Enabled(check, filename string) bool {
+	lists := [][]string{c.Checks}
+	for _, o := range c.Overrides {
+		if o.matches(filename) {
+			lists = append(lists, o.Checks)
+		}
+	}
+	enabled := false
+	for _, list := range lists {
+		for _, entry := range list {
+			pattern := strings.TrimPrefix(entry, "-")
+			if matchCheck(pattern, check) {
+				enabled = !strings.HasPrefix(entry, "-")
+			}
+		}
+	}
+	return enabled
+}
+
+// severityOf returns the severity of check in m, preferring exact
+// matches over patterns and longer patterns over shorter ones.
+func severityOf(m map[string]string, check string) (string, bool) {
+	if sev, ok := m[check]; ok {
+		return sev, true
+	}
+	var patterns []string
+	for p := range m {
+		if matchCheck(p, check) {
+			patterns = append(patterns, p)
+		}
+	}
+	if len(patterns) == 0 {
+		return "", false
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if patterns[i] == "all" || patterns[j] == "all" {
+			return patterns[j] == "all" && patterns[i] != "all"
+		}
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+	return m[patterns[0]], true
+}
+
+// SeverityOf returns the severity of problems of check in the file
+// filename.
+func (c Config) SeverityOf(check, filename string) string {
+	sev := SeverityError
+	if s, ok := severityOf(c.Severity, check); ok {
+		sev = s
+	}
+	for _, o := range c.Overrides {
+		if !o.matches(filename) {
+			continue
+		}
+		if s, ok := severityOf(o.Severity, check); ok {
+			sev = s
+		}
+	}
+	return sev
+}
@@ -0,0 +1,257 @@
+// Package config loads the configuration of the linters from
+// staticcheck.conf files.
+//
+// Configuration files are looked up in the directory of a package and
+// its parent directories, up to the root of the module containing the
+// package, and in the staticcheck directory of the user's
+// configuration directory. Files closer to the package take
+// precedence over files further up the tree, which take precedence
+// over the user's configuration. List options that
+// contain the special value "inherit" extend the list of the parent
+// configuration instead of replacing it.
+package config // import "honnef.co/go/tools/config"
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"honnef.co/go/tools/internal/gomod"
+)
+
+// ConfigName is the name of configuration files.
+const ConfigName = "staticcheck.conf"
+
+// Config is the configuration of the linters for a package.
+type Config struct {
+	// Checks is the list of enabled checks. Entries are check names
+	// or patterns such as ST* and may be negated with a leading -, as
+	// in ["all", "-ST1020"]. Later entries take precedence.
+	Checks []string `toml:"checks"`
+
+	// Severity maps check names or patterns to the severity of their
+	// problems, either "error" or "warning".
+	Severity map[string]string `toml:"severity"`
+
+	// Overrides change the enabled checks and their severities for
+	// files matching path patterns.
+	Overrides []Override `toml:"overrides"`
+
+	// Initialisms is the list of initialisms that names are expected
+	// to spell in a consistent case, such as ID or URL.
+	Initialisms []string `toml:"initialisms"`
+
+	// PackageNameDenylist is the list of package names that are too
+	// generic to describe a package's purpose.
+	PackageNameDenylist []string `toml:"package_name_denylist"`
+
+	// DocCommentsExempt is a list of patterns of files that don't
+	// require doc comments on exported identifiers. Patterns are
+	// matched against the import path of the package joined with the
+	// name of the file, such as "example.com/pkg/internal/*/*.go".
+	DocCommentsExempt []string `toml:"doc_comments_exempt"`
+
+	// Options holds the options of individual checks, keyed by check
+	// and option name, as in
+	//
+	//   [options.ST1006]
+	//   max_receiver_name_length = 3
+	//
+	// The values are validated by the checks that declare them.
+	Options map[string]map[string]interface{} `toml:"options"`
+
+	// Rules are user-defined checks that report code matching syntax
+	// patterns.
+	Rules []Rule `toml:"rules"`
+}
+
+// DefaultConfig is the configuration that configuration files are
+// applied on top of.
+var DefaultConfig = Config{
+	Checks: []string{"all"},
+	Initialisms: []string{
+		"ACL", "API", "ASCII", "CPU", "CSS", "DNS",
+		"EOF", "GUID", "HTML", "HTTP", "HTTPS", "ID",
+		"IP", "JSON", "QPS", "RAM", "RPC", "SLA",
+		"SMTP", "SQL", "SSH", "TCP", "TLS", "TTL",
+		"UDP", "UI", "GID", "UID", "UUID", "URI",
+		"URL", "UTF8", "VM", "XML", "XMPP", "XSRF",
+		"XSS",
+	},
+	PackageNameDenylist: []string{"util", "common", "helpers", "base"},
+}
+
+func mergeLists(parent, child []string) []string {
+	if child == nil {
+		return parent
+	}
+	var out []string
+	for _, s := range child {
+		if s == "inherit" {
+			out = append(out, parent...)
+		} else {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func mergeOptions(parent, child map[string]map[string]interface{}) map[string]map[string]interface{} {
+	if len(child) == 0 {
+		return parent
+	}
+	out := map[string]map[string]interface{}{}
+	for _, m := range []map[string]map[string]interface{}{parent, child} {
+		for check, opts := range m {
+			if out[check] == nil {
+				out[check] = map[string]interface{}{}
+			}
+			for k, v := range opts {
+				out[check][k] = v
+			}
+		}
+	}
+	return out
+}
+
+// Merge returns the configuration that results from applying child
+// on top of c.
+func (c Config) Merge(child Config) Config {
+	return Config{
+		Checks:              mergeLists(c.Checks, child.Checks),
+		Severity:            mergeSeverity(c.Severity, child.Severity),
+		Overrides:           append(append([]Override(nil), c.Overrides...), child.Overrides...),
+		Initialisms:         mergeLists(c.Initialisms, child.Initialisms),
+		PackageNameDenylist: mergeLists(c.PackageNameDenylist, child.PackageNameDenylist),
+		DocCommentsExempt:   mergeLists(c.DocCommentsExempt, child.DocCommentsExempt),
+		Options:             mergeOptions(c.Options, child.Options),
+		Rules:               mergeRules(c.Rules, child.Rules),
+	}
+}
+
+// Parse parses the configuration file at path.
+func Parse(path string) (Config, error) {
+	var cfg Config
+	md, err := toml.DecodeFile(path, &cfg)
+	if err != nil {
+		return Config{}, err
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return Config{}, &UnknownKeyError{Path: path, Key: undecoded[0].String()}
+	}
+	if err := validateSeverity(cfg.Severity); err != nil {
+		return Config{}, fmt.Errorf("%s: %s", path, err)
+	}
+	if err := validateRules(cfg.Rules); err != nil {
+		return Config{}, fmt.Errorf("%s: %s", path, err)
+	}
+	for i, s := range cfg.Initialisms {
+		// Names are matched against initialisms in upper case.
+		if s != "inherit" {
+			cfg.Initialisms[i] = strings.ToUpper(s)
+		}
+	}
+	dir := filepath.Dir(path)
+	for i := range cfg.Overrides {
+		o := &cfg.Overrides[i]
+		if len(o.Paths) == 0 {
+			return Config{}, fmt.Errorf("%s: override without paths", path)
+		}
+		if err := validateSeverity(o.Severity); err != nil {
+			return Config{}, fmt.Errorf("%s: %s", path, err)
+		}
+		for j, p := range o.Paths {
+			o.Paths[j] = filepath.ToSlash(filepath.Join(dir, filepath.FromSlash(p)))
+		}
+	}
+	return cfg, nil
+}
+
+// An UnknownKeyError is returned when a configuration file contains
+// a key that isn't understood.
+type UnknownKeyError struct {
+	Path string
+	Key  string
+}
+
+func (err *UnknownKeyError) Error() string {
+	return err.Path + ": unknown configuration key " + err.Key
+}
+
+// userConfigDir returns the directory of the user's configuration
+// file. It is a variable so that tests can replace it.
+var userConfigDir = func() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "staticcheck"), nil
+}
+
+// ModuleGoVersion returns the minor Go version, such as 13 for Go
+// 1.13, that the go directive of the module containing dir declares.
+// It returns false if dir isn't part of a module or its go.mod file
+// lacks a go directive.
+func ModuleGoVersion(dir string) (int, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return 0, false
+	}
+	return gomod.GoVersion(dir)
+}
+
+// Files returns the configuration files that apply to the package in
+// dir, in the order they are applied: the user's configuration file,
+// followed by the files in dir and its parent directories, outermost
+// first. Inside a module, directories above the module root are not
+// considered.
+func Files(dir string) ([]string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	root, _ := gomod.ModuleRoot(dir)
+	var paths []string
+	for {
+		path := filepath.Join(dir, ConfigName)
+		if _, err := os.Stat(path); err == nil {
+			paths = append(paths, path)
+		}
+		parent := filepath.Dir(dir)
+		if dir == root || parent == dir {
+			break
+		}
+		dir = parent
+	}
+	if udir, err := userConfigDir(); err == nil {
+		path := filepath.Join(udir, ConfigName)
+		if _, err := os.Stat(path); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	for i, j := 0, len(paths)-1; i < j; i, j = i+1, j-1 {
+		paths[i], paths[j] = paths[j], paths[i]
+	}
+	return paths, nil
+}
+
+// Load returns the effective configuration for the package in dir,
+// merging DefaultConfig with all configuration files returned by
+// Files.
+func Load(dir string) (Config, error) {
+	paths, err := Files(dir)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg := DefaultConfig
+	for _, path := range paths {
+		child, err := Parse(path)
+		if err != nil {
+			return Config{}, err
+		}
+		cfg = cfg.Merge(child)
+	}
+	return cfg, nil
+}
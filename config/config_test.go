@@ -0,0 +1,316 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	parent := Config{Initialisms: []string{"ID", "URL"}}
+	tests := []struct {
+		child Config
+		want  []string
+	}{
+		{Config{}, []string{"ID", "URL"}},
+		{Config{Initialisms: []string{"SKU"}}, []string{"SKU"}},
+		{Config{Initialisms: []string{"inherit", "SKU"}}, []string{"ID", "URL", "SKU"}},
+		{Config{Initialisms: []string{}}, []string{}},
+	}
+	for _, tt := range tests {
+		got := parent.Merge(tt.child).Initialisms
+		if !reflect.DeepEqual(got, tt.want) && !(len(got) == 0 && len(tt.want) == 0) {
+			t.Errorf("Merge(%v) = %v, want %v", tt.child.Initialisms, got, tt.want)
+		}
+	}
+}
+
+func TestMergeOptions(t *testing.T) {
+	parent := Config{Options: map[string]map[string]interface{}{
+		"ST1006": {"a": int64(1), "b": int64(2)},
+		"ST1020": {"c": true},
+	}}
+	child := Config{Options: map[string]map[string]interface{}{
+		"ST1006": {"b": int64(3)},
+	}}
+	got := parent.Merge(child).Options
+	want := map[string]map[string]interface{}{
+		"ST1006": {"a": int64(1), "b": int64(3)},
+		"ST1020": {"c": true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if parent.Options["ST1006"]["b"] != int64(2) {
+		t.Errorf("Merge modified the parent configuration")
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	cfg := Config{
+		Checks: []string{"all", "-ST1020", "-ST1021"},
+		Overrides: []Override{
+			{Paths: []string{"/repo/pkg/api/**"}, Checks: []string{"ST1020"}},
+			{Paths: []string{"/repo/internal/legacy/**"}, Checks: []string{"-ST*"}},
+		},
+	}
+	tests := []struct {
+		check, file string
+		want        bool
+	}{
+		{"ST1003", "/repo/main.go", true},
+		{"ST1020", "/repo/main.go", false},
+		{"ST1020", "/repo/pkg/api/api.go", true},
+		{"ST1020", "/repo/pkg/api/v1/api.go", true},
+		{"ST1021", "/repo/pkg/api/api.go", false},
+		{"ST1003", "/repo/internal/legacy/old.go", false},
+		{"SA1000", "/repo/internal/legacy/old.go", true},
+	}
+	for _, tt := range tests {
+		if got := cfg.Enabled(tt.check, tt.file); got != tt.want {
+			t.Errorf("Enabled(%s, %s) = %t, want %t", tt.check, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestSeverityOf(t *testing.T) {
+	cfg := Config{
+		Severity: map[string]string{"ST*": SeverityWarning, "ST1003": SeverityError},
+		Overrides: []Override{
+			{Paths: []string{"/repo/pkg/api/**"}, Severity: map[string]string{"all": SeverityError}},
+		},
+	}
+	tests := []struct {
+		check, file string
+		want        string
+	}{
+		{"SA1000", "/repo/main.go", SeverityError},
+		{"ST1020", "/repo/main.go", SeverityWarning},
+		{"ST1003", "/repo/main.go", SeverityError},
+		{"ST1020", "/repo/pkg/api/api.go", SeverityError},
+	}
+	for _, tt := range tests {
+		if got := cfg.SeverityOf(tt.check, tt.file); got != tt.want {
+			t.Errorf("SeverityOf(%s, %s) = %s, want %s", tt.check, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"/a/**", "/a/b.go", true},
+		{"/a/**", "/a/b/c.go", true},
+		{"/a/**/c.go", "/a/c.go", true},
+		{"/a/**/c.go", "/a/b/d/c.go", true},
+		{"/a/*.go", "/a/b/c.go", false},
+		{"/a/*.go", "/a/c.go", true},
+		{"/a/**", "/b/c.go", false},
+	}
+	for _, tt := range tests {
+		if got := matchPath(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchPath(%q, %q) = %t, want %t", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFiles(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	write := func(path string) {
+		path = filepath.Join(tmp, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("user/staticcheck.conf")
+	write("staticcheck.conf")
+	write("mod/go.mod")
+	write("mod/staticcheck.conf")
+	write("mod/pkg/staticcheck.conf")
+	write("gopath/src/pkg/staticcheck.conf")
+
+	old := userConfigDir
+	defer func() { userConfigDir = old }()
+	userConfigDir = func() (string, error) { return filepath.Join(tmp, "user"), nil }
+
+	tests := []struct {
+		dir  string
+		want []string
+	}{
+		{"mod/pkg", []string{"user", "mod", "mod/pkg"}},
+		{"mod", []string{"user", "mod"}},
+		{"gopath/src/pkg", []string{"user", "", "gopath/src/pkg"}},
+	}
+	for _, tt := range tests {
+		got, err := Files(filepath.Join(tmp, tt.dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var want []string
+		for _, dir := range tt.want {
+			want = append(want, filepath.Join(tmp, dir, ConfigName))
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Files(%s) = %v, want %v", tt.dir, got, want)
+		}
+	}
+}
+
+func TestMergeRules(t *testing.T) {
+	parent := []Rule{{ID: "A1", Message: "a"}, {ID: "A2", Message: "b"}}
+	child := []Rule{{ID: "A2", Message: "c"}, {ID: "B1", Message: "d"}}
+	got := mergeRules(parent, child)
+	want := []Rule{{ID: "A1", Message: "a"}, {ID: "A2", Message: "c"}, {ID: "B1", Message: "d"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	path := filepath.Join(tmp, ConfigName)
+
+	tests := []struct {
+		src string
+		err string
+	}{
+		{`
+[[rules]]
+id = "ACME1000"
+pattern = "log.Printf($*_)"
+message = "use the structured logger"
+`, ""},
+		{`
+[[rules]]
+id = "ACME1000"
+pattern = "f($x)"
+where = ["$x: kind(pointer)"]
+message = "don't pass pointers to f"
+`, ""},
+		{`
+[[rules]]
+pattern = "f()"
+message = "m"
+`, "rule without id"},
+		{`
+[[rules]]
+id = "ACME1000"
+pattern = "f()"
+`, "rule ACME1000 without message"},
+		{`
+[[rules]]
+id = "ACME1000"
+pattern = "f("
+message = "m"
+`, "rule ACME1000: couldn't parse pattern"},
+		{`
+[[rules]]
+id = "ACME1000"
+pattern = "f($x)"
+where = ["$x: kind(lists)"]
+message = "m"
+`, `rule ACME1000: unknown kind "lists"`},
+		{`
+[[rules]]
+id = "ACME1000"
+pattern = "f()"
+message = "m"
+
+[[rules]]
+id = "ACME1000"
+pattern = "g()"
+message = "m"
+`, "duplicate rule ACME1000"},
+	}
+	for _, tt := range tests {
+		if err := ioutil.WriteFile(path, []byte(tt.src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := Parse(path)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("unexpected error %q for %s", err, tt.src)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("got error %v, want %q for %s", err, tt.err, tt.src)
+		}
+	}
+}
+
+func TestParseInitialisms(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	path := filepath.Join(tmp, ConfigName)
+	if err := ioutil.WriteFile(path, []byte(`initialisms = ["inherit", "Http", "sku", "QPS"]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"inherit", "HTTP", "SKU", "QPS"}; !reflect.DeepEqual(cfg.Initialisms, want) {
+		t.Errorf("got initialisms %q, want %q", cfg.Initialisms, want)
+	}
+}
+
+func TestModuleGoVersion(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	mods := map[string]string{
+		"a":  "module a\n\ngo 1.13\n",
+		"b":  "module b\n\ngo 1.21.0\n",
+		"c":  "module c\n",
+		"d":  "module d\ngo 1.18rc1\n",
+		"no": "",
+	}
+	for dir, data := range mods {
+		if err := os.MkdirAll(filepath.Join(tmp, dir, "pkg"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if dir == "no" {
+			continue
+		}
+		if err := ioutil.WriteFile(filepath.Join(tmp, dir, "go.mod"), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		dir  string
+		want int
+		ok   bool
+	}{
+		{"a/pkg", 13, true},
+		{"b", 21, true},
+		{"c", 0, false},
+		{"d/pkg", 18, true},
+		{"no/pkg", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ModuleGoVersion(filepath.Join(tmp, tt.dir))
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ModuleGoVersion(%s) = %d, %t, want %d, %t", tt.dir, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"honnef.co/go/tools/pattern"
+)
+
+// A Rule is a user-defined check that reports code matching a syntax
+// pattern, as in
+//
+//	[[rules]]
+//	id = "ACME1000"
+//	pattern = "log.Printf($*_)"
+//	message = "use the structured logger instead of log.Printf"
+//
+// See package honnef.co/go/tools/pattern for the syntax of patterns
+// and constraints.
+type Rule struct {
+	// ID is the name of the check. Rules are enabled, disabled and
+	// ignored by their IDs, like built-in checks.
+	ID string `toml:"id"`
+	// Pattern is the syntax pattern of the code to report.
+	Pattern string `toml:"pattern"`
+	// Where are the constraints of the pattern's wildcards, such as
+	// "$x: kind(pointer)".
+	Where []string `toml:"where"`
+	// Message is the text of the problems reported for matches.
+	Message string `toml:"message"`
+}
+
+// Compile parses the pattern and constraints of r.
+func (r Rule) Compile() (*pattern.Pattern, error) {
+	p, err := pattern.Parse(r.Pattern)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range r.Where {
+		if err := p.Where(c); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func validateRules(rules []Rule) error {
+	seen := map[string]bool{}
+	for _, r := range rules {
+		if r.ID == "" {
+			return fmt.Errorf("rule without id")
+		}
+		if strings.ContainsAny(r.ID, " \t,*?[]-") || r.ID == "all" {
+			return fmt.Errorf("invalid rule id %q", r.ID)
+		}
+		if seen[r.ID] {
+			return fmt.Errorf("duplicate rule %s", r.ID)
+		}
+		seen[r.ID] = true
+		if r.Message == "" {
+			return fmt.Errorf("rule %s without message", r.ID)
+		}
+		if _, err := r.Compile(); err != nil {
+			return fmt.Errorf("rule %s: %s", r.ID, err)
+		}
+	}
+	return nil
+}
+
+// mergeRules returns the rules of parent and child. Rules of child
+// replace those of parent with the same ID.
+func mergeRules(parent, child []Rule) []Rule {
+	if len(child) == 0 {
+		return parent
+	}
+	replaced := map[string]bool{}
+	for _, r := range child {
+		replaced[r.ID] = true
+	}
+	var out []Rule
+	for _, r := range parent {
+		if !replaced[r.ID] {
+			out = append(out, r)
+		}
+	}
+	return append(out, child...)
+}
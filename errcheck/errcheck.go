@@ -1,7 +1,9 @@
 package errcheck
 
 import (
+	"go/ast"
 	"go/types"
+	"strings"
 
 	"honnef.co/go/tools/functions"
 	"honnef.co/go/tools/lint"
@@ -114,7 +116,10 @@ func (c *Checker) CheckErrcheck(j *lint.Job) {
 						continue
 					}
 				}
-				j.Errorf(ins, "unchecked error")
+				p := j.Errorf(ins, "unchecked error")
+				if call, ok := ins.(*ssa.Call); ok {
+					p.Fix = checkErrorFix(j, ssafn, call)
+				}
 			}
 		}
 	}
@@ -152,3 +157,112 @@ func isReadOnlyFile(val ssa.Value, seen map[ssa.Value]bool) bool {
 	}
 	return false
 }
+
+// checkErrorFix returns a fix that checks the error returned by call,
+// a statement of fn, and returns it together with the zero values of
+// fn's other results. It returns nil if fn doesn't return an error as
+// its last result, or if their zero values can't be written.
+func checkErrorFix(j *lint.Job, fn *ssa.Function, call *ssa.Call) *lint.Fix {
+	if fn.Syntax() == nil {
+		return nil
+	}
+	results := fn.Signature.Results()
+	if results.Len() == 0 || types.TypeString(results.At(results.Len()-1).Type(), nil) != "error" {
+		return nil
+	}
+	var stmt *ast.ExprStmt
+	ast.Inspect(fn.Syntax(), func(node ast.Node) bool {
+		if s, ok := node.(*ast.ExprStmt); ok {
+			if expr, ok := s.X.(*ast.CallExpr); ok && expr.Lparen == call.Pos() {
+				stmt = s
+			}
+		}
+		return stmt == nil
+	})
+	if stmt == nil {
+		return nil
+	}
+
+	// Qualify names the way the file does, which may import packages
+	// under a different name, or not at all.
+	pkg := j.NodePackage(stmt).Pkg
+	names := map[*types.Package]string{}
+	for _, spec := range j.File(stmt).Imports {
+		obj := j.Program.Info.Implicits[spec]
+		if spec.Name != nil {
+			obj = j.Program.Info.Defs[spec.Name]
+		}
+		pkgName, ok := obj.(*types.PkgName)
+		if !ok {
+			continue
+		}
+		switch pkgName.Name() {
+		case "_":
+		case ".":
+			names[pkgName.Imported()] = ""
+		default:
+			names[pkgName.Imported()] = pkgName.Name()
+		}
+	}
+	qualified := true
+	qf := func(other *types.Package) string {
+		if other == pkg {
+			return ""
+		}
+		name, ok := names[other]
+		if !ok {
+			qualified = false
+		}
+		return name
+	}
+	var ret []string
+	for i := 0; i < results.Len()-1; i++ {
+		zero, ok := zeroValue(results.At(i).Type(), qf)
+		if !ok || !qualified {
+			return nil
+		}
+		ret = append(ret, zero)
+	}
+	ret = append(ret, "err")
+	lhs := []string{"err"}
+	for i := 1; i < call.Common().Signature().Results().Len(); i++ {
+		lhs = append([]string{"_"}, lhs...)
+	}
+
+	indent := strings.Repeat("\t", j.Program.SSA.Fset.Position(stmt.Pos()).Column-1)
+	text := "if " + strings.Join(lhs, ", ") + " := " + j.Render(stmt.X) + "; err != nil {\n" +
+		indent + "\treturn " + strings.Join(ret, ", ") + "\n" +
+		indent + "}"
+	return lint.Replace(stmt, text)
+}
+
+// zeroValue returns the zero value of T, written as in the code of
+// the package that qf qualifies names relative to.
+func zeroValue(T types.Type, qf types.Qualifier) (string, bool) {
+	if _, ok := T.(*types.TypeParam); ok {
+		// The zero value of a type parameter can only be written as
+		// *new(T) or as a variable, neither of which we want to
+		// suggest.
+		return "", false
+	}
+	switch U := T.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case U.Info()&types.IsBoolean != 0:
+			return "false", true
+		case U.Info()&types.IsNumeric != 0:
+			return "0", true
+		case U.Info()&types.IsString != 0:
+			return `""`, true
+		case U.Kind() == types.UnsafePointer:
+			return "nil", true
+		}
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Signature, *types.Interface:
+		return "nil", true
+	case *types.Struct, *types.Array:
+		if _, ok := T.(*types.Named); ok {
+			return types.TypeString(T, qf) + "{}", true
+		}
+	}
+	return "", false
+}
@@ -1,6 +1,8 @@
 package errcheck
 
 import (
+	"go/token"
+	"go/types"
 	"testing"
 
 	"honnef.co/go/tools/lint/testutil"
@@ -10,3 +12,27 @@ func TestAll(t *testing.T) {
 	c := NewChecker()
 	testutil.TestAll(t, c, "")
 }
+
+func TestZeroValue(t *testing.T) {
+	pkg := types.NewPackage("example.com/pkg", "pkg")
+	named := types.NewNamed(types.NewTypeName(token.NoPos, pkg, "T", nil), types.NewStruct(nil, nil), nil)
+	param := types.NewTypeParam(types.NewTypeName(token.NoPos, pkg, "P", nil), types.NewInterfaceType(nil, nil))
+	tests := []struct {
+		typ  types.Type
+		want string
+		ok   bool
+	}{
+		{types.Typ[types.Int], "0", true},
+		{types.Typ[types.String], `""`, true},
+		{types.NewPointer(named), "nil", true},
+		{named, "pkg.T{}", true},
+		{types.NewStruct(nil, nil), "", false},
+		{param, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := zeroValue(tt.typ, (*types.Package).Name)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("zeroValue(%s) = %q, %t, want %q, %t", tt.typ, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package pkg
+
+import (
+	. "bytes"
+	stdio "io"
+	"os"
+	"strings"
+)
+
+type T struct{ x int }
+
+func basic(name string) (int, string, *T, error) {
+	os.Remove(name) // MATCH /unchecked error/
+	return 0, "", nil, nil
+}
+
+func local(name string) (T, error) {
+	os.Remove(name) // MATCH /unchecked error/
+	return T{}, nil
+}
+
+func unnamed(name string) (struct{}, error) {
+	os.Remove(name) // MATCH /unchecked error/
+	return struct{}{}, nil
+}
+
+func renamed(name string) (stdio.SectionReader, strings.Builder, error) {
+	os.Remove(name) // MATCH /unchecked error/
+	return stdio.SectionReader{}, strings.Builder{}, nil
+}
+
+func dot(name string) (Buffer, error) {
+	os.Remove(name) // MATCH /unchecked error/
+	return Buffer{}, nil
+}
+
+func noError(name string) int {
+	os.Remove(name) // MATCH /unchecked error/
+	return 0
+}
@@ -0,0 +1,96 @@
+package functions
+
+import (
+	"go/token"
+	"go/types"
+
+	"honnef.co/go/tools/ssa"
+)
+
+// An Allocation is an instruction that allocates memory on the heap.
+type Allocation struct {
+	Instr ssa.Instruction
+	// Kind describes the pattern that causes the allocation, such as
+	// "escaping variable" or "string concatenation".
+	Kind string
+	// InLoop reports whether the instruction is part of a loop, where
+	// it may allocate many times per call of the function.
+	InLoop bool
+}
+
+// Allocations returns the heap allocations in fn, in the order of
+// their instructions. Local variables and composite literals whose
+// addresses escape the function are considered heap allocated; the
+// SSA builder only keeps on the stack what it can prove doesn't
+// escape, which makes this an upper bound.
+func Allocations(fn *ssa.Function) []Allocation {
+	if fn.Blocks == nil {
+		return nil
+	}
+	inLoop := map[*ssa.BasicBlock]bool{}
+	for _, l := range findLoops(fn) {
+		for b := range l {
+			inLoop[b] = true
+		}
+	}
+	var allocs []Allocation
+	for _, b := range fn.Blocks {
+		for _, ins := range b.Instrs {
+			if kind := allocKind(ins); kind != "" {
+				allocs = append(allocs, Allocation{Instr: ins, Kind: kind, InLoop: inLoop[b]})
+			}
+		}
+	}
+	return allocs
+}
+
+// allocKind returns the kind of heap allocation that ins makes, or the
+// empty string if it doesn't allocate.
+func allocKind(ins ssa.Instruction) string {
+	switch ins := ins.(type) {
+	case *ssa.Alloc:
+		if !ins.Heap {
+			return ""
+		}
+		switch ins.Comment {
+		case "complit", "slicelit":
+			return "escaping composite literal"
+		case "new":
+			return "escaping new"
+		case "varargs":
+			return "variadic arguments"
+		}
+		return "escaping variable"
+	case *ssa.MakeMap:
+		return "make map"
+	case *ssa.MakeChan:
+		return "make channel"
+	case *ssa.MakeSlice:
+		return "make slice"
+	case *ssa.MakeInterface:
+		return "conversion to interface"
+	case *ssa.MakeClosure:
+		if len(ins.Bindings) > 0 {
+			return "closure"
+		}
+	case *ssa.BinOp:
+		if b, ok := ins.Type().Underlying().(*types.Basic); ok && b.Info()&types.IsString != 0 && ins.Op == token.ADD {
+			return "string concatenation"
+		}
+	case *ssa.Convert:
+		// Conversions between strings and byte or rune slices
+		// copy.
+		_, fromSlice := ins.X.Type().Underlying().(*types.Slice)
+		_, toSlice := ins.Type().Underlying().(*types.Slice)
+		if fromSlice != toSlice {
+			return "string conversion"
+		}
+	case *ssa.Go:
+		return "goroutine"
+	case *ssa.Call:
+		if b, ok := ins.Common().Value.(*ssa.Builtin); ok && b.Name() == "append" {
+			return "append"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,60 @@
+package functions
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+const allocsSrc = `package pkg
+
+var sink interface{}
+var ch chan int
+
+func allocs(s string, b []byte, xs []int) []int {
+	x := 0
+	defer func() { x++ }()
+	m := map[int]int{}
+	m[0] = len(s + "x")
+	for i := 0; i < 3; i++ {
+		xs = append(xs, i)
+	}
+	sink = string(b)
+	ch = make(chan int)
+	go noAllocs(1)
+	return xs
+}
+
+func noAllocs(x int) int {
+	var arr [4]int
+	arr[x%4] = x
+	return arr[0]
+}
+`
+
+func TestAllocations(t *testing.T) {
+	_, pkg := build(t, allocsSrc)
+
+	var got []string
+	for _, alloc := range Allocations(pkg.Func("allocs")) {
+		got = append(got, fmt.Sprintf("%s %t", alloc.Kind, alloc.InLoop))
+	}
+	want := []string{
+		"escaping variable false",
+		"closure false",
+		"make map false",
+		"string concatenation false",
+		"variadic arguments true",
+		"append true",
+		"string conversion false",
+		"conversion to interface false",
+		"make channel false",
+		"goroutine false",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got allocations\n%q\nwant\n%q", got, want)
+	}
+	if allocs := Allocations(pkg.Func("noAllocs")); len(allocs) != 0 {
+		t.Errorf("got %d allocations in noAllocs, want none", len(allocs))
+	}
+}
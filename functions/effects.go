@@ -0,0 +1,150 @@
+package functions
+
+import (
+	"go/token"
+
+	"honnef.co/go/tools/ssa"
+)
+
+// Effects summarizes the side effects of a function, including those
+// of the functions it calls.
+type Effects struct {
+	// The function stores to package-level variables, or to memory
+	// they refer to.
+	WritesGlobals bool
+	// The function makes system calls, directly or through packages
+	// such as os and net.
+	IO bool
+	// The function panics explicitly. Run-time panics, such as those
+	// caused by out of bounds accesses, aren't considered.
+	MayPanic bool
+	// The function allocates memory on the heap.
+	Allocates bool
+	// The function makes calls whose targets aren't known
+	// statically, such as calls of interface methods, and whose
+	// effects aren't included.
+	Dynamic bool
+}
+
+func (e Effects) union(o Effects) Effects {
+	return Effects{
+		WritesGlobals: e.WritesGlobals || o.WritesGlobals,
+		IO:            e.IO || o.IO,
+		MayPanic:      e.MayPanic || o.MayPanic,
+		Allocates:     e.Allocates || o.Allocates,
+		Dynamic:       e.Dynamic || o.Dynamic,
+	}
+}
+
+// ioPackages are the packages whose functions without Go bodies
+// perform system calls.
+var ioPackages = map[string]bool{
+	"syscall":               true,
+	"internal/poll":         true,
+	"internal/syscall/unix": true,
+	"golang.org/x/sys/unix": true,
+}
+
+// localEffects returns the effects of the instructions of fn, not
+// counting the functions it calls statically.
+func localEffects(fn *ssa.Function) Effects {
+	var e Effects
+	if fn.Blocks == nil {
+		e.IO = fn.Pkg != nil && ioPackages[fn.Pkg.Pkg.Path()]
+		return e
+	}
+	for _, b := range fn.Blocks {
+		for _, ins := range b.Instrs {
+			if allocKind(ins) != "" {
+				e.Allocates = true
+			}
+			switch ins := ins.(type) {
+			case *ssa.Store:
+				addr := ins.Addr
+			loop:
+				for {
+					switch v := addr.(type) {
+					case *ssa.FieldAddr:
+						addr = v.X
+					case *ssa.IndexAddr:
+						addr = v.X
+					case *ssa.UnOp:
+						// Writes through pointers, slices and maps
+						// loaded from globals modify global state,
+						// too.
+						if v.Op != token.MUL {
+							break loop
+						}
+						addr = v.X
+					default:
+						break loop
+					}
+				}
+				if _, ok := addr.(*ssa.Global); ok {
+					e.WritesGlobals = true
+				}
+			case *ssa.Panic:
+				e.MayPanic = true
+			case ssa.CallInstruction:
+				common := ins.Common()
+				if _, ok := common.Value.(*ssa.Builtin); ok {
+					continue
+				}
+				if common.StaticCallee() == nil {
+					e.Dynamic = true
+				}
+			}
+		}
+	}
+	return e
+}
+
+// computeEffects computes the effects of all functions in the call
+// graph, propagating the effects of callees to their callers until a
+// fixed point is reached.
+func (d *Descriptions) computeEffects() {
+	d.effects = map[*ssa.Function]Effects{}
+	var queue []*ssa.Function
+	for fn := range d.CallGraph.Nodes {
+		if fn == nil {
+			continue
+		}
+		d.effects[fn] = localEffects(fn)
+		queue = append(queue, fn)
+	}
+	queued := map[*ssa.Function]bool{}
+	for _, fn := range queue {
+		queued[fn] = true
+	}
+	for len(queue) > 0 {
+		fn := queue[0]
+		queue = queue[1:]
+		queued[fn] = false
+		e := d.effects[fn]
+		for _, edge := range d.CallGraph.Nodes[fn].In {
+			caller := edge.Caller.Func
+			if caller == nil {
+				continue
+			}
+			old := d.effects[caller]
+			if n := old.union(e); n != old {
+				d.effects[caller] = n
+				if !queued[caller] {
+					queued[caller] = true
+					queue = append(queue, caller)
+				}
+			}
+		}
+	}
+}
+
+// Effects returns the side effects of fn and the functions it calls.
+// They are computed for the whole call graph the first time Effects
+// is called.
+func (d *Descriptions) Effects(fn *ssa.Function) Effects {
+	d.effectsOnce.Do(d.computeEffects)
+	if e, ok := d.effects[fn]; ok {
+		return e
+	}
+	return localEffects(fn)
+}
@@ -0,0 +1,83 @@
+package functions
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/loader"
+	"honnef.co/go/tools/ssa"
+	"honnef.co/go/tools/ssa/ssautil"
+)
+
+func build(t *testing.T, src string) (*ssa.Program, *ssa.Package) {
+	conf := loader.Config{}
+	f, err := conf.ParseFile("pkg.go", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.CreateFromFiles("pkg", f)
+	lprog, err := conf.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prog := ssautil.CreateProgram(lprog, 0)
+	prog.Build()
+	return prog, prog.Package(lprog.Created[0].Pkg)
+}
+
+const effectsSrc = `package pkg
+
+var global int
+var gp *int
+
+type I interface{ M() }
+
+func writes()        { global = 1 }
+func writesThrough() { *gp = 1 }
+func callsWrites()   { writes() }
+func panics()        { panic("boom") }
+func allocates() *int { return new(int) }
+func dynamic(i I)    { i.M() }
+func pure(x int) int { return x * 2 }
+func callsPure() int { return pure(1) + pure(2) }
+func external()
+
+func even(n int) {
+	if n > 0 {
+		odd(n - 1)
+	}
+}
+
+func odd(n int) {
+	even(n - 1)
+	if n == 1 {
+		panic("odd")
+	}
+}
+`
+
+func TestEffects(t *testing.T) {
+	prog, pkg := build(t, effectsSrc)
+	d := NewDescriptions(prog)
+
+	tests := map[string]Effects{
+		"writes":        {WritesGlobals: true},
+		"writesThrough": {WritesGlobals: true},
+		"callsWrites":   {WritesGlobals: true},
+		// Converting the argument of panic to interface{}
+		// allocates.
+		"panics":    {MayPanic: true, Allocates: true},
+		"allocates": {Allocates: true},
+		"dynamic":   {Dynamic: true},
+		"pure":      {},
+		"callsPure": {},
+		"external":  {},
+		// The effects of odd propagate to even and back.
+		"even": {MayPanic: true, Allocates: true},
+		"odd":  {MayPanic: true, Allocates: true},
+	}
+	for name, want := range tests {
+		if got := d.Effects(pkg.Func(name)); got != want {
+			t.Errorf("%s: got %+v, want %+v", name, got, want)
+		}
+	}
+}
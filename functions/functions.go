@@ -60,6 +60,9 @@ type Descriptions struct {
 	CallGraph *callgraph.Graph
 	mu        sync.Mutex
 	cache     map[*ssa.Function]*descriptionEntry
+
+	effectsOnce sync.Once
+	effects     map[*ssa.Function]Effects
 }
 
 func NewDescriptions(prog *ssa.Program) *Descriptions {
@@ -0,0 +1,196 @@
+// Package diff computes line-based differences between two texts and
+// formats them as unified diffs.
+package diff // import "honnef.co/go/tools/internal/diff"
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// context is the number of unchanged lines printed around each change.
+const context = 3
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+type op struct {
+	kind opKind
+	line string
+}
+
+func splitLines(b []byte) []string {
+	if len(b) == 0 {
+		return nil
+	}
+	var lines []string
+	for len(b) > 0 {
+		i := bytes.IndexByte(b, '\n')
+		if i == -1 {
+			lines = append(lines, string(b)+"\n\\ No newline at end of file\n")
+			break
+		}
+		lines = append(lines, string(b[:i+1]))
+		b = b[i+1:]
+	}
+	return lines
+}
+
+// ops computes the shortest edit script that transforms a into b,
+// using the algorithm described in Eugene W. Myers, "An O(ND)
+// Difference Algorithm and Its Variations".
+func ops(a, b []string) []op {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+	v := make([]int, 2*max+1)
+	var trace [][]int
+	for d := 0; d <= max; d++ {
+		vc := make([]int, len(v))
+		copy(vc, v)
+		trace = append(trace, vc)
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[max+k-1] < v[max+k+1]) {
+				x = v[max+k+1]
+			} else {
+				x = v[max+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[max+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b, d)
+			}
+		}
+	}
+	panic("unreachable")
+}
+
+func backtrack(trace [][]int, a, b []string, d int) []op {
+	max := len(a) + len(b)
+	x, y := len(a), len(b)
+	var out []op
+	for ; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[max+k-1] < v[max+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[max+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			out = append(out, op{opEqual, a[x]})
+		}
+		if d > 0 {
+			if x == prevX {
+				y--
+				out = append(out, op{opInsert, b[y]})
+			} else {
+				x--
+				out = append(out, op{opDelete, a[x]})
+			}
+		}
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
+
+// Unified returns a unified diff of old and new, labelled with
+// oldName and newName. It returns nil if the texts are identical.
+func Unified(oldName, newName string, old, new []byte) []byte {
+	if bytes.Equal(old, new) {
+		return nil
+	}
+	edits := ops(splitLines(old), splitLines(new))
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", oldName, newName)
+	for i := 0; i < len(edits); {
+		if edits[i].kind == opEqual {
+			i++
+			continue
+		}
+		// Find the extent of the hunk, merging changes that are
+		// separated by at most 2*context unchanged lines.
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(edits) {
+			if edits[end].kind != opEqual {
+				end++
+				continue
+			}
+			n := 0
+			for end+n < len(edits) && edits[end+n].kind == opEqual {
+				n++
+			}
+			if end+n == len(edits) || n > 2*context {
+				if n > context {
+					n = context
+				}
+				end += n
+				break
+			}
+			end += n
+		}
+
+		// Compute line numbers of the hunk.
+		oldStart, newStart := 1, 1
+		for _, e := range edits[:start] {
+			if e.kind != opInsert {
+				oldStart++
+			}
+			if e.kind != opDelete {
+				newStart++
+			}
+		}
+		oldLen, newLen := 0, 0
+		for _, e := range edits[start:end] {
+			if e.kind != opInsert {
+				oldLen++
+			}
+			if e.kind != opDelete {
+				newLen++
+			}
+		}
+		if oldLen == 0 {
+			oldStart--
+		}
+		if newLen == 0 {
+			newStart--
+		}
+		fmt.Fprintf(&buf, "@@ -%s +%s @@\n", hunkRange(oldStart, oldLen), hunkRange(newStart, newLen))
+		for _, e := range edits[start:end] {
+			buf.WriteByte(byte(e.kind))
+			buf.WriteString(e.line)
+		}
+		i = end
+	}
+	return buf.Bytes()
+}
+
+func hunkRange(start, n int) string {
+	if n == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, n)
+}
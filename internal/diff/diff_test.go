@@ -0,0 +1,31 @@
+package diff
+
+import "testing"
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		old, new string
+		want     string
+	}{
+		{"a\nb\nc\n", "a\nb\nc\n", ""},
+		{"a\nb\nc\n", "a\nx\nc\n", "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n"},
+		{"", "a\n", "--- old\n+++ new\n@@ -0,0 +1 @@\n+a\n"},
+		{"a\n", "", "--- old\n+++ new\n@@ -1 +0,0 @@\n-a\n"},
+		{
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			"0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			"--- old\n+++ new\n@@ -1,3 +1,4 @@\n+0\n 1\n 2\n 3\n@@ -7,4 +8,3 @@\n 7\n 8\n 9\n-10\n",
+		},
+		{
+			"1\n2\n3\n4\n5\n",
+			"1\n2\nx\n4\n5\n6\n",
+			"--- old\n+++ new\n@@ -1,5 +1,6 @@\n 1\n 2\n-3\n+x\n 4\n 5\n+6\n",
+		},
+	}
+	for _, tt := range tests {
+		got := string(Unified("old", "new", []byte(tt.old), []byte(tt.new)))
+		if got != tt.want {
+			t.Errorf("Unified(%q, %q) = %q, want %q", tt.old, tt.new, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// Package gomod finds go.mod and go.work files and reads the few
+// directives of theirs that the tools need.
+package gomod // import "honnef.co/go/tools/internal/gomod"
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// FindUp returns the closest directory, starting at dir and walking
+// up the tree, that contains a file called name.
+func FindUp(dir, name string) (string, bool) {
+	for {
+		if fi, err := os.Stat(filepath.Join(dir, name)); err == nil && !fi.IsDir() {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// ModuleRoot returns the root directory of the module containing dir.
+func ModuleRoot(dir string) (string, bool) {
+	return FindUp(dir, "go.mod")
+}
+
+// Enabled reports whether the go command runs in module mode in dir.
+func Enabled(dir string) bool {
+	switch os.Getenv("GO111MODULE") {
+	case "off":
+		return false
+	case "on":
+		return true
+	}
+	_, ok := ModuleRoot(dir)
+	return ok
+}
+
+// Directives returns the arguments of the directives called name in
+// the go.mod or go.work file at file, in single-line as well as block
+// form.
+func Directives(file, name string) ([]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var args []string
+	inBlock := false
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case inBlock && line == ")":
+			inBlock = false
+		case inBlock && line != "":
+			args = append(args, strings.Trim(line, `"`))
+		case line == name+" (":
+			inBlock = true
+		case strings.HasPrefix(line, name+" "):
+			args = append(args, strings.Trim(strings.TrimSpace(line[len(name)+1:]), `"`))
+		}
+	}
+	return args, sc.Err()
+}
+
+// ModulePath returns the module path declared by the go.mod file in
+// the module root dir.
+func ModulePath(dir string) (string, error) {
+	file := filepath.Join(dir, "go.mod")
+	paths, err := Directives(file, "module")
+	if err != nil {
+		return "", err
+	}
+	if len(paths) == 0 {
+		return "", errors.New("no module directive in " + file)
+	}
+	return paths[0], nil
+}
+
+// GoVersion returns the minor Go version, such as 13 for Go 1.13,
+// that the go directive of the module containing dir declares. It
+// returns false if dir isn't part of a module or its go.mod file
+// lacks a go directive.
+func GoVersion(dir string) (int, bool) {
+	root, ok := ModuleRoot(dir)
+	if !ok {
+		return 0, false
+	}
+	versions, err := Directives(filepath.Join(root, "go.mod"), "go")
+	if err != nil || len(versions) == 0 {
+		return 0, false
+	}
+	// The version is of the form 1.N, 1.N.P or 1.NrcP.
+	v := strings.TrimPrefix(versions[0], "1.")
+	if v == versions[0] {
+		return 0, false
+	}
+	end := 0
+	for end < len(v) && v[end] >= '0' && v[end] <= '9' {
+		end++
+	}
+	minor, err := strconv.Atoi(v[:end])
+	if err != nil {
+		return 0, false
+	}
+	return minor, true
+}
+
+// WorkspaceRoots returns the root directories of the modules that
+// make up the workspace containing dir: the modules used by the
+// closest go.work file, or else the closest module.
+func WorkspaceRoots(dir string) ([]string, error) {
+	if root, ok := FindUp(dir, "go.work"); ok {
+		uses, err := Directives(filepath.Join(root, "go.work"), "use")
+		if err != nil {
+			return nil, err
+		}
+		for i, use := range uses {
+			if !filepath.IsAbs(use) {
+				uses[i] = filepath.Join(root, use)
+			}
+		}
+		return uses, nil
+	}
+	if root, ok := ModuleRoot(dir); ok {
+		return []string{root}, nil
+	}
+	return nil, errors.New("not inside a module or go.work workspace")
+}
+
+// ImportPath returns the import path of the package in dir, an
+// absolute path, if dir belongs to one of the modules of the
+// workspace containing it. Packages of nested modules that aren't
+// part of the workspace don't have one.
+func ImportPath(dir string) (string, bool) {
+	root, ok := ModuleRoot(dir)
+	if !ok {
+		return "", false
+	}
+	roots, err := WorkspaceRoots(dir)
+	if err != nil {
+		return "", false
+	}
+	member := false
+	for _, r := range roots {
+		if r == root {
+			member = true
+			break
+		}
+	}
+	if !member {
+		return "", false
+	}
+	modPath, err := ModulePath(root)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return "", false
+	}
+	return path.Join(modPath, filepath.ToSlash(rel)), true
+}
@@ -0,0 +1,138 @@
+package gomod
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	tmp, err := ioutil.TempDir("", "gomod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		path := filepath.Join(tmp, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return tmp
+}
+
+func TestDirectives(t *testing.T) {
+	tmp := writeFiles(t, map[string]string{
+		"go.work": "go 1.21\n\nuse ./a // the first\nuse (\n\t./b\n\t\"./c\"\n)\n",
+	})
+	defer os.RemoveAll(tmp)
+
+	got, err := Directives(filepath.Join(tmp, "go.work"), "use")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"./a", "./b", "./c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Directives(go.work, use) = %q, want %q", got, want)
+	}
+}
+
+func TestWorkspaceRoots(t *testing.T) {
+	tmp := writeFiles(t, map[string]string{
+		"work/go.work":       "use (\n\t./a\n\t./b\n)\n",
+		"work/a/go.mod":      "module a\n",
+		"work/a/pkg/x.go":    "package pkg\n",
+		"work/b/go.mod":      "module b\n",
+		"mod/go.mod":         "module example.com/mod\n\ngo 1.18rc1\n",
+		"mod/sub/pkg/x.go":   "package pkg\n",
+		"none/pkg/x.go":      "package pkg\n",
+		"mod/nogo/go.mod":    "module nogo\n",
+		"mod/nogo/pkg/x.go":  "package pkg\n",
+		"mod/badgo/go.mod":   "module badgo\n\ngo 2\n",
+		"mod/badgo/pkg/x.go": "package pkg\n",
+	})
+	defer os.RemoveAll(tmp)
+
+	tests := []struct {
+		dir  string
+		want []string
+	}{
+		{"work/a/pkg", []string{"work/a", "work/b"}},
+		{"mod/sub/pkg", []string{"mod"}},
+		{"mod/nogo/pkg", []string{"mod/nogo"}},
+		{"none/pkg", nil},
+	}
+	for _, tt := range tests {
+		got, err := WorkspaceRoots(filepath.Join(tmp, tt.dir))
+		if (err != nil) != (tt.want == nil) {
+			t.Errorf("WorkspaceRoots(%s) returned error %v", tt.dir, err)
+			continue
+		}
+		var want []string
+		for _, dir := range tt.want {
+			want = append(want, filepath.Join(tmp, dir))
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("WorkspaceRoots(%s) = %q, want %q", tt.dir, got, want)
+		}
+	}
+
+	if path, err := ModulePath(filepath.Join(tmp, "mod")); err != nil || path != "example.com/mod" {
+		t.Errorf("ModulePath(mod) = %q, %v, want %q", path, err, "example.com/mod")
+	}
+	versions := []struct {
+		dir  string
+		want int
+		ok   bool
+	}{
+		{"mod/sub/pkg", 18, true},
+		{"mod/nogo/pkg", 0, false},
+		{"mod/badgo/pkg", 0, false},
+		{"none/pkg", 0, false},
+	}
+	for _, tt := range versions {
+		got, ok := GoVersion(filepath.Join(tmp, tt.dir))
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("GoVersion(%s) = %d, %t, want %d, %t", tt.dir, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestImportPath(t *testing.T) {
+	tmp := writeFiles(t, map[string]string{
+		"work/go.work":         "use ./a\n",
+		"work/a/go.mod":        "module example.com/a\n",
+		"work/a/pkg/x.go":      "package pkg\n",
+		"work/a/nested/go.mod": "module example.com/nested\n",
+		"mod/go.mod":           "module example.com/mod\n",
+		"mod/sub/pkg/x.go":     "package pkg\n",
+		"gopath/src/pkg/x.go":  "package pkg\n",
+		"work/b/go.mod":        "module example.com/b\n",
+		"work/b/pkg/x.go":      "package pkg\n",
+		"work/a/nested/y/x.go": "package y\n",
+	})
+	defer os.RemoveAll(tmp)
+
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{"work/a", "example.com/a"},
+		{"work/a/pkg", "example.com/a/pkg"},
+		// nested and b are modules, but not part of the workspace
+		{"work/a/nested/y", ""},
+		{"work/b/pkg", ""},
+		{"mod", "example.com/mod"},
+		{"mod/sub/pkg", "example.com/mod/sub/pkg"},
+		{"gopath/src/pkg", ""},
+	}
+	for _, tt := range tests {
+		got, ok := ImportPath(filepath.Join(tmp, tt.dir))
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("ImportPath(%s) = %q, %t, want %q", tt.dir, got, ok, tt.want)
+		}
+	}
+}
@@ -0,0 +1,677 @@
+// Package interp is a small interpreter for SSA functions, meant for
+// testing checks.
+//
+// It lets tests confirm that flow-sensitive findings are real, for
+// example by running the function a check reported and asserting
+// that it dereferences a nil pointer at the reported instruction.
+//
+// Only the sequential core of the language is supported: basic types,
+// pointers, structs, arrays, slices, maps, interfaces, closures,
+// defer, and calls of functions with Go bodies. Goroutines, channels,
+// select, recover, ranging over maps, complex numbers and calls of
+// external functions, which includes the standard library's assembly
+// and runtime functions, result in an UnsupportedError.
+//
+// Package-level variables start out as zero values; package
+// initializers aren't run.
+package interp // import "honnef.co/go/tools/internal/interp"
+
+import (
+	"fmt"
+	"go/constant"
+	"go/token"
+	"go/types"
+
+	"honnef.co/go/tools/ssa"
+)
+
+// A Value is the value of an SSA value during interpretation. Booleans,
+// strings and numbers are represented by bool, string, int64, uint64
+// and float64. Nil pointers, slices, maps and functions are nil.
+type Value interface{}
+
+type (
+	// A structure is the value of a struct. Fields are stored in
+	// order.
+	structure []Value
+	// An array is the value of an array.
+	array []Value
+	// A slice is the value of a non-nil slice.
+	slice struct {
+		elems []Value
+		off   int
+		len   int
+		cap   int
+	}
+	// A hashmap is the value of a non-nil map, keyed by the keys
+	// returned by mapKey.
+	hashmap struct {
+		entries map[interface{}]Value
+	}
+	// An iface is the value of an interface. Its type is nil for nil
+	// interfaces.
+	iface struct {
+		t types.Type
+		v Value
+	}
+	// A closure is a function value with bound free variables.
+	closure struct {
+		fn  *ssa.Function
+		env []Value
+	}
+	// A tuple is the result of a call returning several values.
+	tuple []Value
+)
+
+// A RuntimeError is a run-time panic caused by an instruction, such as
+// a nil pointer dereference or an out of range index.
+type RuntimeError struct {
+	Instr ssa.Instruction
+	Msg   string
+}
+
+func (err *RuntimeError) Error() string {
+	return "runtime error: " + err.Msg
+}
+
+// A Panic is a call of the built-in panic function.
+type Panic struct {
+	Instr ssa.Instruction
+	Value Value
+}
+
+func (err *Panic) Error() string {
+	return fmt.Sprintf("panic: %v", err.Value)
+}
+
+// An UnsupportedError is returned for code the interpreter can't
+// execute.
+type UnsupportedError struct {
+	Instr ssa.Instruction
+	Msg   string
+}
+
+func (err *UnsupportedError) Error() string {
+	return "unsupported: " + err.Msg
+}
+
+// An Interp runs SSA functions.
+type Interp struct {
+	// MaxSteps limits the number of instructions that are executed,
+	// to catch infinite loops. Zero means 100000.
+	MaxSteps int
+	// Trace, if not nil, is called for each instruction before it is
+	// executed.
+	Trace func(ssa.Instruction)
+
+	steps   int
+	globals map[*ssa.Global]*Value
+}
+
+// A panicked is used to unwind the Go stack of the interpreter.
+type panicked struct{ err error }
+
+// Call calls fn with args and returns its results. If the function
+// panics or can't be interpreted, err describes why, and is a
+// *RuntimeError, *Panic or *UnsupportedError.
+func (in *Interp) Call(fn *ssa.Function, args ...Value) (results []Value, err error) {
+	if in.globals == nil {
+		in.globals = map[*ssa.Global]*Value{}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			p, ok := r.(panicked)
+			if !ok {
+				panic(r)
+			}
+			results, err = nil, p.err
+		}
+	}()
+	if len(args) != len(fn.Params) {
+		return nil, fmt.Errorf("%s takes %d arguments, got %d", fn, len(fn.Params), len(args))
+	}
+	v := in.call(nil, fn, args, nil)
+	switch fn.Signature.Results().Len() {
+	case 0:
+		return nil, nil
+	case 1:
+		return []Value{v}, nil
+	default:
+		return []Value(v.(tuple)), nil
+	}
+}
+
+// Call calls fn with args using a new interpreter.
+func Call(fn *ssa.Function, args ...Value) ([]Value, error) {
+	return (&Interp{}).Call(fn, args...)
+}
+
+func fail(err error) {
+	panic(panicked{err})
+}
+
+func unsupported(instr ssa.Instruction, format string, args ...interface{}) {
+	fail(&UnsupportedError{Instr: instr, Msg: fmt.Sprintf(format, args...)})
+}
+
+func runtimeError(instr ssa.Instruction, format string, args ...interface{}) {
+	fail(&RuntimeError{Instr: instr, Msg: fmt.Sprintf(format, args...)})
+}
+
+type frame struct {
+	fn     *ssa.Function
+	env    map[ssa.Value]Value
+	free   []Value
+	defers []func()
+}
+
+func (fr *frame) get(v ssa.Value) Value {
+	switch v := v.(type) {
+	case *ssa.Const:
+		return constValue(v)
+	case *ssa.Function:
+		return v
+	case *ssa.FreeVar:
+		for i, fv := range fr.fn.FreeVars {
+			if fv == v {
+				return fr.free[i]
+			}
+		}
+	}
+	if x, ok := fr.env[v]; ok {
+		return x
+	}
+	panic(fmt.Sprintf("no value for %s", v.Name()))
+}
+
+func (in *Interp) call(site ssa.Instruction, fn *ssa.Function, args []Value, free []Value) Value {
+	if fn.Blocks == nil {
+		unsupported(site, "call of external function %s", fn)
+	}
+	fr := &frame{fn: fn, env: map[ssa.Value]Value{}, free: free}
+	for i, p := range fn.Params {
+		fr.env[p] = args[i]
+	}
+
+	defer func() {
+		// Deferred calls that haven't been run by RunDefers run when
+		// the function panics. A panic in a deferred call replaces
+		// the original one.
+		for i := len(fr.defers) - 1; i >= 0; i-- {
+			fr.defers[i]()
+		}
+	}()
+	return in.run(fr)
+}
+
+func (in *Interp) run(fr *frame) Value {
+	max := in.MaxSteps
+	if max == 0 {
+		max = 100000
+	}
+	var prev *ssa.BasicBlock
+	b := fr.fn.Blocks[0]
+	for {
+		// Phis are evaluated simultaneously on entry to the block.
+		phis := map[*ssa.Phi]Value{}
+		for _, instr := range b.Instrs {
+			phi, ok := instr.(*ssa.Phi)
+			if !ok {
+				break
+			}
+			for i, pred := range b.Preds {
+				if pred == prev {
+					phis[phi] = fr.get(phi.Edges[i])
+				}
+			}
+		}
+		for phi, v := range phis {
+			fr.env[phi] = v
+		}
+
+		var next *ssa.BasicBlock
+		for _, instr := range b.Instrs {
+			in.steps++
+			if in.steps > max {
+				unsupported(instr, "exceeded the limit of %d steps", max)
+			}
+			if in.Trace != nil {
+				in.Trace(instr)
+			}
+			switch instr := instr.(type) {
+			case *ssa.Phi:
+			case *ssa.Jump:
+				next = b.Succs[0]
+			case *ssa.If:
+				if fr.get(instr.Cond).(bool) {
+					next = b.Succs[0]
+				} else {
+					next = b.Succs[1]
+				}
+			case *ssa.Return:
+				switch len(instr.Results) {
+				case 0:
+					return nil
+				case 1:
+					return fr.get(instr.Results[0])
+				default:
+					var t tuple
+					for _, r := range instr.Results {
+						t = append(t, fr.get(r))
+					}
+					return t
+				}
+			case *ssa.Panic:
+				fail(&Panic{Instr: instr, Value: fr.get(instr.X)})
+			default:
+				in.exec(fr, instr)
+			}
+		}
+		if next == nil {
+			unsupported(b.Instrs[len(b.Instrs)-1], "block without successor")
+		}
+		prev, b = b, next
+	}
+}
+
+func (in *Interp) exec(fr *frame, instr ssa.Instruction) {
+	set := func(v Value) {
+		fr.env[instr.(ssa.Value)] = v
+	}
+	switch instr := instr.(type) {
+	case *ssa.DebugRef:
+	case *ssa.RunDefers:
+		defers := fr.defers
+		fr.defers = nil
+		for i := len(defers) - 1; i >= 0; i-- {
+			defers[i]()
+		}
+	case *ssa.Alloc:
+		v := zero(instr.Type().(*types.Pointer).Elem())
+		set(&v)
+	case *ssa.Store:
+		p := in.deref(fr, instr, instr.Addr)
+		*p = copyValue(fr.get(instr.Val))
+	case *ssa.UnOp:
+		set(in.unop(fr, instr))
+	case *ssa.BinOp:
+		set(binop(instr, instr.Op, instr.X.Type(), fr.get(instr.X), fr.get(instr.Y)))
+	case *ssa.FieldAddr:
+		p := in.deref(fr, instr, instr.X)
+		set(&(*p).(structure)[instr.Field])
+	case *ssa.Field:
+		set(copyValue(fr.get(instr.X).(structure)[instr.Field]))
+	case *ssa.IndexAddr:
+		idx := int(toInt64(fr.get(instr.Index)))
+		switch x := fr.get(instr.X).(type) {
+		case *Value:
+			if x == nil {
+				runtimeError(instr, "invalid memory address or nil pointer dereference")
+			}
+			a := (*x).(array)
+			checkIndex(instr, idx, len(a))
+			set(&a[idx])
+		case slice:
+			checkIndex(instr, idx, x.len)
+			set(&x.elems[x.off+idx])
+		case nil:
+			if _, ok := instr.X.Type().Underlying().(*types.Pointer); ok {
+				runtimeError(instr, "invalid memory address or nil pointer dereference")
+			}
+			checkIndex(instr, idx, 0)
+		}
+	case *ssa.Index:
+		idx := int(toInt64(fr.get(instr.Index)))
+		a := fr.get(instr.X).(array)
+		checkIndex(instr, idx, len(a))
+		set(copyValue(a[idx]))
+	case *ssa.Lookup:
+		set(in.lookup(fr, instr))
+	case *ssa.MapUpdate:
+		m, ok := fr.get(instr.Map).(*hashmap)
+		if !ok || m == nil {
+			runtimeError(instr, "assignment to entry in nil map")
+		}
+		m.entries[mapKey(fr.get(instr.Key))] = copyValue(fr.get(instr.Value))
+	case *ssa.MakeMap:
+		set(&hashmap{entries: map[interface{}]Value{}})
+	case *ssa.MakeSlice:
+		n := int(toInt64(fr.get(instr.Len)))
+		c := int(toInt64(fr.get(instr.Cap)))
+		if n < 0 || c < n {
+			runtimeError(instr, "makeslice: len out of range")
+		}
+		elem := instr.Type().Underlying().(*types.Slice).Elem()
+		elems := make([]Value, c)
+		for i := range elems {
+			elems[i] = zero(elem)
+		}
+		set(slice{elems: elems, len: n, cap: c})
+	case *ssa.Slice:
+		set(in.slice(fr, instr))
+	case *ssa.MakeInterface:
+		set(iface{t: instr.X.Type(), v: fr.get(instr.X)})
+	case *ssa.ChangeInterface:
+		set(fr.get(instr.X))
+	case *ssa.ChangeType:
+		set(fr.get(instr.X))
+	case *ssa.Convert:
+		set(convert(instr, fr.get(instr.X), instr.X.Type(), instr.Type()))
+	case *ssa.TypeAssert:
+		set(typeAssert(instr, fr.get(instr.X).(iface)))
+	case *ssa.Extract:
+		set(fr.get(instr.Tuple).(tuple)[instr.Index])
+	case *ssa.MakeClosure:
+		var env []Value
+		for _, b := range instr.Bindings {
+			env = append(env, fr.get(b))
+		}
+		set(&closure{fn: instr.Fn.(*ssa.Function), env: env})
+	case *ssa.Call:
+		set(in.callCommon(fr, instr, instr.Common()))
+	case *ssa.Defer:
+		common := instr.Common()
+		fn, args := in.prepareCall(fr, instr, common)
+		fr.defers = append(fr.defers, func() { fn(args) })
+	default:
+		unsupported(instr, "instruction %T", instr)
+	}
+}
+
+// prepareCall evaluates the function and arguments of a call.
+func (in *Interp) prepareCall(fr *frame, instr ssa.Instruction, common *ssa.CallCommon) (func([]Value) Value, []Value) {
+	var args []Value
+	for _, arg := range common.Args {
+		args = append(args, fr.get(arg))
+	}
+	if common.IsInvoke() {
+		recv := fr.get(common.Value).(iface)
+		if recv.t == nil {
+			runtimeError(instr, "invalid memory address or nil pointer dereference")
+		}
+		mset := instr.Parent().Prog.MethodSets.MethodSet(recv.t)
+		sel := mset.Lookup(common.Method.Pkg(), common.Method.Name())
+		if sel == nil {
+			unsupported(instr, "no method %s on %s", common.Method.Name(), recv.t)
+		}
+		fn := instr.Parent().Prog.MethodValue(sel)
+		args = append([]Value{recv.v}, args...)
+		return func(args []Value) Value { return in.call(instr, fn, args, nil) }, args
+	}
+	switch fn := common.Value.(type) {
+	case *ssa.Builtin:
+		return func(args []Value) Value { return builtin(instr, fn.Name(), args) }, args
+	}
+	switch fn := fr.get(common.Value).(type) {
+	case *ssa.Function:
+		return func(args []Value) Value { return in.call(instr, fn, args, nil) }, args
+	case *closure:
+		return func(args []Value) Value { return in.call(instr, fn.fn, args, fn.env) }, args
+	case nil:
+		runtimeError(instr, "invalid memory address or nil pointer dereference")
+	}
+	unsupported(instr, "call of %s", common.Value)
+	return nil, nil
+}
+
+func (in *Interp) callCommon(fr *frame, instr ssa.Instruction, common *ssa.CallCommon) Value {
+	fn, args := in.prepareCall(fr, instr, common)
+	return fn(args)
+}
+
+// deref returns the variable that the pointer v points to.
+func (in *Interp) deref(fr *frame, instr ssa.Instruction, v ssa.Value) *Value {
+	if g, ok := v.(*ssa.Global); ok {
+		p, ok := in.globals[g]
+		if !ok {
+			z := zero(g.Type().(*types.Pointer).Elem())
+			p = &z
+			in.globals[g] = p
+		}
+		return p
+	}
+	p, _ := fr.get(v).(*Value)
+	if p == nil {
+		runtimeError(instr, "invalid memory address or nil pointer dereference")
+	}
+	return p
+}
+
+func (in *Interp) unop(fr *frame, instr *ssa.UnOp) Value {
+	if instr.Op == token.MUL {
+		return copyValue(*in.deref(fr, instr, instr.X))
+	}
+	x := fr.get(instr.X)
+	switch instr.Op {
+	case token.NOT:
+		return !x.(bool)
+	case token.SUB:
+		switch x := x.(type) {
+		case int64:
+			return wrap(instr.Type(), -x)
+		case uint64:
+			return wrap(instr.Type(), -x)
+		case float64:
+			return -x
+		}
+	case token.XOR:
+		switch x := x.(type) {
+		case int64:
+			return wrap(instr.Type(), ^x)
+		case uint64:
+			return wrap(instr.Type(), ^x)
+		}
+	}
+	unsupported(instr, "unary operator %s", instr.Op)
+	return nil
+}
+
+func (in *Interp) lookup(fr *frame, instr *ssa.Lookup) Value {
+	x := fr.get(instr.X)
+	idx := fr.get(instr.Index)
+	if s, ok := x.(string); ok {
+		i := int(toInt64(idx))
+		checkIndex(instr, i, len(s))
+		return uint64(s[i])
+	}
+	elem := instr.X.Type().Underlying().(*types.Map).Elem()
+	v, ok := zero(elem), false
+	if m, _ := x.(*hashmap); m != nil {
+		var e Value
+		if e, ok = m.entries[mapKey(idx)]; ok {
+			v = copyValue(e)
+		}
+	}
+	if instr.CommaOk {
+		return tuple{v, ok}
+	}
+	return v
+}
+
+func (in *Interp) slice(fr *frame, instr *ssa.Slice) Value {
+	x := fr.get(instr.X)
+	bound := func(v ssa.Value, def int) int {
+		if v == nil {
+			return def
+		}
+		return int(toInt64(fr.get(v)))
+	}
+	if s, ok := x.(string); ok {
+		lo, hi := bound(instr.Low, 0), bound(instr.High, len(s))
+		if lo < 0 || hi < lo || hi > len(s) {
+			runtimeError(instr, "slice bounds out of range [%d:%d] with length %d", lo, hi, len(s))
+		}
+		return s[lo:hi]
+	}
+	var s slice
+	switch x := x.(type) {
+	case slice:
+		s = x
+	case *Value:
+		if x == nil {
+			runtimeError(instr, "invalid memory address or nil pointer dereference")
+		}
+		a := (*x).(array)
+		s = slice{elems: a, len: len(a), cap: len(a)}
+	}
+	lo, hi, max := bound(instr.Low, 0), bound(instr.High, s.len), bound(instr.Max, s.cap)
+	if lo < 0 || hi < lo || max < hi || max > s.cap {
+		runtimeError(instr, "slice bounds out of range [%d:%d:%d] with capacity %d", lo, hi, max, s.cap)
+	}
+	if s.elems == nil {
+		return nil
+	}
+	return slice{elems: s.elems, off: s.off + lo, len: hi - lo, cap: max - lo}
+}
+
+func checkIndex(instr ssa.Instruction, idx, n int) {
+	if idx < 0 || idx >= n {
+		runtimeError(instr, "index out of range [%d] with length %d", idx, n)
+	}
+}
+
+func builtin(instr ssa.Instruction, name string, args []Value) Value {
+	switch name {
+	case "len", "cap":
+		switch x := args[0].(type) {
+		case nil:
+			return int64(0)
+		case string:
+			return int64(len(x))
+		case slice:
+			if name == "len" {
+				return int64(x.len)
+			}
+			return int64(x.cap)
+		case array:
+			return int64(len(x))
+		case *hashmap:
+			return int64(len(x.entries))
+		}
+	case "append":
+		s, _ := args[0].(slice)
+		var add []Value
+		switch y := args[1].(type) {
+		case slice:
+			add = y.elems[y.off : y.off+y.len]
+		case string:
+			for i := 0; i < len(y); i++ {
+				add = append(add, uint64(y[i]))
+			}
+		}
+		if s.len+len(add) > s.cap {
+			elems := make([]Value, s.len, (s.len+len(add))*2)
+			copy(elems, s.elems[s.off:s.off+s.len])
+			s = slice{elems: elems[:cap(elems)], len: s.len, cap: cap(elems)}
+		}
+		for i, v := range add {
+			s.elems[s.off+s.len+i] = copyValue(v)
+		}
+		s.len += len(add)
+		return s
+	case "print", "println":
+		return nil
+	}
+	unsupported(instr, "built-in function %s", name)
+	return nil
+}
+
+func constValue(c *ssa.Const) Value {
+	if c.Value == nil {
+		return zero(c.Type())
+	}
+	switch c.Value.Kind() {
+	case constant.Bool:
+		return constant.BoolVal(c.Value)
+	case constant.String:
+		return constant.StringVal(c.Value)
+	case constant.Int:
+		if isUnsigned(c.Type()) {
+			v, _ := constant.Uint64Val(c.Value)
+			return v
+		}
+		if isFloat(c.Type()) {
+			v, _ := constant.Float64Val(c.Value)
+			return v
+		}
+		v, _ := constant.Int64Val(c.Value)
+		return v
+	case constant.Float:
+		v, _ := constant.Float64Val(c.Value)
+		return v
+	}
+	fail(&UnsupportedError{Msg: fmt.Sprintf("constant %s", c)})
+	return nil
+}
+
+// zero returns the zero value of T.
+func zero(T types.Type) Value {
+	switch T := T.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case T.Info()&types.IsBoolean != 0:
+			return false
+		case T.Info()&types.IsString != 0:
+			return ""
+		case T.Info()&types.IsUnsigned != 0:
+			return uint64(0)
+		case T.Info()&types.IsFloat != 0:
+			return float64(0)
+		case T.Info()&types.IsInteger != 0:
+			return int64(0)
+		}
+		return nil
+	case *types.Struct:
+		s := make(structure, T.NumFields())
+		for i := range s {
+			s[i] = zero(T.Field(i).Type())
+		}
+		return s
+	case *types.Array:
+		a := make(array, T.Len())
+		for i := range a {
+			a[i] = zero(T.Elem())
+		}
+		return a
+	case *types.Interface:
+		return iface{}
+	case *types.Tuple:
+		t := make(tuple, T.Len())
+		for i := range t {
+			t[i] = zero(T.At(i).Type())
+		}
+		return t
+	}
+	return nil
+}
+
+// copyValue returns a copy of v, copying the elements of structs and
+// arrays, which have value semantics.
+func copyValue(v Value) Value {
+	switch v := v.(type) {
+	case structure:
+		c := make(structure, len(v))
+		for i := range v {
+			c[i] = copyValue(v[i])
+		}
+		return c
+	case array:
+		c := make(array, len(v))
+		for i := range v {
+			c[i] = copyValue(v[i])
+		}
+		return c
+	}
+	return v
+}
+
+// mapKey returns a comparable representation of v.
+func mapKey(v Value) interface{} {
+	switch v := v.(type) {
+	case structure, array:
+		return fmt.Sprint(v)
+	case iface:
+		return fmt.Sprintf("%s:%v", v.t, mapKey(v.v))
+	}
+	return v
+}
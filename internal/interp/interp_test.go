@@ -0,0 +1,147 @@
+package interp
+
+import (
+	"go/parser"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/go/loader"
+	"honnef.co/go/tools/ssa"
+	"honnef.co/go/tools/ssa/ssautil"
+)
+
+const input = `package pkg
+
+type T struct {
+	x int
+	next *T
+}
+
+func sum(n int) int {
+	s := 0
+	for i := 1; i <= n; i++ {
+		s += i
+	}
+	return s
+}
+
+func list(n int) int {
+	var head *T
+	for i := 0; i < n; i++ {
+		head = &T{x: i, next: head}
+	}
+	total := 0
+	for t := head; t != nil; t = t.next {
+		total += t.x
+	}
+	return total
+}
+
+func last(t *T) int {
+	for t.next != nil {
+		t = t.next
+	}
+	return t.x
+}
+
+func words() (string, int) {
+	m := map[string]int{}
+	var s []string
+	for _, w := range []string{"a", "b", "a"} {
+		m[w]++
+		s = append(s, w)
+	}
+	return s[0] + s[1], m["a"]
+}
+
+func overflow() uint8 {
+	var x uint8 = 250
+	x += 10
+	return x
+}
+
+type I interface{ get() int }
+
+func (t T) get() int { return t.x }
+
+func iface(b bool) (n int) {
+	var i I
+	if b {
+		i = T{x: 7}
+	}
+	defer func() { n++ }()
+	return i.get()
+}
+
+func explicit() { panic("boom") }
+`
+
+func load(t *testing.T) *ssa.Package {
+	conf := loader.Config{ParserMode: parser.ParseComments}
+	f, err := conf.ParseFile("pkg.go", input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.CreateFromFiles("pkg", f)
+	iprog, err := conf.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prog := ssautil.CreateProgram(iprog, 0)
+	prog.Build()
+	return prog.Package(iprog.Created[0].Pkg)
+}
+
+func TestCall(t *testing.T) {
+	pkg := load(t)
+	tests := []struct {
+		fn   string
+		args []Value
+		want []Value
+	}{
+		{"sum", []Value{int64(10)}, []Value{int64(55)}},
+		{"list", []Value{int64(4)}, []Value{int64(6)}},
+		{"words", nil, []Value{"ab", int64(2)}},
+		{"overflow", nil, []Value{uint64(4)}},
+		{"iface", []Value{true}, []Value{int64(8)}},
+	}
+	for _, tt := range tests {
+		got, err := Call(pkg.Func(tt.fn), tt.args...)
+		if err != nil {
+			t.Errorf("%s: %s", tt.fn, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s returned %v, want %v", tt.fn, got, tt.want)
+		}
+	}
+}
+
+func TestPanics(t *testing.T) {
+	pkg := load(t)
+
+	_, err := Call(pkg.Func("last"), nil)
+	rerr, ok := err.(*RuntimeError)
+	if !ok {
+		t.Fatalf("got %v, want a nil dereference", err)
+	}
+	if _, ok := rerr.Instr.(*ssa.FieldAddr); !ok || rerr.Instr.Parent() != pkg.Func("last") {
+		t.Errorf("nil dereference at %s, want the field access in last", rerr.Instr)
+	}
+
+	// The deferred call runs, but the interface is nil.
+	_, err = Call(pkg.Func("iface"), false)
+	if _, ok := err.(*RuntimeError); !ok {
+		t.Errorf("got %v, want a nil dereference", err)
+	}
+
+	_, err = Call(pkg.Func("explicit"))
+	if p, ok := err.(*Panic); !ok || p.Value.(iface).v != "boom" {
+		t.Errorf("got %v, want a panic", err)
+	}
+
+	in := &Interp{MaxSteps: 100}
+	if _, err := in.Call(pkg.Func("sum"), int64(1000)); err == nil {
+		t.Errorf("got no error, want the step limit to be exceeded")
+	}
+}
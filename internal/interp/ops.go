@@ -0,0 +1,310 @@
+package interp
+
+import (
+	"go/token"
+	"go/types"
+
+	"honnef.co/go/tools/ssa"
+)
+
+func isUnsigned(T types.Type) bool {
+	b, ok := T.Underlying().(*types.Basic)
+	return ok && b.Info()&types.IsUnsigned != 0
+}
+
+func isFloat(T types.Type) bool {
+	b, ok := T.Underlying().(*types.Basic)
+	return ok && b.Info()&types.IsFloat != 0
+}
+
+// bits returns the size in bits of the integer type T. int, uint and
+// uintptr are 64 bits wide.
+func bits(T types.Type) uint {
+	switch T.Underlying().(*types.Basic).Kind() {
+	case types.Int8, types.Uint8:
+		return 8
+	case types.Int16, types.Uint16:
+		return 16
+	case types.Int32, types.Uint32:
+		return 32
+	default:
+		return 64
+	}
+}
+
+// wrap truncates the integer x to the size of T, the way arithmetic on
+// values of type T overflows.
+func wrap(T types.Type, x Value) Value {
+	n := bits(T)
+	switch x := x.(type) {
+	case int64:
+		return x << (64 - n) >> (64 - n)
+	case uint64:
+		return x << (64 - n) >> (64 - n)
+	}
+	return x
+}
+
+func toInt64(v Value) int64 {
+	switch v := v.(type) {
+	case int64:
+		return v
+	case uint64:
+		return int64(v)
+	}
+	panic("not an integer")
+}
+
+func binop(instr ssa.Instruction, op token.Token, T types.Type, x, y Value) Value {
+	switch op {
+	case token.EQL:
+		return equal(x, y)
+	case token.NEQ:
+		return !equal(x, y)
+	}
+	switch x := x.(type) {
+	case int64:
+		y := toInt64(y)
+		switch op {
+		case token.ADD:
+			return wrap(T, x+y)
+		case token.SUB:
+			return wrap(T, x-y)
+		case token.MUL:
+			return wrap(T, x*y)
+		case token.QUO, token.REM:
+			if y == 0 {
+				runtimeError(instr, "integer divide by zero")
+			}
+			if op == token.QUO {
+				return wrap(T, x/y)
+			}
+			return wrap(T, x%y)
+		case token.AND:
+			return x & y
+		case token.OR:
+			return x | y
+		case token.XOR:
+			return x ^ y
+		case token.AND_NOT:
+			return x &^ y
+		case token.SHL:
+			return wrap(T, x<<uint64(y))
+		case token.SHR:
+			return x >> uint64(y)
+		case token.LSS:
+			return x < y
+		case token.LEQ:
+			return x <= y
+		case token.GTR:
+			return x > y
+		case token.GEQ:
+			return x >= y
+		}
+	case uint64:
+		y := uint64(toInt64(y))
+		switch op {
+		case token.ADD:
+			return wrap(T, x+y)
+		case token.SUB:
+			return wrap(T, x-y)
+		case token.MUL:
+			return wrap(T, x*y)
+		case token.QUO, token.REM:
+			if y == 0 {
+				runtimeError(instr, "integer divide by zero")
+			}
+			if op == token.QUO {
+				return x / y
+			}
+			return x % y
+		case token.AND:
+			return x & y
+		case token.OR:
+			return x | y
+		case token.XOR:
+			return x ^ y
+		case token.AND_NOT:
+			return x &^ y
+		case token.SHL:
+			return wrap(T, x<<y)
+		case token.SHR:
+			return x >> y
+		case token.LSS:
+			return x < y
+		case token.LEQ:
+			return x <= y
+		case token.GTR:
+			return x > y
+		case token.GEQ:
+			return x >= y
+		}
+	case float64:
+		y := y.(float64)
+		switch op {
+		case token.ADD:
+			return x + y
+		case token.SUB:
+			return x - y
+		case token.MUL:
+			return x * y
+		case token.QUO:
+			return x / y
+		case token.LSS:
+			return x < y
+		case token.LEQ:
+			return x <= y
+		case token.GTR:
+			return x > y
+		case token.GEQ:
+			return x >= y
+		}
+	case string:
+		y := y.(string)
+		switch op {
+		case token.ADD:
+			return x + y
+		case token.LSS:
+			return x < y
+		case token.LEQ:
+			return x <= y
+		case token.GTR:
+			return x > y
+		case token.GEQ:
+			return x >= y
+		}
+	}
+	unsupported(instr, "binary operator %s on %T", op, x)
+	return nil
+}
+
+func equal(x, y Value) bool {
+	switch x := x.(type) {
+	case structure:
+		y := y.(structure)
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case array:
+		y := y.(array)
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case iface:
+		y := y.(iface)
+		if x.t == nil || y.t == nil {
+			return x.t == y.t
+		}
+		return types.Identical(x.t, y.t) && equal(x.v, y.v)
+	case slice:
+		// Non-nil slices can only be compared to nil.
+		return false
+	case *hashmap:
+		m, _ := y.(*hashmap)
+		return x == m
+	}
+	return x == y
+}
+
+func convert(instr ssa.Instruction, x Value, from, to types.Type) Value {
+	switch T := to.Underlying().(type) {
+	case *types.Slice:
+		s := x.(string)
+		var elems []Value
+		if isUnsigned(T.Elem()) {
+			for i := 0; i < len(s); i++ {
+				elems = append(elems, uint64(s[i]))
+			}
+		} else {
+			for _, r := range s {
+				elems = append(elems, int64(r))
+			}
+		}
+		return slice{elems: elems, len: len(elems), cap: len(elems)}
+	case *types.Basic:
+	default:
+		unsupported(instr, "conversion from %s to %s", from, to)
+	}
+	tb := to.Underlying().(*types.Basic)
+	switch {
+	case tb.Info()&types.IsString != 0:
+		switch x := x.(type) {
+		case string:
+			return x
+		case slice:
+			var b []byte
+			for _, e := range x.elems[x.off : x.off+x.len] {
+				switch e := e.(type) {
+				case uint64:
+					b = append(b, byte(e))
+				case int64:
+					b = append(b, string(rune(e))...)
+				}
+			}
+			return string(b)
+		case int64:
+			return string(rune(x))
+		case uint64:
+			return string(rune(x))
+		}
+	case tb.Info()&types.IsFloat != 0:
+		switch x := x.(type) {
+		case int64:
+			return float64(x)
+		case uint64:
+			return float64(x)
+		case float64:
+			return x
+		}
+	case tb.Info()&types.IsUnsigned != 0:
+		switch x := x.(type) {
+		case int64:
+			return wrap(to, uint64(x))
+		case uint64:
+			return wrap(to, x)
+		case float64:
+			return wrap(to, uint64(x))
+		}
+	case tb.Info()&types.IsInteger != 0:
+		switch x := x.(type) {
+		case int64:
+			return wrap(to, x)
+		case uint64:
+			return wrap(to, int64(x))
+		case float64:
+			return wrap(to, int64(x))
+		}
+	}
+	unsupported(instr, "conversion from %s to %s", from, to)
+	return nil
+}
+
+func typeAssert(instr *ssa.TypeAssert, x iface) Value {
+	var v Value
+	ok := false
+	if x.t != nil {
+		if I, isIface := instr.AssertedType.Underlying().(*types.Interface); isIface {
+			ok = types.Implements(x.t, I)
+			v = x
+		} else {
+			ok = types.Identical(x.t, instr.AssertedType)
+			v = x.v
+		}
+	}
+	if !ok {
+		v = zero(instr.AssertedType)
+	}
+	if instr.CommaOk {
+		return tuple{v, ok}
+	}
+	if !ok {
+		runtimeError(instr, "interface conversion: interface is %v, not %s", x.t, instr.AssertedType)
+	}
+	return v
+}
@@ -0,0 +1,161 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// A directive is a //lint:ignore or //lint:file-ignore comment that
+// suppresses the problems of some checks, either on the line of the
+// node that it documents or in the whole file.
+type directive struct {
+	command string
+	checks  []string
+	reason  string
+	until   time.Time // zero if the directive doesn't expire
+	issue   string    // empty if the directive doesn't reference an issue
+
+	pos  token.Pos // position of the comment
+	file string
+	line int // the line the directive applies to; unused for file-ignore
+
+	matched bool
+}
+
+// parseDirective parses a comment of the form
+//
+//	//lint:ignore Check1[,Check2,...] reason [until=YYYY-MM-DD] [issue=URL]
+//
+// ok is false if the comment isn't a linter directive.
+func parseDirective(text string) (d directive, ok bool, err error) {
+	if !strings.HasPrefix(text, "//lint:") {
+		return directive{}, false, nil
+	}
+	fields := strings.Fields(strings.TrimPrefix(text, "//lint:"))
+	if len(fields) == 0 {
+		return directive{}, true, errors.New("malformed linter directive")
+	}
+	d.command = fields[0]
+	if d.command == "used" {
+		// Handled by unused.
+		return directive{}, false, nil
+	}
+	if d.command != "ignore" && d.command != "file-ignore" {
+		return directive{}, true, fmt.Errorf("unknown linter directive %q", d.command)
+	}
+	if len(fields) < 3 {
+		return directive{}, true, errors.New("malformed linter directive; missing the required reason field?")
+	}
+	d.checks = strings.Split(fields[1], ",")
+	d.reason = strings.Join(fields[2:], " ")
+	for _, field := range fields[2:] {
+		switch {
+		case strings.HasPrefix(field, "until="):
+			v := strings.TrimPrefix(field, "until=")
+			d.until, err = time.Parse("2006-01-02", v)
+			if err != nil {
+				return directive{}, true, fmt.Errorf("malformed linter directive: invalid date %q, expected YYYY-MM-DD", v)
+			}
+		case strings.HasPrefix(field, "issue="):
+			d.issue = strings.TrimPrefix(field, "issue=")
+			if d.issue == "" {
+				return directive{}, true, errors.New("malformed linter directive: empty issue reference")
+			}
+		}
+	}
+	return d, true, nil
+}
+
+// parseDirectives returns the linter directives in f. Malformed
+// directives are returned as problems.
+func parseDirectives(fset *token.FileSet, f *ast.File) ([]*directive, []Problem) {
+	var out []*directive
+	var problems []Problem
+	cm := ast.NewCommentMap(fset, f, f.Comments)
+	for node, cgs := range cm {
+		for _, cg := range cgs {
+			for _, c := range cg.List {
+				d, ok, err := parseDirective(c.Text)
+				if !ok {
+					continue
+				}
+				if err != nil {
+					problems = append(problems, Problem{Position: c.Pos(), Text: err.Error()})
+					continue
+				}
+				pos := fset.Position(node.Pos())
+				d.pos = c.Pos()
+				d.file = pos.Filename
+				d.line = pos.Line
+				out = append(out, &d)
+			}
+		}
+	}
+	return out, problems
+}
+
+// match reports whether d suppresses problems of check at pos.
+func (d *directive) match(pos token.Position, check string) bool {
+	if pos.Filename != d.file {
+		return false
+	}
+	if d.command == "ignore" && pos.Line != d.line {
+		return false
+	}
+	for _, c := range d.checks {
+		if m, _ := filepath.Match(c, check); m {
+			return true
+		}
+	}
+	return false
+}
+
+// stale returns a description of why d, which didn't match any
+// problems, should be removed, or the empty string if it is about
+// checks that didn't run or aren't enabled. funcs are the checks that
+// ran; enabled reports whether a check is enabled in d's file.
+func (d *directive) stale(funcs map[string]Func, enabled func(check string) bool) string {
+	prefixes := checkPrefixes(funcs)
+	relevant := false
+	for _, c := range d.checks {
+		if strings.ContainsAny(c, "*?[") {
+			for check := range funcs {
+				if m, _ := filepath.Match(c, check); m && enabled(check) {
+					relevant = true
+				}
+			}
+			continue
+		}
+		if _, ok := funcs[c]; ok {
+			if enabled(c) {
+				relevant = true
+			}
+			continue
+		}
+		if prefixes[checkPrefix(c)] {
+			return fmt.Sprintf("linter directive refers to unknown check %s", c)
+		}
+	}
+	if !relevant {
+		return ""
+	}
+	return "this linter directive didn't match anything; should it be removed?"
+}
+
+// audit returns a description of why d may have become a permanent
+// suppression, or the empty string if it hasn't.
+func (d *directive) audit(now time.Time) string {
+	switch {
+	case !d.until.IsZero() && !now.Before(d.until):
+		return fmt.Sprintf("linter directive expired on %s", d.until.Format("2006-01-02"))
+	case d.until.IsZero() && d.issue == "":
+		return "linter directive has neither an expiry date nor an issue reference"
+	default:
+		return ""
+	}
+}
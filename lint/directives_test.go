@@ -0,0 +1,98 @@
+package lint
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseDirective(t *testing.T) {
+	tests := []struct {
+		text   string
+		ok     bool
+		err    bool
+		checks []string
+		until  string
+		issue  string
+	}{
+		{text: "// just a comment"},
+		{text: "//lint:ignore SA1000,S1000 reason", ok: true, checks: []string{"SA1000", "S1000"}},
+		{text: "//lint:file-ignore ST1003 generated code", ok: true, checks: []string{"ST1003"}},
+		{text: "//lint:ignore SA1000", ok: true, err: true},
+		{text: "//lint:frobnicate SA1000 reason", ok: true, err: true},
+		{text: "//lint:used registered by plugins"},
+		{text: "//lint:ignore SA1000 reason until=2025-01-01", ok: true, checks: []string{"SA1000"}, until: "2025-01-01"},
+		{text: "//lint:ignore SA1000 reason until=tomorrow", ok: true, err: true},
+		{text: "//lint:ignore SA1000 issue=https://example.com/1", ok: true, checks: []string{"SA1000"}, issue: "https://example.com/1"},
+	}
+	for _, tt := range tests {
+		d, ok, err := parseDirective(tt.text)
+		if ok != tt.ok || (err != nil) != tt.err {
+			t.Errorf("parseDirective(%q) = _, %t, %v, want _, %t, error %t", tt.text, ok, err, tt.ok, tt.err)
+			continue
+		}
+		if !ok || err != nil {
+			continue
+		}
+		if !reflect.DeepEqual(d.checks, tt.checks) {
+			t.Errorf("parseDirective(%q).checks = %v, want %v", tt.text, d.checks, tt.checks)
+		}
+		var until string
+		if !d.until.IsZero() {
+			until = d.until.Format("2006-01-02")
+		}
+		if until != tt.until || d.issue != tt.issue {
+			t.Errorf("parseDirective(%q) = until %q, issue %q, want until %q, issue %q", tt.text, until, d.issue, tt.until, tt.issue)
+		}
+	}
+}
+
+func TestAuditDirective(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"//lint:ignore SA1000 reason until=2024-07-01", ""},
+		{"//lint:ignore SA1000 reason until=2024-06-01", "linter directive expired on 2024-06-01"},
+		{"//lint:ignore SA1000 reason issue=#123", ""},
+		{"//lint:ignore SA1000 reason issue=#123 until=2024-01-01", "linter directive expired on 2024-01-01"},
+		{"//lint:ignore SA1000 reason", "linter directive has neither an expiry date nor an issue reference"},
+	}
+	for _, tt := range tests {
+		d, _, err := parseDirective(tt.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := d.audit(now); got != tt.want {
+			t.Errorf("audit(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestStaleDirective(t *testing.T) {
+	funcs := map[string]Func{"ST1000": nil, "ST1003": nil, "ST1005": nil}
+	enabled := func(check string) bool { return check != "ST1005" }
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"//lint:ignore ST1003 reason", "this linter directive didn't match anything; should it be removed?"},
+		{"//lint:ignore ST* reason", "this linter directive didn't match anything; should it be removed?"},
+		{"//lint:ignore ST9999 reason", "linter directive refers to unknown check ST9999"},
+		// checks of other linters and disabled checks may still
+		// suppress problems elsewhere
+		{"//lint:ignore SA1000 reason", ""},
+		{"//lint:ignore SA* reason", ""},
+		{"//lint:ignore ST1005 reason", ""},
+	}
+	for _, tt := range tests {
+		d, _, err := parseDirective(tt.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := d.stale(funcs, enabled); got != tt.want {
+			t.Errorf("stale(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,384 @@
+package lint
+
+import (
+	"bytes"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// An Edit replaces the source code between Pos and End with NewText.
+type Edit struct {
+	Pos     token.Pos
+	End     token.Pos
+	NewText string
+}
+
+// A Fix is a suggested change that resolves a problem. It consists of
+// one or more edits in a single file, and the import paths that the
+// edited code refers to.
+type Fix struct {
+	Edits   []Edit
+	Imports []string
+}
+
+// Replace returns a fix that replaces node with text.
+func Replace(node ast.Node, text string, imports ...string) *Fix {
+	return &Fix{
+		Edits:   []Edit{{Pos: node.Pos(), End: node.End(), NewText: text}},
+		Imports: imports,
+	}
+}
+
+// ReplaceRange returns a fix that replaces the source code between
+// pos and end with text.
+func ReplaceRange(pos, end token.Pos, text string, imports ...string) *Fix {
+	return &Fix{
+		Edits:   []Edit{{Pos: pos, End: end, NewText: text}},
+		Imports: imports,
+	}
+}
+
+type offsetEdit struct {
+	start, end int
+	text       string
+}
+
+// ApplyFixes applies fixes to src, the contents of a file in fset.
+// Fixes are applied in the order of their positions; fixes that
+// overlap with an already applied fix are skipped and returned.
+//
+// After applying the edits, imports required by the fixes are added
+// and imports whose last use was removed by the fixes are deleted.
+func ApplyFixes(fset *token.FileSet, src []byte, fixes []*Fix) (out []byte, skipped []*Fix, err error) {
+	if len(fixes) == 0 {
+		return src, nil, nil
+	}
+	tf := fset.File(fixes[0].Edits[0].Pos)
+
+	toOffsets := func(fix *Fix) []offsetEdit {
+		var edits []offsetEdit
+		for _, e := range fix.Edits {
+			edits = append(edits, offsetEdit{tf.Offset(e.Pos), tf.Offset(e.End), e.NewText})
+		}
+		return edits
+	}
+	sorted := make([]*Fix, len(fixes))
+	copy(sorted, fixes)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Edits[0].Pos < sorted[j].Edits[0].Pos
+	})
+
+	var applied []offsetEdit
+	imports := map[string]bool{}
+	overlaps := func(e offsetEdit) bool {
+		for _, a := range applied {
+			if e.start < a.end && a.start < e.end {
+				return true
+			}
+			if e.start == e.end && e.start == a.start {
+				// Two insertions at the same position
+				return true
+			}
+		}
+		return false
+	}
+fixes:
+	for _, fix := range sorted {
+		edits := toOffsets(fix)
+		for _, e := range edits {
+			if overlaps(e) {
+				skipped = append(skipped, fix)
+				continue fixes
+			}
+		}
+		applied = append(applied, edits...)
+		for _, imp := range fix.Imports {
+			imports[imp] = true
+		}
+	}
+
+	out, err = fixImports(tf.Name(), src, applyEdits(src, applied), imports)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, skipped, nil
+}
+
+// fixImports adds the imports in add to the file in src and removes
+// the imports whose last use was removed by the fixes, i.e. those
+// that were used in orig but aren't used in src anymore. Imports are
+// edited textually, leaving the rest of the file alone; the result is
+// only formatted if orig was formatted already.
+func fixImports(filename string, orig, src []byte, add map[string]bool) ([]byte, error) {
+	fset := token.NewFileSet()
+	before, err := parser.ParseFile(fset, filename, orig, 0)
+	if err != nil {
+		return nil, err
+	}
+	f, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	tf := fset.File(f.Pos())
+	offset := func(pos token.Pos) int { return tf.Offset(pos) }
+
+	usedBefore := packageUses(before)
+	usedAfter := packageUses(f)
+	unused := func(spec *ast.ImportSpec) bool {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		if add[path] {
+			return false
+		}
+		if spec.Name != nil {
+			name := spec.Name.Name
+			if name == "_" || name == "." {
+				return false
+			}
+			return usedBefore[name] && !usedAfter[name]
+		}
+		names := importNames(filepath.Dir(filename), path)
+		removed := false
+		for _, name := range names {
+			if usedAfter[name] {
+				return false
+			}
+			if usedBefore[name] {
+				removed = true
+			}
+		}
+		return removed
+	}
+
+	var edits []offsetEdit
+	have := map[string]bool{}
+	var decls []*ast.GenDecl
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.IMPORT {
+			continue
+		}
+		var dead []*ast.ImportSpec
+		for _, spec := range gd.Specs {
+			spec := spec.(*ast.ImportSpec)
+			if unused(spec) {
+				dead = append(dead, spec)
+				continue
+			}
+			path, _ := strconv.Unquote(spec.Path.Value)
+			have[path] = true
+		}
+		if len(dead) < len(gd.Specs) {
+			decls = append(decls, gd)
+		}
+		if len(dead) == 0 {
+			continue
+		}
+		if len(dead) == len(gd.Specs) {
+			start, end := gd.Pos(), gd.End()
+			if gd.Doc != nil {
+				start = gd.Doc.Pos()
+			}
+			edits = append(edits, deleteLines(src, offset(start), offset(end)))
+			continue
+		}
+		for _, spec := range dead {
+			start, end := spec.Pos(), spec.End()
+			if spec.Doc != nil {
+				start = spec.Doc.Pos()
+			}
+			if spec.Comment != nil {
+				end = spec.Comment.End()
+			}
+			edits = append(edits, deleteLines(src, offset(start), offset(end)))
+		}
+	}
+
+	var paths []string
+	for path := range add {
+		if !have[path] {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	inserts := map[int]string{}
+	var order []int
+	insert := func(off int, text string) {
+		if _, ok := inserts[off]; !ok {
+			order = append(order, off)
+		}
+		inserts[off] += text
+	}
+	if len(paths) > 0 && len(decls) > 0 && !decls[0].Lparen.IsValid() {
+		// Turn the import declaration into an import block, so that
+		// the imports stay sorted.
+		spec := decls[0].Specs[0].(*ast.ImportSpec)
+		path, _ := strconv.Unquote(spec.Path.Value)
+		lines := map[string]string{path: string(src[offset(spec.Pos()):offset(spec.End())])}
+		for _, path := range paths {
+			lines[path] = strconv.Quote(path)
+		}
+		paths = append(paths, path)
+		sort.Strings(paths)
+		text := "(\n"
+		for _, path := range paths {
+			text += "\t" + lines[path] + "\n"
+		}
+		text += ")"
+		edits = append(edits, offsetEdit{offset(spec.Pos()), offset(spec.End()), text})
+		paths = nil
+	}
+	for _, path := range paths {
+		quoted := strconv.Quote(path)
+		switch {
+		case len(decls) > 0 && decls[0].Lparen.IsValid() && len(decls[0].Specs) > 0:
+			// Insert into the first group of the import block, which
+			// conventionally holds the standard library.
+			var at, last ast.Spec
+			for _, spec := range decls[0].Specs {
+				if last != nil && tf.Line(spec.Pos()) > tf.Line(last.End())+1 {
+					break
+				}
+				if p, _ := strconv.Unquote(spec.(*ast.ImportSpec).Path.Value); p > path {
+					at = spec
+					break
+				}
+				last = spec
+			}
+			if at != nil {
+				insert(lineStart(src, offset(at.Pos())), "\t"+quoted+"\n")
+			} else {
+				insert(lineEnd(src, offset(last.End()))+1, "\t"+quoted+"\n")
+			}
+		case len(decls) > 0:
+			insert(lineEnd(src, offset(decls[len(decls)-1].End())), "\nimport "+quoted)
+		default:
+			insert(offset(f.Name.End()), "\n\nimport "+quoted)
+		}
+	}
+	for _, off := range order {
+		edits = append(edits, offsetEdit{off, off, inserts[off]})
+	}
+	out := applyEdits(src, edits)
+
+	if formatted, err := format.Source(orig); err == nil && bytes.Equal(formatted, orig) {
+		return format.Source(out)
+	}
+	return out, nil
+}
+
+// packageUses returns the names that f uses as the package in a
+// qualified identifier, i.e. the unresolved identifiers that are the
+// operand of a selector expression.
+func packageUses(f *ast.File) map[string]bool {
+	used := map[string]bool{}
+	ast.Inspect(f, func(node ast.Node) bool {
+		sel, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if ident, ok := sel.X.(*ast.Ident); ok && ident.Obj == nil {
+			used[ident.Name] = true
+		}
+		return true
+	})
+	return used
+}
+
+// importNames returns the possible names of the package with the
+// given import path. If the package can be found, that is its actual
+// name. Otherwise it is every name that the path plausibly declares,
+// so that gopkg.in/yaml.v2, math/rand/v2 and github.com/x/go-foo are
+// only considered unused if none of their possible names are used.
+func importNames(dir, path string) []string {
+	if pkg, err := build.Import(path, dir, 0); err == nil && pkg.Name != "" {
+		return []string{pkg.Name}
+	}
+	elems := strings.Split(path, "/")
+	last := elems[len(elems)-1]
+	names := []string{last}
+	if len(elems) > 1 && len(last) > 1 && last[0] == 'v' && strings.Trim(last[1:], "0123456789") == "" {
+		last = elems[len(elems)-2]
+		names = append(names, last)
+	}
+	if i := strings.Index(last, "."); i > 0 {
+		last = last[:i]
+		names = append(names, last)
+	}
+	for _, prefix := range []string{"go-", "go."} {
+		if strings.HasPrefix(last, prefix) {
+			names = append(names, last[len(prefix):])
+		}
+	}
+	if strings.HasSuffix(last, "-go") {
+		names = append(names, strings.TrimSuffix(last, "-go"))
+	}
+	for i, name := range names {
+		names[i] = strings.Replace(name, "-", "_", -1)
+	}
+	return names
+}
+
+// deleteLines returns an edit that deletes src[start:end]. If that is
+// all there is on its lines, the lines are deleted as a whole.
+func deleteLines(src []byte, start, end int) offsetEdit {
+	ls, le := lineStart(src, start), lineEnd(src, end)
+	if len(bytes.TrimSpace(src[ls:start])) == 0 && len(bytes.TrimSpace(src[end:le])) == 0 {
+		if le < len(src) {
+			le++
+		}
+		return offsetEdit{ls, le, ""}
+	}
+	return offsetEdit{start, end, ""}
+}
+
+// lineStart returns the offset of the start of the line containing
+// off.
+func lineStart(src []byte, off int) int {
+	return bytes.LastIndexByte(src[:off], '\n') + 1
+}
+
+// lineEnd returns the offset of the newline ending the line
+// containing off, or len(src).
+func lineEnd(src []byte, off int) int {
+	if i := bytes.IndexByte(src[off:], '\n'); i != -1 {
+		return off + i
+	}
+	return len(src)
+}
+
+// applyEdits applies non-overlapping edits to src.
+func applyEdits(src []byte, edits []offsetEdit) []byte {
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].start > edits[j].start
+	})
+	out := make([]byte, len(src))
+	copy(out, src)
+	for _, e := range edits {
+		var buf bytes.Buffer
+		buf.Write(out[:e.start])
+		buf.WriteString(e.text)
+		buf.Write(out[e.end:])
+		out = buf.Bytes()
+	}
+	return out
+}
+
+// ApplyFixesToFile reads the file that the fixes apply to and returns
+// its original and its fixed contents.
+func ApplyFixesToFile(fset *token.FileSet, fixes []*Fix) (filename string, orig, out []byte, skipped []*Fix, err error) {
+	filename = fset.File(fixes[0].Edits[0].Pos).Name()
+	orig, err = ioutil.ReadFile(filename)
+	if err != nil {
+		return filename, nil, nil, nil, err
+	}
+	out, skipped, err = ApplyFixes(fset, orig, fixes)
+	return filename, orig, out, skipped, err
+}
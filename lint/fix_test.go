@@ -0,0 +1,90 @@
+package lint
+
+import (
+	"bytes"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyFixes(t *testing.T) {
+	type edit struct {
+		old, new string
+		imports  []string
+	}
+	tests := []struct {
+		name    string
+		fixes   []edit
+		skipped int
+	}{
+		{
+			name: "overlap",
+			fixes: []edit{
+				{old: "1 + 2", new: "3"},
+				{old: "2", new: "two"},
+			},
+			skipped: 1,
+		},
+		{
+			name:  "imports",
+			fixes: []edit{{old: "os.Args", new: `strings.Fields("")`, imports: []string{"strings"}}},
+		},
+		{
+			name: "v2",
+			fixes: []edit{
+				{old: "sort.Ints(nil)", new: "slices.Sort([]int(nil))", imports: []string{"slices"}},
+				{old: "sort.Strings(nil)", new: "slices.Sort([]string(nil))", imports: []string{"slices"}},
+			},
+		},
+		{
+			name:  "add",
+			fixes: []edit{{old: "s[i]", new: "strings.ToLower(s[i])", imports: []string{"strings"}}},
+		},
+		{
+			name:  "remove",
+			fixes: []edit{{old: `strings.HasPrefix(s, "")`, new: "true"}},
+		},
+		{
+			name:  "swap",
+			fixes: []edit{{old: "sort.Strings", new: "slices.Sort", imports: []string{"slices"}}},
+		},
+		{
+			name:  "replace",
+			fixes: []edit{{old: "sort.Strings", new: "slices.Sort", imports: []string{"slices"}}},
+		},
+	}
+	for _, tt := range tests {
+		filename := filepath.Join("testdata", "fix", tt.name+".input")
+		src, err := ioutil.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := ioutil.ReadFile(filepath.Join("testdata", "fix", tt.name+".golden"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		fset := token.NewFileSet()
+		tf := fset.AddFile(filename, -1, len(src))
+		var fixes []*Fix
+		for _, e := range tt.fixes {
+			off := strings.Index(string(src), e.old)
+			if off == -1 {
+				t.Fatalf("%s: couldn't find %q", tt.name, e.old)
+			}
+			fixes = append(fixes, ReplaceRange(tf.Pos(off), tf.Pos(off+len(e.old)), e.new, e.imports...))
+		}
+		got, skipped, err := ApplyFixes(fset, src, fixes)
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		if len(skipped) != tt.skipped {
+			t.Errorf("%s: got %d skipped fixes, want %d", tt.name, len(skipped), tt.skipped)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.name, got, want)
+		}
+	}
+}
@@ -15,14 +15,17 @@ import (
 	"go/printer"
 	"go/token"
 	"go/types"
+	"io/ioutil"
 	"path/filepath"
 	"runtime"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"golang.org/x/tools/go/ast/astutil"
 	"golang.org/x/tools/go/loader"
+	"honnef.co/go/tools/config"
 	"honnef.co/go/tools/ssa"
 	"honnef.co/go/tools/ssa/ssautil"
 )
@@ -50,8 +53,19 @@ type Program struct {
 	Info             *types.Info
 	GoVersion        int
 
+	// NodeFns maps AST nodes in the initial packages to the SSA
+	// functions containing them. It is computed once and shared by
+	// all checkers running on the program.
+	NodeFns map[ast.Node]*ssa.Function
+
 	tokenFileMap map[*token.File]*ast.File
 	astFileMap   map[*ast.File]*Pkg
+
+	sourcesMu sync.Mutex
+	sources   map[string][]byte
+
+	// the options declared by the checks, keyed by check name
+	options map[string][]Option
 }
 
 type Func func(*Job)
@@ -60,6 +74,9 @@ type Func func(*Job)
 type Problem struct {
 	Position token.Pos // position in source file
 	Text     string    // the prose that describes the problem
+	Check    string    // the name of the check that found the problem
+	Severity string    // config.SeverityError or config.SeverityWarning
+	Fix      *Fix      // optional, automatic fix for the problem
 }
 
 func (p *Problem) String() string {
@@ -76,6 +93,11 @@ type Linter struct {
 	Checker   Checker
 	Ignores   []Ignore
 	GoVersion int
+
+	// AuditIgnores causes linter directives that have expired, or
+	// that have neither an expiry date nor an issue reference, to be
+	// reported.
+	AuditIgnores bool
 }
 
 func (l *Linter) ignore(j *Job, p Problem) bool {
@@ -93,7 +115,7 @@ func (l *Linter) ignore(j *Job, p Problem) bool {
 			continue
 		}
 		for _, c := range ig.Checks {
-			if m, _ := filepath.Match(c, j.check); m {
+			if m, _ := filepath.Match(c, p.Check); m {
 				return true
 			}
 		}
@@ -140,11 +162,28 @@ func (l *Linter) Lint(lprog *loader.Program) []Problem {
 	ssaprog.Build()
 	pkgMap := map[*ssa.Package]*Pkg{}
 	var pkgs []*Pkg
+	var out []Problem
 	for _, pkginfo := range lprog.InitialPackages() {
 		ssapkg := ssaprog.Package(pkginfo.Pkg)
 		pkg := &Pkg{
 			Package: ssapkg,
 			Info:    pkginfo,
+			Config:  config.DefaultConfig,
+		}
+		if len(pkginfo.Files) > 0 {
+			f := pkginfo.Files[0]
+			dir := filepath.Dir(lprog.Fset.File(f.Pos()).Name())
+			cfg, err := config.Load(dir)
+			if err != nil {
+				out = append(out, Problem{
+					Position: f.Package,
+					Text:     fmt.Sprintf("couldn't load configuration: %s", err),
+					Severity: config.SeverityError,
+				})
+			} else {
+				pkg.Config = cfg
+			}
+			pkg.GoVersion, _ = config.ModuleGoVersion(dir)
 		}
 		pkgMap[ssapkg] = pkg
 		pkgs = append(pkgs, pkg)
@@ -157,6 +196,7 @@ func (l *Linter) Lint(lprog *loader.Program) []Problem {
 		GoVersion:    l.GoVersion,
 		tokenFileMap: map[*token.File]*ast.File{},
 		astFileMap:   map[*ast.File]*Pkg{},
+		sources:      map[string][]byte{},
 	}
 	initial := map[*types.Package]struct{}{}
 	for _, pkg := range pkgs {
@@ -171,6 +211,7 @@ func (l *Linter) Lint(lprog *loader.Program) []Problem {
 			prog.InitialFunctions = append(prog.InitialFunctions, fn)
 		}
 	}
+	prog.NodeFns = NodeFns(pkgs)
 	for _, pkg := range pkgs {
 		prog.Files = append(prog.Files, pkg.Info.Files...)
 
@@ -224,9 +265,46 @@ func (l *Linter) Lint(lprog *loader.Program) []Problem {
 			prog.Info.Scopes[k] = v
 		}
 	}
-	l.Checker.Init(prog)
+	var directives []*directive
+	for _, f := range prog.Files {
+		ds, ps := parseDirectives(lprog.Fset, f)
+		directives = append(directives, ds...)
+		for _, p := range ps {
+			p.Severity = config.SeverityError
+			out = append(out, p)
+		}
+	}
+	if l.AuditIgnores {
+		now := time.Now()
+		for _, d := range directives {
+			if msg := d.audit(now); msg != "" {
+				out = append(out, Problem{
+					Position: d.pos,
+					Text:     msg,
+					Severity: config.SeverityError,
+				})
+			}
+		}
+	}
 
 	funcs := l.Checker.Funcs()
+	if provider, ok := l.Checker.(OptionsProvider); ok {
+		prog.options = provider.Options()
+	}
+	for _, pkg := range pkgs {
+		opts, err := checkOptions(pkg.Config, funcs, prog.options)
+		if err != nil {
+			out = append(out, Problem{
+				Position: pkg.Info.Files[0].Package,
+				Text:     fmt.Sprintf("invalid configuration: %s", err),
+				Severity: config.SeverityError,
+			})
+			continue
+		}
+		pkg.options = opts
+	}
+	l.Checker.Init(prog)
+
 	var keys []string
 	for k := range funcs {
 		keys = append(keys, k)
@@ -254,13 +332,46 @@ func (l *Linter) Lint(lprog *loader.Program) []Problem {
 		}(j)
 	}
 	wg.Wait()
+	if rr, ok := l.Checker.(RuleRunner); ok && rr.RunsRules() {
+		jobs = append(jobs, &Job{Program: prog, problems: runRules(prog)})
+	}
 
-	var out []Problem
 	for _, j := range jobs {
 		for _, p := range j.problems {
-			if !l.ignore(j, p) {
-				out = append(out, p)
+			tf := lprog.Fset.File(p.Position)
+			cfg := prog.astFileMap[prog.tokenFileMap[tf]].Config
+			if !cfg.Enabled(p.Check, tf.Name()) {
+				continue
+			}
+			pos := lprog.Fset.Position(p.Position)
+			ignored := false
+			for _, d := range directives {
+				if d.match(pos, p.Check) {
+					d.matched = true
+					ignored = true
+				}
 			}
+			if ignored || l.ignore(j, p) {
+				continue
+			}
+			p.Severity = cfg.SeverityOf(p.Check, tf.Name())
+			out = append(out, p)
+		}
+	}
+
+	for _, d := range directives {
+		if d.matched {
+			continue
+		}
+		tf := lprog.Fset.File(d.pos)
+		cfg := prog.astFileMap[prog.tokenFileMap[tf]].Config
+		enabled := func(check string) bool { return cfg.Enabled(check, tf.Name()) }
+		if msg := d.stale(funcs, enabled); msg != "" {
+			out = append(out, Problem{
+				Position: d.pos,
+				Text:     msg,
+				Severity: config.SeverityError,
+			})
 		}
 	}
 
@@ -271,7 +382,14 @@ func (l *Linter) Lint(lprog *loader.Program) []Problem {
 // Pkg represents a package being linted.
 type Pkg struct {
 	*ssa.Package
-	Info *loader.PackageInfo
+	Info   *loader.PackageInfo
+	Config config.Config
+	// GoVersion is the minor Go version that the go.mod file of the
+	// package's module declares, or 0 if it doesn't declare one.
+	GoVersion int
+
+	// the validated options of checks, keyed by check and option name
+	options map[string]map[string]interface{}
 }
 
 type packager interface {
@@ -313,11 +431,35 @@ func (j *Job) Errorf(n Positioner, format string, args ...interface{}) *Problem
 	problem := Problem{
 		Position: n.Pos(),
 		Text:     fmt.Sprintf(format, args...) + fmt.Sprintf(" (%s)", j.check),
+		Check:    j.check,
+		Severity: config.SeverityError,
 	}
 	j.problems = append(j.problems, problem)
 	return &j.problems[len(j.problems)-1]
 }
 
+// Source returns the source code between pos and end, exactly as it
+// appears in the file, or the empty string if the file can't be read.
+func (j *Job) Source(pos, end token.Pos) string {
+	prog := j.Program
+	tf := prog.SSA.Fset.File(pos)
+	if tf == nil {
+		return ""
+	}
+	prog.sourcesMu.Lock()
+	src, ok := prog.sources[tf.Name()]
+	if !ok {
+		src, _ = ioutil.ReadFile(tf.Name())
+		prog.sources[tf.Name()] = src
+	}
+	prog.sourcesMu.Unlock()
+	start, stop := tf.Offset(pos), tf.Offset(end)
+	if stop > len(src) || start > stop {
+		return ""
+	}
+	return string(src[start:stop])
+}
+
 func (j *Job) Render(x interface{}) string {
 	fset := j.Program.SSA.Fset
 	var buf bytes.Buffer
@@ -422,8 +564,15 @@ func IsGenerated(f *ast.File) bool {
 	return false
 }
 
-func (j *Job) IsGoVersion(minor int) bool {
-	return j.Program.GoVersion >= minor
+// IsGoVersion reports whether node may use features of Go 1.minor.
+// The targeted version is the one set with the -go flag, lowered to
+// the version declared in the go.mod file of node's module.
+func (j *Job) IsGoVersion(node Positioner, minor int) bool {
+	version := j.Program.GoVersion
+	if pkg := j.NodePackage(node); pkg != nil && pkg.GoVersion != 0 && pkg.GoVersion < version {
+		version = pkg.GoVersion
+	}
+	return version >= minor
 }
 
 func (j *Job) IsCallToAST(node ast.Node, name string) bool {
@@ -0,0 +1,66 @@
+package lintutil
+
+import (
+	"fmt"
+	"go/build"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/internal/gomod"
+
+	"github.com/BurntSushi/toml"
+	"github.com/kisielk/gotool"
+)
+
+// showConfig prints the effective configuration of each of the
+// packages in pkgs, preceded by the configuration files it was merged
+// from.
+func showConfig(w io.Writer, pkgs []string, tags []string) error {
+	paths := gotool.ImportPaths(pkgs)
+	var dirs []string
+	if len(paths) > 0 && strings.HasSuffix(paths[0], ".go") {
+		dirs = []string{filepath.Dir(paths[0])}
+		paths = []string{"adhoc"}
+	} else {
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		ctx := build.Default
+		ctx.BuildTags = tags
+		modules := gomod.Enabled(wd)
+		for i, path := range paths {
+			ipath, dir, err := resolvePackage(&ctx, wd, path, modules)
+			if err != nil {
+				return err
+			}
+			paths[i] = ipath
+			dirs = append(dirs, dir)
+		}
+	}
+
+	for i, dir := range dirs {
+		files, err := config.Files(dir)
+		if err != nil {
+			return err
+		}
+		cfg, err := config.Load(dir)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "# %s\n", paths[i])
+		for _, f := range files {
+			fmt.Fprintf(w, "# merged from %s\n", f)
+		}
+		if err := toml.NewEncoder(w).Encode(cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
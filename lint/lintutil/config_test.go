@@ -0,0 +1,79 @@
+package lintutil
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShowConfigModule(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "lintutil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	tmp, err = filepath.EvalSymlinks(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"mod/go.mod":               "module example.com/mod\n",
+		"mod/staticcheck.conf":     "checks = [\"all\"]\n",
+		"mod/sub/staticcheck.conf": "initialisms = [\"FOO\"]\n",
+		"mod/sub/pkg.go":           "package sub\n",
+		"mod/other/pkg/x.go":       "package pkg\n",
+	}
+	for name, data := range files {
+		path := filepath.Join(tmp, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Neither a user configuration nor the environment of the test
+	// may interfere.
+	for key, value := range map[string]string{
+		"XDG_CONFIG_HOME": filepath.Join(tmp, "user"),
+		"GO111MODULE":     "on",
+	} {
+		old, ok := os.LookupEnv(key)
+		os.Setenv(key, value)
+		if ok {
+			defer os.Setenv(key, old)
+		} else {
+			defer os.Unsetenv(key)
+		}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(filepath.Join(tmp, "mod")); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	var buf bytes.Buffer
+	if err := showConfig(&buf, []string{"./sub", "./other/pkg"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"# example.com/mod/sub\n" +
+			"# merged from " + filepath.Join(tmp, "mod", "staticcheck.conf") + "\n" +
+			"# merged from " + filepath.Join(tmp, "mod", "sub", "staticcheck.conf") + "\n",
+		"# example.com/mod/other/pkg\n" +
+			"# merged from " + filepath.Join(tmp, "mod", "staticcheck.conf") + "\n",
+		`initialisms = ["FOO"]`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output doesn't contain %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package lintutil
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"honnef.co/go/tools/internal/diff"
+	"honnef.co/go/tools/lint"
+)
+
+type fixMode int
+
+const (
+	fixWrite fixMode = iota
+	fixDiff
+)
+
+var errQuit = errors.New("quit")
+
+// FixProblems applies the fixes of ps, grouped by file, and either
+// writes the changed files or, if diff is true, prints the changes as
+// a unified diff. It returns the problems whose fixes were applied,
+// those whose fixes were skipped because they overlap with another
+// fix, and the number of changed files.
+func FixProblems(ps []lint.Problem, fset *token.FileSet, diff bool) (applied, skipped []lint.Problem, changed int, err error) {
+	mode := fixWrite
+	if diff {
+		mode = fixDiff
+	}
+	return applyFixes(ps, fset, mode, false)
+}
+
+// applyFixes implements FixProblems. If interactive is true, it asks
+// for confirmation before applying each fix.
+func applyFixes(ps []lint.Problem, fset *token.FileSet, mode fixMode, interactive bool) (applied, skipped []lint.Problem, changed int, err error) {
+	var files []string
+	byFile := map[string][]lint.Problem{}
+	var stdin *bufio.Reader
+	if interactive {
+		stdin = bufio.NewReader(os.Stdin)
+	}
+	for _, p := range ps {
+		if p.Fix == nil || len(p.Fix.Edits) == 0 {
+			continue
+		}
+		if interactive {
+			ok, err := confirm(stdin, fset, p)
+			if err == errQuit {
+				break
+			}
+			if err != nil {
+				return nil, nil, 0, err
+			}
+			if !ok {
+				continue
+			}
+		}
+		name := fset.File(p.Fix.Edits[0].Pos).Name()
+		if _, ok := byFile[name]; !ok {
+			files = append(files, name)
+		}
+		byFile[name] = append(byFile[name], p)
+	}
+
+	for _, name := range files {
+		var fixes []*lint.Fix
+		for _, p := range byFile[name] {
+			fixes = append(fixes, p.Fix)
+		}
+		_, orig, out, skippedFixes, err := lint.ApplyFixesToFile(fset, fixes)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("couldn't fix %s: %s", name, err)
+		}
+		isSkipped := map[*lint.Fix]bool{}
+		for _, fix := range skippedFixes {
+			isSkipped[fix] = true
+		}
+		for _, p := range byFile[name] {
+			if isSkipped[p.Fix] {
+				skipped = append(skipped, p)
+			} else {
+				applied = append(applied, p)
+			}
+		}
+		if bytes.Equal(orig, out) {
+			continue
+		}
+		changed++
+		switch mode {
+		case fixWrite:
+			fi, err := os.Stat(name)
+			if err != nil {
+				return nil, nil, 0, err
+			}
+			if err := ioutil.WriteFile(name, out, fi.Mode()); err != nil {
+				return nil, nil, 0, err
+			}
+		case fixDiff:
+			short := shortPath(name)
+			os.Stdout.Write(diff.Unified("a/"+short, "b/"+short, orig, out))
+		}
+	}
+	return applied, skipped, changed, nil
+}
+
+func confirm(stdin *bufio.Reader, fset *token.FileSet, p lint.Problem) (bool, error) {
+	pos := fset.Position(p.Position)
+	for {
+		fmt.Fprintf(os.Stderr, "%v: %s\napply fix? [y/n/q] ", relativePositionString(pos), p.Text)
+		line, err := stdin.ReadString('\n')
+		if err != nil && line == "" {
+			return false, errQuit
+		}
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		case "q", "quit":
+			return false, errQuit
+		}
+	}
+}
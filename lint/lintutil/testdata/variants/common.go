@@ -0,0 +1,10 @@
+package variants
+
+const always = true
+
+func Common() {
+	if always {
+	}
+	if debug {
+	}
+}
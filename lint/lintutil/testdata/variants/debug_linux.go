@@ -0,0 +1,3 @@
+package variants
+
+const debug = true
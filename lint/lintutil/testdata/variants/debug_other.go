@@ -0,0 +1,5 @@
+// +build !linux
+
+package variants
+
+const debug = false
@@ -0,0 +1,10 @@
+// +build linux darwin
+
+package variants
+
+const unix = true
+
+func Unix() {
+	if unix {
+	}
+}
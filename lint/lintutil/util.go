@@ -19,6 +19,8 @@ import (
 	"strconv"
 	"strings"
 
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/internal/gomod"
 	"honnef.co/go/tools/lint"
 
 	"github.com/kisielk/gotool"
@@ -42,6 +44,7 @@ type runner struct {
 	tags    []string
 	ignores []lint.Ignore
 	version int
+	audit   bool
 }
 
 func (runner runner) resolveRelative(importPaths []string) (goFiles bool, err error) {
@@ -58,16 +61,37 @@ func (runner runner) resolveRelative(importPaths []string) (goFiles bool, err er
 	}
 	ctx := build.Default
 	ctx.BuildTags = runner.tags
+	modules := gomod.Enabled(wd)
 	for i, path := range importPaths {
-		bpkg, err := ctx.Import(path, wd, build.FindOnly)
+		importPaths[i], _, err = resolvePackage(&ctx, wd, path, modules)
 		if err != nil {
-			return false, fmt.Errorf("can't load package %q: %v", path, err)
+			return false, err
 		}
-		importPaths[i] = bpkg.ImportPath
 	}
 	return false, nil
 }
 
+// resolvePackage returns the import path and the directory of the
+// package path, which may be relative to wd. modules reports whether
+// the go command runs in module mode in wd.
+func resolvePackage(ctx *build.Context, wd, path string, modules bool) (importPath, dir string, err error) {
+	if modules && build.IsLocalImport(path) {
+		// go/build doesn't know the import paths of local
+		// packages in module mode. Packages of the modules of
+		// the go.work workspace or the module containing them
+		// are named by their module path.
+		dir := filepath.Join(wd, path)
+		if ipath, ok := gomod.ImportPath(dir); ok {
+			return ipath, dir, nil
+		}
+	}
+	bpkg, err := ctx.Import(path, wd, build.FindOnly)
+	if err != nil {
+		return "", "", fmt.Errorf("can't load package %q: %v", path, err)
+	}
+	return bpkg.ImportPath, bpkg.Dir, nil
+}
+
 func parseIgnore(s string) ([]lint.Ignore, error) {
 	var out []lint.Ignore
 	if len(s) == 0 {
@@ -117,6 +141,11 @@ func FlagSet(name string) *flag.FlagSet {
 	flags.String("tags", "", "List of `build tags`")
 	flags.String("ignore", "", "Space separated list of checks to ignore, in the following format: 'import/path/file.go:Check1,Check2,...' Both the import path and file name sections support globbing, e.g. 'os/exec/*_test.go'")
 	flags.Bool("tests", true, "Include tests")
+	flags.Bool("fix", false, "Apply suggested fixes to the source files")
+	flags.Bool("diff", false, "Print suggested fixes as a unified diff instead of applying them")
+	flags.Bool("interactive", false, "Ask for confirmation before applying each fix")
+	flags.Bool("audit-ignores", false, "Report linter directives that have expired or that reference neither an expiry date nor an issue")
+	flags.Bool("show-config", false, "Print the effective configuration of each package and exit")
 
 	tags := build.Default.ReleaseTags
 	v := tags[len(tags)-1][2:]
@@ -129,26 +158,90 @@ func FlagSet(name string) *flag.FlagSet {
 	return flags
 }
 
-func ProcessFlagSet(c lint.Checker, fs *flag.FlagSet) {
+// FlagOptions returns the Options described by the flags in fs, which
+// must have been created by FlagSet.
+func FlagOptions(fs *flag.FlagSet) *Options {
 	tags := fs.Lookup("tags").Value.(flag.Getter).Get().(string)
 	ignore := fs.Lookup("ignore").Value.(flag.Getter).Get().(string)
 	tests := fs.Lookup("tests").Value.(flag.Getter).Get().(bool)
 	version := fs.Lookup("go").Value.(flag.Getter).Get().(int)
+	audit := fs.Lookup("audit-ignores").Value.(flag.Getter).Get().(bool)
 
-	ps, lprog, err := Lint(c, fs.Args(), &Options{
+	return &Options{
 		Tags:      strings.Fields(tags),
 		LintTests: tests,
 		Ignores:   ignore,
 		GoVersion: version,
+		Audit:     audit,
+	}
+}
+
+func ProcessFlagSet(c lint.Checker, fs *flag.FlagSet) {
+	ProcessPackages(c, fs, fs.Args())
+}
+
+// ProcessPackages is like ProcessFlagSet, but lints pkgs instead of
+// the arguments of fs.
+func ProcessPackages(c lint.Checker, fs *flag.FlagSet, pkgs []string) {
+	process(fs, pkgs, func(opts *Options) ([]lint.Problem, *token.FileSet, error) {
+		ps, lprog, err := Lint(c, pkgs, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		return ps, lprog.Fset, nil
 	})
+}
+
+func process(fs *flag.FlagSet, pkgs []string, lintFn func(opts *Options) ([]lint.Problem, *token.FileSet, error)) {
+	fix := fs.Lookup("fix").Value.(flag.Getter).Get().(bool)
+	diff := fs.Lookup("diff").Value.(flag.Getter).Get().(bool)
+	interactive := fs.Lookup("interactive").Value.(flag.Getter).Get().(bool)
+	showCfg := fs.Lookup("show-config").Value.(flag.Getter).Get().(bool)
+	opts := FlagOptions(fs)
+
+	if showCfg {
+		if err := showConfig(os.Stdout, pkgs, opts.Tags); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	ps, fset, err := lintFn(opts)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
+	if fix || diff || interactive {
+		mode := fixWrite
+		if diff {
+			mode = fixDiff
+		}
+		applied, _, _, err := applyFixes(ps, fset, mode, interactive)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fixed := map[*lint.Fix]bool{}
+		for _, p := range applied {
+			fixed[p.Fix] = true
+		}
+		var rest []lint.Problem
+		for _, p := range ps {
+			if p.Fix == nil || !fixed[p.Fix] {
+				rest = append(rest, p)
+			}
+		}
+		ps = rest
+	}
 	unclean := false
 	for _, p := range ps {
+		pos := fset.Position(p.Position)
+		if p.Severity == config.SeverityWarning {
+			fmt.Printf("%v: warning: %s\n", relativePositionString(pos), p.Text)
+			continue
+		}
 		unclean = true
-		pos := lprog.Fset.Position(p.Position)
 		fmt.Printf("%v: %s\n", relativePositionString(pos), p.Text)
 	}
 	if unclean {
@@ -161,6 +254,14 @@ type Options struct {
 	LintTests bool
 	Ignores   string
 	GoVersion int
+	Audit     bool
+
+	// GOOS and GOARCH override the target platform when not empty.
+	GOOS   string
+	GOARCH string
+
+	// the file set to load packages into, if not nil
+	fset *token.FileSet
 }
 
 func Lint(c lint.Checker, pkgs []string, opt *Options) ([]lint.Problem, *loader.Program, error) {
@@ -178,6 +279,7 @@ func Lint(c lint.Checker, pkgs []string, opt *Options) ([]lint.Problem, *loader.
 		tags:    opt.Tags,
 		ignores: ignores,
 		version: opt.GoVersion,
+		audit:   opt.Audit,
 	}
 	paths := gotool.ImportPaths(pkgs)
 	goFiles, err := runner.resolveRelative(paths)
@@ -186,7 +288,18 @@ func Lint(c lint.Checker, pkgs []string, opt *Options) ([]lint.Problem, *loader.
 	}
 	ctx := build.Default
 	ctx.BuildTags = runner.tags
+	if opt.GOOS != "" {
+		ctx.GOOS = opt.GOOS
+	}
+	if opt.GOARCH != "" {
+		ctx.GOARCH = opt.GOARCH
+	}
+	if ctx.GOOS != build.Default.GOOS || ctx.GOARCH != build.Default.GOARCH {
+		// We can't run cgo for other platforms.
+		ctx.CgoEnabled = false
+	}
 	conf := &loader.Config{
+		Fset:       opt.fset,
 		Build:      &ctx,
 		ParserMode: parser.ParseComments,
 		ImportPkgs: map[string]bool{},
@@ -239,9 +352,10 @@ func ProcessArgs(name string, c lint.Checker, args []string) {
 
 func (runner *runner) lint(lprog *loader.Program) []lint.Problem {
 	l := &lint.Linter{
-		Checker:   runner.checker,
-		Ignores:   runner.ignores,
-		GoVersion: runner.version,
+		Checker:      runner.checker,
+		Ignores:      runner.ignores,
+		GoVersion:    runner.version,
+		AuditIgnores: runner.audit,
 	}
 	return l.Lint(lprog)
 }
@@ -0,0 +1,149 @@
+package lintutil
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/token"
+	"sort"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+)
+
+// A Variant is a build configuration to lint packages in.
+type Variant struct {
+	GOOS   string
+	GOARCH string
+	Tags   []string
+}
+
+func (v Variant) String() string {
+	s := v.GOOS + "/" + v.GOARCH
+	if len(v.Tags) > 0 {
+		s += "," + strings.Join(v.Tags, ",")
+	}
+	return s
+}
+
+// ParseVariants parses a whitespace-separated list of variants of the
+// form GOOS/GOARCH[,tag...], such as "linux/amd64 windows/amd64,cgo".
+// The list must contain at least one variant.
+func ParseVariants(s string) ([]Variant, error) {
+	var out []Variant
+	for _, field := range strings.Fields(s) {
+		parts := strings.Split(field, ",")
+		platform := strings.Split(parts[0], "/")
+		if len(platform) != 2 || platform[0] == "" || platform[1] == "" {
+			return nil, fmt.Errorf("malformed variant %q, expected GOOS/GOARCH[,tag...]", field)
+		}
+		out = append(out, Variant{
+			GOOS:   platform[0],
+			GOARCH: platform[1],
+			Tags:   parts[1:],
+		})
+	}
+	if len(out) == 0 {
+		return nil, errors.New("no variants, expected GOOS/GOARCH[,tag...]")
+	}
+	return out, nil
+}
+
+// LintVariants lints pkgs in each of the variants, using a new checker
+// for each, and returns the problems that were reported in all
+// variants that include the file the problem is in. Problems about
+// code that is only compiled in some variants are thus only reported
+// if they apply to all of them.
+func LintVariants(newChecker func() lint.Checker, pkgs []string, opt *Options, variants []Variant) ([]lint.Problem, *token.FileSet, error) {
+	if opt == nil {
+		opt = &Options{}
+	}
+	fset := token.NewFileSet()
+	var results []variantResult
+	for _, v := range variants {
+		vopt := *opt
+		vopt.GOOS = v.GOOS
+		vopt.GOARCH = v.GOARCH
+		vopt.Tags = append(append([]string(nil), opt.Tags...), v.Tags...)
+		vopt.fset = fset
+		ps, lprog, err := Lint(newChecker(), pkgs, &vopt)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %s", v, err)
+		}
+		res := variantResult{problems: ps, files: map[string]bool{}}
+		for _, pkg := range lprog.InitialPackages() {
+			for _, f := range pkg.Files {
+				res.files[fset.Position(f.Pos()).Filename] = true
+			}
+		}
+		results = append(results, res)
+	}
+	return mergeVariants(fset, results), fset, nil
+}
+
+// A variantResult holds the problems reported in one variant and
+// the files that the variant compiled.
+type variantResult struct {
+	problems []lint.Problem
+	files    map[string]bool
+}
+
+// mergeVariants returns the problems that were reported in every
+// result whose files include the file the problem is in, sorted by
+// position. Problems without a file have to be reported in all
+// results.
+func mergeVariants(fset *token.FileSet, results []variantResult) []lint.Problem {
+	type key struct {
+		pos  token.Position
+		text string
+	}
+	counts := map[key]int{}
+	problems := map[key]lint.Problem{}
+	files := map[string]int{}
+	for _, res := range results {
+		for name := range res.files {
+			files[name]++
+		}
+		for _, p := range res.problems {
+			k := key{fset.Position(p.Position), p.Text}
+			if _, ok := problems[k]; !ok {
+				problems[k] = p
+			}
+			counts[k]++
+		}
+	}
+
+	var keys []key
+	for k, n := range counts {
+		want := files[k.pos.Filename]
+		if k.pos.Filename == "" {
+			want = len(results)
+		}
+		if n >= want {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		pi, pj := keys[i].pos, keys[j].pos
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		if pi.Offset != pj.Offset {
+			return pi.Offset < pj.Offset
+		}
+		return keys[i].text < keys[j].text
+	})
+	out := make([]lint.Problem, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, problems[k])
+	}
+	return out
+}
+
+// ProcessVariants is like ProcessPackages, but lints pkgs in each of
+// the variants, as described by LintVariants.
+func ProcessVariants(newChecker func() lint.Checker, fs *flag.FlagSet, pkgs []string, variants []Variant) {
+	process(fs, pkgs, func(opts *Options) ([]lint.Problem, *token.FileSet, error) {
+		return LintVariants(newChecker, pkgs, opts, variants)
+	})
+}
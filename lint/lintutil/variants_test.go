@@ -0,0 +1,126 @@
+package lintutil
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"honnef.co/go/tools/lint"
+)
+
+func TestParseVariants(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []Variant
+		err  bool
+	}{
+		{"", nil, true},
+		{" \t\n", nil, true},
+		{"linux/amd64", []Variant{{GOOS: "linux", GOARCH: "amd64", Tags: []string{}}}, false},
+		{
+			" linux/amd64  windows/386,cgo,foo\n",
+			[]Variant{
+				{GOOS: "linux", GOARCH: "amd64", Tags: []string{}},
+				{GOOS: "windows", GOARCH: "386", Tags: []string{"cgo", "foo"}},
+			},
+			false,
+		},
+		{"linux", nil, true},
+		{"linux/", nil, true},
+		{"/amd64", nil, true},
+		{"linux/amd64/v2", nil, true},
+		{"linux/amd64 ,cgo", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseVariants(tt.in)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseVariants(%q) returned error %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseVariants(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMergeVariants(t *testing.T) {
+	fset := token.NewFileSet()
+	a := fset.AddFile("a.go", -1, 100)
+	b := fset.AddFile("b.go", -1, 100)
+	p := func(pos token.Pos, text string) lint.Problem {
+		return lint.Problem{Position: pos, Text: text}
+	}
+	results := []variantResult{
+		{
+			problems: []lint.Problem{p(a.Pos(10), "a"), p(a.Pos(20), "some"), p(b.Pos(5), "b"), p(token.NoPos, "all"), p(token.NoPos, "partial")},
+			files:    map[string]bool{"a.go": true, "b.go": true},
+		},
+		{
+			// b.go isn't compiled in this variant
+			problems: []lint.Problem{p(a.Pos(10), "a"), p(token.NoPos, "all")},
+			files:    map[string]bool{"a.go": true},
+		},
+		{
+			problems: []lint.Problem{p(a.Pos(10), "a"), p(a.Pos(10), "other"), p(b.Pos(5), "b"), p(token.NoPos, "all")},
+			files:    map[string]bool{"a.go": true, "b.go": true},
+		},
+	}
+	var got []string
+	for _, p := range mergeVariants(fset, results) {
+		got = append(got, fmt.Sprintf("%s: %s", fset.Position(p.Position), p.Text))
+	}
+	want := []string{"-: all", "a.go:1:11: a", "b.go:1:6: b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// constChecker reports if statements whose condition is a constant
+// true.
+type constChecker struct{}
+
+func (constChecker) Init(*lint.Program) {}
+
+func (constChecker) Funcs() map[string]lint.Func {
+	return map[string]lint.Func{
+		"TEST": func(j *lint.Job) {
+			fn := func(node ast.Node) bool {
+				stmt, ok := node.(*ast.IfStmt)
+				if ok && j.IsBoolConst(stmt.Cond) && j.BoolConst(stmt.Cond) {
+					j.Errorf(stmt, "condition is always true")
+				}
+				return true
+			}
+			for _, f := range j.Program.Files {
+				ast.Inspect(f, fn)
+			}
+		},
+	}
+}
+
+func TestLintVariants(t *testing.T) {
+	variants := []Variant{
+		{GOOS: "linux", GOARCH: "amd64"},
+		{GOOS: "darwin", GOARCH: "amd64"},
+		{GOOS: "windows", GOARCH: "amd64"},
+	}
+	newChecker := func() lint.Checker { return constChecker{} }
+	ps, fset, err := LintVariants(newChecker, []string{"./testdata/variants"}, nil, variants)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, p := range ps {
+		pos := fset.Position(p.Position)
+		got = append(got, fmt.Sprintf("%s:%d", filepath.Base(pos.Filename), pos.Line))
+	}
+	// debug is only true on Linux, and unix.go isn't compiled on
+	// Windows.
+	want := []string{"common.go:6", "unix.go:8"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,45 @@
+package lint
+
+// A MultiChecker combines several checkers into one, running the
+// checks of all of them. It provides the options of its checkers and
+// runs the user-defined rules if any of them does.
+type MultiChecker struct {
+	Checkers []Checker
+}
+
+func (c *MultiChecker) Init(prog *Program) {
+	for _, cc := range c.Checkers {
+		cc.Init(prog)
+	}
+}
+
+func (c *MultiChecker) Funcs() map[string]Func {
+	fns := map[string]Func{}
+	for _, cc := range c.Checkers {
+		for k, v := range cc.Funcs() {
+			fns[k] = v
+		}
+	}
+	return fns
+}
+
+func (c *MultiChecker) Options() map[string][]Option {
+	opts := map[string][]Option{}
+	for _, cc := range c.Checkers {
+		if provider, ok := cc.(OptionsProvider); ok {
+			for k, v := range provider.Options() {
+				opts[k] = v
+			}
+		}
+	}
+	return opts
+}
+
+func (c *MultiChecker) RunsRules() bool {
+	for _, cc := range c.Checkers {
+		if rr, ok := cc.(RuleRunner); ok && rr.RunsRules() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package lint
+
+import (
+	"go/ast"
+	"go/token"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"honnef.co/go/tools/ssa"
+)
+
+// Path returns the AST nodes enclosing the position of n, from the
+// innermost node to the file, or nil if n doesn't belong to a file of
+// the program.
+func (j *Job) Path(n Positioner) []ast.Node {
+	pos := n.Pos()
+	if !pos.IsValid() {
+		return nil
+	}
+	f := j.File(n)
+	if f == nil {
+		return nil
+	}
+	path, _ := astutil.PathEnclosingInterval(f, pos, pos)
+	return path
+}
+
+// ExprFor returns the expression that v was computed from, or nil if
+// it can't be determined.
+//
+// The debug information recorded by the SSA builder is consulted
+// first, which maps expressions to their values precisely. For
+// instructions without debug information, the innermost expression
+// at the instruction's position is used if it has the right shape,
+// for example a call expression for a call.
+func (j *Job) ExprFor(v ssa.Value) ast.Expr {
+	if refs := v.Referrers(); refs != nil {
+		for _, ref := range *refs {
+			if ref, ok := ref.(*ssa.DebugRef); ok && ref.X == v && !ref.IsAddr {
+				return ref.Expr
+			}
+		}
+	}
+	instr, ok := v.(ssa.Instruction)
+	if !ok {
+		return nil
+	}
+	for _, n := range j.Path(instr) {
+		expr, ok := n.(ast.Expr)
+		if !ok {
+			return nil
+		}
+		if matchesInstr(expr, instr) {
+			return expr
+		}
+	}
+	return nil
+}
+
+// matchesInstr reports whether expr is of the kind that the SSA
+// builder translates to instructions like instr. The positions of
+// instructions refer to tokens within the corresponding expressions,
+// such as the opening parenthesis of a call.
+func matchesInstr(expr ast.Expr, instr ssa.Instruction) bool {
+	switch instr := instr.(type) {
+	case *ssa.Call:
+		call, ok := expr.(*ast.CallExpr)
+		return ok && call.Lparen == instr.Pos()
+	case *ssa.BinOp:
+		bin, ok := expr.(*ast.BinaryExpr)
+		return ok && bin.OpPos == instr.Pos()
+	case *ssa.UnOp:
+		switch expr := expr.(type) {
+		case *ast.UnaryExpr:
+			return expr.OpPos == instr.Pos()
+		case *ast.StarExpr:
+			return instr.Op == token.MUL && expr.Star == instr.Pos()
+		}
+		return false
+	case *ssa.FieldAddr, *ssa.Field:
+		sel, ok := expr.(*ast.SelectorExpr)
+		return ok && sel.Sel.Pos() == instr.Pos()
+	case *ssa.IndexAddr, *ssa.Index, *ssa.Lookup:
+		idx, ok := expr.(*ast.IndexExpr)
+		return ok && idx.Lbrack == instr.Pos()
+	case *ssa.Slice:
+		sl, ok := expr.(*ast.SliceExpr)
+		return ok && sl.Lbrack == instr.Pos()
+	case *ssa.TypeAssert:
+		ta, ok := expr.(*ast.TypeAssertExpr)
+		return ok && ta.Lparen == instr.Pos()
+	case *ssa.MakeClosure:
+		lit, ok := expr.(*ast.FuncLit)
+		return ok && lit.Type.Func == instr.Pos()
+	}
+	return false
+}
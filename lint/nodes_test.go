@@ -0,0 +1,92 @@
+package lint
+
+import (
+	"fmt"
+	"testing"
+
+	"golang.org/x/tools/go/loader"
+	"honnef.co/go/tools/ssa"
+)
+
+type nodesChecker struct {
+	fn func(j *Job)
+}
+
+func (c nodesChecker) Init(*Program) {}
+
+func (c nodesChecker) Funcs() map[string]Func {
+	return map[string]Func{"TEST": c.fn}
+}
+
+const nodesSrc = `package pkg
+
+type T struct{ f int }
+
+func fn(x, y int, t *T, s []int) int {
+	z := x + y
+	g(z)
+	t.f = s[1]
+	if h := func() int { return -z }; h() > 0 {
+		return z * 2
+	}
+	return *&z
+}
+
+func g(int) {}
+`
+
+func TestExprFor(t *testing.T) {
+	conf := &loader.Config{}
+	f, err := conf.ParseFile("nodes.go", nodesSrc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.CreateFromFiles("pkg", f)
+	lprog, err := conf.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the instructions that were mapped to expressions, by
+	// instruction kind and expression
+	got := map[string]bool{}
+	check := func(j *Job) {
+		for _, fn := range j.Program.InitialFunctions {
+			if fn.Name() != "fn" && fn.Parent() == nil {
+				// Only look at fn and its closure
+				continue
+			}
+			for _, b := range fn.Blocks {
+				for _, instr := range b.Instrs {
+					v, ok := instr.(ssa.Value)
+					if !ok {
+						continue
+					}
+					expr := j.ExprFor(v)
+					if expr == nil {
+						continue
+					}
+					got[fmt.Sprintf("%T: %s", instr, j.Render(expr))] = true
+				}
+			}
+		}
+	}
+	l := &Linter{Checker: nodesChecker{check}}
+	l.Lint(lprog)
+
+	want := []string{
+		"*ssa.BinOp: x + y",
+		"*ssa.Call: g(z)",
+		"*ssa.IndexAddr: s[1]",
+		"*ssa.FieldAddr: t.f",
+		"*ssa.MakeClosure: func() int { return -z }",
+		"*ssa.Call: h()",
+		"*ssa.UnOp: -z",
+		"*ssa.BinOp: z * 2",
+	}
+	for _, k := range want {
+		if !got[k] {
+			t.Errorf("%s: no expression found", k)
+		}
+	}
+}
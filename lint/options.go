@@ -0,0 +1,183 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"honnef.co/go/tools/config"
+)
+
+// An Option is a configuration option of a check. The type of Default
+// determines the type of the option and must be one of bool, int,
+// string and []string.
+type Option struct {
+	Name    string
+	Default interface{}
+	Doc     string
+}
+
+// An OptionsProvider is a Checker whose checks have options.
+type OptionsProvider interface {
+	// Options returns the options of checks, keyed by check name.
+	Options() map[string][]Option
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case bool:
+		return "a boolean"
+	case int, int64:
+		return "an integer"
+	case string:
+		return "a string"
+	case []string:
+		return "a list of strings"
+	case []interface{}:
+		return "a list"
+	case map[string]interface{}:
+		return "a table"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// convertOption converts v, a value decoded from a configuration file,
+// to the type of opt.
+func convertOption(opt Option, v interface{}) (interface{}, bool) {
+	switch opt.Default.(type) {
+	case bool:
+		b, ok := v.(bool)
+		return b, ok
+	case int:
+		n, ok := v.(int64)
+		return int(n), ok
+	case string:
+		s, ok := v.(string)
+		return s, ok
+	case []string:
+		l, ok := v.([]interface{})
+		if !ok {
+			return nil, false
+		}
+		out := make([]string, 0, len(l))
+		for _, e := range l {
+			s, ok := e.(string)
+			if !ok {
+				return nil, false
+			}
+			out = append(out, s)
+		}
+		return out, true
+	default:
+		panic(fmt.Sprintf("option %s has unsupported type %T", opt.Name, opt.Default))
+	}
+}
+
+// checkPrefix returns the letters that check names of a family of
+// checks start with, such as SA for SA1000.
+func checkPrefix(check string) string {
+	return strings.TrimRight(check, "0123456789")
+}
+
+// checkPrefixes returns the prefixes of the families of checks in
+// funcs.
+func checkPrefixes(funcs map[string]Func) map[string]bool {
+	prefixes := map[string]bool{}
+	for check := range funcs {
+		prefixes[checkPrefix(check)] = true
+	}
+	return prefixes
+}
+
+// checkOptions validates the options in cfg against the options
+// declared by the checks in funcs and returns them converted to their
+// declared types.
+//
+// The configuration is shared by all linters, so options of checks
+// that belong to other linters are ignored.
+func checkOptions(cfg config.Config, funcs map[string]Func, declared map[string][]Option) (map[string]map[string]interface{}, error) {
+	prefixes := checkPrefixes(funcs)
+	out := map[string]map[string]interface{}{}
+	var checks []string
+	for check := range cfg.Options {
+		checks = append(checks, check)
+	}
+	sort.Strings(checks)
+	for _, check := range checks {
+		if _, ok := funcs[check]; !ok {
+			if prefixes[checkPrefix(check)] {
+				return nil, fmt.Errorf("options for unknown check %s", check)
+			}
+			continue
+		}
+		opts := map[string]Option{}
+		var names []string
+		for _, opt := range declared[check] {
+			opts[opt.Name] = opt
+			names = append(names, opt.Name)
+		}
+		var keys []string
+		for k := range cfg.Options[check] {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			opt, ok := opts[k]
+			if !ok {
+				if len(names) == 0 {
+					return nil, fmt.Errorf("unknown option %s for check %s, which has no options", k, check)
+				}
+				return nil, fmt.Errorf("unknown option %s for check %s, known options are: %s", k, check, strings.Join(names, ", "))
+			}
+			v, ok := convertOption(opt, cfg.Options[check][k])
+			if !ok {
+				return nil, fmt.Errorf("option %s of check %s must be %s, not %s",
+					k, check, typeName(opt.Default), typeName(cfg.Options[check][k]))
+			}
+			if out[check] == nil {
+				out[check] = map[string]interface{}{}
+			}
+			out[check][k] = v
+		}
+	}
+	return out, nil
+}
+
+// Option returns the value of the option name of the current check,
+// as configured for the package containing node.
+func (j *Job) Option(node Positioner, name string) interface{} {
+	return j.CheckOption(j.check, node, name)
+}
+
+// CheckOption is like Option, but returns the option of check, which
+// allows related checks to share options.
+func (j *Job) CheckOption(check string, node Positioner, name string) interface{} {
+	if pkg := j.NodePackage(node); pkg != nil {
+		if v, ok := pkg.options[check][name]; ok {
+			return v
+		}
+	}
+	for _, opt := range j.Program.options[check] {
+		if opt.Name == name {
+			return opt.Default
+		}
+	}
+	panic(fmt.Sprintf("check %s has no option %s", check, name))
+}
+
+func (j *Job) BoolOption(node Positioner, name string) bool {
+	return j.Option(node, name).(bool)
+}
+
+func (j *Job) IntOption(node Positioner, name string) int {
+	return j.Option(node, name).(int)
+}
+
+func (j *Job) StringOption(node Positioner, name string) string {
+	return j.Option(node, name).(string)
+}
+
+func (j *Job) StringsOption(node Positioner, name string) []string {
+	return j.Option(node, name).([]string)
+}
@@ -0,0 +1,66 @@
+package lint
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"honnef.co/go/tools/config"
+)
+
+func TestCheckOptions(t *testing.T) {
+	funcs := map[string]Func{"ST1000": nil, "ST1001": nil}
+	declared := map[string][]Option{
+		"ST1000": {
+			{Name: "max", Default: 5},
+			{Name: "names", Default: []string{"a"}},
+		},
+	}
+	tests := []struct {
+		options map[string]map[string]interface{}
+		want    map[string]map[string]interface{}
+		err     string
+	}{
+		{
+			options: map[string]map[string]interface{}{"ST1000": {"max": int64(3), "names": []interface{}{"b", "c"}}},
+			want:    map[string]map[string]interface{}{"ST1000": {"max": 3, "names": []string{"b", "c"}}},
+		},
+		{
+			// options of other linters are ignored
+			options: map[string]map[string]interface{}{"SA1000": {"foo": true}},
+			want:    map[string]map[string]interface{}{},
+		},
+		{
+			options: map[string]map[string]interface{}{"ST9999": {"max": int64(3)}},
+			err:     "options for unknown check ST9999",
+		},
+		{
+			options: map[string]map[string]interface{}{"ST1000": {"mx": int64(3)}},
+			err:     "unknown option mx for check ST1000, known options are: max, names",
+		},
+		{
+			options: map[string]map[string]interface{}{"ST1001": {"max": int64(3)}},
+			err:     "unknown option max for check ST1001, which has no options",
+		},
+		{
+			options: map[string]map[string]interface{}{"ST1000": {"max": "3"}},
+			err:     "option max of check ST1000 must be an integer, not a string",
+		},
+	}
+	for _, tt := range tests {
+		got, err := checkOptions(config.Config{Options: tt.options}, funcs, declared)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("got error %v, want %q", err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("got %v, want %v", got, tt.want)
+		}
+	}
+}
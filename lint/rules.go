@@ -0,0 +1,43 @@
+package lint
+
+import (
+	"fmt"
+
+	"honnef.co/go/tools/config"
+)
+
+// A RuleRunner is a Checker that runs the user-defined rules of
+// configuration files alongside its own checks. Of the checkers run
+// on a program, only one should run them, or their problems would be
+// reported repeatedly.
+type RuleRunner interface {
+	// RunsRules reports whether the checker runs the rules.
+	RunsRules() bool
+}
+
+// runRules returns the problems that the rules of the packages'
+// configurations report.
+func runRules(prog *Program) []Problem {
+	var out []Problem
+	for _, pkg := range prog.Packages {
+		for _, r := range pkg.Config.Rules {
+			// Rules have been validated when their configuration
+			// files were parsed.
+			p, err := r.Compile()
+			if err != nil {
+				continue
+			}
+			for _, f := range pkg.Info.Files {
+				for _, m := range p.Find(f, pkg.Info.Pkg, &pkg.Info.Info) {
+					out = append(out, Problem{
+						Position: m.Node.Pos(),
+						Text:     fmt.Sprintf("%s (%s)", r.Message, r.ID),
+						Check:    r.ID,
+						Severity: config.SeverityError,
+					})
+				}
+			}
+		}
+	}
+	return out
+}
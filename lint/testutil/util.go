@@ -7,13 +7,13 @@
 package testutil // import "honnef.co/go/tools/lint/testutil"
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
 	"go/parser"
 	"go/token"
 	"io/ioutil"
 	"os"
-	"path"
 	"path/filepath"
 	"regexp"
 	"strconv"
@@ -68,7 +68,10 @@ func TestAll(t *testing.T, c lint.Checker, dir string) {
 	}
 	sources := map[string][]byte{}
 	for _, fi := range fis {
-		filename := path.Join(baseDir, fi.Name())
+		if !strings.HasSuffix(fi.Name(), ".go") {
+			continue
+		}
+		filename := filepath.Join(baseDir, fi.Name())
 		src, err := ioutil.ReadFile(filename)
 		if err != nil {
 			t.Errorf("Failed reading %s: %v", fi.Name(), err)
@@ -97,6 +100,7 @@ func TestAll(t *testing.T, c lint.Checker, dir string) {
 			src := sources[name]
 
 			ins := parseInstructions(t, name, src)
+			checkFixes(t, lprog.Fset, filepath.Join(baseDir, name+".golden"), name, src, res)
 
 			for _, in := range ins {
 				ok := false
@@ -133,6 +137,34 @@ func TestAll(t *testing.T, c lint.Checker, dir string) {
 	}
 }
 
+// checkFixes applies the fixes of the problems in the file name and
+// compares the result with the contents of golden, if that file
+// exists.
+func checkFixes(t *testing.T, fset *token.FileSet, golden, name string, src []byte, ps []lint.Problem) {
+	want, err := ioutil.ReadFile(golden)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		t.Errorf("Failed reading %s: %v", golden, err)
+		return
+	}
+	var fixes []*lint.Fix
+	for _, p := range ps {
+		if p.Fix != nil && filepath.Base(fset.Position(p.Position).Filename) == name {
+			fixes = append(fixes, p.Fix)
+		}
+	}
+	got, _, err := lint.ApplyFixes(fset, src, fixes)
+	if err != nil {
+		t.Errorf("Failed applying fixes to %s: %v", name, err)
+		return
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Fixes for %s don't match %s, got:\n%s", name, filepath.Base(golden), got)
+	}
+}
+
 type instruction struct {
 	Line        int            // the line number this applies to
 	Match       *regexp.Regexp // what pattern to match
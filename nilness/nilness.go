@@ -0,0 +1,219 @@
+// Package nilness answers whether SSA values can be nil at a given
+// point in a function.
+//
+// The analysis is intraprocedural. It combines what is known about
+// the instruction that produced a value with the facts established by
+// the code dominating the point in question: comparisons against nil
+// that guard it, and operations that would have panicked had the value
+// been nil.
+package nilness // import "honnef.co/go/tools/nilness"
+
+import (
+	"go/token"
+	"go/types"
+
+	"honnef.co/go/tools/ssa"
+)
+
+// Nilness describes whether a value is nil.
+type Nilness int
+
+const (
+	// Unknown means that the value may or may not be nil.
+	Unknown Nilness = iota
+	// Nil means that the value is definitely nil.
+	Nil
+	// NonNil means that the value is definitely not nil.
+	NonNil
+)
+
+func (n Nilness) String() string {
+	switch n {
+	case Nil:
+		return "nil"
+	case NonNil:
+		return "non-nil"
+	default:
+		return "unknown"
+	}
+}
+
+// IsNillable reports whether values of type T can be nil.
+func IsNillable(T types.Type) bool {
+	switch T := T.Underlying().(type) {
+	case *types.Pointer, *types.Map, *types.Slice, *types.Chan, *types.Signature, *types.Interface:
+		return true
+	case *types.Basic:
+		return T.Kind() == types.UnsafePointer || T.Kind() == types.UntypedNil
+	default:
+		return false
+	}
+}
+
+// Of returns the nilness of v that follows from the way it was
+// created, regardless of where in the function it is used.
+func Of(v ssa.Value) Nilness {
+	return of(v, map[*ssa.Phi]bool{})
+}
+
+func of(v ssa.Value, seen map[*ssa.Phi]bool) Nilness {
+	if !IsNillable(v.Type()) {
+		return NonNil
+	}
+	switch v := v.(type) {
+	case *ssa.Const:
+		if v.IsNil() {
+			return Nil
+		}
+		return NonNil
+	case *ssa.Alloc, *ssa.MakeChan, *ssa.MakeClosure, *ssa.MakeInterface,
+		*ssa.MakeMap, *ssa.MakeSlice, *ssa.FieldAddr, *ssa.IndexAddr,
+		*ssa.Function, *ssa.Global:
+		return NonNil
+	case *ssa.Slice:
+		// Slicing a pointer to an array or a string never yields
+		// nil; slicing a nil slice does.
+		if _, ok := v.X.Type().Underlying().(*types.Slice); ok {
+			return of(v.X, seen)
+		}
+		return NonNil
+	case *ssa.ChangeType:
+		return of(v.X, seen)
+	case *ssa.Phi:
+		return phi(v, seen)
+	}
+	return Unknown
+}
+
+// At returns the nilness of v immediately before instr executes.
+func At(v ssa.Value, instr ssa.Instruction) Nilness {
+	b := instr.Block()
+	for i, ins := range b.Instrs {
+		if ins == instr {
+			return at(v, b, i, map[*ssa.Phi]bool{})
+		}
+	}
+	return Of(v)
+}
+
+// CanBeNil reports whether v may be nil immediately before instr
+// executes.
+func CanBeNil(v ssa.Value, instr ssa.Instruction) bool {
+	return At(v, instr) != NonNil
+}
+
+// at returns the nilness of v before the instruction at index idx of b
+// executes. An idx of len(b.Instrs) refers to the end of b.
+func at(v ssa.Value, b *ssa.BasicBlock, idx int, seen map[*ssa.Phi]bool) Nilness {
+	if n := of(v, seen); n != Unknown {
+		return n
+	}
+	for d := b; d != nil; d = d.Idom() {
+		instrs := d.Instrs
+		if d == b {
+			instrs = instrs[:idx]
+		}
+		for _, ins := range instrs {
+			if dereferences(ins, v) {
+				return NonNil
+			}
+		}
+		// b can only be reached from d through a successor that
+		// dominates it; if that successor has no other way in, the
+		// branch condition holds in b.
+		if d == b {
+			continue
+		}
+		for _, succ := range d.Succs {
+			if len(succ.Preds) == 1 && succ.Dominates(b) {
+				if n := branch(v, d, succ); n != Unknown {
+					return n
+				}
+			}
+		}
+	}
+	return Unknown
+}
+
+// phi returns the nilness of v that all of its incoming edges agree
+// on.
+func phi(v *ssa.Phi, seen map[*ssa.Phi]bool) Nilness {
+	if seen[v] {
+		// Phis in loops can depend on themselves; they don't
+		// contribute anything new.
+		return Unknown
+	}
+	seen[v] = true
+	defer delete(seen, v)
+
+	out := Unknown
+	for i, edge := range v.Edges {
+		pred := v.Block().Preds[i]
+		n := branch(edge, pred, v.Block())
+		if n == Unknown {
+			n = at(edge, pred, len(pred.Instrs), seen)
+		}
+		if n == Unknown || (out != Unknown && n != out) {
+			return Unknown
+		}
+		out = n
+	}
+	return out
+}
+
+// branch returns the nilness of v that follows from the control flow
+// edge from the block from to its successor to, if from ends in a
+// comparison of v against nil.
+func branch(v ssa.Value, from, to *ssa.BasicBlock) Nilness {
+	if len(from.Instrs) == 0 || len(from.Succs) != 2 || from.Succs[0] == from.Succs[1] {
+		return Unknown
+	}
+	ifi, ok := from.Instrs[len(from.Instrs)-1].(*ssa.If)
+	if !ok {
+		return Unknown
+	}
+	binop, ok := ifi.Cond.(*ssa.BinOp)
+	if !ok || (binop.Op != token.EQL && binop.Op != token.NEQ) {
+		return Unknown
+	}
+	var other ssa.Value
+	switch v {
+	case binop.X:
+		other = binop.Y
+	case binop.Y:
+		other = binop.X
+	default:
+		return Unknown
+	}
+	if c, ok := other.(*ssa.Const); !ok || !c.IsNil() {
+		return Unknown
+	}
+	// The first successor is taken if the condition is true.
+	isNil := (to == from.Succs[0]) == (binop.Op == token.EQL)
+	if isNil {
+		return Nil
+	}
+	return NonNil
+}
+
+// dereferences reports whether ins panics if v is nil, which
+// establishes that v isn't nil once ins has executed.
+func dereferences(ins ssa.Instruction, v ssa.Value) bool {
+	switch ins := ins.(type) {
+	case *ssa.UnOp:
+		return ins.Op == token.MUL && ins.X == v
+	case *ssa.FieldAddr:
+		return ins.X == v
+	case *ssa.IndexAddr:
+		// Indexing a nil slice is always out of bounds.
+		return ins.X == v
+	case *ssa.Store:
+		return ins.Addr == v
+	case *ssa.MapUpdate:
+		return ins.Map == v
+	case ssa.CallInstruction:
+		common := ins.Common()
+		return common.IsInvoke() && common.Value == v
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package pattern
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+)
+
+// A constraint is a predicate on the node bound to a wildcard.
+type constraint struct {
+	neg  bool
+	pred string
+	arg  string
+}
+
+var predicates = map[string]bool{
+	"const":      false,
+	"kind":       true,
+	"type":       true,
+	"implements": true,
+}
+
+// Where adds the constraint c to p. A constraint consists of the name
+// of a wildcard, a colon and a predicate, optionally negated with an
+// exclamation mark, as in `$y: !kind(pointer)`. The predicates are:
+//
+//	const          the expression is a constant
+//	kind(k)        the expression's underlying type is of kind k, one
+//	               of array, bool, chan, complex, float, func, int,
+//	               interface, map, pointer, slice, string or struct
+//	type(T)        the expression's type is T, written as in
+//	               *net/http.Request
+//	implements(I)  the expression's type implements the interface I,
+//	               written as in io.Reader or error
+//
+// Predicates only hold for expressions with type information.
+func (p *Pattern) Where(c string) error {
+	i := strings.Index(c, ":")
+	if i == -1 {
+		return fmt.Errorf("constraint %q lacks a colon", c)
+	}
+	name := strings.TrimSpace(c[:i])
+	if !strings.HasPrefix(name, "$") || len(name) == 1 {
+		return fmt.Errorf("constraint %q must start with the name of a wildcard", c)
+	}
+	name = name[1:]
+	var con constraint
+	pred := strings.TrimSpace(c[i+1:])
+	if strings.HasPrefix(pred, "!") {
+		con.neg = true
+		pred = strings.TrimSpace(pred[1:])
+	}
+	if i := strings.Index(pred, "("); i != -1 {
+		if !strings.HasSuffix(pred, ")") {
+			return fmt.Errorf("constraint %q lacks a closing parenthesis", c)
+		}
+		con.arg = strings.TrimSpace(pred[i+1 : len(pred)-1])
+		pred = strings.TrimSpace(pred[:i])
+	}
+	hasArg, ok := predicates[pred]
+	if !ok {
+		return fmt.Errorf("unknown predicate %q in constraint %q", pred, c)
+	}
+	if hasArg != (con.arg != "") {
+		if hasArg {
+			return fmt.Errorf("predicate %s in constraint %q needs an argument", pred, c)
+		}
+		return fmt.Errorf("predicate %s in constraint %q doesn't take an argument", pred, c)
+	}
+	if pred == "kind" && kinds[con.arg] == nil {
+		return fmt.Errorf("unknown kind %q in constraint %q", con.arg, c)
+	}
+	con.pred = pred
+	p.constraints[name] = append(p.constraints[name], con)
+	return nil
+}
+
+var kinds = map[string]func(types.Type) bool{
+	"array":     func(T types.Type) bool { _, ok := T.(*types.Array); return ok },
+	"chan":      func(T types.Type) bool { _, ok := T.(*types.Chan); return ok },
+	"func":      func(T types.Type) bool { _, ok := T.(*types.Signature); return ok },
+	"interface": func(T types.Type) bool { _, ok := T.(*types.Interface); return ok },
+	"map":       func(T types.Type) bool { _, ok := T.(*types.Map); return ok },
+	"pointer":   func(T types.Type) bool { _, ok := T.(*types.Pointer); return ok },
+	"slice":     func(T types.Type) bool { _, ok := T.(*types.Slice); return ok },
+	"struct":    func(T types.Type) bool { _, ok := T.(*types.Struct); return ok },
+	"bool":      basicInfo(types.IsBoolean),
+	"complex":   basicInfo(types.IsComplex),
+	"float":     basicInfo(types.IsFloat),
+	"int":       basicInfo(types.IsInteger),
+	"string":    basicInfo(types.IsString),
+}
+
+func basicInfo(info types.BasicInfo) func(types.Type) bool {
+	return func(T types.Type) bool {
+		b, ok := T.(*types.Basic)
+		return ok && b.Info()&info != 0
+	}
+}
+
+// holds reports whether the constraint holds for n.
+func (c constraint) holds(n ast.Node, pkg *types.Package, info *types.Info) bool {
+	expr, ok := n.(ast.Expr)
+	if !ok || info == nil {
+		return false
+	}
+	tv, ok := info.Types[expr]
+	if !ok {
+		// Identifiers that aren't used as expressions, such as those
+		// on the left side of definitions, only have objects.
+		id, isIdent := expr.(*ast.Ident)
+		if !isIdent || info.ObjectOf(id) == nil {
+			return false
+		}
+		tv.Type = info.ObjectOf(id).Type()
+	}
+	if tv.Type == nil {
+		return false
+	}
+	var r bool
+	switch c.pred {
+	case "const":
+		r = tv.Value != nil
+	case "kind":
+		r = kinds[c.arg](tv.Type.Underlying())
+	case "type":
+		r = types.TypeString(tv.Type, nil) == c.arg
+	case "implements":
+		iface := lookupInterface(pkg, c.arg)
+		if iface == nil {
+			return false
+		}
+		r = types.Implements(tv.Type, iface)
+	}
+	return r != c.neg
+}
+
+// lookupInterface returns the interface type called name, such as
+// io.Reader, from among pkg and the packages it imports, directly or
+// indirectly, or from the universe.
+func lookupInterface(pkg *types.Package, name string) *types.Interface {
+	var obj types.Object
+	if i := strings.LastIndex(name, "."); i == -1 {
+		obj = types.Universe.Lookup(name)
+		if obj == nil && pkg != nil {
+			obj = pkg.Scope().Lookup(name)
+		}
+	} else if pkg != nil {
+		path, name := name[:i], name[i+1:]
+		seen := map[*types.Package]bool{}
+		var find func(p *types.Package) *types.Package
+		find = func(p *types.Package) *types.Package {
+			if seen[p] {
+				return nil
+			}
+			seen[p] = true
+			if p.Path() == path {
+				return p
+			}
+			for _, imp := range p.Imports() {
+				if found := find(imp); found != nil {
+					return found
+				}
+			}
+			return nil
+		}
+		if p := find(pkg); p != nil {
+			obj = p.Scope().Lookup(name)
+		}
+	}
+	if obj == nil {
+		return nil
+	}
+	iface, _ := obj.Type().Underlying().(*types.Interface)
+	return iface
+}
@@ -0,0 +1,184 @@
+package pattern
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+)
+
+var (
+	posType     = reflect.TypeOf(token.NoPos)
+	objectType  = reflect.TypeOf((*ast.Object)(nil))
+	scopeType   = reflect.TypeOf((*ast.Scope)(nil))
+	commentType = reflect.TypeOf((*ast.CommentGroup)(nil))
+	nodeType    = reflect.TypeOf((*ast.Node)(nil)).Elem()
+)
+
+type matcher struct {
+	p     *Pattern
+	pkg   *types.Package
+	info  *types.Info
+	binds map[string]ast.Node
+}
+
+// node reports whether n matches the pattern p.
+func (m *matcher) node(p, n ast.Node) bool {
+	pv, nv := reflect.ValueOf(p), reflect.ValueOf(n)
+	if !pv.IsValid() || pv.IsNil() {
+		return !nv.IsValid() || nv.IsNil()
+	}
+	if !nv.IsValid() || nv.IsNil() {
+		return false
+	}
+	if name, list, ok := wildcard(p); ok && !list {
+		if _, isField := p.(*ast.Field); isField {
+			// A wildcard field only matches fields, binding their
+			// types.
+			f, ok := n.(*ast.Field)
+			if !ok || len(f.Names) != 0 {
+				return false
+			}
+			n = f.Type
+		}
+		return m.bind(name, n)
+	}
+	if pv.Type() != nv.Type() {
+		return false
+	}
+	return m.value(pv.Elem(), nv.Elem())
+}
+
+func (m *matcher) value(p, n reflect.Value) bool {
+	switch p.Kind() {
+	case reflect.Struct:
+		for i := 0; i < p.NumField(); i++ {
+			switch p.Field(i).Type() {
+			case objectType, scopeType, commentType:
+				continue
+			case posType:
+				// Positions only matter in that some of them denote
+				// optional tokens, such as the ellipsis in a call.
+				ppos := p.Field(i).Interface().(token.Pos)
+				npos := n.Field(i).Interface().(token.Pos)
+				if ppos.IsValid() != npos.IsValid() {
+					return false
+				}
+				continue
+			}
+			if !m.value(p.Field(i), n.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Ptr, reflect.Interface:
+		if p.IsNil() || n.IsNil() {
+			return p.IsNil() == n.IsNil()
+		}
+		if pn, ok := p.Interface().(ast.Node); ok {
+			nn, ok := n.Interface().(ast.Node)
+			return ok && m.node(pn, nn)
+		}
+		return m.value(p.Elem(), n.Elem())
+	case reflect.Slice:
+		if p.Type().Elem() == commentType {
+			return true
+		}
+		ps := make([]ast.Node, p.Len())
+		ns := make([]ast.Node, n.Len())
+		if !p.Type().Elem().Implements(nodeType) {
+			if p.Len() != n.Len() {
+				return false
+			}
+			for i := 0; i < p.Len(); i++ {
+				if !m.value(p.Index(i), n.Index(i)) {
+					return false
+				}
+			}
+			return true
+		}
+		for i := range ps {
+			ps[i], _ = p.Index(i).Interface().(ast.Node)
+		}
+		for i := range ns {
+			ns[i], _ = n.Index(i).Interface().(ast.Node)
+		}
+		return m.list(ps, ns)
+	default:
+		return p.Interface() == n.Interface()
+	}
+}
+
+// list reports whether the list of nodes ns matches the list of
+// patterns ps, trying all ways of matching list wildcards.
+func (m *matcher) list(ps, ns []ast.Node) bool {
+	if len(ps) == 0 {
+		return len(ns) == 0
+	}
+	saved := m.save()
+	if name, list, ok := wildcard(ps[0]); ok && list {
+		for k := 0; k <= len(ns); k++ {
+			if m.bind(name, List(ns[:k])) && m.list(ps[1:], ns[k:]) {
+				return true
+			}
+			m.binds = saved
+			saved = m.save()
+		}
+		return false
+	}
+	if len(ns) == 0 {
+		return false
+	}
+	if m.node(ps[0], ns[0]) && m.list(ps[1:], ns[1:]) {
+		return true
+	}
+	m.binds = saved
+	return false
+}
+
+func (m *matcher) save() map[string]ast.Node {
+	binds := make(map[string]ast.Node, len(m.binds))
+	for k, v := range m.binds {
+		binds[k] = v
+	}
+	return binds
+}
+
+// bind binds n to the wildcard name, if n is identical to what the
+// wildcard is already bound to and satisfies its constraints.
+func (m *matcher) bind(name string, n ast.Node) bool {
+	if name == "_" {
+		return true
+	}
+	if old, ok := m.binds[name]; ok {
+		return identical(old, n)
+	}
+	for _, c := range m.p.constraints[name] {
+		if !c.holds(n, m.pkg, m.info) {
+			return false
+		}
+	}
+	m.binds[name] = n
+	return true
+}
+
+// identical reports whether the nodes a and b are structurally
+// identical.
+func identical(a, b ast.Node) bool {
+	if la, ok := a.(List); ok {
+		lb, ok := b.(List)
+		if !ok || len(la) != len(lb) {
+			return false
+		}
+		for i := range la {
+			if !identical(la[i], lb[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	// Code doesn't contain wildcards, so matching it against other
+	// code compares it.
+	m := &matcher{p: &Pattern{}, binds: map[string]ast.Node{}}
+	return m.node(a, b)
+}
@@ -0,0 +1,254 @@
+// Package pattern matches Go code against syntax patterns.
+//
+// A pattern is an expression, a statement or a list of statements,
+// written in Go syntax, that may contain wildcards:
+//
+//	$x    matches any single node and binds it to x
+//	$*x   matches a list of zero or more nodes, such as arguments or
+//	      statements, and binds it to x
+//	$_    matches any single node without binding it
+//
+// A wildcard that appears more than once only matches nodes that are
+// structurally identical, so that `$x == $x` matches comparisons of
+// an expression with itself.
+//
+// Constraints restrict what a wildcard may match, based on type
+// information. They are written as the name of the wildcard, followed
+// by a colon and a predicate, such as `$y: !kind(pointer)`. See Where
+// for the available predicates.
+//
+// Matching is syntactic: identifiers, including package names, match
+// by name.
+package pattern // import "honnef.co/go/tools/pattern"
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+const (
+	anyPrefix  = "__pattern_any_"
+	listPrefix = "__pattern_list_"
+)
+
+// A Pattern is a parsed syntax pattern.
+type Pattern struct {
+	src   string
+	root  ast.Node   // the pattern, if it is a single node
+	stmts []ast.Stmt // the pattern, if it is a list of statements
+
+	constraints map[string][]constraint
+}
+
+// Parse parses the pattern src.
+func Parse(src string) (*Pattern, error) {
+	repl, err := replaceWildcards(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &Pattern{src: src, constraints: map[string][]constraint{}}
+	if expr, err := parser.ParseExpr(repl); err == nil {
+		p.root = expr
+		return p, nil
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", "package p; func _() {\n"+repl+"\n}", 0)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse pattern %q: %s", src, err)
+	}
+	body := f.Decls[0].(*ast.FuncDecl).Body.List
+	switch len(body) {
+	case 0:
+		return nil, fmt.Errorf("empty pattern %q", src)
+	case 1:
+		p.root = body[0]
+	default:
+		p.stmts = body
+	}
+	return p, nil
+}
+
+// MustParse is like Parse but panics if the pattern can't be parsed.
+func MustParse(src string) *Pattern {
+	p, err := Parse(src)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func (p *Pattern) String() string { return p.src }
+
+// replaceWildcards replaces the wildcards in src with identifiers, so
+// that it can be parsed as Go code.
+func replaceWildcards(src string) (string, error) {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", -1, len(src))
+	var s scanner.Scanner
+	var errs scanner.ErrorList
+	s.Init(file, []byte(src), func(pos token.Position, msg string) {
+		if !strings.Contains(msg, "'$'") {
+			errs.Add(pos, msg)
+		}
+	}, 0)
+
+	var out []byte
+	last := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok != token.ILLEGAL || lit != "$" {
+			continue
+		}
+		off := file.Offset(pos)
+		prefix := anyPrefix
+		pos, tok, lit = s.Scan()
+		if tok == token.MUL {
+			prefix = listPrefix
+			pos, tok, lit = s.Scan()
+		}
+		if tok != token.IDENT {
+			return "", fmt.Errorf("wildcard at offset %d of %q must be followed by a name", off, src)
+		}
+		out = append(out, src[last:off]...)
+		out = append(out, prefix+lit...)
+		last = file.Offset(pos) + len(lit)
+	}
+	if len(errs) > 0 {
+		return "", errs.Err()
+	}
+	out = append(out, src[last:]...)
+	return string(out), nil
+}
+
+// wildcard returns the name of the wildcard that n is, and whether it
+// matches lists of nodes.
+func wildcard(n ast.Node) (name string, list bool, ok bool) {
+	switch n := n.(type) {
+	case *ast.Ident:
+		if strings.HasPrefix(n.Name, anyPrefix) {
+			return n.Name[len(anyPrefix):], false, true
+		}
+		if strings.HasPrefix(n.Name, listPrefix) {
+			return n.Name[len(listPrefix):], true, true
+		}
+	case *ast.ExprStmt:
+		return wildcard(n.X)
+	case *ast.Field:
+		if len(n.Names) == 0 && n.Tag == nil {
+			return wildcard(n.Type)
+		}
+	}
+	return "", false, false
+}
+
+// A List is a list of nodes bound to a list wildcard.
+type List []ast.Node
+
+func (l List) Pos() token.Pos {
+	if len(l) == 0 {
+		return token.NoPos
+	}
+	return l[0].Pos()
+}
+
+func (l List) End() token.Pos {
+	if len(l) == 0 {
+		return token.NoPos
+	}
+	return l[len(l)-1].End()
+}
+
+// A Match is a part of the code that matches a pattern.
+type Match struct {
+	// Node is the node that matches the pattern. For patterns that
+	// are lists of statements, it is a List.
+	Node ast.Node
+	// Bindings maps the names of wildcards to the nodes they
+	// matched.
+	Bindings map[string]ast.Node
+}
+
+// Match reports whether node matches p, and returns the bindings of
+// the wildcards. info and pkg provide the type information that
+// constraints need; if they are nil, constraints never hold.
+func (p *Pattern) Match(node ast.Node, pkg *types.Package, info *types.Info) (map[string]ast.Node, bool) {
+	if p.root == nil {
+		return nil, false
+	}
+	m := &matcher{p: p, pkg: pkg, info: info, binds: map[string]ast.Node{}}
+	if _, _, ok := wildcard(p.root); ok {
+		// Wildcards on their own match only nodes of the same
+		// category.
+		switch p.root.(type) {
+		case ast.Expr:
+			if _, ok := node.(ast.Expr); !ok {
+				return nil, false
+			}
+		case ast.Stmt:
+			if _, ok := node.(ast.Stmt); !ok {
+				return nil, false
+			}
+		}
+	}
+	if !m.node(p.root, node) {
+		return nil, false
+	}
+	return m.binds, true
+}
+
+// Find returns all matches of p in node, in the order of their
+// positions.
+func (p *Pattern) Find(node ast.Node, pkg *types.Package, info *types.Info) []Match {
+	var matches []Match
+	ast.Inspect(node, func(n ast.Node) bool {
+		if n == nil {
+			return true
+		}
+		if p.root != nil {
+			if binds, ok := p.Match(n, pkg, info); ok {
+				matches = append(matches, Match{Node: n, Bindings: binds})
+			}
+			return true
+		}
+		var list []ast.Stmt
+		switch n := n.(type) {
+		case *ast.BlockStmt:
+			list = n.List
+		case *ast.CaseClause:
+			list = n.Body
+		case *ast.CommClause:
+			list = n.Body
+		default:
+			return true
+		}
+		ps := make([]ast.Node, len(p.stmts))
+		for i, stmt := range p.stmts {
+			ps[i] = stmt
+		}
+		for i := 0; i < len(list); i++ {
+			for j := len(list); j > i; j-- {
+				ns := make([]ast.Node, j-i)
+				for k := range ns {
+					ns[k] = list[i+k]
+				}
+				m := &matcher{p: p, pkg: pkg, info: info, binds: map[string]ast.Node{}}
+				if m.list(ps, ns) {
+					matches = append(matches, Match{Node: List(ns), Bindings: m.binds})
+					// Don't report overlapping matches.
+					i = j - 1
+					break
+				}
+			}
+		}
+		return true
+	})
+	return matches
+}
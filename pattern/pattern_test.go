@@ -0,0 +1,117 @@
+package pattern
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const src = `package pkg
+
+type E struct{}
+
+func (E) Error() string { return "" }
+
+func f(args ...interface{}) {}
+
+func g() error {
+	a, b := 1, 2
+	_ = a == a
+	_ = a == b
+	f(a, nil)
+	f(nil)
+	f(a, b)
+	v := E{}
+	f(v)
+	f(&v)
+	x := E{}
+	return x
+}
+`
+
+func TestFind(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "pkg.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{
+		Types: map[ast.Expr]types.TypeAndValue{},
+		Defs:  map[*ast.Ident]types.Object{},
+		Uses:  map[*ast.Ident]types.Object{},
+	}
+	pkg, err := (&types.Config{}).Check("pkg", fset, []*ast.File{f}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	render := func(n ast.Node) string {
+		if l, ok := n.(List); ok {
+			var out []string
+			for _, n := range l {
+				out = append(out, render1(fset, n))
+			}
+			return strings.Join(out, "\n")
+		}
+		return render1(fset, n)
+	}
+
+	tests := []struct {
+		pattern string
+		where   []string
+		want    []string
+	}{
+		{`$x == $x`, nil, []string{"a == a"}},
+		{`f($*_, nil)`, nil, []string{"f(a, nil)", "f(nil)"}},
+		{`f($_, $_)`, nil, []string{"f(a, nil)", "f(a, b)"}},
+		{`f($x)`, []string{"$x: !kind(pointer)"}, []string{"f(nil)", "f(v)"}},
+		{`f($x)`, []string{"$x: kind(pointer)"}, []string{"f(&v)"}},
+		{`f($x)`, []string{"$x: implements(error)"}, []string{"f(v)", "f(&v)"}},
+		{`f($x, $_)`, []string{"$x: type(int)"}, []string{"f(a, nil)", "f(a, b)"}},
+		{`$x := E{}; return $x`, nil, []string{"x := E{}\nreturn x"}},
+	}
+	for _, tt := range tests {
+		p, err := Parse(tt.pattern)
+		if err != nil {
+			t.Errorf("%s: %s", tt.pattern, err)
+			continue
+		}
+		for _, c := range tt.where {
+			if err := p.Where(c); err != nil {
+				t.Errorf("%s: %s", tt.pattern, err)
+			}
+		}
+		var got []string
+		for _, m := range p.Find(f, pkg, info) {
+			got = append(got, render(m.Node))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %v: got %q, want %q", tt.pattern, tt.where, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, src := range []string{"$", "f($1)", "f("} {
+		if _, err := Parse(src); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", src)
+		}
+	}
+	p := MustParse("f($x)")
+	for _, c := range []string{"x: const", "$x const", "$x: unknown", "$x: kind(lists)", "$x: kind", "$x: const(1)"} {
+		if err := p.Where(c); err == nil {
+			t.Errorf("Where(%q) succeeded, want error", c)
+		}
+	}
+}
+
+func render1(fset *token.FileSet, n ast.Node) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, fset, n)
+	return buf.String()
+}
@@ -2,6 +2,8 @@
 package simple // import "honnef.co/go/tools/simple"
 
 import (
+	"bytes"
+	"fmt"
 	"go/ast"
 	"go/constant"
 	"go/token"
@@ -32,7 +34,7 @@ func NewChecker() *Checker {
 }
 
 func (c *Checker) Init(prog *lint.Program) {
-	c.nodeFns = lint.NodeFns(prog.Packages)
+	c.nodeFns = prog.NodeFns
 }
 
 func (c *Checker) Funcs() map[string]lint.Func {
@@ -68,6 +70,13 @@ func (c *Checker) Funcs() map[string]lint.Func {
 		"S1028": c.LintErrorsNewSprintf,
 		"S1029": c.LintRangeStringRunes,
 		"S1030": c.LintBytesBufferConversions,
+		"S1031": c.LintErrorfWrap,
+		"S1032": c.LintStringConcatInLoop,
+		"S1033": c.LintSortSlice,
+		"S1034": c.LintInterfaceAny,
+		"S1035": c.LintSlicesMaps,
+		"S1036": c.LintIfElseChainToSwitch,
+		"S1037": c.LintErrorfNoVerbs,
 	}
 }
 
@@ -84,31 +93,90 @@ func (c *Checker) filterGenerated(files []*ast.File) []*ast.File {
 	return out
 }
 
+// hasUnlabeledBreak reports whether body contains a break statement
+// that would terminate the statement that body belongs to.
+func hasUnlabeledBreak(body []ast.Stmt) bool {
+	found := false
+	fn := func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.BranchStmt:
+			if node.Tok == token.BREAK && node.Label == nil {
+				found = true
+			}
+		case *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt, *ast.FuncLit:
+			return false
+		}
+		return !found
+	}
+	for _, stmt := range body {
+		ast.Inspect(stmt, fn)
+	}
+	return found
+}
+
 func (c *Checker) LintSingleCaseSelect(j *lint.Job) {
 	isSingleSelect := func(node ast.Node) bool {
 		v, ok := node.(*ast.SelectStmt)
 		if !ok {
 			return false
 		}
-		return len(v.Body.List) == 1
+		if len(v.Body.List) != 1 {
+			return false
+		}
+		return v.Body.List[0].(*ast.CommClause).Comm != nil
+	}
+
+	// rangeClause returns the header of a range loop equivalent to
+	// looping over the receive operation in comm.
+	rangeClause := func(comm ast.Stmt) (string, bool) {
+		switch comm := comm.(type) {
+		case *ast.ExprStmt:
+			recv, ok := comm.X.(*ast.UnaryExpr)
+			if !ok || recv.Op != token.ARROW {
+				return "", false
+			}
+			return fmt.Sprintf("for range %s", j.Render(recv.X)), true
+		case *ast.AssignStmt:
+			if len(comm.Lhs) != 1 {
+				// x, ok := <-ch needs to observe closing of the
+				// channel, which a range loop cannot express.
+				return "", false
+			}
+			recv := comm.Rhs[0].(*ast.UnaryExpr)
+			return fmt.Sprintf("for %s %s range %s", j.Render(comm.Lhs[0]), comm.Tok, j.Render(recv.X)), true
+		default:
+			// Don't suggest using range for channel sends
+			return "", false
+		}
 	}
 
 	seen := map[ast.Node]struct{}{}
 	fn := func(node ast.Node) bool {
 		switch v := node.(type) {
 		case *ast.ForStmt:
+			if v.Init != nil || v.Cond != nil || v.Post != nil {
+				return true
+			}
 			if len(v.Body.List) != 1 {
 				return true
 			}
 			if !isSingleSelect(v.Body.List[0]) {
 				return true
 			}
-			if _, ok := v.Body.List[0].(*ast.SelectStmt).Body.List[0].(*ast.CommClause).Comm.(*ast.SendStmt); ok {
-				// Don't suggest using range for channel sends
+			clause := v.Body.List[0].(*ast.SelectStmt).Body.List[0].(*ast.CommClause)
+			if hasUnlabeledBreak(clause.Body) {
+				// The break only terminates the select statement. In
+				// a range loop, it would terminate the loop.
+				return true
+			}
+			header, ok := rangeClause(clause.Comm)
+			if !ok {
 				return true
 			}
-			seen[v.Body.List[0]] = struct{}{}
-			j.Errorf(node, "should use for range instead of for { select {} }")
+			sel := v.Body.List[0].(*ast.SelectStmt)
+			seen[sel] = struct{}{}
+			p := j.Errorf(node, "should use '%s' instead of for { select {} }", header)
+			p.Fix = lint.Replace(v, header+" {"+j.Source(clause.Colon+1, sel.Body.Rbrace)+"}")
 		case *ast.SelectStmt:
 			if _, ok := seen[v]; ok {
 				return true
@@ -240,7 +308,14 @@ func (c *Checker) LintIfBoolCmp(j *lint.Job) {
 		if (l1-len(r))%2 == 1 {
 			r = "!" + r
 		}
-		j.Errorf(expr, "should omit comparison to bool constant, can be simplified to %s", r)
+		p := j.Errorf(expr, "should omit comparison to bool constant, can be simplified to %s", r)
+		fix := r
+		if strings.HasPrefix(r, "!") {
+			if _, ok := other.(*ast.BinaryExpr); ok {
+				fix = "!(" + strings.TrimLeft(r, "!") + ")"
+			}
+		}
+		p.Fix = lint.Replace(expr, fix)
 		return true
 	}
 	for _, f := range c.filterGenerated(j.Program.Files) {
@@ -266,9 +341,11 @@ func (c *Checker) LintBytesBufferConversions(j *lint.Job) {
 
 		typ := j.Program.Info.TypeOf(call.Fun)
 		if typ == types.Universe.Lookup("string").Type() && j.IsCallToAST(call.Args[0], "(*bytes.Buffer).Bytes") {
-			j.Errorf(call, "should use %v.String() instead of %v", j.Render(sel.X), j.Render(call))
+			p := j.Errorf(call, "should use %v.String() instead of %v", j.Render(sel.X), j.Render(call))
+			p.Fix = lint.Replace(call, j.Render(sel.X)+".String()")
 		} else if typ, ok := typ.(*types.Slice); ok && typ.Elem() == types.Universe.Lookup("byte").Type() && j.IsCallToAST(call.Args[0], "(*bytes.Buffer).String") {
-			j.Errorf(call, "should use %v.Bytes() instead of %v", j.Render(sel.X), j.Render(call))
+			p := j.Errorf(call, "should use %v.Bytes() instead of %v", j.Render(sel.X), j.Render(call))
+			p.Fix = lint.Replace(call, j.Render(sel.X)+".Bytes()")
 		}
 
 		return true
@@ -341,7 +418,8 @@ func (c *Checker) LintStringsContains(j *lint.Job) {
 		if !b {
 			prefix = "!"
 		}
-		j.Errorf(node, "should use %s%s.%s(%s) instead", prefix, pkgIdent.Name, newFunc, j.RenderArgs(call.Args))
+		p := j.Errorf(node, "should use %s%s.%s(%s) instead", prefix, pkgIdent.Name, newFunc, j.RenderArgs(call.Args))
+		p.Fix = lint.Replace(node, fmt.Sprintf("%s%s.%s(%s)", prefix, pkgIdent.Name, newFunc, j.RenderArgs(call.Args)))
 
 		return true
 	}
@@ -375,7 +453,8 @@ func (c *Checker) LintBytesCompare(j *lint.Job) {
 		if expr.Op == token.NEQ {
 			prefix = "!"
 		}
-		j.Errorf(node, "should use %sbytes.Equal(%s) instead", prefix, args)
+		p := j.Errorf(node, "should use %sbytes.Equal(%s) instead", prefix, args)
+		p.Fix = lint.Replace(node, fmt.Sprintf("%sbytes.Equal(%s)", prefix, args))
 		return true
 	}
 	for _, f := range c.filterGenerated(j.Program.Files) {
@@ -390,7 +469,8 @@ func (c *Checker) LintRanges(j *lint.Job) {
 			return true
 		}
 		if lint.IsBlank(rs.Key) && (rs.Value == nil || lint.IsBlank(rs.Value)) {
-			j.Errorf(rs.Key, "should omit values from range; this loop is equivalent to `for range ...`")
+			p := j.Errorf(rs.Key, "should omit values from range; this loop is equivalent to `for range ...`")
+			p.Fix = lint.ReplaceRange(rs.Key.Pos(), rs.X.Pos(), "range ")
 		}
 
 		return true
@@ -412,7 +492,8 @@ func (c *Checker) LintForTrue(j *lint.Job) {
 		if !j.IsBoolConst(loop.Cond) || !j.BoolConst(loop.Cond) {
 			return true
 		}
-		j.Errorf(loop, "should use for {} instead of for true {}")
+		p := j.Errorf(loop, "should use for {} instead of for true {}")
+		p.Fix = lint.Replace(loop.Cond, "")
 		return true
 	}
 	for _, f := range c.filterGenerated(j.Program.Files) {
@@ -707,7 +788,8 @@ func (c *Checker) LintSlicing(j *lint.Job) {
 		if !ok || arg.Obj != s.Obj {
 			return true
 		}
-		j.Errorf(n, "should omit second index in slice, s[a:len(s)] is identical to s[a:]")
+		p := j.Errorf(n, "should omit second index in slice, s[a:len(s)] is identical to s[a:]")
+		p.Fix = lint.Replace(n.High, "")
 		return true
 	}
 	for _, f := range c.filterGenerated(j.Program.Files) {
@@ -806,23 +888,83 @@ func (c *Checker) LintLoopAppend(j *lint.Job) {
 	}
 }
 
-func (c *Checker) LintTimeSince(j *lint.Job) {
-	fn := func(node ast.Node) bool {
-		call, ok := node.(*ast.CallExpr)
+// isTimeNowSub reports whether node is of the form time.Now().Sub(t)
+// and returns t.
+func isTimeNowSub(j *lint.Job, node ast.Node) (ast.Expr, bool) {
+	call, ok := node.(*ast.CallExpr)
+	if !ok {
+		return nil, false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil, false
+	}
+	if !j.IsCallToAST(sel.X, "time.Now") {
+		return nil, false
+	}
+	if sel.Sel.Name != "Sub" {
+		return nil, false
+	}
+	return call.Args[0], true
+}
+
+// isSubTimeNow reports whether node is of the form t.Sub(time.Now())
+// and returns t.
+func isSubTimeNow(j *lint.Job, node ast.Node) (ast.Expr, bool) {
+	call, ok := node.(*ast.CallExpr)
+	if !ok {
+		return nil, false
+	}
+	if !j.IsCallToAST(call, "(time.Time).Sub") {
+		return nil, false
+	}
+	if !j.IsCallToAST(call.Args[0], "time.Now") {
+		return nil, false
+	}
+	return call.Fun.(*ast.SelectorExpr).X, true
+}
+
+// isNegation returns the operand of node if node is of the form -x.
+func isNegation(node ast.Node) (ast.Expr, bool) {
+	neg, ok := node.(*ast.UnaryExpr)
+	if !ok || neg.Op != token.SUB {
+		return nil, false
+	}
+	x := neg.X
+	for {
+		paren, ok := x.(*ast.ParenExpr)
 		if !ok {
-			return true
+			return x, true
 		}
-		sel, ok := call.Fun.(*ast.SelectorExpr)
-		if !ok {
+		x = paren.X
+	}
+}
+
+func (c *Checker) LintTimeSince(j *lint.Job) {
+	seen := map[ast.Node]bool{}
+	fn := func(node ast.Node) bool {
+		if seen[node] {
 			return true
 		}
-		if !j.IsCallToAST(sel.X, "time.Now") {
+		if x, ok := isNegation(node); ok {
+			// -t.Sub(time.Now()) is the same as time.Since(t)
+			if t, ok := isSubTimeNow(j, x); ok {
+				seen[x] = true
+				p := j.Errorf(node, "should use time.Since(%s) instead of %s", j.Render(t), j.Render(node))
+				p.Fix = lint.Replace(node, "time.Since("+j.Render(t)+")")
+			}
+			if _, ok := isTimeNowSub(j, x); ok && j.IsGoVersion(x, 8) {
+				// Will be flagged as time.Until by S1024
+				seen[x] = true
+			}
 			return true
 		}
-		if sel.Sel.Name != "Sub" {
+		t, ok := isTimeNowSub(j, node)
+		if !ok {
 			return true
 		}
-		j.Errorf(call, "should use time.Since instead of time.Now().Sub")
+		p := j.Errorf(node, "should use time.Since(%s) instead of %s", j.Render(t), j.Render(node))
+		p.Fix = lint.Replace(node, "time.Since("+j.Render(t)+")")
 		return true
 	}
 	for _, f := range c.filterGenerated(j.Program.Files) {
@@ -831,24 +973,36 @@ func (c *Checker) LintTimeSince(j *lint.Job) {
 }
 
 func (c *Checker) LintTimeUntil(j *lint.Job) {
-	if !j.IsGoVersion(8) {
-		return
-	}
+	seen := map[ast.Node]bool{}
 	fn := func(node ast.Node) bool {
-		call, ok := node.(*ast.CallExpr)
-		if !ok {
+		if seen[node] {
 			return true
 		}
-		if !j.IsCallToAST(call, "(time.Time).Sub") {
+		if x, ok := isNegation(node); ok {
+			// -time.Now().Sub(t) is the same as time.Until(t)
+			if t, ok := isTimeNowSub(j, x); ok {
+				seen[x] = true
+				p := j.Errorf(node, "should use time.Until(%s) instead of %s", j.Render(t), j.Render(node))
+				p.Fix = lint.Replace(node, "time.Until("+j.Render(t)+")")
+			}
+			if _, ok := isSubTimeNow(j, x); ok {
+				// Will be flagged as time.Since by S1012
+				seen[x] = true
+			}
 			return true
 		}
-		if !j.IsCallToAST(call.Args[0], "time.Now") {
+		t, ok := isSubTimeNow(j, node)
+		if !ok {
 			return true
 		}
-		j.Errorf(call, "should use time.Until instead of t.Sub(time.Now())")
+		p := j.Errorf(node, "should use time.Until(%s) instead of %s", j.Render(t), j.Render(node))
+		p.Fix = lint.Replace(node, "time.Until("+j.Render(t)+")")
 		return true
 	}
 	for _, f := range c.filterGenerated(j.Program.Files) {
+		if !j.IsGoVersion(f, 8) {
+			continue
+		}
 		ast.Inspect(f, fn)
 	}
 }
@@ -1504,12 +1658,14 @@ func (c *Checker) LintMakeLenCap(j *lint.Job) {
 				break
 			}
 			if lint.IsZero(call.Args[1]) {
-				j.Errorf(call.Args[1], "should use make(%s) instead", j.Render(call.Args[0]))
+				p := j.Errorf(call.Args[1], "should use make(%s) instead", j.Render(call.Args[0]))
+				p.Fix = lint.ReplaceRange(call.Args[0].End(), call.Args[1].End(), "")
 			}
 		case 3:
 			// make(T, len, cap)
 			if j.Render(call.Args[1]) == j.Render(call.Args[2]) {
-				j.Errorf(call.Args[1], "should use make(%s, %s) instead", j.Render(call.Args[0]), j.Render(call.Args[1]))
+				p := j.Errorf(call.Args[1], "should use make(%s, %s) instead", j.Render(call.Args[0]), j.Render(call.Args[1]))
+				p.Fix = lint.ReplaceRange(call.Args[1].End(), call.Args[2].End(), "")
 			}
 		}
 		return false
@@ -1678,7 +1834,8 @@ func (c *Checker) LintRedundantBreak(j *lint.Job) {
 		if !ok || branch.Tok != token.BREAK || branch.Label != nil {
 			return true
 		}
-		j.Errorf(branch, "redundant break statement")
+		p := j.Errorf(branch, "redundant break statement")
+		p.Fix = deleteStmt(j, branch)
 		return true
 	}
 	for _, f := range c.filterGenerated(j.Program.Files) {
@@ -1686,6 +1843,33 @@ func (c *Checker) LintRedundantBreak(j *lint.Job) {
 	}
 }
 
+// deleteStmt returns a fix that deletes stmt. If stmt is on a line of
+// its own, the whole line is deleted, keeping the comments of
+// neighbouring statements intact.
+func deleteStmt(j *lint.Job, stmt ast.Stmt) *lint.Fix {
+	return &lint.Fix{Edits: []lint.Edit{deleteLines(j, stmt.Pos(), stmt.End())}}
+}
+
+// deleteLines returns an edit that deletes the source code between pos
+// and end, including the lines it is on if there is nothing else on
+// them.
+func deleteLines(j *lint.Job, pos, end token.Pos) lint.Edit {
+	tf := j.Program.SSA.Fset.File(pos)
+	line := tf.Line(pos)
+	start := tf.LineStart(line)
+	if strings.TrimSpace(j.Source(start, pos)) != "" {
+		return lint.Edit{Pos: pos, End: end}
+	}
+	stop := token.Pos(tf.Base() + tf.Size())
+	if line := tf.Line(end); line < tf.LineCount() {
+		stop = tf.LineStart(line + 1)
+	}
+	if strings.TrimSpace(j.Source(end, stop)) != "" {
+		return lint.Edit{Pos: pos, End: end}
+	}
+	return lint.Edit{Pos: start, End: stop}
+}
+
 func (c *Checker) Implements(j *lint.Job, typ types.Type, iface string) bool {
 	// OPT(dh): we can cache the type lookup
 	idx := strings.IndexRune(iface, '.')
@@ -1854,7 +2038,8 @@ func (c *Checker) LintRedundantReturn(j *lint.Job) {
 		}
 		// we don't need to check rst.Results as we already
 		// checked x.Type.Results to be nil.
-		j.Errorf(rst, "redundant return statement")
+		p := j.Errorf(rst, "redundant return statement")
+		p.Fix = deleteStmt(j, rst)
 		return true
 	}
 	for _, f := range c.filterGenerated(j.Program.Files) {
@@ -1871,7 +2056,9 @@ func (c *Checker) LintErrorsNewSprintf(j *lint.Job) {
 		if !j.IsCallToAST(call.Args[0], "fmt.Sprintf") {
 			return true
 		}
-		j.Errorf(node, "should use fmt.Errorf(...) instead of errors.New(fmt.Sprintf(...))")
+		sprintf := call.Args[0].(*ast.CallExpr)
+		p := j.Errorf(node, "should use fmt.Errorf(...) instead of errors.New(fmt.Sprintf(...))")
+		p.Fix = lint.Replace(node, "fmt.Errorf("+j.Source(sprintf.Lparen+1, sprintf.Rparen)+")", "fmt")
 		return true
 	}
 	for _, f := range c.filterGenerated(j.Program.Files) {
@@ -1882,3 +2069,1221 @@ func (c *Checker) LintErrorsNewSprintf(j *lint.Job) {
 func (c *Checker) LintRangeStringRunes(j *lint.Job) {
 	sharedcheck.CheckRangeStringRunes(c.nodeFns, j)
 }
+
+// printfVerbs returns the verbs used in a printf-style format string,
+// in the order they consume arguments. It returns false if the format
+// uses features that make the mapping from verbs to arguments
+// non-trivial, such as explicit argument indexes or '*' widths.
+func printfVerbs(format string) ([]rune, bool) {
+	var verbs []rune
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		// flags, width and precision
+		for i < len(format) && strings.ContainsRune("+-# 0123456789.", rune(format[i])) {
+			i++
+		}
+		if i == len(format) {
+			return nil, false
+		}
+		switch format[i] {
+		case '%':
+			continue
+		case '[', '*':
+			return nil, false
+		}
+		verbs = append(verbs, rune(format[i]))
+	}
+	return verbs, true
+}
+
+// replaceVerb replaces the nth verb in the string literal lit with
+// verb. It only replaces verbs without flags, width or precision.
+func replaceVerb(lit string, n int, verb rune) (string, bool) {
+	for i := 0; i < len(lit); i++ {
+		if lit[i] != '%' {
+			continue
+		}
+		if i+1 < len(lit) && lit[i+1] == '%' {
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(lit) && strings.IndexByte("+-# 0123456789.", lit[j]) != -1 {
+			j++
+		}
+		if j == len(lit) {
+			return "", false
+		}
+		if n > 0 {
+			n--
+			i = j
+			continue
+		}
+		if j != i+1 {
+			return "", false
+		}
+		return lit[:j] + string(verb) + lit[j+1:], true
+	}
+	return "", false
+}
+
+func isErrorType(typ types.Type) bool {
+	iface := types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+	return types.Implements(typ, iface)
+}
+
+func (c *Checker) LintErrorfWrap(j *lint.Job) {
+	fn := func(node ast.Node) bool {
+		if !j.IsCallToAST(node, "fmt.Errorf") {
+			return true
+		}
+		call := node.(*ast.CallExpr)
+		format, ok := j.ExprToString(call.Args[0])
+		if !ok {
+			return true
+		}
+		verbs, ok := printfVerbs(format)
+		if !ok || len(verbs) != len(call.Args)-1 {
+			return true
+		}
+		var wrap ast.Expr
+		var verb rune
+		var idx int
+		// the receiver of the Error call, if the error is formatted by
+		// calling its Error method
+		var errorCall ast.Expr
+		for i, v := range verbs {
+			if v == 'w' {
+				// Already wrapping an error
+				return true
+			}
+			if v != 'v' && v != 's' {
+				continue
+			}
+			arg := call.Args[i+1]
+			isError := false
+			var recv ast.Expr
+			if call, ok := arg.(*ast.CallExpr); ok && len(call.Args) == 0 {
+				if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Error" {
+					isError = isErrorType(j.Program.Info.TypeOf(sel.X))
+					recv = sel.X
+				}
+			}
+			if !isError && !j.IsNil(arg) {
+				isError = isErrorType(j.Program.Info.TypeOf(arg))
+			}
+			if !isError {
+				continue
+			}
+			if wrap != nil {
+				// More than one error, we can't know which one
+				// should be wrapped.
+				return true
+			}
+			wrap = arg
+			verb = v
+			idx = i
+			errorCall = recv
+		}
+		if wrap == nil {
+			return true
+		}
+		var fix *lint.Fix
+		if lit, ok := call.Args[0].(*ast.BasicLit); ok {
+			if s, ok := replaceVerb(lit.Value, idx, 'w'); ok {
+				fix = lint.Replace(lit, s)
+			}
+		}
+		if errorCall != nil {
+			p := j.Errorf(wrap, "should pass %s with %%w instead of calling its Error method, so that errors.Is and errors.As can inspect the wrapped error", j.Render(errorCall))
+			if fix != nil {
+				fix.Edits = append(fix.Edits, lint.Edit{Pos: wrap.Pos(), End: wrap.End(), NewText: j.Render(errorCall)})
+				p.Fix = fix
+			}
+			return true
+		}
+		p := j.Errorf(wrap, "should use %%w instead of %%%c to wrap %s, so that errors.Is and errors.As can inspect the wrapped error", verb, j.Render(wrap))
+		p.Fix = fix
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		if !j.IsGoVersion(f, 13) {
+			// %w was added in Go 1.13
+			continue
+		}
+		ast.Inspect(f, fn)
+	}
+}
+
+func (c *Checker) LintStringConcatInLoop(j *lint.Job) {
+	isString := func(expr ast.Expr) bool {
+		typ := j.Program.Info.TypeOf(expr)
+		if typ == nil {
+			return false
+		}
+		basic, ok := typ.Underlying().(*types.Basic)
+		return ok && basic.Kind() == types.String
+	}
+	// accumulator returns the local variable that assign appends to,
+	// if any.
+	accumulator := func(assign *ast.AssignStmt) *types.Var {
+		if len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+			return nil
+		}
+		ident, ok := assign.Lhs[0].(*ast.Ident)
+		if !ok || !isString(ident) {
+			return nil
+		}
+		obj, ok := j.Program.Info.ObjectOf(ident).(*types.Var)
+		if !ok || obj.Parent() == nil || obj.Parent() == obj.Pkg().Scope() {
+			return nil
+		}
+		switch assign.Tok {
+		case token.ADD_ASSIGN:
+			return obj
+		case token.ASSIGN:
+			// s = s + x
+			if bin, ok := assign.Rhs[0].(*ast.BinaryExpr); ok && bin.Op == token.ADD {
+				if refersTo(j.Program.Info, bin.X, ident) {
+					return obj
+				}
+			}
+			// s = fmt.Sprintf("...", s, ...)
+			if j.IsCallToAST(assign.Rhs[0], "fmt.Sprintf") {
+				call := assign.Rhs[0].(*ast.CallExpr)
+				if len(call.Args) > 1 && refersTo(j.Program.Info, call.Args[1], ident) {
+					return obj
+				}
+			}
+		}
+		return nil
+	}
+
+	seen := map[*ast.AssignStmt]bool{}
+	fixed := map[*types.Var]bool{}
+	fn := func(node ast.Node) bool {
+		var body *ast.BlockStmt
+		switch loop := node.(type) {
+		case *ast.ForStmt:
+			body = loop.Body
+		case *ast.RangeStmt:
+			body = loop.Body
+		default:
+			return true
+		}
+		fn2 := func(node2 ast.Node) bool {
+			if _, ok := node2.(*ast.FuncLit); ok {
+				return false
+			}
+			assign, ok := node2.(*ast.AssignStmt)
+			if !ok || seen[assign] {
+				return true
+			}
+			obj := accumulator(assign)
+			if obj == nil {
+				return true
+			}
+			if obj.Pos() >= node.Pos() && obj.Pos() < node.End() {
+				// The variable is declared inside the loop and
+				// doesn't accumulate across iterations.
+				return true
+			}
+			seen[assign] = true
+			p := j.Errorf(assign, "should use strings.Builder instead of concatenating %s in a loop, each iteration copies the whole string", obj.Name())
+			if !fixed[obj] {
+				fixed[obj] = true
+				p.Fix = builderFix(j, node.(ast.Stmt), obj)
+			}
+			return true
+		}
+		ast.Inspect(body, fn2)
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		if !j.IsGoVersion(f, 10) {
+			// strings.Builder was added in Go 1.10
+			continue
+		}
+		ast.Inspect(f, fn)
+	}
+}
+
+// builderFix returns a fix that turns the string s, which is appended
+// to in loop, into a strings.Builder. It only handles the simple case
+// of a variable that is declared empty right before the loop, only
+// appended to with += in the loop and only read after it.
+func builderFix(j *lint.Job, loop ast.Stmt, s *types.Var) *lint.Fix {
+	if !types.Identical(s.Type(), types.Typ[types.String]) {
+		return nil
+	}
+	// Find the block that contains the loop and the declaration.
+	var block *ast.BlockStmt
+	idx := -1
+	ast.Inspect(j.File(loop), func(node ast.Node) bool {
+		b, ok := node.(*ast.BlockStmt)
+		if !ok || block != nil {
+			return block == nil
+		}
+		for i, stmt := range b.List {
+			if l, ok := stmt.(*ast.LabeledStmt); ok {
+				stmt = l.Stmt
+			}
+			if stmt == loop {
+				block, idx = b, i
+			}
+		}
+		return block == nil
+	})
+	if block == nil {
+		return nil
+	}
+	isEmpty := func(expr ast.Expr) bool {
+		lit, ok := expr.(*ast.BasicLit)
+		return ok && (lit.Value == `""` || lit.Value == "``")
+	}
+	var decl ast.Stmt
+	for _, stmt := range block.List[:idx] {
+		switch stmt := stmt.(type) {
+		case *ast.DeclStmt:
+			gen, ok := stmt.Decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR || len(gen.Specs) != 1 {
+				continue
+			}
+			spec := gen.Specs[0].(*ast.ValueSpec)
+			if len(spec.Names) != 1 || j.Program.Info.Defs[spec.Names[0]] != s {
+				continue
+			}
+			if len(spec.Values) == 0 || (len(spec.Values) == 1 && isEmpty(spec.Values[0])) {
+				decl = stmt
+			}
+		case *ast.AssignStmt:
+			if stmt.Tok != token.DEFINE || len(stmt.Lhs) != 1 || len(stmt.Rhs) != 1 {
+				continue
+			}
+			if ident, ok := stmt.Lhs[0].(*ast.Ident); ok && j.Program.Info.Defs[ident] == s && isEmpty(stmt.Rhs[0]) {
+				decl = stmt
+			}
+		}
+	}
+	if decl == nil {
+		return nil
+	}
+
+	fix := &lint.Fix{Imports: []string{"strings"}}
+	fix.Edits = append(fix.Edits, lint.Edit{Pos: decl.Pos(), End: decl.End(), NewText: "var " + s.Name() + " strings.Builder"})
+	isS := func(expr ast.Expr) bool {
+		ident, ok := expr.(*ast.Ident)
+		return ok && j.Program.Info.Uses[ident] == s
+	}
+	handled := map[*ast.Ident]bool{}
+	ok := true
+	ast.Inspect(block, func(node ast.Node) bool {
+		if !ok {
+			return false
+		}
+		switch node := node.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range node.Lhs {
+				if !isS(lhs) {
+					continue
+				}
+				if node.Tok != token.ADD_ASSIGN || node.Pos() < loop.Pos() || node.End() > loop.End() {
+					ok = false
+					return false
+				}
+				handled[lhs.(*ast.Ident)] = true
+				fix.Edits = append(fix.Edits, lint.Edit{
+					Pos:     node.Pos(),
+					End:     node.End(),
+					NewText: s.Name() + ".WriteString(" + j.Source(node.Rhs[0].Pos(), node.Rhs[0].End()) + ")",
+				})
+			}
+		case *ast.UnaryExpr:
+			if node.Op == token.AND && isS(node.X) {
+				ok = false
+			}
+		case *ast.Ident:
+			if !isS(node) || handled[node] {
+				return true
+			}
+			if node.Pos() < loop.End() {
+				// Read before or inside the loop
+				ok = false
+				return false
+			}
+			fix.Edits = append(fix.Edits, lint.Edit{Pos: node.Pos(), End: node.End(), NewText: s.Name() + ".String()"})
+		}
+		return true
+	})
+	if !ok {
+		return nil
+	}
+	return fix
+}
+
+func (c *Checker) LintSortSlice(j *lint.Job) {
+	// Find unexported slice types whose only methods are those of
+	// sort.Interface.
+	candidates := map[*types.TypeName]bool{}
+	for _, pkg := range j.Program.Packages {
+		scope := pkg.Pkg.Scope()
+		for _, name := range scope.Names() {
+			tname, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || tname.Exported() {
+				continue
+			}
+			named, ok := tname.Type().(*types.Named)
+			if !ok {
+				continue
+			}
+			if _, ok := named.Underlying().(*types.Slice); !ok {
+				continue
+			}
+			if named.NumMethods() != 3 {
+				continue
+			}
+			methods := map[string]bool{}
+			for i := 0; i < named.NumMethods(); i++ {
+				methods[named.Method(i).Name()] = true
+			}
+			if methods["Len"] && methods["Less"] && methods["Swap"] {
+				candidates[tname] = true
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return
+	}
+
+	typeName := func(expr ast.Expr) *types.TypeName {
+		for {
+			switch e := expr.(type) {
+			case *ast.ParenExpr:
+				expr = e.X
+			case *ast.StarExpr:
+				expr = e.X
+			case *ast.Ident:
+				tname, _ := j.Program.Info.ObjectOf(e).(*types.TypeName)
+				if !candidates[tname] {
+					return nil
+				}
+				return tname
+			default:
+				return nil
+			}
+		}
+	}
+
+	// Account for all uses of the candidate types. A type is
+	// throwaway if it is only referred to by its own methods and a
+	// single conversion passed to sort.Sort or sort.Stable.
+	uses := map[*types.TypeName]int{}
+	for _, obj := range j.Program.Info.Uses {
+		if tname, ok := obj.(*types.TypeName); ok && candidates[tname] {
+			uses[tname]++
+		}
+	}
+	accounted := map[*types.TypeName]int{}
+	sorts := map[*types.TypeName][]*ast.CallExpr{}
+	methods := map[*types.TypeName][]*ast.FuncDecl{}
+	decls := map[*types.TypeName]*ast.GenDecl{}
+	fn := func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.GenDecl:
+			if node.Tok != token.TYPE || len(node.Specs) != 1 {
+				return true
+			}
+			spec := node.Specs[0].(*ast.TypeSpec)
+			if tname, ok := j.Program.Info.Defs[spec.Name].(*types.TypeName); ok && candidates[tname] {
+				decls[tname] = node
+			}
+		case *ast.FuncDecl:
+			if node.Recv == nil || len(node.Recv.List) != 1 {
+				return true
+			}
+			if tname := typeName(node.Recv.List[0].Type); tname != nil {
+				accounted[tname]++
+				methods[tname] = append(methods[tname], node)
+			}
+		case *ast.CallExpr:
+			if !j.IsCallToAnyAST(node, "sort.Sort", "sort.Stable") {
+				return true
+			}
+			conv, ok := node.Args[0].(*ast.CallExpr)
+			if !ok || len(conv.Args) != 1 {
+				return true
+			}
+			if tname := typeName(conv.Fun); tname != nil {
+				accounted[tname]++
+				sorts[tname] = append(sorts[tname], node)
+			}
+		}
+		return true
+	}
+	for _, f := range j.Program.Files {
+		ast.Inspect(f, fn)
+	}
+
+	for tname, calls := range sorts {
+		if len(calls) != 1 || uses[tname] != accounted[tname] {
+			continue
+		}
+		call := calls[0]
+		if !j.IsGoVersion(call, 8) {
+			// sort.Slice was added in Go 1.8
+			continue
+		}
+		if lint.IsGenerated(j.File(call)) && !c.CheckGenerated {
+			continue
+		}
+		alt := "sort.Slice"
+		if j.IsCallToAST(call, "sort.Stable") {
+			alt = "sort.SliceStable"
+		}
+		p := j.Errorf(call, "should use %s instead of declaring %s only to implement sort.Interface", alt, tname.Name())
+		p.Fix = sortSliceFix(j, call, alt, decls[tname], methods[tname])
+	}
+}
+
+// sortSliceFix returns a fix that replaces call, a call to sort.Sort or
+// sort.Stable with a conversion to a type that only implements
+// sort.Interface, with a call to alt, and deletes the type and its
+// methods. Len and Swap must have their canonical implementations and
+// Less must consist of a single return statement.
+func sortSliceFix(j *lint.Job, call *ast.CallExpr, alt string, decl *ast.GenDecl, methods []*ast.FuncDecl) *lint.Fix {
+	if decl == nil || j.File(decl) != j.File(call) {
+		return nil
+	}
+	x, ok := call.Args[0].(*ast.CallExpr).Args[0].(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	fix := &lint.Fix{}
+	fix.Edits = append(fix.Edits, deleteLines(j, docStart(decl.Doc, decl), decl.End()))
+	var less string
+	for _, m := range methods {
+		if j.File(m) != j.File(call) || len(m.Recv.List[0].Names) != 1 || len(m.Body.List) != 1 {
+			return nil
+		}
+		recv := m.Recv.List[0].Names[0]
+		var params []*ast.Ident
+		for _, field := range m.Type.Params.List {
+			params = append(params, field.Names...)
+		}
+		var want string
+		switch m.Name.Name {
+		case "Len":
+			want = fmt.Sprintf("return len(%s)", recv.Name)
+		case "Swap":
+			if len(params) != 2 {
+				return nil
+			}
+			a, b := params[0].Name, params[1].Name
+			want = fmt.Sprintf("%[1]s[%[2]s], %[1]s[%[3]s] = %[1]s[%[3]s], %[1]s[%[2]s]", recv.Name, a, b)
+		case "Less":
+			ret, ok := m.Body.List[0].(*ast.ReturnStmt)
+			if !ok || len(ret.Results) != 1 || len(params) != 2 {
+				return nil
+			}
+			if params[0].Name == x.Name || params[1].Name == x.Name || lint.IsBlank(params[0]) || lint.IsBlank(params[1]) {
+				return nil
+			}
+			if less = sortSliceLess(j, call, m, x); less == "" {
+				return nil
+			}
+			less = fmt.Sprintf("%s(%s, func(%s, %s int) bool { return %s })", alt, x.Name, params[0].Name, params[1].Name, less)
+		}
+		if want != "" && j.Render(m.Body.List[0]) != want {
+			return nil
+		}
+		fix.Edits = append(fix.Edits, deleteLines(j, docStart(m.Doc, m), m.End()))
+	}
+	fix.Edits = append(fix.Edits, lint.Edit{Pos: call.Pos(), End: call.End(), NewText: less})
+	return fix
+}
+
+// sortSliceLess returns the source of the result of less, a Less
+// method, with its receiver replaced by x, for use in a function
+// literal at call. It returns the empty string if the result refers to
+// objects that aren't visible at call.
+func sortSliceLess(j *lint.Job, call *ast.CallExpr, less *ast.FuncDecl, x *ast.Ident) string {
+	info := j.Program.Info
+	scope := j.NodePackage(call).Pkg.Scope().Innermost(call.Pos())
+	if scope == nil {
+		return ""
+	}
+	recv := info.Defs[less.Recv.List[0].Names[0]]
+	expr := less.Body.List[0].(*ast.ReturnStmt).Results[0]
+	var edits []lint.Edit
+	ok := true
+	ast.Inspect(expr, func(node ast.Node) bool {
+		ident, isIdent := node.(*ast.Ident)
+		if !isIdent {
+			return true
+		}
+		obj := info.Uses[ident]
+		switch {
+		case obj == nil || obj.Parent() == nil:
+			// Fields and methods
+		case obj == recv:
+			edits = append(edits, lint.Edit{Pos: ident.Pos(), End: ident.End(), NewText: x.Name})
+		case obj.Pos() >= less.Pos() && obj.Pos() < less.End():
+			// The parameters of Less, which the function literal
+			// declares, too
+		default:
+			if _, found := scope.LookupParent(ident.Name, call.Pos()); found != obj {
+				ok = false
+			}
+		}
+		return true
+	})
+	if !ok {
+		return ""
+	}
+	src := j.Source(expr.Pos(), expr.End())
+	base := int(expr.Pos())
+	for i := len(edits) - 1; i >= 0; i-- {
+		e := edits[i]
+		src = src[:int(e.Pos)-base] + e.NewText + src[int(e.End)-base:]
+	}
+	return src
+}
+
+// docStart returns the position of doc, or that of node if it has
+// no doc comment.
+func docStart(doc *ast.CommentGroup, node ast.Node) token.Pos {
+	if doc != nil {
+		return doc.Pos()
+	}
+	return node.Pos()
+}
+
+func (c *Checker) LintInterfaceAny(j *lint.Job) {
+	universeAny := types.Universe.Lookup("any")
+	if universeAny == nil {
+		return
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		if !j.IsGoVersion(f, 18) {
+			// any was added in Go 1.18
+			continue
+		}
+		pkg := j.NodePackage(f)
+		var first ast.Node
+		n := 0
+		fix := &lint.Fix{}
+		fn := func(node ast.Node) bool {
+			iface, ok := node.(*ast.InterfaceType)
+			if !ok {
+				return true
+			}
+			if len(iface.Methods.List) != 0 || iface.Methods.Closing < iface.Methods.Opening {
+				return true
+			}
+			if hasComments(f, iface) {
+				return true
+			}
+			scope := pkg.Pkg.Scope().Innermost(iface.Pos())
+			if scope == nil {
+				return true
+			}
+			if _, obj := scope.LookupParent("any", iface.Pos()); obj != universeAny {
+				// any has been shadowed
+				return true
+			}
+			if first == nil {
+				first = iface
+			}
+			n++
+			fix.Edits = append(fix.Edits, lint.Edit{Pos: iface.Pos(), End: iface.End(), NewText: "any"})
+			return true
+		}
+		ast.Inspect(f, fn)
+		switch n {
+		case 0:
+		case 1:
+			p := j.Errorf(first, "should use any instead of interface{}")
+			p.Fix = fix
+		default:
+			p := j.Errorf(first, "should use any instead of interface{} (%d occurrences in this file)", n)
+			p.Fix = fix
+		}
+	}
+}
+
+// hasComments reports whether there are any comments inside node.
+func hasComments(f *ast.File, node ast.Node) bool {
+	for _, cg := range f.Comments {
+		if cg.Pos() >= node.Pos() && cg.End() <= node.End() {
+			return true
+		}
+	}
+	return false
+}
+
+// stmtLists calls fn for every list of statements in node.
+func stmtLists(node ast.Node, fn func([]ast.Stmt)) {
+	ast.Inspect(node, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.BlockStmt:
+			fn(node.List)
+		case *ast.CaseClause:
+			fn(node.Body)
+		case *ast.CommClause:
+			fn(node.Body)
+		}
+		return true
+	})
+}
+
+func isBuiltinCall(j *lint.Job, expr ast.Expr, name string, nargs int) (*ast.CallExpr, bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != nargs {
+		return nil, false
+	}
+	ident, ok := call.Fun.(*ast.Ident)
+	if !ok || ident.Name != name {
+		return nil, false
+	}
+	_, ok = j.Program.Info.ObjectOf(ident).(*types.Builtin)
+	return call, ok
+}
+
+// singleReturn returns the only result of stmt if it is a return
+// statement with exactly one result.
+func singleReturn(stmt ast.Stmt) (ast.Expr, bool) {
+	ret, ok := stmt.(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return nil, false
+	}
+	return ret.Results[0], true
+}
+
+func (c *Checker) isBoolLit(j *lint.Job, expr ast.Expr, b bool) bool {
+	return j.IsBoolConst(expr) && j.BoolConst(expr) == b
+}
+
+// nonNil reports whether expr, a slice or map, is provably not nil,
+// i.e. whether it is a composite literal, a call to make or a slice of
+// an array.
+func nonNil(j *lint.Job, expr ast.Expr) bool {
+	switch expr := expr.(type) {
+	case *ast.ParenExpr:
+		return nonNil(j, expr.X)
+	case *ast.CompositeLit:
+		return true
+	case *ast.CallExpr:
+		_, ok := isBuiltinCall(j, expr, "make", len(expr.Args))
+		return ok
+	case *ast.SliceExpr:
+		typ := j.Program.Info.TypeOf(expr.X).Underlying()
+		if ptr, ok := typ.(*types.Pointer); ok {
+			typ = ptr.Elem().Underlying()
+		}
+		_, ok := typ.(*types.Array)
+		return ok
+	default:
+		return false
+	}
+}
+
+// simpleIf returns the condition and the only statement of the body
+// of stmt if it is an if statement without init statement, else
+// branch, and with a single statement in its body.
+func simpleIf(stmt ast.Stmt) (ast.Expr, ast.Stmt, bool) {
+	ifstmt, ok := stmt.(*ast.IfStmt)
+	if !ok || ifstmt.Init != nil || ifstmt.Else != nil || len(ifstmt.Body.List) != 1 {
+		return nil, nil, false
+	}
+	return ifstmt.Cond, ifstmt.Body.List[0], true
+}
+
+func (c *Checker) LintSlicesMaps(j *lint.Job) {
+	info := j.Program.Info
+	sameObj := func(expr ast.Expr, ident *ast.Ident) bool {
+		ident2, ok := expr.(*ast.Ident)
+		return ok && ident != nil && !lint.IsBlank(ident) && info.ObjectOf(ident2) == info.ObjectOf(ident)
+	}
+	sliceElem := func(expr ast.Expr) (types.Type, bool) {
+		s, ok := info.TypeOf(expr).Underlying().(*types.Slice)
+		if !ok {
+			return nil, false
+		}
+		return s.Elem(), true
+	}
+	isMap := func(expr ast.Expr) bool {
+		_, ok := info.TypeOf(expr).Underlying().(*types.Map)
+		return ok
+	}
+	rangeVars := func(loop *ast.RangeStmt) (key, value *ast.Ident) {
+		key, _ = loop.Key.(*ast.Ident)
+		value, _ = loop.Value.(*ast.Ident)
+		if key != nil && lint.IsBlank(key) {
+			key = nil
+		}
+		return key, value
+	}
+
+	// for _, v := range s { if v == x { return true } }; return false
+	// for i, v := range s { if v == x { return i } }; return -1
+	containsOrIndex := func(loop *ast.RangeStmt, next ast.Stmt) {
+		key, value := rangeVars(loop)
+		if value == nil || loop.Tok != token.DEFINE || len(loop.Body.List) != 1 {
+			return
+		}
+		elem, ok := sliceElem(loop.X)
+		if !ok {
+			return
+		}
+		cond, body, ok := simpleIf(loop.Body.List[0])
+		if !ok {
+			return
+		}
+		bin, ok := cond.(*ast.BinaryExpr)
+		if !ok || bin.Op != token.EQL {
+			return
+		}
+		var x ast.Expr
+		switch {
+		case sameObj(bin.X, value):
+			x = bin.Y
+		case sameObj(bin.Y, value):
+			x = bin.X
+		default:
+			return
+		}
+		if refersTo(info, x, value) || (key != nil && refersTo(info, x, key)) {
+			return
+		}
+		if !types.AssignableTo(info.TypeOf(x), elem) {
+			return
+		}
+		res, ok := singleReturn(body)
+		if !ok {
+			return
+		}
+		after, ok := singleReturn(next)
+		if !ok {
+			return
+		}
+		switch {
+		case key == nil && c.isBoolLit(j, res, true) && c.isBoolLit(j, after, false):
+			p := j.Errorf(loop, "should use 'return slices.Contains(%s, %s)' instead of a loop", j.Render(loop.X), j.Render(x))
+			p.Fix = lint.ReplaceRange(loop.Pos(), next.End(),
+				fmt.Sprintf("return slices.Contains(%s, %s)", j.Render(loop.X), j.Render(x)), "slices")
+		case key != nil && sameObj(res, key):
+			if n, ok := j.ExprToInt(after); ok && n == -1 {
+				p := j.Errorf(loop, "should use 'return slices.Index(%s, %s)' instead of a loop", j.Render(loop.X), j.Render(x))
+				p.Fix = lint.ReplaceRange(loop.Pos(), next.End(),
+					fmt.Sprintf("return slices.Index(%s, %s)", j.Render(loop.X), j.Render(x)), "slices")
+			}
+		}
+	}
+
+	// if len(a) != len(b) { return false }
+	// for i := range a { if a[i] != b[i] { return false } }
+	// return true
+	equal := func(stmts []ast.Stmt) {
+		cond, body, ok := simpleIf(stmts[0])
+		if !ok {
+			return
+		}
+		bin, ok := cond.(*ast.BinaryExpr)
+		if !ok || bin.Op != token.NEQ {
+			return
+		}
+		lenA, ok1 := isBuiltinCall(j, bin.X, "len", 1)
+		lenB, ok2 := isBuiltinCall(j, bin.Y, "len", 1)
+		if !ok1 || !ok2 {
+			return
+		}
+		a, b := lenA.Args[0], lenB.Args[0]
+		if res, ok := singleReturn(body); !ok || !c.isBoolLit(j, res, false) {
+			return
+		}
+		if _, ok := sliceElem(a); !ok {
+			return
+		}
+		if !types.Identical(info.TypeOf(a), info.TypeOf(b)) {
+			return
+		}
+		loop, ok := stmts[1].(*ast.RangeStmt)
+		if !ok || j.Render(loop.X) != j.Render(a) || loop.Value != nil || len(loop.Body.List) != 1 {
+			return
+		}
+		key, _ := rangeVars(loop)
+		if key == nil {
+			return
+		}
+		cond, body, ok = simpleIf(loop.Body.List[0])
+		if !ok {
+			return
+		}
+		bin, ok = cond.(*ast.BinaryExpr)
+		if !ok || bin.Op != token.NEQ {
+			return
+		}
+		idxA, ok1 := bin.X.(*ast.IndexExpr)
+		idxB, ok2 := bin.Y.(*ast.IndexExpr)
+		if !ok1 || !ok2 || !sameObj(idxA.Index, key) || !sameObj(idxB.Index, key) {
+			return
+		}
+		if j.Render(idxA.X) != j.Render(a) || j.Render(idxB.X) != j.Render(b) {
+			return
+		}
+		if res, ok := singleReturn(body); !ok || !c.isBoolLit(j, res, false) {
+			return
+		}
+		if res, ok := singleReturn(stmts[2]); !ok || !c.isBoolLit(j, res, true) {
+			return
+		}
+		p := j.Errorf(stmts[0], "should use 'return slices.Equal(%s, %s)' instead of comparing the slices manually", j.Render(a), j.Render(b))
+		p.Fix = lint.ReplaceRange(stmts[0].Pos(), stmts[2].End(),
+			fmt.Sprintf("return slices.Equal(%s, %s)", j.Render(a), j.Render(b)), "slices")
+	}
+
+	// b := make([]T, len(a)); copy(b, a)
+	// m2 := make(map[K]V, len(m)); for k, v := range m { m2[k] = v }
+	clone := func(assign *ast.AssignStmt, next ast.Stmt) {
+		if len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+			return
+		}
+		if assign.Tok != token.DEFINE && assign.Tok != token.ASSIGN {
+			return
+		}
+		dst, ok := assign.Lhs[0].(*ast.Ident)
+		if !ok || lint.IsBlank(dst) {
+			return
+		}
+		mk, ok := assign.Rhs[0].(*ast.CallExpr)
+		if !ok {
+			return
+		}
+		if _, ok := isBuiltinCall(j, mk, "make", len(mk.Args)); !ok {
+			return
+		}
+		switch next := next.(type) {
+		case *ast.ExprStmt:
+			if len(mk.Args) != 2 {
+				return
+			}
+			ln, ok := isBuiltinCall(j, mk.Args[1], "len", 1)
+			if !ok {
+				return
+			}
+			cp, ok := isBuiltinCall(j, next.X, "copy", 2)
+			if !ok || !sameObj(cp.Args[0], dst) || j.Render(cp.Args[1]) != j.Render(ln.Args[0]) {
+				return
+			}
+			if !types.Identical(info.TypeOf(dst), info.TypeOf(cp.Args[1])) {
+				return
+			}
+			p := j.Errorf(assign, "should use '%s %s slices.Clone(%s)' instead of make and copy",
+				dst.Name, assign.Tok, j.Render(cp.Args[1]))
+			if !nonNil(j, cp.Args[1]) {
+				// slices.Clone returns nil for a nil slice, make
+				// never does.
+				return
+			}
+			p.Fix = lint.ReplaceRange(assign.Pos(), next.End(),
+				fmt.Sprintf("%s %s slices.Clone(%s)", dst.Name, assign.Tok, j.Render(cp.Args[1])), "slices")
+		case *ast.RangeStmt:
+			if !isMap(next.X) || len(next.Body.List) != 1 {
+				return
+			}
+			if len(mk.Args) == 2 {
+				ln, ok := isBuiltinCall(j, mk.Args[1], "len", 1)
+				if !ok || j.Render(ln.Args[0]) != j.Render(next.X) {
+					return
+				}
+			}
+			if !types.Identical(info.TypeOf(dst), info.TypeOf(next.X)) {
+				return
+			}
+			key, value := rangeVars(next)
+			if key == nil || value == nil {
+				return
+			}
+			set, ok := next.Body.List[0].(*ast.AssignStmt)
+			if !ok || set.Tok != token.ASSIGN || len(set.Lhs) != 1 || len(set.Rhs) != 1 {
+				return
+			}
+			idx, ok := set.Lhs[0].(*ast.IndexExpr)
+			if !ok || !sameObj(idx.X, dst) || !sameObj(idx.Index, key) || !sameObj(set.Rhs[0], value) {
+				return
+			}
+			p := j.Errorf(assign, "should use '%s %s maps.Clone(%s)' instead of copying the map in a loop",
+				dst.Name, assign.Tok, j.Render(next.X))
+			if !nonNil(j, next.X) {
+				// maps.Clone returns nil for a nil map, make never
+				// does.
+				return
+			}
+			p.Fix = lint.ReplaceRange(assign.Pos(), next.End(),
+				fmt.Sprintf("%s %s maps.Clone(%s)", dst.Name, assign.Tok, j.Render(next.X)), "maps")
+		}
+	}
+
+	// for k := range m { keys = append(keys, k) }
+	keys := func(loop *ast.RangeStmt) {
+		if !j.IsGoVersion(loop, 23) {
+			// maps.Keys and slices.AppendSeq were added in Go 1.23
+			return
+		}
+		key, value := rangeVars(loop)
+		if key == nil || value != nil || !isMap(loop.X) || len(loop.Body.List) != 1 {
+			return
+		}
+		assign, ok := loop.Body.List[0].(*ast.AssignStmt)
+		if !ok || assign.Tok != token.ASSIGN || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+			return
+		}
+		dst, ok := assign.Lhs[0].(*ast.Ident)
+		if !ok {
+			return
+		}
+		app, ok := isBuiltinCall(j, assign.Rhs[0], "append", 2)
+		if !ok || !sameObj(app.Args[0], dst) || !sameObj(app.Args[1], key) || app.Ellipsis.IsValid() {
+			return
+		}
+		p := j.Errorf(loop, "should use '%s = slices.AppendSeq(%s, maps.Keys(%s))' instead of a loop",
+			dst.Name, dst.Name, j.Render(loop.X))
+		p.Fix = lint.Replace(loop,
+			fmt.Sprintf("%s = slices.AppendSeq(%s, maps.Keys(%s))", dst.Name, dst.Name, j.Render(loop.X)), "maps", "slices")
+	}
+
+	// sort.Slice(s, func(i, j int) bool { return s[i] < s[j] })
+	sortSlice := func(node ast.Node) bool {
+		if !j.IsCallToAnyAST(node, "sort.Slice", "sort.SliceStable") {
+			return true
+		}
+		call := node.(*ast.CallExpr)
+		lit, ok := call.Args[1].(*ast.FuncLit)
+		if !ok || len(lit.Body.List) != 1 {
+			return true
+		}
+		params := lit.Type.Params.List
+		if len(params) != 1 || len(params[0].Names) != 2 {
+			return true
+		}
+		res, ok := singleReturn(lit.Body.List[0])
+		if !ok {
+			return true
+		}
+		bin, ok := res.(*ast.BinaryExpr)
+		if !ok || bin.Op != token.LSS {
+			return true
+		}
+		idxA, ok1 := bin.X.(*ast.IndexExpr)
+		idxB, ok2 := bin.Y.(*ast.IndexExpr)
+		if !ok1 || !ok2 {
+			return true
+		}
+		if !sameObj(idxA.Index, params[0].Names[0]) || !sameObj(idxB.Index, params[0].Names[1]) {
+			return true
+		}
+		s := j.Render(call.Args[0])
+		if j.Render(idxA.X) != s || j.Render(idxB.X) != s {
+			return true
+		}
+		elem, ok := sliceElem(call.Args[0])
+		if !ok {
+			return true
+		}
+		if basic, ok := elem.Underlying().(*types.Basic); !ok || basic.Info()&types.IsOrdered == 0 {
+			return true
+		}
+		p := j.Errorf(call, "should use slices.Sort(%s) instead of %s", s, j.Render(call.Fun))
+		p.Fix = lint.Replace(call, fmt.Sprintf("slices.Sort(%s)", s), "slices")
+		return true
+	}
+
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		if !j.IsGoVersion(f, 21) {
+			// The slices and maps packages were added in Go 1.21
+			continue
+		}
+		stmtLists(f, func(stmts []ast.Stmt) {
+			for i, stmt := range stmts {
+				switch stmt := stmt.(type) {
+				case *ast.RangeStmt:
+					if i+1 < len(stmts) {
+						containsOrIndex(stmt, stmts[i+1])
+					}
+					keys(stmt)
+				case *ast.IfStmt:
+					if i+2 < len(stmts) {
+						equal(stmts[i : i+3])
+					}
+				case *ast.AssignStmt:
+					if i+1 < len(stmts) {
+						clone(stmt, stmts[i+1])
+					}
+				}
+			}
+		})
+		ast.Inspect(f, sortSlice)
+	}
+}
+
+// isSimpleOperand reports whether expr is an identifier or a chain of
+// field selections, which can be evaluated repeatedly without side
+// effects.
+func isSimpleOperand(expr ast.Expr) bool {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return true
+	case *ast.SelectorExpr:
+		return isSimpleOperand(expr.X)
+	case *ast.ParenExpr:
+		return isSimpleOperand(expr.X)
+	default:
+		return false
+	}
+}
+
+func (c *Checker) LintIfElseChainToSwitch(j *lint.Job) {
+	// values returns the values that cond compares x against, if cond
+	// is of the form x == a || x == b || ...
+	var values func(cond ast.Expr, x string) ([]ast.Expr, bool)
+	values = func(cond ast.Expr, x string) ([]ast.Expr, bool) {
+		bin, ok := cond.(*ast.BinaryExpr)
+		if !ok {
+			return nil, false
+		}
+		switch bin.Op {
+		case token.LOR:
+			l, ok1 := values(bin.X, x)
+			r, ok2 := values(bin.Y, x)
+			return append(l, r...), ok1 && ok2
+		case token.EQL:
+			if j.Render(bin.X) == x {
+				return []ast.Expr{bin.Y}, true
+			}
+			if j.Render(bin.Y) == x {
+				return []ast.Expr{bin.X}, true
+			}
+		}
+		return nil, false
+	}
+	// operand returns the expression that cond compares against
+	// values, if any.
+	var operand func(cond ast.Expr) (ast.Expr, bool)
+	operand = func(cond ast.Expr) (ast.Expr, bool) {
+		bin, ok := cond.(*ast.BinaryExpr)
+		if !ok {
+			return nil, false
+		}
+		switch bin.Op {
+		case token.LOR:
+			return operand(bin.X)
+		case token.EQL:
+			if isSimpleOperand(bin.X) {
+				return bin.X, true
+			}
+			if isSimpleOperand(bin.Y) {
+				return bin.Y, true
+			}
+		}
+		return nil, false
+	}
+
+	seen := map[*ast.IfStmt]bool{}
+	fn := func(node ast.Node) bool {
+		ifstmt, ok := node.(*ast.IfStmt)
+		if !ok || seen[ifstmt] {
+			return true
+		}
+		x, ok := operand(ifstmt.Cond)
+		if !ok {
+			return true
+		}
+		if basic, ok := j.Program.Info.TypeOf(x).Underlying().(*types.Basic); ok && basic.Info()&types.IsBoolean != 0 {
+			return true
+		}
+		xs := j.Render(x)
+		n := 0
+		// A break in one of the bodies would terminate the switch
+		// statement instead of an enclosing loop. Comments outside
+		// the bodies would get lost.
+		canFix := !hasComments(j.File(ifstmt), ifstmt)
+		// Duplicate constant cases don't compile.
+		consts := map[string]bool{}
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "switch %s {\n", xs)
+		for stmt := ifstmt; stmt != nil; {
+			seen[stmt] = true
+			if stmt.Init != nil {
+				return true
+			}
+			vals, ok := values(stmt.Cond, xs)
+			if !ok {
+				return true
+			}
+			n++
+			for _, val := range vals {
+				if tv := j.Program.Info.Types[val]; tv.Value != nil {
+					canFix = canFix && !consts[tv.Value.ExactString()]
+					consts[tv.Value.ExactString()] = true
+				}
+			}
+			fmt.Fprintf(&buf, "case %s:%s\n", j.RenderArgs(vals), strings.TrimRight(j.Source(stmt.Body.Lbrace+1, stmt.Body.Rbrace), " \t\n"))
+			canFix = canFix && !hasUnlabeledBreak(stmt.Body.List)
+			switch els := stmt.Else.(type) {
+			case *ast.IfStmt:
+				stmt = els
+				continue
+			case *ast.BlockStmt:
+				fmt.Fprintf(&buf, "default:%s\n", strings.TrimRight(j.Source(els.Lbrace+1, els.Rbrace), " \t\n"))
+				canFix = canFix && !hasUnlabeledBreak(els.List)
+			}
+			break
+		}
+		if n < 3 {
+			return true
+		}
+		buf.WriteString("}")
+		p := j.Errorf(ifstmt, "could use a switch statement on %s instead of a chain of %d if/else-if comparisons", xs, n)
+		if canFix {
+			p.Fix = lint.Replace(ifstmt, buf.String())
+		}
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
+
+func (c *Checker) LintErrorfNoVerbs(j *lint.Job) {
+	fn := func(node ast.Node) bool {
+		if !j.IsCallToAST(node, "fmt.Errorf") {
+			return true
+		}
+		call := node.(*ast.CallExpr)
+		if len(call.Args) != 1 || call.Ellipsis.IsValid() {
+			return true
+		}
+		format, ok := j.ExprToString(call.Args[0])
+		if !ok {
+			return true
+		}
+		verbs, ok := printfVerbs(format)
+		if !ok || len(verbs) != 0 {
+			return true
+		}
+		arg := j.Render(call.Args[0])
+		if strings.Contains(format, "%") {
+			// The format string contains escaped percent signs
+			arg = strconv.Quote(strings.Replace(format, "%%", "%", -1))
+		}
+		p := j.Errorf(call, "should use errors.New(%s) instead of fmt.Errorf, the message has no formatting verbs", arg)
+		p.Fix = lint.Replace(call, "errors.New("+arg+")", "errors")
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
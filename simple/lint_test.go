@@ -9,3 +9,7 @@ import (
 func TestAll(t *testing.T) {
 	testutil.TestAll(t, NewChecker(), "")
 }
+
+func TestGoVersion(t *testing.T) {
+	testutil.TestAll(t, NewChecker(), "goversion")
+}
@@ -3,8 +3,11 @@ package pkg
 import "time"
 
 func fn(t time.Time) {
-	t.Sub(time.Now()) // MATCH "time.Until"
+	t.Sub(time.Now()) // MATCH "should use time.Until(t) instead of t.Sub(time.Now())"
 	t.Sub(t)
 	t2 := time.Now()
 	t.Sub(t2)
+	_ = -time.Now().Sub(t)              // MATCH "should use time.Until(t) instead of -time.Now().Sub(t)"
+	_ = t.Sub(time.Now()).Seconds() > 1 // MATCH "should use time.Until(t)"
+	_ = -t.Sub(time.Now())              // MATCH "should use time.Since(t)"
 }
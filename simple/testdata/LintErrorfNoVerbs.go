@@ -0,0 +1,15 @@
+package pkg
+
+import "fmt"
+
+const msg = "constant"
+
+func fn() {
+	_ = fmt.Errorf("foo")        // MATCH "should use errors.New("foo") instead of fmt.Errorf"
+	_ = fmt.Errorf("100%% done") // MATCH "should use errors.New("100% done")"
+	_ = fmt.Errorf(msg)          // MATCH "should use errors.New(msg)"
+	_ = fmt.Errorf("foo %d", 1)
+	_ = fmt.Errorf("foo %s", "bar")
+	var s string
+	_ = fmt.Errorf(s)
+}
@@ -0,0 +1,8 @@
+package pkg
+
+import "fmt"
+
+func fn() {
+	var err error
+	_ = fmt.Errorf("foo: %v", err)
+}
@@ -0,0 +1,24 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+)
+
+type myError struct{}
+
+func (myError) Error() string { return "" }
+
+func fn() {
+	var err error
+	var merr myError
+	_ = fmt.Errorf("foo: %v", err)         // MATCH "should use %w instead of %v to wrap err"
+	_ = fmt.Errorf("foo: %s", err.Error()) // MATCH "should pass err with %w instead of calling its Error method"
+	_ = fmt.Errorf("foo %d: %s", 1, merr)  // MATCH "should use %w instead of %s to wrap merr"
+	_ = fmt.Errorf("foo: %w", err)
+	_ = fmt.Errorf("foo: %v %v", err, err)
+	_ = fmt.Errorf("foo: %q", err)
+	_ = fmt.Errorf("foo: %[1]v", err)
+	_ = fmt.Errorf("100%%: %d", 1)
+	_ = errors.New("foo")
+}
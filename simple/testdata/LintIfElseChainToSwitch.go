@@ -0,0 +1,78 @@
+package pkg
+
+type T struct{ kind int }
+
+type B bool
+
+const (
+	yes B = true
+	no  B = false
+)
+
+func fn(x int, t T, s string, b B) {
+	if x == 1 { // MATCH "could use a switch statement on x instead of a chain of 3 if/else-if comparisons"
+		println(1)
+	} else if x == 2 || x == 3 {
+		println(2)
+	} else if 4 == x {
+		println(4)
+	} else {
+		println("other")
+	}
+
+	if t.kind == 1 { // MATCH "switch statement on t.kind"
+	} else if t.kind == 2 {
+	} else if t.kind == 3 {
+	} else if t.kind == 4 {
+	}
+
+	if x == 1 {
+	} else if x == 2 {
+	}
+
+	if x == 1 {
+	} else if x == 2 {
+	} else if x > 3 {
+	}
+
+	if x == 1 {
+	} else if y := x; y == 2 {
+	} else if x == 3 {
+	}
+
+	if s == "a" {
+	} else if s == "b" {
+	} else if x == 3 {
+	}
+
+	if x == 1 { // MATCH "switch statement on x"
+	} else if x == 2 {
+	} else if x == 1 {
+	}
+
+	if s == "a" { // MATCH "switch statement on s"
+		println(1)
+	} else if s == "b" {
+		println(2)
+	} else /* neither */ if s == "c" {
+		println(3)
+	}
+
+	if b == yes {
+	} else if b == no {
+	} else if b == yes {
+	}
+}
+
+// MATCH:69 "could use a switch statement on t.kind instead of a chain of 3 if/else-if comparisons"
+func fix(t T) {
+	if t.kind == 1 {
+		println(1)
+	} else if t.kind == 2 || t.kind == 3 {
+		println(2)
+	} else if t.kind == 4 {
+		println(4)
+	} else {
+		println("other")
+	}
+}
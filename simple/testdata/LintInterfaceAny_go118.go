@@ -0,0 +1,22 @@
+package pkg
+
+type T struct {
+	x interface{} // MATCH "should use any instead of interface{} (3 occurrences in this file)"
+}
+
+func fn(x interface{}) interface{} {
+	return x
+}
+
+func fn2[T interface{ ~int }](x T) {}
+
+func fn3() {
+	type any int
+	var _ interface{}
+	var _ any
+}
+
+func fn4(x interface {
+	// a comment
+}) {
+}
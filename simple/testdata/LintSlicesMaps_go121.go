@@ -0,0 +1,76 @@
+package pkg
+
+import "sort"
+
+func contains(s []string, x string) bool {
+	for _, v := range s { // MATCH "should use 'return slices.Contains(s, x)' instead of a loop"
+		if v == x {
+			return true
+		}
+	}
+	return false
+}
+
+func index(s []int, x int) int {
+	for i, v := range s { // MATCH "should use 'return slices.Index(s, x)' instead of a loop"
+		if x == v {
+			return i
+		}
+	}
+	return -1
+}
+
+func notContains(s []string, x string) bool {
+	for _, v := range s {
+		if v == x {
+			return false
+		}
+	}
+	return true
+}
+
+func equal(a, b []int) bool {
+	if len(a) != len(b) { // MATCH "should use 'return slices.Equal(a, b)'"
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func clone(a []int) []int {
+	b := make([]int, len(a)) // MATCH "should use 'b := slices.Clone(a)' instead of make and copy"
+	copy(b, a)
+	return b
+}
+
+func cloneMap(m map[string]int) map[string]int {
+	m2 := make(map[string]int, len(m)) // MATCH "should use 'm2 := maps.Clone(m)' instead of copying the map in a loop"
+	for k, v := range m {
+		m2[k] = v
+	}
+	return m2
+}
+
+func cloneNonNil() []int {
+	b := make([]int, len([]int{1, 2})) // MATCH "should use 'b := slices.Clone([]int{1, 2})' instead of make and copy"
+	copy(b, []int{1, 2})
+	return b
+}
+
+func sorting(s []int, t []string) {
+	sort.Slice(s, func(i, j int) bool { return s[i] < s[j] }) // MATCH "should use slices.Sort(s) instead of sort.Slice"
+	sort.Slice(s, func(i, j int) bool { return s[i] > s[j] })
+	sort.SliceStable(t, func(a, b int) bool { return t[a] < t[b] }) // MATCH "should use slices.Sort(t) instead of sort.SliceStable"
+}
+
+func keys(m map[string]int) []string {
+	var out []string
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
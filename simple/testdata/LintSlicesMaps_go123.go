@@ -0,0 +1,9 @@
+package pkg
+
+func keys(m map[string]int) []string {
+	var out []string
+	for k := range m { // MATCH "should use 'out = slices.AppendSeq(out, maps.Keys(m))' instead of a loop"
+		out = append(out, k)
+	}
+	return out
+}
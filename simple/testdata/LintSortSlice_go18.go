@@ -0,0 +1,66 @@
+package pkg
+
+import "sort"
+
+type byLen []string
+
+func (s byLen) Len() int           { return len(s) }
+func (s byLen) Less(i, j int) bool { return len(s[i]) < len(s[j]) }
+func (s byLen) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+type byName []string
+
+func (s byName) Len() int           { return len(s) }
+func (s byName) Less(i, j int) bool { return s[i] < s[j] }
+func (s byName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+type stable []int
+
+func (s stable) Len() int           { return len(s) }
+func (s stable) Less(i, j int) bool { return s[i] < s[j] }
+func (s stable) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+type ByAge []int
+
+func (s ByAge) Len() int           { return len(s) }
+func (s ByAge) Less(i, j int) bool { return s[i] < s[j] }
+func (s ByAge) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// parallel also reorders names when sorting.
+type parallel []int
+
+var names []string
+
+func (s parallel) Len() int           { return len(s) }
+func (s parallel) Less(i, j int) bool { return s[i] < s[j] }
+func (s parallel) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+	names[i], names[j] = names[j], names[i]
+}
+
+type byWeight []int
+
+var weights map[int]int
+
+func (s byWeight) Len() int           { return len(s) }
+func (s byWeight) Less(i, j int) bool { return weights[s[i]] < weights[s[j]] }
+func (s byWeight) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+func fn(xs []string, ys []int) {
+	sort.Sort(byLen(xs)) // MATCH "should use sort.Slice instead of declaring byLen only to implement sort.Interface"
+
+	sort.Sort(byName(xs))
+	sort.Sort(byName(xs))
+
+	sort.Stable(stable(ys)) // MATCH "should use sort.SliceStable instead of declaring stable"
+
+	sort.Sort(ByAge(ys))
+
+	sort.Sort(parallel(ys)) // MATCH "should use sort.Slice instead of declaring parallel"
+}
+
+func shadowed(ys []int) {
+	weights := 0
+	_ = weights
+	sort.Sort(byWeight(ys)) // MATCH "should use sort.Slice instead of declaring byWeight"
+}
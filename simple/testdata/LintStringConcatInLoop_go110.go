@@ -0,0 +1,62 @@
+package pkg
+
+import "fmt"
+
+var global string
+
+func fn(xs []string) string {
+	var s string
+	for _, x := range xs {
+		s += x // MATCH "should use strings.Builder instead of concatenating s in a loop"
+	}
+	var s2 string
+	for i := 0; i < 10; i++ {
+		s2 = s2 + "," + xs[i] // MATCH "should use strings.Builder"
+	}
+	var s3 string
+	for _, x := range xs {
+		s3 = fmt.Sprintf("%s %s", s3, x) // MATCH "should use strings.Builder"
+	}
+	for _, x := range xs {
+		var s4 string
+		s4 += x
+		_ = s4
+	}
+	for _, x := range xs {
+		global += x
+	}
+	var n int
+	for range xs {
+		n += 1
+	}
+	for _, x := range xs {
+		for range xs {
+			s += x // MATCH "should use strings.Builder"
+		}
+	}
+	s += "foo"
+	return s + s2 + s3
+}
+
+func fix(xs []string) string {
+	s := ""
+	for _, x := range xs {
+		s += x // MATCH "should use strings.Builder instead of concatenating s in a loop"
+		if x != "" {
+			s += "," // MATCH "should use strings.Builder"
+		}
+	}
+	if len(s) > 10 {
+		return s[:10]
+	}
+	return s
+}
+
+func readInLoop(xs []string) string {
+	var s string
+	for _, x := range xs {
+		s += x // MATCH "should use strings.Builder"
+		println(s)
+	}
+	return s
+}
@@ -0,0 +1,16 @@
+package pkg
+
+import "time"
+
+// The module declares Go 1.17, so only suggestions that work with Go
+// 1.17 are made, even though Go 1.21 is targeted.
+
+func fn(s []string, x string, v interface{}, t time.Time) bool {
+	_ = -time.Now().Sub(t) // MATCH "should use time.Until"
+	for _, e := range s {
+		if e == x {
+			return true
+		}
+	}
+	return false
+}
@@ -6,14 +6,14 @@ func fn() {
 	case <-ch:
 	}
 outer:
-	for { // MATCH /should use for range/
+	for { // MATCH /should use 'for range ch'/
 		select {
 		case <-ch:
 			break outer
 		}
 	}
 
-	for { // MATCH /should use for range/
+	for { // MATCH /should use 'for x := range ch'/
 		select {
 		case x := <-ch:
 			_ = x
@@ -25,4 +25,43 @@ outer:
 		case ch <- 0:
 		}
 	}
+
+	var x int
+	for { // MATCH /should use 'for x = range ch'/
+		select {
+		case x = <-ch:
+			println(x)
+		}
+	}
+
+	for {
+		select { // MATCH /should use a simple channel send/
+		case x, ok := <-ch:
+			if !ok {
+				return
+			}
+			println(x)
+		}
+	}
+
+	for {
+		select { // MATCH /should use a simple channel send/
+		case <-ch:
+			if x > 0 {
+				break
+			}
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		select { // MATCH /should use a simple channel send/
+		case <-ch:
+		}
+	}
+
+	for {
+		select {
+		default:
+		}
+	}
 }
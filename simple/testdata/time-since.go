@@ -4,6 +4,10 @@ import "time"
 
 func fn() {
 	t1 := time.Now()
-	_ = time.Now().Sub(t1) // MATCH "time.Since"
+	_ = time.Now().Sub(t1) // MATCH "should use time.Since(t1) instead of time.Now().Sub(t1)"
 	_ = time.Date(0, 0, 0, 0, 0, 0, 0, nil).Sub(t1)
+	_ = time.Now().Sub(t1).Seconds() > 1 // MATCH "should use time.Since(t1)"
+	_ = -t1.Sub(time.Now())              // MATCH "should use time.Since(t1) instead of -t1.Sub(time.Now())"
+	_ = -(t1.Sub(time.Now()))            // MATCH "should use time.Since(t1)"
+	_ = -time.Now().Sub(t1)              // MATCH "should use time.Since(t1) instead of time.Now().Sub(t1)"
 }
@@ -10,6 +10,7 @@ import (
 	"go/types"
 	htmltemplate "html/template"
 	"net/http"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -20,8 +21,10 @@ import (
 	"honnef.co/go/tools/gcsizes"
 	"honnef.co/go/tools/internal/sharedcheck"
 	"honnef.co/go/tools/lint"
+	"honnef.co/go/tools/nilness"
 	"honnef.co/go/tools/ssa"
 	"honnef.co/go/tools/staticcheck/vrp"
+	"honnef.co/go/tools/taint"
 
 	"golang.org/x/tools/go/ast/astutil"
 )
@@ -56,8 +59,61 @@ func uniqueCutset(call *Call) {
 
 func unmarshalPointer(name string, arg int) CallCheck {
 	return func(call *Call) {
-		if !Pointer(call.Args[arg].Value) {
+		if arg < 0 {
+			arg += len(call.Args)
+		}
+		if arg < 0 || arg >= len(call.Args) {
+			return
+		}
+		v := call.Args[arg].Value
+		if k, ok := v.Value.(*ssa.Const); ok && k.Value == nil {
+			call.Args[arg].Invalid(fmt.Sprintf("%s expects to unmarshal into a pointer, but the provided value is nil", name))
+			return
+		}
+		if !Pointer(v) {
 			call.Args[arg].Invalid(fmt.Sprintf("%s expects to unmarshal into a pointer, but the provided value is not a pointer", name))
+			return
+		}
+		if param := InterfaceParamAddr(v); param != nil {
+			call.Args[arg].Invalid(fmt.Sprintf("%s is passed a pointer to the interface parameter %s and will replace %s instead of unmarshaling into the value it holds; pass %s instead",
+				name, param.Name(), param.Name(), param.Name()))
+		}
+	}
+}
+
+// unmarshalPointerRules returns the rules of SA1014 for functions
+// named as in (*encoding/json.Decoder).Decode:0, with the index of
+// the argument to unmarshal into. Without an index, it's the last
+// argument.
+func unmarshalPointerRules(funcs []string) map[string]CallCheck {
+	rules := map[string]CallCheck{}
+	for _, fn := range funcs {
+		arg := -1
+		if i := strings.LastIndex(fn, ":"); i >= 0 {
+			if n, err := strconv.Atoi(fn[i+1:]); err == nil {
+				fn, arg = fn[:i], n
+			}
+		}
+		// Name functions like json.Unmarshal and methods like Decode.
+		name := fn
+		if i := strings.LastIndex(name, ")."); i >= 0 {
+			name = name[i+2:]
+		} else if i := strings.LastIndex(name, "/"); i >= 0 {
+			name = name[i+1:]
+		}
+		rules[fn] = unmarshalPointer(name, arg)
+	}
+	return rules
+}
+
+func unsupportedJSONMarshal(name string, arg int) CallCheck {
+	return func(call *Call) {
+		T := call.Args[arg].Value.Value.Type()
+		if types.IsInterface(T) {
+			return
+		}
+		if reason := UnsupportedJSONType(T); reason != "" {
+			call.Args[arg].Invalid(fmt.Sprintf("%s cannot marshal values of type %s: %s", name, T, reason))
 		}
 	}
 }
@@ -95,7 +151,7 @@ var (
 	checkEncodingBinaryRules = map[string]CallCheck{
 		"encoding/binary.Write": func(call *Call) {
 			arg := call.Args[2]
-			if !CanBinaryMarshal(call.Job, arg.Value) {
+			if !CanBinaryMarshal(call.Job, call.Instr, arg.Value) {
 				arg.Invalid(fmt.Sprintf("value of type %s cannot be used with binary.Write", arg.Value.Value.Type()))
 			}
 		},
@@ -149,11 +205,10 @@ var (
 		"strings.TrimRight": uniqueCutset,
 	}
 
-	checkUnmarshalPointerRules = map[string]CallCheck{
-		"encoding/xml.Unmarshal":          unmarshalPointer("xml.Unmarshal", 1),
-		"(*encoding/xml.Decoder).Decode":  unmarshalPointer("Decode", 0),
-		"encoding/json.Unmarshal":         unmarshalPointer("json.Unmarshal", 1),
-		"(*encoding/json.Decoder).Decode": unmarshalPointer("Decode", 0),
+	checkUnsupportedMarshalRules = map[string]CallCheck{
+		"encoding/json.Marshal":           unsupportedJSONMarshal("json.Marshal", 0),
+		"encoding/json.MarshalIndent":     unsupportedJSONMarshal("json.MarshalIndent", 0),
+		"(*encoding/json.Encoder).Encode": unsupportedJSONMarshal("Encode", 0),
 	}
 
 	checkUnbufferedSignalChanRules = map[string]CallCheck{
@@ -225,7 +280,7 @@ func (c *Checker) Funcs() map[string]lint.Func {
 		"SA1011": c.callChecker(checkUTF8CutsetRules),
 		"SA1012": c.CheckNilContext,
 		"SA1013": c.CheckSeeker,
-		"SA1014": c.callChecker(checkUnmarshalPointerRules),
+		"SA1014": c.CheckUnmarshalPointer,
 		"SA1015": c.CheckLeakyTimeTick,
 		"SA1016": c.CheckUntrappableSignal,
 		"SA1017": c.callChecker(checkUnbufferedSignalChanRules),
@@ -236,14 +291,26 @@ func (c *Checker) Funcs() map[string]lint.Func {
 		"SA1022": c.CheckFlagUsage,
 		"SA1023": c.CheckWriterBufferModified,
 		"SA1024": c.callChecker(checkUniqueCutsetRules),
+		"SA1025": c.CheckSQLInjection,
+		"SA1026": c.CheckCommandInjection,
+		"SA1027": c.CheckNewerStdlibAPI,
+		"SA1028": c.callChecker(checkUnsupportedMarshalRules),
+		"SA1029": c.CheckPathFilepathConfusion,
 
 		"SA2000": c.CheckWaitgroupAdd,
 		"SA2001": c.CheckEmptyCriticalSection,
 		"SA2002": c.CheckConcurrentTesting,
 		"SA2003": c.CheckDeferLock,
+		"SA2004": c.CheckMixedAtomicAccess,
 
 		"SA3000": c.CheckTestMainExit,
 		"SA3001": c.CheckBenchmarkN,
+		"SA3002": c.CheckBenchmarkIgnoresN,
+		"SA3003": c.CheckBenchmarkTimer,
+		"SA3004": c.CheckBenchmarkNProblemSize,
+		"SA3005": c.CheckTestHelper,
+		"SA3006": c.CheckParallelAfterRun,
+		"SA3007": c.CheckTestMainRun,
 
 		"SA4000": c.CheckLhsRhsIdentical,
 		"SA4001": c.CheckIneffectiveCopy,
@@ -264,6 +331,8 @@ func (c *Checker) Funcs() map[string]lint.Func {
 		"SA4015": c.callChecker(checkMathIntRules),
 		"SA4016": c.CheckSillyBitwiseOps,
 		"SA4017": c.CheckPureFunctions,
+		"SA4018": c.CheckTimeEquality,
+		"SA4019": c.CheckFloatEquality,
 
 		"SA5000": c.CheckNilMaps,
 		"SA5001": c.CheckEarlyDefer,
@@ -273,6 +342,9 @@ func (c *Checker) Funcs() map[string]lint.Func {
 		"SA5005": c.CheckCyclicFinalizer,
 		// "SA5006": c.CheckSliceOutOfBounds,
 		"SA5007": c.CheckInfiniteRecursion,
+		"SA5008": c.CheckOverflowingConversion,
+		"SA5009": c.CheckCopyAfterUse,
+		"SA5010": c.CheckLostCancel,
 
 		"SA6000": c.callChecker(checkRegexpMatchLoopRules),
 		"SA6001": c.CheckMapBytesKey,
@@ -283,6 +355,71 @@ func (c *Checker) Funcs() map[string]lint.Func {
 		"SA9001": c.CheckDubiousDeferInChannelRangeLoop,
 		"SA9002": c.CheckNonOctalFileMode,
 		"SA9003": c.CheckEmptyBranch,
+		"SA9004": c.CheckMapIterationOrder,
+	}
+}
+
+// taintSources are the default sources of untrusted data of the taint
+// checks.
+var taintSources = []string{
+	"(*net/http.Request).FormValue",
+	"(*net/http.Request).PostFormValue",
+	"(*net/http.Request).Cookie",
+	"(*net/http.Request).Referer",
+	"(*net/http.Request).UserAgent",
+	"(*net/url.URL).Query",
+	"(net/http.Header).Get",
+}
+
+// RunsRules reports that staticcheck runs the user-defined rules of
+// configuration files.
+func (c *Checker) RunsRules() bool { return true }
+
+func taintOptions(sinks []string) []lint.Option {
+	return []lint.Option{
+		{Name: "sources", Default: taintSources, Doc: "Functions whose results are untrusted, named as in (*net/http.Request).FormValue"},
+		{Name: "sinks", Default: sinks, Doc: "Functions that must not be passed untrusted data, optionally followed by the index of the only argument to check, as in (*database/sql.DB).Query:0"},
+		{Name: "sanitizers", Default: []string{}, Doc: "Functions whose results are safe to pass to sinks"},
+	}
+}
+
+func (c *Checker) Options() map[string][]lint.Option {
+	return map[string][]lint.Option{
+		"SA1025": taintOptions([]string{
+			"(*database/sql.DB).Exec:0",
+			"(*database/sql.DB).ExecContext:1",
+			"(*database/sql.DB).Prepare:0",
+			"(*database/sql.DB).PrepareContext:1",
+			"(*database/sql.DB).Query:0",
+			"(*database/sql.DB).QueryContext:1",
+			"(*database/sql.DB).QueryRow:0",
+			"(*database/sql.DB).QueryRowContext:1",
+			"(*database/sql.Tx).Exec:0",
+			"(*database/sql.Tx).ExecContext:1",
+			"(*database/sql.Tx).Prepare:0",
+			"(*database/sql.Tx).PrepareContext:1",
+			"(*database/sql.Tx).Query:0",
+			"(*database/sql.Tx).QueryContext:1",
+			"(*database/sql.Tx).QueryRow:0",
+			"(*database/sql.Tx).QueryRowContext:1",
+		}),
+		"SA1026": taintOptions([]string{
+			"os/exec.Command:0",
+			"os/exec.CommandContext:1",
+			"os.StartProcess:0",
+			"syscall.Exec:0",
+		}),
+		"SA1014": {
+			{Name: "functions", Default: []string{
+				"encoding/xml.Unmarshal:1",
+				"(*encoding/xml.Decoder).Decode:0",
+				"encoding/json.Unmarshal:1",
+				"(*encoding/json.Decoder).Decode:0",
+			}, Doc: "Functions that unmarshal into a pointer, optionally followed by the index of the pointer argument, as in (*encoding/json.Decoder).Decode:0"},
+		},
+		"SA4019": {
+			{Name: "tests", Default: true, Doc: "Report comparisons in test files"},
+		},
 	}
 }
 
@@ -302,7 +439,6 @@ func (c *Checker) filterGenerated(files []*ast.File) []*ast.File {
 func (c *Checker) Init(prog *lint.Program) {
 	c.funcDescs = functions.NewDescriptions(prog.SSA)
 	c.deprecatedObjs = map[types.Object]string{}
-	c.nodeFns = map[ast.Node]*ssa.Function{}
 
 	for _, fn := range prog.AllFunctions {
 		if fn.Blocks != nil {
@@ -311,7 +447,7 @@ func (c *Checker) Init(prog *lint.Program) {
 		}
 	}
 
-	c.nodeFns = lint.NodeFns(prog.Packages)
+	c.nodeFns = prog.NodeFns
 
 	deprecated := []map[types.Object]string{}
 	wg := &sync.WaitGroup{}
@@ -755,47 +891,57 @@ func (c *Checker) CheckDubiousDeferInChannelRangeLoop(j *lint.Job) {
 	}
 }
 
-func (c *Checker) CheckTestMainExit(j *lint.Job) {
+// testMainCalls reports whether decl, a TestMain function, calls m.Run
+// and os.Exit, and whether m escapes: passed to a function, stored or
+// assigned, any of which may lead to m.Run being called elsewhere.
+func testMainCalls(j *lint.Job, decl *ast.FuncDecl) (callsRun, escapes, callsExit bool) {
+	arg := j.Program.Info.ObjectOf(decl.Type.Params.List[0].Names[0])
+	receivers := map[*ast.Ident]bool{}
 	fn := func(node ast.Node) bool {
-		if !isTestMain(j, node) {
-			return true
-		}
-
-		arg := j.Program.Info.ObjectOf(node.(*ast.FuncDecl).Type.Params.List[0].Names[0])
-		callsRun := false
-		fn2 := func(node ast.Node) bool {
-			call, ok := node.(*ast.CallExpr)
-			if !ok {
-				return true
-			}
-			sel, ok := call.Fun.(*ast.SelectorExpr)
-			if !ok {
-				return true
-			}
-			ident, ok := sel.X.(*ast.Ident)
-			if !ok {
-				return true
-			}
-			if arg != j.Program.Info.ObjectOf(ident) {
-				return true
+		switch node := node.(type) {
+		case *ast.SelectorExpr:
+			if ident, ok := node.X.(*ast.Ident); ok {
+				receivers[ident] = true
 			}
-			if sel.Sel.Name == "Run" {
-				callsRun = true
-				return false
+		case *ast.Ident:
+			if !receivers[node] && j.Program.Info.ObjectOf(node) == arg {
+				escapes = true
 			}
+		}
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if j.IsCallToAST(call, "os.Exit") {
+			callsExit = true
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		ident, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		if arg != j.Program.Info.ObjectOf(ident) {
 			return true
 		}
-		ast.Inspect(node.(*ast.FuncDecl).Body, fn2)
+		if sel.Sel.Name == "Run" {
+			callsRun = true
+		}
+		return true
+	}
+	ast.Inspect(decl.Body, fn)
+	return callsRun, escapes, callsExit
+}
 
-		callsExit := false
-		fn3 := func(node ast.Node) bool {
-			if j.IsCallToAST(node, "os.Exit") {
-				callsExit = true
-				return false
-			}
+func (c *Checker) CheckTestMainExit(j *lint.Job) {
+	fn := func(node ast.Node) bool {
+		if !isTestMain(j, node) {
 			return true
 		}
-		ast.Inspect(node.(*ast.FuncDecl).Body, fn3)
+		callsRun, _, callsExit := testMainCalls(j, node.(*ast.FuncDecl))
 		if !callsExit && callsRun {
 			j.Errorf(node, "TestMain should call os.Exit to set exit code")
 		}
@@ -806,6 +952,58 @@ func (c *Checker) CheckTestMainExit(j *lint.Job) {
 	}
 }
 
+func (c *Checker) CheckTestMainRun(j *lint.Job) {
+	fn := func(node ast.Node) bool {
+		if !isTestMain(j, node) {
+			return true
+		}
+		callsRun, escapes, callsExit := testMainCalls(j, node.(*ast.FuncDecl))
+		if escapes {
+			// Whatever m is passed to may call m.Run.
+			return true
+		}
+		if callsExit && !callsRun {
+			j.Errorf(node, "TestMain calls os.Exit but never calls m.Run, no tests will be run")
+		}
+		if callsRun {
+			if ins := testMainSkipsRun(c.nodeFns[node]); ins != nil {
+				j.Errorf(ins, "TestMain doesn't call m.Run on all paths, no tests will be run if it gets here")
+			}
+		}
+		return true
+	}
+	for _, f := range j.Program.Files {
+		ast.Inspect(f, fn)
+	}
+}
+
+// testMainSkipsRun returns a call of os.Exit or a return of fn, a
+// TestMain function, that can be reached without calling m.Run.
+func testMainSkipsRun(fn *ssa.Function) ssa.Instruction {
+	if fn == nil || len(fn.Blocks) == 0 {
+		return nil
+	}
+	runs := map[*ssa.BasicBlock]bool{}
+	for _, b := range fn.Blocks {
+		for _, ins := range b.Instrs {
+			if call, ok := ins.(*ssa.Call); ok && lint.IsCallTo(call.Common(), "(*testing.M).Run") {
+				runs[b] = true
+			}
+		}
+	}
+	return reachableWithout(fn.Blocks[0], runs, func(b *ssa.BasicBlock) ssa.Instruction {
+		for _, ins := range b.Instrs {
+			if call, ok := ins.(*ssa.Call); ok && lint.IsCallTo(call.Common(), "os.Exit") {
+				return call
+			}
+		}
+		if ret, ok := b.Instrs[len(b.Instrs)-1].(*ssa.Return); ok && ret.Pos().IsValid() {
+			return ret
+		}
+		return nil
+	})
+}
+
 func isTestMain(j *lint.Job, node ast.Node) bool {
 	decl, ok := node.(*ast.FuncDecl)
 	if !ok {
@@ -825,6 +1023,202 @@ func isTestMain(j *lint.Job, node ast.Node) bool {
 	return typ != nil && typ.String() == "*testing.M"
 }
 
+// testingParam returns the object of the first parameter of fn that
+// is a *testing.T, *testing.B or testing.TB.
+func testingParam(j *lint.Job, fn *ast.FuncType) types.Object {
+	for _, field := range fn.Params.List {
+		switch types.TypeString(j.Program.Info.TypeOf(field.Type), nil) {
+		case "*testing.T", "*testing.B", "testing.TB":
+		default:
+			continue
+		}
+		for _, name := range field.Names {
+			if !lint.IsBlank(name) {
+				return j.Program.Info.ObjectOf(name)
+			}
+		}
+	}
+	return nil
+}
+
+// isMethodCallOn returns the name of the method if node is a method
+// call on an identifier referring to obj.
+func isMethodCallOn(j *lint.Job, node ast.Node, obj types.Object) (string, bool) {
+	call, ok := node.(*ast.CallExpr)
+	if !ok {
+		return "", false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok || j.Program.Info.ObjectOf(ident) != obj {
+		return "", false
+	}
+	return sel.Sel.Name, true
+}
+
+// isTestFunction reports whether decl is a test, benchmark or fuzz
+// function in a test file.
+func isTestFunction(j *lint.Job, decl *ast.FuncDecl) bool {
+	if !j.IsInTest(decl) || decl.Recv != nil {
+		return false
+	}
+	for _, prefix := range []string{"Test", "Benchmark", "Fuzz"} {
+		if strings.HasPrefix(decl.Name.Name, prefix) && len(decl.Type.Params.List) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// testHelpers returns the functions that are called with the
+// *testing.T, *testing.B or testing.TB of the function calling them,
+// and never used as values. Functions that are passed around, such
+// as subtests passed to t.Run, run as tests of their own and must not
+// be marked as helpers.
+func testHelpers(j *lint.Job) map[*types.Func]bool {
+	calledWithT := map[*types.Func]bool{}
+	callees := map[*ast.Ident]bool{}
+	fn := func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		id, ok := call.Fun.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		callee, ok := j.Program.Info.ObjectOf(id).(*types.Func)
+		if !ok {
+			return true
+		}
+		callees[id] = true
+		for _, arg := range call.Args {
+			arg, ok := arg.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			switch types.TypeString(j.Program.Info.TypeOf(arg), nil) {
+			case "*testing.T", "*testing.B", "testing.TB":
+				calledWithT[callee] = true
+			}
+		}
+		return true
+	}
+	for _, f := range j.Program.Files {
+		ast.Inspect(f, fn)
+	}
+	for id, obj := range j.Program.Info.Uses {
+		if fn, ok := obj.(*types.Func); ok && !callees[id] {
+			delete(calledWithT, fn)
+		}
+	}
+	return calledWithT
+}
+
+func (c *Checker) CheckTestHelper(j *lint.Job) {
+	helpers := testHelpers(j)
+	fn := func(node ast.Node) bool {
+		decl, ok := node.(*ast.FuncDecl)
+		if !ok || decl.Body == nil || decl.Recv != nil {
+			return true
+		}
+		if !j.IsGoVersion(decl, 9) {
+			// t.Helper was added in Go 1.9
+			return true
+		}
+		if isTestFunction(j, decl) || isTestMain(j, decl) {
+			return true
+		}
+		if obj, ok := j.Program.Info.ObjectOf(decl.Name).(*types.Func); !ok || !helpers[obj] {
+			return true
+		}
+		t := testingParam(j, decl.Type)
+		if t == nil {
+			return true
+		}
+		var failure ast.Node
+		var failureName string
+		callsHelper := false
+		fn2 := func(node ast.Node) bool {
+			if _, ok := node.(*ast.FuncLit); ok {
+				return false
+			}
+			name, ok := isMethodCallOn(j, node, t)
+			if !ok {
+				return true
+			}
+			switch name {
+			case "Helper":
+				callsHelper = true
+			case "Error", "Errorf", "Fatal", "Fatalf", "Fail", "FailNow":
+				if failure == nil {
+					failure = node
+					failureName = name
+				}
+			}
+			return true
+		}
+		ast.Inspect(decl.Body, fn2)
+		if failure != nil && !callsHelper {
+			j.Errorf(decl.Name, "%s calls %s.%s but not %s.Helper, failures will be reported at the wrong line",
+				decl.Name.Name, t.Name(), failureName, t.Name())
+		}
+		return true
+	}
+	for _, f := range j.Program.Files {
+		ast.Inspect(f, fn)
+	}
+}
+
+func (c *Checker) CheckParallelAfterRun(j *lint.Job) {
+	check := func(typ *ast.FuncType, body *ast.BlockStmt) {
+		t := testingParam(j, typ)
+		if t == nil {
+			return
+		}
+		var run ast.Node
+		fn := func(node ast.Node) bool {
+			if _, ok := node.(*ast.FuncLit); ok {
+				return false
+			}
+			name, ok := isMethodCallOn(j, node, t)
+			if !ok {
+				return true
+			}
+			switch name {
+			case "Run":
+				if run == nil {
+					run = node
+				}
+			case "Parallel":
+				if run != nil {
+					j.Errorf(node, "%s.Parallel is called after subtests have been started with %s.Run, it should be called first",
+						t.Name(), t.Name())
+				}
+			}
+			return true
+		}
+		ast.Inspect(body, fn)
+	}
+	fn := func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FuncDecl:
+			if node.Body != nil {
+				check(node.Type, node.Body)
+			}
+		case *ast.FuncLit:
+			check(node.Type, node.Body)
+		}
+		return true
+	}
+	for _, f := range j.Program.Files {
+		ast.Inspect(f, fn)
+	}
+}
+
 func (c *Checker) CheckExec(j *lint.Job) {
 	fn := func(node ast.Node) bool {
 		call, ok := node.(*ast.CallExpr)
@@ -1262,44 +1656,318 @@ func (c *Checker) CheckBenchmarkN(j *lint.Job) {
 	}
 }
 
-func (c *Checker) CheckIneffectiveFieldAssignments(j *lint.Job) {
-	for _, ssafn := range j.Program.InitialFunctions {
-		// fset := j.Program.SSA.Fset
-		// if fset.File(f.File.Pos()) != fset.File(ssafn.Pos()) {
-		// 	continue
-		// }
-		if ssafn.Signature.Recv() == nil {
-			continue
-		}
+func isBenchmark(j *lint.Job, node ast.Node) (*ast.FuncDecl, types.Object, bool) {
+	decl, ok := node.(*ast.FuncDecl)
+	if !ok || decl.Body == nil || decl.Recv != nil {
+		return nil, nil, false
+	}
+	if !strings.HasPrefix(decl.Name.Name, "Benchmark") || !j.IsInTest(decl) {
+		return nil, nil, false
+	}
+	if len(decl.Type.Params.List) != 1 {
+		return nil, nil, false
+	}
+	arg := decl.Type.Params.List[0]
+	if len(arg.Names) != 1 || lint.IsBlank(arg.Names[0]) {
+		return nil, nil, false
+	}
+	if !hasType(j, arg.Type, "*testing.B") {
+		return nil, nil, false
+	}
+	return decl, j.Program.Info.ObjectOf(arg.Names[0]), true
+}
 
-		if len(ssafn.Blocks) == 0 {
-			// External function
-			continue
-		}
+// isBenchmarkSelector reports whether expr is of the form b.name,
+// where b refers to obj.
+func isBenchmarkSelector(j *lint.Job, expr ast.Expr, obj types.Object, name string) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	return ok && j.Program.Info.ObjectOf(ident) == obj
+}
 
-		reads := map[*ssa.BasicBlock]map[ssa.Value]bool{}
-		writes := map[*ssa.BasicBlock]map[ssa.Value]bool{}
+func isBenchmarkCall(j *lint.Job, node ast.Node, obj types.Object, name string) bool {
+	call, ok := node.(*ast.CallExpr)
+	return ok && isBenchmarkSelector(j, call.Fun, obj, name)
+}
 
-		recv := ssafn.Params[0]
-		if _, ok := recv.Type().Underlying().(*types.Struct); !ok {
-			continue
-		}
-		recvPtrs := map[ssa.Value]bool{
-			recv: true,
-		}
-		if len(ssafn.Locals) == 0 || ssafn.Locals[0].Heap {
-			continue
+// isBenchmarkLoop reports whether stmt is a loop whose condition
+// refers to b.N, where b refers to obj.
+func isBenchmarkLoop(j *lint.Job, stmt ast.Stmt, obj types.Object) bool {
+	loop, ok := stmt.(*ast.ForStmt)
+	if !ok || loop.Cond == nil {
+		return false
+	}
+	found := false
+	ast.Inspect(loop.Cond, func(node ast.Node) bool {
+		if expr, ok := node.(ast.Expr); ok && isBenchmarkSelector(j, expr, obj, "N") {
+			found = true
 		}
-		blocks := ssafn.DomPreorder()
-		for _, block := range blocks {
-			if writes[block] == nil {
-				writes[block] = map[ssa.Value]bool{}
-			}
-			if reads[block] == nil {
-				reads[block] = map[ssa.Value]bool{}
-			}
+		return !found
+	})
+	return found
+}
 
-			for _, ins := range block.Instrs {
+func (c *Checker) CheckBenchmarkIgnoresN(j *lint.Job) {
+	fn := func(node ast.Node) bool {
+		decl, b, ok := isBenchmark(j, node)
+		if !ok {
+			return true
+		}
+		usesN := false
+		fn2 := func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.SelectorExpr:
+				ident, ok := node.X.(*ast.Ident)
+				if !ok || j.Program.Info.ObjectOf(ident) != b {
+					return true
+				}
+				switch node.Sel.Name {
+				case "N", "Run", "RunParallel":
+					usesN = true
+				}
+				// Don't visit the identifier, it is not escaping.
+				return false
+			case *ast.Ident:
+				if j.Program.Info.ObjectOf(node) == b {
+					// b is being passed around, we have to assume
+					// that b.N is being used elsewhere.
+					usesN = true
+				}
+			}
+			return !usesN
+		}
+		ast.Inspect(decl.Body, fn2)
+		if !usesN {
+			j.Errorf(decl.Name, "benchmark %s doesn't use b.N, its results will be meaningless", decl.Name.Name)
+		}
+		return false
+	}
+	for _, f := range j.Program.Files {
+		ast.Inspect(f, fn)
+	}
+}
+
+func (c *Checker) CheckBenchmarkTimer(j *lint.Job) {
+	fn := func(node ast.Node) bool {
+		decl, b, ok := isBenchmark(j, node)
+		if !ok {
+			return true
+		}
+		sawLoop := false
+		for _, stmt := range decl.Body.List {
+			if isBenchmarkLoop(j, stmt, b) {
+				sawLoop = true
+				loop := stmt.(*ast.ForStmt)
+				var stop ast.Node
+				start := false
+				fn2 := func(node ast.Node) bool {
+					if _, ok := node.(*ast.FuncLit); ok {
+						return false
+					}
+					switch {
+					case isBenchmarkCall(j, node, b, "ResetTimer"):
+						j.Errorf(node, "calling b.ResetTimer in the benchmark loop discards the time of all previous iterations")
+					case isBenchmarkCall(j, node, b, "StopTimer"):
+						stop = node
+					case isBenchmarkCall(j, node, b, "StartTimer"):
+						start = true
+					}
+					return true
+				}
+				ast.Inspect(loop.Body, fn2)
+				if stop != nil && !start {
+					j.Errorf(stop, "b.StopTimer is called in the benchmark loop without a matching b.StartTimer, all following iterations will go unmeasured")
+				}
+				checkBenchmarkSetup(j, loop, b)
+				continue
+			}
+			if !sawLoop {
+				continue
+			}
+			expr, ok := stmt.(*ast.ExprStmt)
+			if !ok {
+				continue
+			}
+			if isBenchmarkCall(j, expr.X, b, "ResetTimer") {
+				j.Errorf(expr, "calling b.ResetTimer after the benchmark loop discards the time measured by the loop")
+			}
+		}
+		return false
+	}
+	for _, f := range j.Program.Files {
+		ast.Inspect(f, fn)
+	}
+}
+
+// benchmarkSetupFuncs are functions that prepare the inputs of
+// benchmarks, such as test data and servers, and that are expensive
+// enough to distort the measurements of loops that call them.
+var benchmarkSetupFuncs = map[string]bool{
+	"database/sql.Open":              true,
+	"io/ioutil.ReadDir":              true,
+	"io/ioutil.ReadFile":             true,
+	"io/ioutil.TempDir":              true,
+	"io/ioutil.TempFile":             true,
+	"net.Listen":                     true,
+	"net/http/httptest.NewServer":    true,
+	"net/http/httptest.NewTLSServer": true,
+	"os.Create":                      true,
+	"os.CreateTemp":                  true,
+	"os.MkdirTemp":                   true,
+	"os.Open":                        true,
+	"os.ReadDir":                     true,
+	"os.ReadFile":                    true,
+	"regexp.Compile":                 true,
+	"regexp.MustCompile":             true,
+}
+
+// checkBenchmarkSetup reports calls of setup functions in the body of
+// loop, the benchmark loop of b, that run while the timer is running.
+// Calls are setup if their arguments don't change between iterations
+// and their results are used by the rest of the loop; calls whose
+// results go unused are what's being measured.
+func checkBenchmarkSetup(j *lint.Job, loop *ast.ForStmt, b types.Object) {
+	// inLoop reports whether any identifier in node refers to an
+	// object declared in the loop, such as the loop variable.
+	inLoop := func(node ast.Node) bool {
+		found := false
+		ast.Inspect(node, func(node ast.Node) bool {
+			if id, ok := node.(*ast.Ident); ok {
+				if obj := j.Program.Info.ObjectOf(id); obj != nil && obj.Pos() >= loop.Pos() && obj.Pos() < loop.End() {
+					found = true
+				}
+			}
+			return !found
+		})
+		return found
+	}
+	// used reports whether obj is used in the loop body after pos.
+	used := func(obj types.Object, pos token.Pos) bool {
+		found := false
+		ast.Inspect(loop.Body, func(node ast.Node) bool {
+			if id, ok := node.(*ast.Ident); ok && id.Pos() > pos && j.Program.Info.Uses[id] == obj {
+				found = true
+			}
+			return !found
+		})
+		return found
+	}
+
+	stopped := false
+	ast.Inspect(loop.Body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			switch {
+			case isBenchmarkCall(j, node, b, "StopTimer"):
+				stopped = true
+			case isBenchmarkCall(j, node, b, "StartTimer"):
+				stopped = false
+			}
+		case *ast.AssignStmt:
+			if stopped || len(node.Rhs) != 1 {
+				return true
+			}
+			call, ok := node.Rhs[0].(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			fn, ok := j.Program.Info.ObjectOf(sel.Sel).(*types.Func)
+			if !ok || !benchmarkSetupFuncs[fn.FullName()] || inLoop(call) {
+				return true
+			}
+			name := fn.FullName()
+			for _, lhs := range node.Lhs {
+				id, ok := lhs.(*ast.Ident)
+				if !ok {
+					continue
+				}
+				if obj := j.Program.Info.ObjectOf(id); obj != nil && used(obj, node.End()) {
+					j.Errorf(call, "%s is called with the same arguments in every iteration of the benchmark loop, which measures the setup along with the benchmark; call it before the loop and call b.ResetTimer, or surround it with b.StopTimer and b.StartTimer", name)
+					break
+				}
+			}
+		}
+		return true
+	})
+}
+
+func (c *Checker) CheckBenchmarkNProblemSize(j *lint.Job) {
+	fn := func(node ast.Node) bool {
+		decl, b, ok := isBenchmark(j, node)
+		if !ok {
+			return true
+		}
+		fn2 := func(node ast.Node) bool {
+			stmt, ok := node.(ast.Stmt)
+			if !ok || !isBenchmarkLoop(j, stmt, b) {
+				return true
+			}
+			fn3 := func(node ast.Node) bool {
+				expr, ok := node.(ast.Expr)
+				if !ok || !isBenchmarkSelector(j, expr, b, "N") {
+					return true
+				}
+				j.Errorf(expr, "b.N should only be used as the number of iterations; using it inside the benchmark loop makes the work done per iteration depend on b.N")
+				return false
+			}
+			ast.Inspect(stmt.(*ast.ForStmt).Body, fn3)
+			return false
+		}
+		ast.Inspect(decl.Body, fn2)
+		return false
+	}
+	for _, f := range j.Program.Files {
+		ast.Inspect(f, fn)
+	}
+}
+
+func (c *Checker) CheckIneffectiveFieldAssignments(j *lint.Job) {
+	for _, ssafn := range j.Program.InitialFunctions {
+		// fset := j.Program.SSA.Fset
+		// if fset.File(f.File.Pos()) != fset.File(ssafn.Pos()) {
+		// 	continue
+		// }
+		if ssafn.Signature.Recv() == nil {
+			continue
+		}
+
+		if len(ssafn.Blocks) == 0 {
+			// External function
+			continue
+		}
+
+		reads := map[*ssa.BasicBlock]map[ssa.Value]bool{}
+		writes := map[*ssa.BasicBlock]map[ssa.Value]bool{}
+
+		recv := ssafn.Params[0]
+		if _, ok := recv.Type().Underlying().(*types.Struct); !ok {
+			continue
+		}
+		recvPtrs := map[ssa.Value]bool{
+			recv: true,
+		}
+		if len(ssafn.Locals) == 0 || ssafn.Locals[0].Heap {
+			continue
+		}
+		blocks := ssafn.DomPreorder()
+		for _, block := range blocks {
+			if writes[block] == nil {
+				writes[block] = map[ssa.Value]bool{}
+			}
+			if reads[block] == nil {
+				reads[block] = map[ssa.Value]bool{}
+			}
+
+			for _, ins := range block.Instrs {
 				switch ins := ins.(type) {
 				case *ssa.Store:
 					if recvPtrs[ins.Val] {
@@ -1501,11 +2169,7 @@ func (c *Checker) CheckNilMaps(j *lint.Job) {
 				if !ok {
 					continue
 				}
-				c, ok := mu.Map.(*ssa.Const)
-				if !ok {
-					continue
-				}
-				if c.Value != nil {
+				if nilness.At(mu.Map, mu) != nilness.Nil {
 					continue
 				}
 				j.Errorf(mu, "assignment to nil map")
@@ -2082,9 +2746,10 @@ func (c *Checker) CheckSliceOutOfBounds(j *lint.Job) {
 				if _, ok := ia.X.Type().Underlying().(*types.Slice); !ok {
 					continue
 				}
-				sr, ok1 := c.funcDescs.Get(ssafn).Ranges[ia.X].(vrp.SliceInterval)
-				idxr, ok2 := c.funcDescs.Get(ssafn).Ranges[ia.Index].(vrp.IntInterval)
-				if !ok1 || !ok2 || !sr.IsKnown() || !idxr.IsKnown() || sr.Length.Empty() || idxr.Empty() {
+				ranges := c.funcDescs.Get(ssafn).Ranges
+				sr, ok1 := ranges.Slice(ia.X)
+				idxr, ok2 := ranges.Int(ia.Index)
+				if !ok1 || !ok2 {
 					continue
 				}
 				if idxr.Lower.Cmp(sr.Length.Upper) >= 0 {
@@ -2095,112 +2760,1032 @@ func (c *Checker) CheckSliceOutOfBounds(j *lint.Job) {
 	}
 }
 
-func (c *Checker) CheckDeferLock(j *lint.Job) {
+func (c *Checker) CheckOverflowingConversion(j *lint.Job) {
 	for _, ssafn := range j.Program.InitialFunctions {
 		for _, block := range ssafn.Blocks {
-			instrs := lint.FilterDebug(block.Instrs)
-			if len(instrs) < 2 {
-				continue
-			}
-			for i, ins := range instrs[:len(instrs)-1] {
-				call, ok := ins.(*ssa.Call)
+			for _, ins := range block.Instrs {
+				conv, ok := ins.(*ssa.Convert)
 				if !ok {
 					continue
 				}
-				if !lint.IsCallTo(call.Common(), "(*sync.Mutex).Lock") && !lint.IsCallTo(call.Common(), "(*sync.RWMutex).RLock") {
+				if _, ok := vrp.IntervalForType(conv.X.Type()); !ok {
 					continue
 				}
-				nins, ok := instrs[i+1].(*ssa.Defer)
+				bounds, ok := vrp.IntervalForType(conv.Type())
 				if !ok {
 					continue
 				}
-				if !lint.IsCallTo(&nins.Call, "(*sync.Mutex).Lock") && !lint.IsCallTo(&nins.Call, "(*sync.RWMutex).RLock") {
-					continue
-				}
-				if call.Common().Args[0] != nins.Call.Args[0] {
+				r, ok := c.funcDescs.Get(ssafn).Ranges.Int(conv.X)
+				if !ok {
 					continue
 				}
-				name := shortCallName(call.Common())
-				alt := ""
-				switch name {
-				case "Lock":
-					alt = "Unlock"
-				case "RLock":
-					alt = "RUnlock"
+				// Ranges are conservative, so only conversions that
+				// overflow for every value in the range are reported.
+				if r.Intersection(bounds).Empty() {
+					j.Errorf(conv, "conversion of value in range %s to %s always overflows", r, conv.Type())
 				}
-				j.Errorf(nins, "deferring %s right after having locked already; did you mean to defer %s?", name, alt)
 			}
 		}
 	}
 }
 
-func (c *Checker) CheckNaNComparison(j *lint.Job) {
-	isNaN := func(v ssa.Value) bool {
-		call, ok := v.(*ssa.Call)
-		if !ok {
-			return false
-		}
-		return lint.IsCallTo(call.Common(), "math.NaN")
+// copyAfterUseReason returns why values of type T must not be copied
+// once they have been used, or the empty string if they may be.
+func copyAfterUseReason(T types.Type, qf types.Qualifier, seen map[types.Type]bool) string {
+	switch types.TypeString(T, nil) {
+	case "strings.Builder":
+		return "writing to the copy panics"
+	case "bytes.Buffer":
+		return "the copy shares its contents with the original"
 	}
-	for _, ssafn := range j.Program.InitialFunctions {
-		for _, block := range ssafn.Blocks {
-			for _, ins := range block.Instrs {
-				ins, ok := ins.(*ssa.BinOp)
-				if !ok {
-					continue
-				}
-				if isNaN(ins.X) || isNaN(ins.Y) {
-					j.Errorf(ins, "no value is equal to NaN, not even NaN itself")
-				}
+	if seen[T] {
+		return ""
+	}
+	seen[T] = true
+	switch U := T.Underlying().(type) {
+	case *types.Struct:
+		for i := 0; i < U.NumFields(); i++ {
+			if named, ok := U.Field(i).Type().(*types.Named); ok && named.Obj().Name() == "noCopy" {
+				return fmt.Sprintf("%s must not be copied after first use", types.TypeString(T, qf))
+			}
+		}
+		for i := 0; i < U.NumFields(); i++ {
+			if reason := copyAfterUseReason(U.Field(i).Type(), qf, seen); reason != "" {
+				return reason
 			}
 		}
+	case *types.Array:
+		return copyAfterUseReason(U.Elem(), qf, seen)
 	}
+	return ""
 }
 
-func (c *Checker) CheckInfiniteRecursion(j *lint.Job) {
-	for _, ssafn := range j.Program.InitialFunctions {
-		node := c.funcDescs.CallGraph.CreateNode(ssafn)
-		for _, edge := range node.Out {
-			if edge.Callee != node {
-				continue
-			}
+func (c *Checker) CheckCopyAfterUse(j *lint.Job) {
+	fn := func(node ast.Node) bool {
+		decl, ok := node.(*ast.FuncDecl)
+		if !ok || decl.Body == nil {
+			return true
+		}
+		checkCopyAfterUse(j, decl)
+		return false
+	}
+	for _, f := range j.Program.Files {
+		ast.Inspect(f, fn)
+	}
+}
 
-			block := edge.Site.Block()
-			canReturn := false
-			for _, b := range ssafn.Blocks {
-				if block.Dominates(b) {
-					continue
+// checkCopyAfterUse reports copies of the variables of fn, and of the
+// values their pointers point to, that follow a use of the variable
+// in the source. Calling methods and taking addresses count as uses.
+// Pointers that are assigned to other variables share their uses.
+func checkCopyAfterUse(j *lint.Job, fn *ast.FuncDecl) {
+	qf := types.RelativeTo(j.NodePackage(fn).Pkg)
+	alias := map[*types.Var]*types.Var{}
+	find := func(v *types.Var) *types.Var {
+		for alias[v] != nil {
+			v = alias[v]
+		}
+		return v
+	}
+	// the position of the first use of each variable
+	used := map[*types.Var]token.Pos{}
+	// addresses assigned to variables, which don't count as uses
+	aliased := map[*ast.UnaryExpr]bool{}
+
+	local := func(id *ast.Ident) *types.Var {
+		v, ok := j.Program.Info.ObjectOf(id).(*types.Var)
+		if !ok || v.IsField() || v.Pos() < fn.Pos() || v.Pos() >= fn.End() {
+			return nil
+		}
+		return v
+	}
+	// tracked returns the variable that expr, a variable or the
+	// dereference of a pointer, denotes, if its value must not be
+	// copied after first use.
+	tracked := func(expr ast.Expr) (*types.Var, string) {
+		expr = astutil.Unparen(expr)
+		var id *ast.Ident
+		switch e := expr.(type) {
+		case *ast.Ident:
+			id = e
+		case *ast.StarExpr:
+			id, _ = astutil.Unparen(e.X).(*ast.Ident)
+		}
+		if id == nil {
+			return nil, ""
+		}
+		v := local(id)
+		if v == nil {
+			return nil, ""
+		}
+		T := j.Program.Info.TypeOf(expr)
+		if T == nil {
+			return nil, ""
+		}
+		return v, copyAfterUseReason(T, qf, map[types.Type]bool{})
+	}
+	// root returns the variable at the root of expr, as in x for
+	// x.f.g, including pointers, as in p for p.f and *p.
+	root := func(expr ast.Expr) *types.Var {
+		for {
+			switch e := astutil.Unparen(expr).(type) {
+			case *ast.SelectorExpr:
+				if sel, ok := j.Program.Info.Selections[e]; !ok || sel.Kind() != types.FieldVal {
+					return nil
 				}
-				if len(b.Instrs) == 0 {
-					continue
+				expr = e.X
+			case *ast.StarExpr:
+				expr = e.X
+			case *ast.Ident:
+				return local(e)
+			default:
+				return nil
+			}
+		}
+	}
+	use := func(v *types.Var, pos token.Pos) {
+		if v == nil {
+			return
+		}
+		v = find(v)
+		if _, ok := used[v]; !ok {
+			used[v] = pos
+		}
+	}
+	checkCopy := func(expr ast.Expr, how string) {
+		v, reason := tracked(expr)
+		if v == nil || reason == "" {
+			return
+		}
+		pos, ok := used[find(v)]
+		if !ok {
+			return
+		}
+		j.Errorf(expr, "%s (%s) is copied by %s after its first use on line %d; %s",
+			j.Render(expr), types.TypeString(j.Program.Info.TypeOf(expr), qf), how,
+			j.Program.Prog.Fset.Position(pos).Line, reason)
+	}
+	assign := func(lhs, rhs ast.Expr, how string) {
+		if id, ok := lhs.(*ast.Ident); ok && id.Name == "_" {
+			return
+		}
+		if addr, ok := astutil.Unparen(rhs).(*ast.UnaryExpr); ok && addr.Op == token.AND {
+			if id, ok := lhs.(*ast.Ident); ok {
+				if dst, src := local(id), root(addr.X); dst != nil && src != nil && find(src) != dst {
+					alias[dst] = find(src)
+					aliased[addr] = true
+					return
 				}
-				if _, ok := b.Instrs[len(b.Instrs)-1].(*ssa.Return); ok {
-					canReturn = true
-					break
+			}
+		}
+		if id, ok := lhs.(*ast.Ident); ok {
+			src, _ := astutil.Unparen(rhs).(*ast.Ident)
+			if dst := local(id); dst != nil && src != nil && local(src) != nil && find(local(src)) != dst {
+				if _, ok := j.Program.Info.TypeOf(rhs).Underlying().(*types.Pointer); ok {
+					alias[dst] = find(local(src))
+					return
 				}
 			}
-			if canReturn {
-				continue
+		}
+		checkCopy(rhs, how)
+		if id, ok := lhs.(*ast.Ident); ok {
+			// Assigning a new value to a variable ends the use of
+			// the old one.
+			if v := local(id); v != nil && alias[v] == nil {
+				if _, ok := v.Type().Underlying().(*types.Pointer); !ok {
+					delete(used, v)
+				}
 			}
-			j.Errorf(edge.Site, "infinite recursive call")
 		}
 	}
-}
 
-func objectName(obj types.Object) string {
-	if obj == nil {
-		return "<nil>"
-	}
-	var name string
-	if obj.Pkg() != nil && obj.Pkg().Scope().Lookup(obj.Name()) == obj {
-		var s string
-		s = obj.Pkg().Path()
-		if s != "" {
-			name += s + "."
-		}
-	}
-	name += obj.Name()
-	return name
+	ast.Inspect(fn.Body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.AssignStmt:
+			if node.Tok != token.ASSIGN && node.Tok != token.DEFINE || len(node.Lhs) != len(node.Rhs) {
+				return true
+			}
+			for i := range node.Lhs {
+				assign(node.Lhs[i], node.Rhs[i], "assignment")
+			}
+		case *ast.ValueSpec:
+			if len(node.Names) != len(node.Values) {
+				return true
+			}
+			for i := range node.Names {
+				assign(node.Names[i], node.Values[i], "declaration")
+			}
+		case *ast.UnaryExpr:
+			if node.Op == token.AND && !aliased[node] {
+				use(root(node.X), node.Pos())
+			}
+		case *ast.CallExpr:
+			if j.Program.Info.Types[node.Fun].IsType() {
+				for _, arg := range node.Args {
+					checkCopy(arg, "converting it to "+j.Render(node.Fun))
+				}
+				return true
+			}
+			if id, ok := astutil.Unparen(node.Fun).(*ast.Ident); ok {
+				if _, ok := j.Program.Info.ObjectOf(id).(*types.Builtin); ok {
+					return true
+				}
+			}
+			name := j.Render(node.Fun)
+			if _, ok := node.Fun.(*ast.FuncLit); ok {
+				name = "a function literal"
+			}
+			for _, arg := range node.Args {
+				checkCopy(arg, "passing it to "+name)
+			}
+			sel, ok := astutil.Unparen(node.Fun).(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			selection, ok := j.Program.Info.Selections[sel]
+			if !ok || selection.Kind() != types.MethodVal {
+				return true
+			}
+			recv := selection.Obj().Type().(*types.Signature).Recv()
+			if _, ok := recv.Type().Underlying().(*types.Pointer); ok {
+				use(root(sel.X), node.Pos())
+			} else if !types.IsInterface(recv.Type()) {
+				checkCopy(sel.X, "calling its value-receiver method "+sel.Sel.Name)
+			}
+		case *ast.ReturnStmt:
+			for _, res := range node.Results {
+				checkCopy(res, "returning it")
+			}
+		case *ast.CompositeLit:
+			for _, elt := range node.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok {
+					elt = kv.Value
+				}
+				checkCopy(elt, "a composite literal")
+			}
+		case *ast.SendStmt:
+			checkCopy(node.Value, "sending it on a channel")
+		}
+		return true
+	})
+}
+
+// atomicWrappers maps the types of variables accessed with the
+// functions of sync/atomic to the types of sync/atomic that wrap them.
+var atomicWrappers = map[string]string{
+	"int32":          "atomic.Int32",
+	"int64":          "atomic.Int64",
+	"uint32":         "atomic.Uint32",
+	"uint64":         "atomic.Uint64",
+	"uintptr":        "atomic.Uintptr",
+	"unsafe.Pointer": "atomic.Pointer",
+}
+
+// atomicVar returns the variable or field whose address expr, the
+// first argument of a function of sync/atomic, takes, and the
+// identifier referring to it.
+func atomicVar(j *lint.Job, expr ast.Expr) (*types.Var, *ast.Ident) {
+	addr, ok := expr.(*ast.UnaryExpr)
+	if !ok || addr.Op != token.AND {
+		return nil, nil
+	}
+	var id *ast.Ident
+	switch x := addr.X.(type) {
+	case *ast.Ident:
+		id = x
+	case *ast.SelectorExpr:
+		id = x.Sel
+	default:
+		return nil, nil
+	}
+	v, _ := j.Program.Info.ObjectOf(id).(*types.Var)
+	return v, id
+}
+
+// unsharedWrites returns the field identifiers of writes, in the
+// function with the body body, to fields of values that the function
+// allocates itself, such as s.n in s := &S{}; s.n = 1; return s.
+// Only writes that happen before the value may be shared are
+// included: before the variable holding it is passed on, stored,
+// returned, assigned to or captured by a closure, and not in a loop
+// that repeats the write after that.
+func unsharedWrites(j *lint.Job, body *ast.BlockStmt) map[*ast.Ident]bool {
+	isNew := func(expr ast.Expr) bool {
+		switch expr := astutil.Unparen(expr).(type) {
+		case *ast.CompositeLit:
+			return true
+		case *ast.UnaryExpr:
+			_, ok := astutil.Unparen(expr.X).(*ast.CompositeLit)
+			return ok && expr.Op == token.AND
+		case *ast.CallExpr:
+			id, ok := astutil.Unparen(expr.Fun).(*ast.Ident)
+			if !ok || id.Name != "new" {
+				return false
+			}
+			_, ok = j.Program.Info.ObjectOf(id).(*types.Builtin)
+			return ok
+		}
+		return false
+	}
+
+	type local struct {
+		// the statement declaring the variable
+		decl ast.Node
+		// the position of the first use that may share the value
+		shared token.Pos
+	}
+	locals := map[*types.Var]*local{}
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.AssignStmt:
+			if node.Tok != token.DEFINE || len(node.Lhs) != len(node.Rhs) {
+				return true
+			}
+			for i, lhs := range node.Lhs {
+				id, ok := lhs.(*ast.Ident)
+				if !ok {
+					continue
+				}
+				if v, ok := j.Program.Info.Defs[id].(*types.Var); ok && isNew(node.Rhs[i]) {
+					locals[v] = &local{decl: node}
+				}
+			}
+		case *ast.DeclStmt:
+			gen, ok := node.Decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				return true
+			}
+			for _, spec := range gen.Specs {
+				spec := spec.(*ast.ValueSpec)
+				for i, id := range spec.Names {
+					v, ok := j.Program.Info.Defs[id].(*types.Var)
+					if !ok {
+						continue
+					}
+					if len(spec.Values) == 0 {
+						if _, ok := v.Type().Underlying().(*types.Struct); ok {
+							locals[v] = &local{decl: node}
+						}
+					} else if len(spec.Values) == len(spec.Names) && isNew(spec.Values[i]) {
+						locals[v] = &local{decl: node}
+					}
+				}
+			}
+		}
+		return true
+	})
+	if len(locals) == 0 {
+		return nil
+	}
+
+	type write struct {
+		field *ast.Ident
+		v     *types.Var
+		loops []ast.Node
+	}
+	var writes []write
+	var stack []ast.Node
+	ast.Inspect(body, func(node ast.Node) bool {
+		if node == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		stack = append(stack, node)
+		id, ok := node.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		v, ok := j.Program.Info.Uses[id].(*types.Var)
+		if !ok || locals[v] == nil {
+			return true
+		}
+		l := locals[v]
+		share := func(pos token.Pos) {
+			if !l.shared.IsValid() || pos < l.shared {
+				l.shared = pos
+			}
+		}
+		var loops []ast.Node
+		for _, n := range stack {
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				share(n.Pos())
+				return true
+			case *ast.ForStmt, *ast.RangeStmt:
+				loops = append(loops, n)
+			}
+		}
+		// Only accessing a field of the value, without taking its
+		// address, doesn't share it.
+		var sel *ast.SelectorExpr
+		if len(stack) >= 3 {
+			sel, _ = stack[len(stack)-2].(*ast.SelectorExpr)
+		}
+		if sel == nil || sel.X != id {
+			share(id.Pos())
+			return true
+		}
+		if s, ok := j.Program.Info.Selections[sel]; !ok || s.Kind() != types.FieldVal {
+			share(id.Pos())
+			return true
+		}
+		switch parent := stack[len(stack)-3].(type) {
+		case *ast.UnaryExpr:
+			if parent.Op == token.AND {
+				share(id.Pos())
+			}
+		case *ast.AssignStmt:
+			for _, lhs := range parent.Lhs {
+				if lhs == sel {
+					writes = append(writes, write{sel.Sel, v, loops})
+				}
+			}
+		case *ast.IncDecStmt:
+			writes = append(writes, write{sel.Sel, v, loops})
+		}
+		return true
+	})
+
+	out := map[*ast.Ident]bool{}
+writes:
+	for _, w := range writes {
+		l := locals[w.v]
+		if l.shared.IsValid() && w.field.Pos() >= l.shared {
+			continue
+		}
+		for _, loop := range w.loops {
+			if l.decl.Pos() < loop.Pos() || l.decl.End() > loop.End() {
+				// The loop may repeat the write after the value
+				// has been shared.
+				continue writes
+			}
+		}
+		out[w.field] = true
+	}
+	return out
+}
+
+func (c *Checker) CheckMixedAtomicAccess(j *lint.Job) {
+	// the position of the first atomic access of each variable
+	atomics := map[*types.Var]token.Pos{}
+	// identifiers that don't count as plain accesses
+	skip := map[*ast.Ident]bool{}
+	for _, f := range j.Program.Files {
+		ast.Inspect(f, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.CallExpr:
+				sel, ok := node.Fun.(*ast.SelectorExpr)
+				if !ok || len(node.Args) == 0 {
+					return true
+				}
+				fn, ok := j.Program.Info.ObjectOf(sel.Sel).(*types.Func)
+				if !ok || fn.Pkg() == nil || fn.Pkg().Path() != "sync/atomic" || fn.Type().(*types.Signature).Recv() != nil {
+					return true
+				}
+				v, id := atomicVar(j, node.Args[0])
+				if v == nil {
+					return true
+				}
+				if _, ok := atomics[v]; !ok {
+					atomics[v] = node.Pos()
+				}
+				skip[id] = true
+			case *ast.UnaryExpr:
+				// Addresses may be passed to functions that access
+				// them atomically.
+				if _, id := atomicVar(j, node); id != nil {
+					skip[id] = true
+				}
+			case *ast.KeyValueExpr:
+				// Initializing fields in composite literals happens
+				// before values are shared.
+				if id, ok := node.Key.(*ast.Ident); ok {
+					skip[id] = true
+				}
+			case *ast.FuncDecl:
+				if node.Body != nil {
+					for id := range unsharedWrites(j, node.Body) {
+						skip[id] = true
+					}
+				}
+			case *ast.FuncLit:
+				for id := range unsharedWrites(j, node.Body) {
+					skip[id] = true
+				}
+			}
+			return true
+		})
+	}
+	if len(atomics) == 0 {
+		return
+	}
+	for id, obj := range j.Program.Info.Uses {
+		v, ok := obj.(*types.Var)
+		if !ok || skip[id] {
+			continue
+		}
+		first, ok := atomics[v]
+		if !ok {
+			continue
+		}
+		pos := j.Program.Prog.Fset.Position(first)
+		msg := fmt.Sprintf("%s is accessed atomically at %s:%d, but not here", v.Name(), filepath.Base(pos.Filename), pos.Line)
+		if wrapper, ok := atomicWrappers[types.TypeString(v.Type(), nil)]; ok && j.IsGoVersion(id, 19) {
+			msg += fmt.Sprintf("; consider using %s, which only allows atomic access", wrapper)
+		}
+		j.Errorf(id, "%s", msg)
+	}
+}
+
+func (c *Checker) CheckDeferLock(j *lint.Job) {
+	for _, ssafn := range j.Program.InitialFunctions {
+		for _, block := range ssafn.Blocks {
+			instrs := lint.FilterDebug(block.Instrs)
+			if len(instrs) < 2 {
+				continue
+			}
+			for i, ins := range instrs[:len(instrs)-1] {
+				call, ok := ins.(*ssa.Call)
+				if !ok {
+					continue
+				}
+				if !lint.IsCallTo(call.Common(), "(*sync.Mutex).Lock") && !lint.IsCallTo(call.Common(), "(*sync.RWMutex).RLock") {
+					continue
+				}
+				nins, ok := instrs[i+1].(*ssa.Defer)
+				if !ok {
+					continue
+				}
+				if !lint.IsCallTo(&nins.Call, "(*sync.Mutex).Lock") && !lint.IsCallTo(&nins.Call, "(*sync.RWMutex).RLock") {
+					continue
+				}
+				if call.Common().Args[0] != nins.Call.Args[0] {
+					continue
+				}
+				name := shortCallName(call.Common())
+				alt := ""
+				switch name {
+				case "Lock":
+					alt = "Unlock"
+				case "RLock":
+					alt = "RUnlock"
+				}
+				j.Errorf(nins, "deferring %s right after having locked already; did you mean to defer %s?", name, alt)
+			}
+		}
+	}
+}
+
+func (c *Checker) CheckNaNComparison(j *lint.Job) {
+	isNaN := func(v ssa.Value) bool {
+		call, ok := v.(*ssa.Call)
+		if !ok {
+			return false
+		}
+		return lint.IsCallTo(call.Common(), "math.NaN")
+	}
+	for _, ssafn := range j.Program.InitialFunctions {
+		for _, block := range ssafn.Blocks {
+			for _, ins := range block.Instrs {
+				ins, ok := ins.(*ssa.BinOp)
+				if !ok {
+					continue
+				}
+				if isNaN(ins.X) || isNaN(ins.Y) {
+					j.Errorf(ins, "no value is equal to NaN, not even NaN itself")
+				}
+			}
+		}
+	}
+}
+
+// timeField returns the path to a time.Time value inside of T, such as
+// ".Created" or "[0].At", which is empty if T is time.Time itself. It
+// returns false if T doesn't contain a time.Time, or only behind
+// pointers, whose comparison doesn't compare the times.
+func timeField(T types.Type, seen map[types.Type]bool) (string, bool) {
+	if types.TypeString(T, nil) == "time.Time" {
+		return "", true
+	}
+	if seen[T] {
+		return "", false
+	}
+	seen[T] = true
+	switch T := T.Underlying().(type) {
+	case *types.Struct:
+		for i := 0; i < T.NumFields(); i++ {
+			if path, ok := timeField(T.Field(i).Type(), seen); ok {
+				return "." + T.Field(i).Name() + path, true
+			}
+		}
+	case *types.Array:
+		if path, ok := timeField(T.Elem(), seen); ok {
+			return "[0]" + path, true
+		}
+	}
+	return "", false
+}
+
+func (c *Checker) CheckTimeEquality(j *lint.Job) {
+	fn := func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.BinaryExpr:
+			if node.Op != token.EQL && node.Op != token.NEQ {
+				return true
+			}
+			T := j.Program.Info.TypeOf(node.X)
+			if T == nil || types.IsInterface(T) {
+				return true
+			}
+			path, ok := timeField(T, map[types.Type]bool{})
+			if !ok {
+				return true
+			}
+			if path != "" {
+				j.Errorf(node, "comparing values of type %s compares their time.Time field %s with %s, which also compares locations and monotonic clock readings",
+					types.TypeString(T, types.RelativeTo(j.NodePackage(node).Pkg)), strings.TrimPrefix(path, "."), node.Op)
+				return true
+			}
+			not := ""
+			if node.Op == token.NEQ {
+				not = "!"
+			}
+			x, y := node.X, node.Y
+			if isZeroTime(j, x) {
+				x, y = y, x
+			}
+			recv := renderOperand(j, x)
+			if isZeroTime(j, y) {
+				p := j.Errorf(node, "should use %s%s.IsZero() instead of comparing with the zero time.Time using %s", not, recv, node.Op)
+				p.Fix = lint.Replace(node, not+recv+".IsZero()")
+				return true
+			}
+			p := j.Errorf(node, "should use %s%s.Equal(%s) instead of %s, which also compares locations and monotonic clock readings", not, recv, j.Render(y), node.Op)
+			p.Fix = lint.Replace(node, not+recv+".Equal("+j.Render(y)+")")
+		case *ast.MapType:
+			T := j.Program.Info.TypeOf(node.Key)
+			if T == nil {
+				return true
+			}
+			if path, ok := timeField(T, map[types.Type]bool{}); ok {
+				if path == "" {
+					j.Errorf(node.Key, "time.Time as map key compares locations and monotonic clock readings; use t.UTC() or t.UnixNano() as key")
+				} else {
+					j.Errorf(node.Key, "map key contains time.Time in field %s, whose comparison also compares locations and monotonic clock readings", strings.TrimPrefix(path, "."))
+				}
+			}
+		}
+		return true
+	}
+	for _, f := range j.Program.Files {
+		ast.Inspect(f, fn)
+	}
+}
+
+// renderOperand renders expr for use as the operand of a selector
+// expression, parenthesizing it unless it is a primary expression. *p
+// becomes (*p), so that selecting a method selects it on the pointee.
+func renderOperand(j *lint.Job, expr ast.Expr) string {
+	switch expr.(type) {
+	case *ast.Ident, *ast.SelectorExpr, *ast.IndexExpr, *ast.SliceExpr,
+		*ast.CallExpr, *ast.TypeAssertExpr, *ast.ParenExpr, *ast.BasicLit:
+		return j.Render(expr)
+	default:
+		return "(" + j.Render(expr) + ")"
+	}
+}
+
+// isZeroTime reports whether expr is the composite literal time.Time{}.
+func isZeroTime(j *lint.Job, expr ast.Expr) bool {
+	lit, ok := expr.(*ast.CompositeLit)
+	return ok && len(lit.Elts) == 0 && hasType(j, lit, "time.Time")
+}
+
+func isFloat(T types.Type) bool {
+	b, ok := T.Underlying().(*types.Basic)
+	return ok && b.Info()&types.IsFloat != 0
+}
+
+func (c *Checker) CheckFloatEquality(j *lint.Job) {
+	// exempt reports whether comparisons with expr are exact by
+	// design, as with zero and infinities, or are reported by SA4012,
+	// as with NaN.
+	exempt := func(expr ast.Expr) bool {
+		if tv := j.Program.Info.Types[expr]; tv.Value != nil {
+			return constant.Sign(tv.Value) == 0
+		}
+		return j.IsCallToAnyAST(expr, "math.Inf", "math.NaN")
+	}
+	fn := func(node ast.Node) bool {
+		expr, ok := node.(*ast.BinaryExpr)
+		if !ok || (expr.Op != token.EQL && expr.Op != token.NEQ) {
+			return true
+		}
+		tx, ty := j.Program.Info.TypeOf(expr.X), j.Program.Info.TypeOf(expr.Y)
+		if tx == nil || ty == nil || !isFloat(tx) || !isFloat(ty) {
+			return true
+		}
+		if j.Program.Info.Types[expr].Value != nil {
+			// Constant expressions are exact.
+			return true
+		}
+		if exempt(expr.X) || exempt(expr.Y) {
+			return true
+		}
+		if j.Render(expr.X) == j.Render(expr.Y) {
+			// x != x is the NaN check
+			return true
+		}
+		if j.IsInTest(expr) && !j.BoolOption(expr, "tests") {
+			return true
+		}
+		j.Errorf(expr, "floating-point values shouldn't be compared with %s, as rounding errors make them differ; compare math.Abs(a-b) with a tolerance instead", expr.Op)
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
+
+// isMapRange reports whether rs ranges over a map.
+func isMapRange(j *lint.Job, rs *ast.RangeStmt) bool {
+	T := j.Program.Info.TypeOf(rs.X)
+	if T == nil {
+		return false
+	}
+	_, ok := T.Underlying().(*types.Map)
+	return ok
+}
+
+// inspectFunc is like ast.Inspect, but doesn't descend into function
+// literals.
+func inspectFunc(node ast.Node, fn func(ast.Node) bool) {
+	ast.Inspect(node, func(node ast.Node) bool {
+		if _, ok := node.(*ast.FuncLit); ok {
+			return false
+		}
+		return fn(node)
+	})
+}
+
+var orderedOutputFuncs = []string{
+	"fmt.Fprint", "fmt.Fprintf", "fmt.Fprintln",
+	"fmt.Print", "fmt.Printf", "fmt.Println",
+	"io.WriteString",
+}
+
+var orderedOutputMethods = map[string]bool{
+	"Encode":      true,
+	"Write":       true,
+	"WriteByte":   true,
+	"WriteRune":   true,
+	"WriteString": true,
+}
+
+// isOrderedOutput reports whether call writes output, whose order
+// matters, such as to an io.Writer.
+func isOrderedOutput(j *lint.Job, call *ast.CallExpr) bool {
+	if j.IsCallToAnyAST(call, orderedOutputFuncs...) {
+		return true
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	selection := j.Program.Info.Selections[sel]
+	return selection != nil && selection.Kind() == types.MethodVal && orderedOutputMethods[sel.Sel.Name]
+}
+
+var sortFuncs = []string{
+	"sort.Float64s", "sort.Ints", "sort.Strings",
+	"sort.Slice", "sort.SliceStable", "sort.Sort", "sort.Stable",
+	"slices.Sort", "slices.SortFunc", "slices.SortStableFunc",
+}
+
+// orderSinks are the functions whose results depend on the order of
+// the elements of slices passed to them.
+var orderSinks = []string{
+	"encoding/json.Marshal", "encoding/json.MarshalIndent",
+	"reflect.DeepEqual", "strings.Join", "bytes.Join",
+}
+
+func (c *Checker) CheckMapIterationOrder(j *lint.Job) {
+	check := func(body *ast.BlockStmt) {
+		if body == nil {
+			return
+		}
+		// the local slices appended to while ranging over maps
+		collected := map[types.Object]*mapOrderSlice{}
+		// Loops over maps may be nested; only report output once.
+		reported := map[*ast.RangeStmt]bool{}
+		inspectFunc(body, func(node ast.Node) bool {
+			rs, ok := node.(*ast.RangeStmt)
+			if !ok || !isMapRange(j, rs) {
+				return true
+			}
+			isKeyOrValue := func(expr ast.Expr) bool {
+				id, ok := expr.(*ast.Ident)
+				if !ok {
+					return false
+				}
+				obj := j.Program.Info.ObjectOf(id)
+				for _, e := range []ast.Expr{rs.Key, rs.Value} {
+					if e, ok := e.(*ast.Ident); ok && j.Program.Info.ObjectOf(e) == obj {
+						return true
+					}
+				}
+				return false
+			}
+			inspectFunc(rs.Body, func(node ast.Node) bool {
+				switch node := node.(type) {
+				case *ast.RangeStmt:
+					if isMapRange(j, node) {
+						reported[node] = true
+					}
+				case *ast.CallExpr:
+					if !reported[rs] && isOrderedOutput(j, node) {
+						j.Errorf(node, "writing output while ranging over a map produces it in random order; range over sorted keys instead")
+						reported[rs] = true
+					}
+				case *ast.AssignStmt:
+					if len(node.Lhs) != 1 || len(node.Rhs) != 1 || !isAppend(j, node.Rhs[0]) {
+						return true
+					}
+					id, ok := node.Lhs[0].(*ast.Ident)
+					if !ok {
+						return true
+					}
+					obj := j.Program.Info.ObjectOf(id)
+					v, ok := obj.(*types.Var)
+					if !ok || v.Parent() == nil || v.Parent() == v.Pkg().Scope() || v.Pos() > rs.Pos() {
+						return true
+					}
+					s := collected[obj]
+					if s == nil {
+						s = &mapOrderSlice{rs: rs, keysOrValues: true}
+						collected[obj] = s
+					}
+					for _, arg := range node.Rhs[0].(*ast.CallExpr).Args[1:] {
+						s.keysOrValues = s.keysOrValues && isKeyOrValue(arg)
+					}
+				}
+				return true
+			})
+			return true
+		})
+		for obj, s := range collected {
+			checkMapOrderSlice(j, body, obj, s)
+		}
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.FuncDecl:
+				check(node.Body)
+			case *ast.FuncLit:
+				check(node.Body)
+			}
+			return true
+		})
+	}
+}
+
+func isAppend(j *lint.Job, expr ast.Expr) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	id, ok := call.Fun.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	_, ok = j.Program.Info.ObjectOf(id).(*types.Builtin)
+	return ok && id.Name == "append"
+}
+
+// A mapOrderSlice is a slice that is appended to while ranging over a
+// map.
+type mapOrderSlice struct {
+	// the outermost loop over a map appending to the slice
+	rs *ast.RangeStmt
+	// whether only the keys or values of maps are appended
+	keysOrValues bool
+}
+
+// checkMapOrderSlice reports uses of the slice obj that depend on its
+// order, unless the slice gets sorted or passed to other functions,
+// which might sort it. Returning the slice only counts if it consists
+// of the keys or values of a map, as other slices often are sorted by
+// the caller.
+func checkMapOrderSlice(j *lint.Job, body *ast.BlockStmt, obj types.Object, s *mapOrderSlice) {
+	rs := s.rs
+	isObj := func(expr ast.Expr) bool {
+		id, ok := expr.(*ast.Ident)
+		return ok && j.Program.Info.ObjectOf(id) == obj
+	}
+	mentions := func(node ast.Node) bool {
+		found := false
+		ast.Inspect(node, func(node ast.Node) bool {
+			if id, ok := node.(*ast.Ident); ok && isObj(id) {
+				found = true
+			}
+			return !found
+		})
+		return found
+	}
+	var sink ast.Node
+	unknown := false
+	ast.Inspect(body, func(node ast.Node) bool {
+		if node == nil || node.Pos() < rs.End() || unknown {
+			return !unknown
+		}
+		switch node := node.(type) {
+		case *ast.ReturnStmt:
+			for _, res := range node.Results {
+				if isObj(res) && sink == nil && s.keysOrValues {
+					sink = res
+				}
+			}
+		case *ast.CallExpr:
+			if id, ok := node.Fun.(*ast.Ident); ok {
+				if _, ok := j.Program.Info.ObjectOf(id).(*types.Builtin); ok && id.Name != "copy" {
+					return true
+				}
+			}
+			direct := false
+			for _, arg := range node.Args {
+				if isObj(arg) {
+					direct = true
+				}
+			}
+			if !direct && !mentions(node) {
+				return true
+			}
+			switch {
+			case j.IsCallToAnyAST(node, orderSinks...) || j.IsCallToAnyAST(node, orderedOutputFuncs...):
+				if direct && sink == nil {
+					sink = node
+				}
+			case direct || j.IsCallToAnyAST(node, sortFuncs...):
+				// The slice may get sorted.
+				unknown = true
+				return false
+			}
+		}
+		return true
+	})
+	if unknown || sink == nil {
+		return
+	}
+	j.Errorf(sink, "%s is built by ranging over a map and isn't sorted, so its order is random", obj.Name())
+}
+
+func (c *Checker) CheckInfiniteRecursion(j *lint.Job) {
+	for _, ssafn := range j.Program.InitialFunctions {
+		node := c.funcDescs.CallGraph.CreateNode(ssafn)
+		for _, edge := range node.Out {
+			if edge.Callee != node {
+				continue
+			}
+
+			block := edge.Site.Block()
+			canReturn := false
+			for _, b := range ssafn.Blocks {
+				if block.Dominates(b) {
+					continue
+				}
+				if len(b.Instrs) == 0 {
+					continue
+				}
+				if _, ok := b.Instrs[len(b.Instrs)-1].(*ssa.Return); ok {
+					canReturn = true
+					break
+				}
+			}
+			if canReturn {
+				continue
+			}
+			j.Errorf(edge.Site, "infinite recursive call")
+		}
+	}
+}
+
+func objectName(obj types.Object) string {
+	if obj == nil {
+		return "<nil>"
+	}
+	var name string
+	if obj.Pkg() != nil && obj.Pkg().Scope().Lookup(obj.Name()) == obj {
+		var s string
+		s = obj.Pkg().Path()
+		if s != "" {
+			name += s + "."
+		}
+	}
+	name += obj.Name()
+	return name
 }
 
 func isName(j *lint.Job, expr ast.Expr, name string) bool {
@@ -2469,6 +4054,20 @@ func (c *Checker) isDeprecated(j *lint.Job, ident *ast.Ident) (bool, string) {
 	return alt != "", alt
 }
 
+var deprecatedSinceRe = regexp.MustCompile(`(?i)(?:as of|since|starting with) Go 1\.(\d+)`)
+
+// deprecatedSince returns the minor Go version that a deprecation
+// message such as "As of Go 1.16, this function simply calls
+// io.ReadAll." names, or 0 if it doesn't name one.
+func deprecatedSince(msg string) int {
+	m := deprecatedSinceRe.FindStringSubmatch(msg)
+	if m == nil {
+		return 0
+	}
+	v, _ := strconv.Atoi(m[1])
+	return v
+}
+
 func (c *Checker) CheckDeprecated(j *lint.Job) {
 	fn := func(node ast.Node) bool {
 		sel, ok := node.(*ast.SelectorExpr)
@@ -2493,6 +4092,11 @@ func (c *Checker) CheckDeprecated(j *lint.Job) {
 			return true
 		}
 		if ok, alt := c.isDeprecated(j, sel.Sel); ok {
+			if v := j.NodePackage(node).GoVersion; v != 0 && deprecatedSince(alt) > v {
+				// The module targets a version of Go that
+				// predates the deprecation and its alternative.
+				return true
+			}
 			j.Errorf(sel, "%s is deprecated: %s", j.Render(sel), alt)
 			return true
 		}
@@ -2503,66 +4107,188 @@ func (c *Checker) CheckDeprecated(j *lint.Job) {
 	}
 }
 
-func (c *Checker) callChecker(rules map[string]CallCheck) func(j *lint.Job) {
-	return func(j *lint.Job) {
-		c.checkCalls(j, rules)
+// stdlibKeys returns the possible keys of obj in stdlibVersions. sel
+// is the selection obj was selected by, if any. Methods are looked up
+// by the type they were selected from before the type declaring them,
+// which differ for promoted methods.
+func stdlibKeys(obj types.Object, sel *types.Selection) []string {
+	if obj.Pkg() == nil {
+		return nil
+	}
+	if obj.Parent() == obj.Pkg().Scope() {
+		return []string{obj.Pkg().Path() + "." + obj.Name()}
+	}
+	var recvs []types.Type
+	switch obj := obj.(type) {
+	case *types.Func:
+		if sel != nil {
+			recvs = append(recvs, sel.Recv())
+		}
+		recvs = append(recvs, obj.Type().(*types.Signature).Recv().Type())
+	case *types.Var:
+		// Promoted fields belong to embedded types.
+		if sel == nil || sel.Kind() != types.FieldVal || len(sel.Index()) != 1 {
+			return nil
+		}
+		recvs = append(recvs, sel.Recv())
 	}
+	var keys []string
+	for _, recv := range recvs {
+		if ptr, ok := recv.(*types.Pointer); ok {
+			recv = ptr.Elem()
+		}
+		named, ok := recv.(*types.Named)
+		if !ok || named.Obj().Pkg() == nil {
+			continue
+		}
+		keys = append(keys, named.Obj().Pkg().Path()+"."+named.Obj().Name()+"."+obj.Name())
+	}
+	return keys
 }
 
-func (c *Checker) checkCalls(j *lint.Job, rules map[string]CallCheck) {
-	for _, ssafn := range j.Program.InitialFunctions {
-		node := c.funcDescs.CallGraph.CreateNode(ssafn)
-		for _, edge := range node.Out {
-			callee := edge.Callee.Func
-			obj, ok := callee.Object().(*types.Func)
-			if !ok {
-				continue
-			}
-
-			r, ok := rules[obj.FullName()]
-			if !ok {
+func (c *Checker) CheckNewerStdlibAPI(j *lint.Job) {
+	for _, f := range j.Program.Files {
+		pkg := j.NodePackage(f)
+		declared := pkg.GoVersion
+		if declared == 0 {
+			// Without a go directive, there is no version to
+			// compare against.
+			continue
+		}
+		reason := "the module declares"
+		if v := constraintVersion(f); v > declared {
+			// The file only builds with newer versions of Go.
+			declared = v
+			reason = "the file's build constraints require"
+		}
+		report := func(node ast.Node, what string, added int) {
+			j.Errorf(node, "%s was added in Go 1.%d, but %s Go 1.%d", what, added, reason, declared)
+		}
+		for _, imp := range f.Imports {
+			path, err := strconv.Unquote(imp.Path.Value)
+			if err != nil {
 				continue
 			}
-			var args []*Argument
-			ssaargs := edge.Site.Common().Args
-			if callee.Signature.Recv() != nil {
-				ssaargs = ssaargs[1:]
+			if added, ok := stdlibVersions[path]; ok && added > declared {
+				report(imp, "package "+path, added)
 			}
-			for _, arg := range ssaargs {
-				if iarg, ok := arg.(*ssa.MakeInterface); ok {
-					arg = iarg.X
+		}
+		// Identifiers selected from values are looked at with their
+		// selector expressions, which know their receivers.
+		selected := map[*ast.Ident]bool{}
+		ast.Inspect(f, func(node ast.Node) bool {
+			var id *ast.Ident
+			var sel *types.Selection
+			switch node := node.(type) {
+			case *ast.SelectorExpr:
+				id = node.Sel
+				sel = j.Program.Info.Selections[node]
+				selected[id] = true
+			case *ast.Ident:
+				if selected[node] {
+					return true
 				}
-				vr := c.funcDescs.Get(edge.Site.Parent()).Ranges[arg]
-				args = append(args, &Argument{Value: Value{arg, vr}})
+				id = node
+			default:
+				return true
 			}
-			call := &Call{
-				Job:     j,
-				Instr:   edge.Site,
-				Args:    args,
-				Checker: c,
-				Parent:  edge.Site.Parent(),
+			obj := j.Program.Info.Uses[id]
+			if obj == nil || obj.Pkg() == nil || obj.Pkg() == pkg.Pkg {
+				return true
 			}
-			r(call)
-			for idx, arg := range call.Args {
-				_ = idx
-				for _, e := range arg.invalids {
-					// path, _ := astutil.PathEnclosingInterval(f.File, edge.Site.Pos(), edge.Site.Pos())
-					// if len(path) < 2 {
-					// 	continue
-					// }
-					// astcall, ok := path[0].(*ast.CallExpr)
-					// if !ok {
-					// 	continue
-					// }
-					// j.Errorf(astcall.Args[idx], "%s", e)
-
-					j.Errorf(edge.Site, "%s", e)
+			for _, key := range stdlibKeys(obj, sel) {
+				if added, ok := stdlibVersions[key]; ok {
+					if added > declared {
+						report(node, key, added)
+					}
+					break
 				}
 			}
-			for _, e := range call.invalids {
-				j.Errorf(call.Instr.Common(), "%s", e)
+			return true
+		})
+	}
+}
+
+func (c *Checker) callChecker(rules map[string]CallCheck) func(j *lint.Job) {
+	return func(j *lint.Job) {
+		c.checkCalls(j, rules)
+	}
+}
+
+func (c *Checker) checkCalls(j *lint.Job, rules map[string]CallCheck) {
+	for _, ssafn := range j.Program.InitialFunctions {
+		c.checkFunctionCalls(j, ssafn, rules)
+	}
+}
+
+func (c *Checker) checkFunctionCalls(j *lint.Job, ssafn *ssa.Function, rules map[string]CallCheck) {
+	node := c.funcDescs.CallGraph.CreateNode(ssafn)
+	for _, edge := range node.Out {
+		callee := edge.Callee.Func
+		obj, ok := callee.Object().(*types.Func)
+		if !ok {
+			continue
+		}
+
+		r, ok := rules[obj.FullName()]
+		if !ok {
+			continue
+		}
+		var args []*Argument
+		ssaargs := edge.Site.Common().Args
+		if callee.Signature.Recv() != nil {
+			ssaargs = ssaargs[1:]
+		}
+		for _, arg := range ssaargs {
+			if iarg, ok := arg.(*ssa.MakeInterface); ok {
+				arg = iarg.X
+			}
+			vr := c.funcDescs.Get(edge.Site.Parent()).Ranges[arg]
+			args = append(args, &Argument{Value: Value{arg, vr}})
+		}
+		call := &Call{
+			Job:     j,
+			Instr:   edge.Site,
+			Args:    args,
+			Checker: c,
+			Parent:  edge.Site.Parent(),
+		}
+		r(call)
+		for idx, arg := range call.Args {
+			_ = idx
+			for _, e := range arg.invalids {
+				// path, _ := astutil.PathEnclosingInterval(f.File, edge.Site.Pos(), edge.Site.Pos())
+				// if len(path) < 2 {
+				// 	continue
+				// }
+				// astcall, ok := path[0].(*ast.CallExpr)
+				// if !ok {
+				// 	continue
+				// }
+				// j.Errorf(astcall.Args[idx], "%s", e)
+
+				j.Errorf(edge.Site, "%s", e)
 			}
 		}
+		for _, e := range call.invalids {
+			j.Errorf(call.Instr.Common(), "%s", e)
+		}
+	}
+}
+
+func (c *Checker) CheckUnmarshalPointer(j *lint.Job) {
+	// Packages usually share their configuration, and with it the
+	// rules.
+	cache := map[string]map[string]CallCheck{}
+	for _, ssafn := range j.Program.InitialFunctions {
+		funcs := j.StringsOption(ssafn, "functions")
+		key := strings.Join(funcs, "\n")
+		rules, ok := cache[key]
+		if !ok {
+			rules = unmarshalPointerRules(funcs)
+			cache[key] = rules
+		}
+		c.checkFunctionCalls(j, ssafn, rules)
 	}
 }
 
@@ -2799,3 +4525,357 @@ func (c *Checker) CheckMapBytesKey(j *lint.Job) {
 func (c *Checker) CheckRangeStringRunes(j *lint.Job) {
 	sharedcheck.CheckRangeStringRunes(c.nodeFns, j)
 }
+
+// checkTaint reports flows of untrusted data into sinks, as configured
+// by the options of the current check.
+func (c *Checker) checkTaint(j *lint.Job, what string) {
+	for _, ssafn := range j.Program.InitialFunctions {
+		cfg := taint.Config{
+			Sources:    j.StringsOption(ssafn, "sources"),
+			Sinks:      j.StringsOption(ssafn, "sinks"),
+			Sanitizers: j.StringsOption(ssafn, "sanitizers"),
+		}
+		for _, flow := range taint.Analyze(ssafn, cfg) {
+			pos := j.Program.SSA.Fset.Position(flow.Source.Pos())
+			j.Errorf(flow.Sink, "possible %s: untrusted data from %s on line %d is passed to %s",
+				what, flow.SourceName(), pos.Line, flow.SinkName())
+		}
+	}
+}
+
+func (c *Checker) CheckSQLInjection(j *lint.Job) {
+	c.checkTaint(j, "SQL injection")
+}
+
+func (c *Checker) CheckCommandInjection(j *lint.Job) {
+	c.checkTaint(j, "command injection")
+}
+
+// osPathConfig describes the flow of operating system paths into the
+// functions of package path, which only understand slashes.
+var osPathConfig = taint.Config{
+	Sources: []string{
+		"os.Getwd",
+		"os.Executable",
+		"os.TempDir",
+		"os.UserCacheDir",
+		"os.UserConfigDir",
+		"os.UserHomeDir",
+		"os.MkdirTemp",
+		"(*os.File).Name",
+		"io/ioutil.TempDir",
+		"path/filepath.Abs",
+		"path/filepath.Clean",
+		"path/filepath.Dir",
+		"path/filepath.EvalSymlinks",
+		"path/filepath.FromSlash",
+		"path/filepath.Glob",
+		"path/filepath.Join",
+		"path/filepath.Rel",
+	},
+	Sinks: []string{
+		"path.Base",
+		"path.Clean",
+		"path.Dir",
+		"path.Ext",
+		"path.IsAbs",
+		"path.Join",
+		"path.Match:1",
+		"path.Split",
+	},
+	Sanitizers: []string{
+		"path/filepath.Base",
+		"path/filepath.Ext",
+		"path/filepath.ToSlash",
+		// Files are named by paths, but don't contain them.
+		"io/ioutil.ReadDir",
+		"io/ioutil.ReadFile",
+		"os.Create",
+		"os.Lstat",
+		"os.Open",
+		"os.OpenFile",
+		"os.ReadDir",
+		"os.ReadFile",
+		"os.Stat",
+	},
+}
+
+// urlPathFields are the fields of net/url.URL that hold paths
+// separated by slashes.
+var urlPathFields = map[string]bool{
+	"Path":    true,
+	"RawPath": true,
+}
+
+// slashPathFuncs are the functions of path/filepath that have
+// counterparts in package path and don't convert between paths, as
+// filepath.FromSlash does and filepath.Join with a directory of the
+// operating system does.
+var slashPathFuncs = map[string]bool{
+	"Base":  true,
+	"Clean": true,
+	"Dir":   true,
+	"Ext":   true,
+	"IsAbs": true,
+	"Split": true,
+}
+
+func (c *Checker) CheckPathFilepathConfusion(j *lint.Job) {
+	for _, ssafn := range j.Program.InitialFunctions {
+		for _, flow := range taint.Analyze(ssafn, osPathConfig) {
+			pos := j.Program.SSA.Fset.Position(flow.Source.Pos())
+			name := flow.SinkName()
+			p := j.Errorf(flow.Sink, "%s is passed the operating system path returned by %s on line %d, but only understands slashes; use filepath.%s instead",
+				name, flow.SourceName(), pos.Line, strings.TrimPrefix(name, "path."))
+			p.Fix = switchPackageFix(j, flow.Sink, "path/filepath")
+		}
+
+		for _, block := range ssafn.Blocks {
+			for _, ins := range block.Instrs {
+				call, ok := ins.(*ssa.Call)
+				if !ok {
+					continue
+				}
+				callee := call.Common().StaticCallee()
+				if callee == nil || callee.Package() == nil || callee.Package().Pkg.Path() != "path/filepath" || !slashPathFuncs[callee.Name()] {
+					continue
+				}
+				for _, arg := range call.Common().Args {
+					if field, ok := urlPathField(arg); ok {
+						p := j.Errorf(call, "filepath.%s is passed the URL path url.URL.%s, which is separated by slashes on all systems; use path.%s instead",
+							callee.Name(), field, callee.Name())
+						p.Fix = switchPackageFix(j, call, "path")
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+// switchPackageFix returns a fix that makes call call the function of
+// the same name in the package with the import path path, as in
+// replacing path.Join with filepath.Join, or nil if the call can't be
+// rewritten.
+func switchPackageFix(j *lint.Job, call ssa.CallInstruction, path string) *lint.Fix {
+	v, ok := call.(*ssa.Call)
+	if !ok {
+		return nil
+	}
+	expr, ok := j.ExprFor(v).(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	sel, ok := astutil.Unparen(expr.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return nil
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	if _, ok := j.Program.Info.Uses[ident].(*types.PkgName); !ok {
+		return nil
+	}
+
+	// Use the name the file imports the package by, or else the
+	// package's own name, as long as nothing else goes by it.
+	name := path[strings.LastIndex(path, "/")+1:]
+	for _, imp := range j.File(expr).Imports {
+		if imp.Path.Value == strconv.Quote(path) && imp.Name != nil {
+			name = imp.Name.Name
+		}
+	}
+	if name == "_" || name == "." {
+		return nil
+	}
+	scope := j.NodePackage(expr).Pkg.Scope().Innermost(expr.Pos())
+	if scope == nil {
+		return nil
+	}
+	if _, obj := scope.LookupParent(name, expr.Pos()); obj != nil {
+		if pkg, ok := obj.(*types.PkgName); !ok || pkg.Imported().Path() != path {
+			return nil
+		}
+	}
+	return lint.Replace(ident, name, path)
+}
+
+// urlPathField returns the name of the field of net/url.URL that v is
+// loaded from, if it holds a path.
+func urlPathField(v ssa.Value) (string, bool) {
+	var T types.Type
+	var idx int
+	switch v := v.(type) {
+	case *ssa.UnOp:
+		addr, ok := v.X.(*ssa.FieldAddr)
+		if !ok || v.Op != token.MUL {
+			return "", false
+		}
+		T, idx = addr.X.Type().Underlying().(*types.Pointer).Elem(), addr.Field
+	case *ssa.Field:
+		T, idx = v.X.Type(), v.Field
+	default:
+		return "", false
+	}
+	if types.TypeString(T, nil) != "net/url.URL" {
+		return "", false
+	}
+	name := T.Underlying().(*types.Struct).Field(idx).Name()
+	return name, urlPathFields[name]
+}
+
+// cancelFuncs are the functions of package context that return
+// contexts and the functions that cancel them.
+var cancelFuncs = map[string]bool{
+	"context.WithCancel":        true,
+	"context.WithCancelCause":   true,
+	"context.WithDeadline":      true,
+	"context.WithDeadlineCause": true,
+	"context.WithTimeout":       true,
+	"context.WithTimeoutCause":  true,
+}
+
+func (c *Checker) CheckLostCancel(j *lint.Job) {
+	// checkStmts reports discarded cancel functions among stmts, and
+	// offers to call them when the function returns.
+	checkStmts := func(stmts []ast.Stmt) {
+		for _, stmt := range stmts {
+			assign, ok := stmt.(*ast.AssignStmt)
+			if !ok || len(assign.Lhs) != 2 || len(assign.Rhs) != 1 {
+				continue
+			}
+			if id, ok := assign.Lhs[1].(*ast.Ident); !ok || id.Name != "_" {
+				continue
+			}
+			call, ok := assign.Rhs[0].(*ast.CallExpr)
+			if !ok {
+				continue
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				continue
+			}
+			fn, ok := j.Program.Info.ObjectOf(sel.Sel).(*types.Func)
+			if !ok || !cancelFuncs[fn.FullName()] {
+				continue
+			}
+			p := j.Errorf(assign.Lhs[1], "the cancel function returned by %s is discarded, which leaks the context until its parent is canceled; call it, as in defer cancel()",
+				fn.FullName())
+			scope := j.NodePackage(assign).Pkg.Scope().Innermost(assign.Pos())
+			if assign.Tok != token.DEFINE || scope == nil {
+				continue
+			}
+			if _, obj := scope.LookupParent("cancel", assign.Pos()); obj != nil {
+				continue
+			}
+			indent := strings.Repeat("\t", j.Program.SSA.Fset.Position(assign.Pos()).Column-1)
+			p.Fix = &lint.Fix{Edits: []lint.Edit{
+				{Pos: assign.Lhs[1].Pos(), End: assign.Lhs[1].End(), NewText: "cancel"},
+				{Pos: assign.End(), End: assign.End(), NewText: "\n" + indent + "defer cancel()"},
+			}}
+		}
+	}
+	fn := func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.BlockStmt:
+			checkStmts(node.List)
+		case *ast.CaseClause:
+			checkStmts(node.Body)
+		case *ast.CommClause:
+			checkStmts(node.Body)
+		}
+		return true
+	}
+	for _, f := range j.Program.Files {
+		ast.Inspect(f, fn)
+	}
+
+	for _, ssafn := range j.Program.InitialFunctions {
+		for _, block := range ssafn.Blocks {
+			for _, ins := range block.Instrs {
+				call, ok := ins.(*ssa.Call)
+				if !ok || !cancelFuncs[lint.CallName(call.Common())] {
+					continue
+				}
+				cancel := extractIndex(call, 1)
+				if cancel == nil {
+					// Discarded, which we've reported above.
+					continue
+				}
+				name := lint.CallName(call.Common())
+				refs := lint.FilterDebug(*cancel.Referrers())
+				if len(refs) == 0 {
+					j.Errorf(call, "the cancel function returned by %s is never called, which leaks the context until its parent is canceled; call it, as in defer cancel()", name)
+					continue
+				}
+				ret, ok := uncanceledReturn(call, refs)
+				if !ok {
+					continue
+				}
+				if ret.Pos().IsValid() {
+					j.Errorf(ret, "this return may be reached without calling the cancel function returned by %s on line %d, which leaks the context",
+						name, j.Program.SSA.Fset.Position(call.Pos()).Line)
+				} else {
+					j.Errorf(call, "the cancel function returned by %s isn't called on all paths, which leaks the context; call it, as in defer cancel()", name)
+				}
+			}
+		}
+	}
+}
+
+// extractIndex returns the value extracted from the result with index
+// idx of call, or nil.
+func extractIndex(call *ssa.Call, idx int) *ssa.Extract {
+	for _, ref := range *call.Referrers() {
+		if ex, ok := ref.(*ssa.Extract); ok && ex.Index == idx {
+			return ex
+		}
+	}
+	return nil
+}
+
+// uncanceledReturn returns a return instruction that is reachable from
+// call without passing through any of uses, which are the uses of the
+// cancel function returned by call.
+func uncanceledReturn(call *ssa.Call, uses []ssa.Instruction) (*ssa.Return, bool) {
+	used := map[*ssa.BasicBlock]bool{}
+	for _, use := range uses {
+		used[use.Block()] = true
+	}
+	// Uses in the block of the call follow the call, unless the call
+	// is in a loop, where the use of the previous iteration is as
+	// good as any.
+	ins := reachableWithout(call.Block(), used, func(b *ssa.BasicBlock) ssa.Instruction {
+		if ret, ok := b.Instrs[len(b.Instrs)-1].(*ssa.Return); ok {
+			return ret
+		}
+		return nil
+	})
+	ret, ok := ins.(*ssa.Return)
+	return ret, ok
+}
+
+// reachableWithout returns the first instruction that target finds in
+// the blocks reachable from start without passing through any of the
+// blocks in avoid, or nil.
+func reachableWithout(start *ssa.BasicBlock, avoid map[*ssa.BasicBlock]bool, target func(*ssa.BasicBlock) ssa.Instruction) ssa.Instruction {
+	seen := map[*ssa.BasicBlock]bool{}
+	var visit func(b *ssa.BasicBlock) ssa.Instruction
+	visit = func(b *ssa.BasicBlock) ssa.Instruction {
+		if seen[b] || avoid[b] {
+			return nil
+		}
+		seen[b] = true
+		if ins := target(b); ins != nil {
+			return ins
+		}
+		for _, succ := range b.Succs {
+			if ins := visit(succ); ins != nil {
+				return ins
+			}
+		}
+		return nil
+	}
+	return visit(start)
+}
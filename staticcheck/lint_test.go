@@ -12,6 +12,14 @@ func TestAll(t *testing.T) {
 	testutil.TestAll(t, c, "")
 }
 
+func TestGoVersion(t *testing.T) {
+	testutil.TestAll(t, NewChecker(), "goversion")
+}
+
+func TestOptions(t *testing.T) {
+	testutil.TestAll(t, NewChecker(), "options")
+}
+
 func BenchmarkStdlib(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		c := NewChecker()
@@ -6,6 +6,7 @@ import (
 	"go/types"
 	"net"
 	"net/url"
+	"reflect"
 	"regexp"
 	"sort"
 	"strconv"
@@ -183,7 +184,7 @@ func ConvertedFromInt(v Value) bool {
 	return true
 }
 
-func validEncodingBinaryType(j *lint.Job, typ types.Type) bool {
+func validEncodingBinaryType(j *lint.Job, node lint.Positioner, typ types.Type) bool {
 	typ = typ.Underlying()
 	switch typ := typ.(type) {
 	case *types.Basic:
@@ -193,19 +194,19 @@ func validEncodingBinaryType(j *lint.Job, typ types.Type) bool {
 			types.Float32, types.Float64, types.Complex64, types.Complex128, types.Invalid:
 			return true
 		case types.Bool:
-			return j.IsGoVersion(8)
+			return j.IsGoVersion(node, 8)
 		}
 		return false
 	case *types.Struct:
 		n := typ.NumFields()
 		for i := 0; i < n; i++ {
-			if !validEncodingBinaryType(j, typ.Field(i).Type()) {
+			if !validEncodingBinaryType(j, node, typ.Field(i).Type()) {
 				return false
 			}
 		}
 		return true
 	case *types.Array:
-		return validEncodingBinaryType(j, typ.Elem())
+		return validEncodingBinaryType(j, node, typ.Elem())
 	case *types.Interface:
 		// we can't determine if it's a valid type or not
 		return true
@@ -213,7 +214,7 @@ func validEncodingBinaryType(j *lint.Job, typ types.Type) bool {
 	return false
 }
 
-func CanBinaryMarshal(j *lint.Job, v Value) bool {
+func CanBinaryMarshal(j *lint.Job, node lint.Positioner, v Value) bool {
 	typ := v.Value.Type().Underlying()
 	if ttyp, ok := typ.(*types.Pointer); ok {
 		typ = ttyp.Elem().Underlying()
@@ -226,7 +227,7 @@ func CanBinaryMarshal(j *lint.Job, v Value) bool {
 		}
 	}
 
-	return validEncodingBinaryType(j, typ)
+	return validEncodingBinaryType(j, node, typ)
 }
 
 func RepeatZeroTimes(name string, arg int) CallCheck {
@@ -319,3 +320,108 @@ func UniqueStringCutset(v Value) bool {
 	}
 	return true
 }
+
+// hasMethod reports whether values of type T, or pointers to them,
+// have a method called name.
+func hasMethod(T types.Type, name string) bool {
+	if _, ok := T.Underlying().(*types.Pointer); !ok {
+		T = types.NewPointer(T)
+	}
+	return types.NewMethodSet(T).Lookup(nil, name) != nil
+}
+
+// UnsupportedJSONType explains why encoding/json fails to marshal
+// values of type T, or returns the empty string if it might succeed.
+// Types are unsupported if they contain channels, functions, complex
+// numbers or maps whose keys are neither strings, integers nor
+// encoding.TextMarshalers in places that encoding/json marshals.
+func UnsupportedJSONType(T types.Type) string {
+	return unsupportedJSONType(T, "", map[types.Type]bool{})
+}
+
+func unsupportedJSONType(T types.Type, path string, seen map[types.Type]bool) string {
+	if seen[T] {
+		return ""
+	}
+	seen[T] = true
+	if hasMethod(T, "MarshalJSON") || hasMethod(T, "MarshalText") {
+		return ""
+	}
+	where := "it"
+	if strings.HasPrefix(path, ".") {
+		where = "its field " + path[1:]
+	} else if path != "" {
+		where = "its element " + path
+	}
+	switch U := T.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case U.Info()&types.IsComplex != 0:
+			return fmt.Sprintf("%s is a complex number", where)
+		case U.Kind() == types.UnsafePointer:
+			return fmt.Sprintf("%s is an unsafe.Pointer", where)
+		}
+	case *types.Chan:
+		return fmt.Sprintf("%s is a channel", where)
+	case *types.Signature:
+		return fmt.Sprintf("%s is a function", where)
+	case *types.Pointer:
+		return unsupportedJSONType(U.Elem(), path, seen)
+	case *types.Slice:
+		return unsupportedJSONType(U.Elem(), path+"[0]", seen)
+	case *types.Array:
+		return unsupportedJSONType(U.Elem(), path+"[0]", seen)
+	case *types.Map:
+		K := U.Key()
+		if b, ok := K.Underlying().(*types.Basic); !ok || b.Info()&(types.IsString|types.IsInteger) == 0 {
+			if !hasMethod(K, "MarshalText") {
+				return fmt.Sprintf("%s is a map with keys of type %s, which are neither strings, integers nor encoding.TextMarshalers", where, K)
+			}
+		}
+		return unsupportedJSONType(U.Elem(), path+"[k]", seen)
+	case *types.Struct:
+		for i := 0; i < U.NumFields(); i++ {
+			field := U.Field(i)
+			if !field.Exported() {
+				// The fields of unexported embedded structs are
+				// promoted, but unexported fields are ignored.
+				if !field.Anonymous() {
+					continue
+				}
+				T := field.Type()
+				if ptr, ok := T.Underlying().(*types.Pointer); ok {
+					T = ptr.Elem()
+				}
+				if _, ok := T.Underlying().(*types.Struct); !ok {
+					continue
+				}
+			}
+			if reflect.StructTag(U.Tag(i)).Get("json") == "-" {
+				continue
+			}
+			if reason := unsupportedJSONType(field.Type(), path+"."+field.Name(), seen); reason != "" {
+				return reason
+			}
+		}
+	}
+	return ""
+}
+
+// InterfaceParamAddr returns the parameter of interface type whose
+// address v is, or nil.
+func InterfaceParamAddr(v Value) *ssa.Parameter {
+	alloc, ok := v.Value.(*ssa.Alloc)
+	if !ok || !types.IsInterface(alloc.Type().(*types.Pointer).Elem()) {
+		return nil
+	}
+	for _, ref := range *alloc.Referrers() {
+		store, ok := ref.(*ssa.Store)
+		if !ok || store.Addr != alloc {
+			continue
+		}
+		if param, ok := store.Val.(*ssa.Parameter); ok {
+			return param
+		}
+	}
+	return nil
+}
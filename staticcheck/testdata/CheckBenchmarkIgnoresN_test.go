@@ -0,0 +1,30 @@
+package pkg
+
+import "testing"
+
+func BenchmarkFoo(b *testing.B) { // MATCH /benchmark BenchmarkFoo doesn't use b.N/
+	b.ResetTimer()
+	fn()
+}
+
+func BenchmarkBar(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		fn()
+	}
+}
+
+func BenchmarkBaz(b *testing.B) {
+	b.Run("sub", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			fn()
+		}
+	})
+}
+
+func BenchmarkQux(b *testing.B) {
+	helper(b)
+}
+
+func helper(b *testing.B) {}
+
+func fn() {}
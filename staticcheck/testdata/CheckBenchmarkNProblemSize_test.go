@@ -0,0 +1,18 @@
+package pkg
+
+import "testing"
+
+func BenchmarkFoo(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		fn(b.N) // MATCH /b.N should only be used as the number of iterations/
+	}
+}
+
+func BenchmarkBar(b *testing.B) {
+	s := make([]int, b.N)
+	for i := 0; i < b.N; i++ {
+		s[i] = i
+	}
+}
+
+func fn(int) {}
@@ -0,0 +1,72 @@
+package pkg
+
+import (
+	"os"
+	"testing"
+)
+
+func BenchmarkFoo(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		fn()
+	}
+	b.ResetTimer() // MATCH /calling b.ResetTimer after the benchmark loop/
+}
+
+func BenchmarkBar(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		b.ResetTimer() // MATCH /calling b.ResetTimer in the benchmark loop/
+		fn()
+	}
+}
+
+func BenchmarkBaz(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer() // MATCH /without a matching b.StartTimer/
+		fn()
+	}
+}
+
+func BenchmarkQux(b *testing.B) {
+	fn()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		fn()
+		b.StartTimer()
+		fn()
+	}
+}
+
+func fn() {}
+
+func BenchmarkSetup(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		data, _ := os.ReadFile("testdata/input") // MATCH /os.ReadFile is called with the same arguments in every iteration of the benchmark loop/
+		parse(data)
+	}
+}
+
+func BenchmarkSetupStopped(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		data, _ := os.ReadFile("testdata/input")
+		b.StartTimer()
+		parse(data)
+	}
+}
+
+func BenchmarkReadFile(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		os.ReadFile("testdata/input")
+	}
+}
+
+func BenchmarkSetupPerIteration(b *testing.B) {
+	names := []string{"a", "b"}
+	for i := 0; i < b.N; i++ {
+		data, _ := os.ReadFile(names[i%2])
+		parse(data)
+	}
+}
+
+func parse([]byte) {}
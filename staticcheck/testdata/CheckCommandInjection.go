@@ -0,0 +1,26 @@
+package pkg
+
+import (
+	"context"
+	"net/http"
+	"os/exec"
+)
+
+func fn1(r *http.Request) {
+	exec.Command(r.FormValue("cmd"))                                      // MATCH /possible command injection: untrusted data from \(\*net\/http.Request\).FormValue on line 10 is passed to os\/exec.Command/
+	exec.CommandContext(context.Background(), "/bin/"+r.FormValue("cmd")) // MATCH /possible command injection/
+	exec.Command("ls", "--", r.FormValue("dir"))
+	exec.Command("ls", r.URL.Path)
+}
+
+type command struct {
+	name string
+	args []string
+}
+
+func fn2(r *http.Request) {
+	var cmd command
+	cmd.name = "ls"
+	cmd.args = []string{r.FormValue("dir")}
+	exec.Command(cmd.name, cmd.args...)
+}
@@ -0,0 +1,78 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+func fn1() string {
+	var b strings.Builder
+	b2 := b
+	b.WriteString("foo")
+	b3 := b // MATCH /b \(strings.Builder\) is copied by assignment after its first use on line 13; writing to the copy panics/
+	_ = b2
+	_ = b3
+	return b.String()
+}
+
+func fn2() {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "foo")
+	fmt.Println(buf) // MATCH /buf \(bytes.Buffer\) is copied by passing it to fmt.Println after its first use on line 22; the copy shares its contents with the original/
+}
+
+func fn3() bytes.Buffer {
+	buf := &bytes.Buffer{}
+	w := buf
+	w.WriteString("foo")
+	return *buf // MATCH /\*buf \(bytes.Buffer\) is copied by returning it after its first use on line 29/
+}
+
+func fn4(buf *bytes.Buffer) bytes.Buffer {
+	return *buf
+}
+
+type server struct {
+	wg sync.WaitGroup
+}
+
+func (s server) String() string { return "" }
+
+func fn5() {
+	var s server
+	s.wg.Add(1)
+	_ = s.String() // MATCH /s \(server\) is copied by calling its value-receiver method String after its first use on line 45; sync.WaitGroup must not be copied after first use/
+	ch := make(chan server, 1)
+	ch <- s         // MATCH /sending it on a channel/
+	_ = []server{s} // MATCH /a composite literal/
+}
+
+func fn6() {
+	var b strings.Builder
+	b.WriteString("foo")
+	b = strings.Builder{}
+	b2 := b
+	_ = b2
+}
+
+type noCopy struct{}
+
+func (*noCopy) Lock()   {}
+func (*noCopy) Unlock() {}
+
+type T struct {
+	noCopy noCopy
+	n      int
+}
+
+func (t *T) Inc() { t.n++ }
+
+func fn7() {
+	var t T
+	t2 := t
+	t.Inc()
+	var t3 = t // MATCH /t \(T\) is copied by declaration after its first use on line 75; T must not be copied after first use/
+	_, _ = t2, t3
+}
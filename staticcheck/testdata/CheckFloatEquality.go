@@ -0,0 +1,20 @@
+package pkg
+
+import "math"
+
+type celsius float64
+
+func fn(a, b float64, c celsius, f float32, i, j int) {
+	_ = a == b          // MATCH "floating-point values shouldn't be compared with =="
+	_ = a != b          // MATCH "floating-point values shouldn't be compared with !="
+	_ = c == 36.6       // MATCH "floating-point values shouldn't be compared"
+	_ = f == float32(a) // MATCH "floating-point values shouldn't be compared"
+	_ = a == 0
+	_ = 0.0 != b
+	_ = a != a
+	_ = a == math.Inf(1)
+	_ = i == j
+	_ = a < b
+	const x, y = 0.1, 0.2
+	_ = x+y == 0.3
+}
@@ -0,0 +1,43 @@
+package pkg
+
+import (
+	"context"
+	"time"
+)
+
+func fn1(ctx context.Context) {
+	ctx, _ = context.WithCancel(ctx)                 // MATCH /the cancel function returned by context.WithCancel is discarded, which leaks the context until its parent is canceled/
+	ctx2, _ := context.WithTimeout(ctx, time.Second) // MATCH /context.WithTimeout is discarded/
+	_ = ctx2
+}
+
+func fn2(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	_ = ctx
+}
+
+func fn4(ctx context.Context, b bool) error {
+	ctx, cancel := context.WithCancel(ctx)
+	if b {
+		return nil // MATCH /this return may be reached without calling the cancel function returned by context.WithCancel on line 21, which leaks the context/
+	}
+	cancel()
+	return ctx.Err()
+}
+
+func fn5(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithCancel(ctx)
+}
+
+func fn6(ctx context.Context) context.CancelFunc {
+	_, cancel := context.WithCancel(ctx)
+	return cancel
+}
+
+func fn7(ctx context.Context) {
+	_, cancel := context.WithCancel(ctx)
+	go func() {
+		cancel()
+	}()
+}
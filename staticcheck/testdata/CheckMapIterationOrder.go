@@ -0,0 +1,115 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+func keys(m map[string]int) []string {
+	var out []string
+	for k := range m {
+		out = append(out, k)
+	}
+	return out // MATCH "out is built by ranging over a map and isn't sorted, so its order is random"
+}
+
+func sortedKeys(m map[string]int) []string {
+	var out []string
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func sortedKeys2(m map[string]int) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Sort(sort.StringSlice(out))
+	return out
+}
+
+func joined(m map[string]int) string {
+	var out []string
+	for k := range m {
+		out = append(out, k)
+	}
+	if len(out) == 0 {
+		return ""
+	}
+	return strings.Join(out, ",") // MATCH "out is built by ranging over a map"
+}
+
+func marshal(m map[string]int) ([]byte, error) {
+	var out []int
+	for _, v := range m {
+		out = append(out, v)
+	}
+	return json.Marshal(out) // MATCH "out is built by ranging over a map"
+}
+
+func passedOn(m map[string]int) []string {
+	var out []string
+	for k := range m {
+		out = append(out, k)
+	}
+	mySort(out)
+	return out
+}
+
+func mySort([]string) {}
+
+func sum(m map[string]int) int {
+	var out []int
+	for _, v := range m {
+		out = append(out, v)
+	}
+	n := 0
+	for _, v := range out {
+		n += v
+	}
+	return n
+}
+
+func fromSlice(s []string) []string {
+	var out []string
+	for _, k := range s {
+		out = append(out, k)
+	}
+	return out
+}
+
+func write(w io.Writer, m map[string]int) {
+	for k, v := range m {
+		fmt.Fprintf(w, "%s=%d\n", k, v) // MATCH "writing output while ranging over a map produces it in random order"
+	}
+	for k := range m {
+		io.WriteString(w, k) // MATCH "writing output while ranging over a map"
+		w.Write([]byte(k))
+	}
+	enc := json.NewEncoder(w)
+	for _, v := range m {
+		enc.Encode(v) // MATCH "writing output while ranging over a map"
+	}
+}
+
+func lookup(m map[string]int) {
+	n := map[string]int{}
+	for k, v := range m {
+		n[k] = v
+	}
+}
+
+func derived(m map[string]int) []string {
+	var out []string
+	for k, v := range m {
+		out = append(out, fmt.Sprintf("%s=%d", k, v))
+	}
+	// Callers often sort results themselves.
+	return out
+}
@@ -0,0 +1,107 @@
+package pkg
+
+import "sync/atomic"
+
+var counter int64
+
+func fn1() {
+	atomic.AddInt64(&counter, 1)
+	_ = atomic.LoadInt64(&counter)
+}
+
+func fn2() {
+	counter++        // MATCH /counter is accessed atomically at CheckMixedAtomicAccess.go:8, but not here/
+	println(counter) // MATCH /counter is accessed atomically/
+}
+
+type T struct {
+	hits  uint32
+	total uint32
+}
+
+func (t *T) fn3() {
+	atomic.AddUint32(&t.hits, 1)
+	t.total++
+}
+
+func (t *T) fn4() uint32 {
+	return t.hits // MATCH /hits is accessed atomically/
+}
+
+func fn5() *T {
+	return &T{hits: 1}
+}
+
+func load(p *uint32) uint32 { return atomic.LoadUint32(p) }
+
+func (t *T) fn6() uint32 {
+	return load(&t.hits)
+}
+
+var onlyAtomic int32
+
+func fn7() int32 {
+	atomic.StoreInt32(&onlyAtomic, 1)
+	return atomic.LoadInt32(&onlyAtomic)
+}
+
+type S struct {
+	n    int64
+	name string
+}
+
+func (s *S) inc() { atomic.AddInt64(&s.n, 1) }
+
+func register(*S) {}
+
+func NewS() *S {
+	s := &S{}
+	s.n = 5
+	return s
+}
+
+func NewS2() *S {
+	s := new(S)
+	s.n++
+	register(s)
+	s.n = 1 // MATCH /n is accessed atomically/
+	return s
+}
+
+func newS3() S {
+	var s S
+	s.n = 2
+	s.name = "s"
+	return s
+}
+
+func newS4(ch chan *S) {
+	for i := 0; i < 2; i++ {
+		s := &S{}
+		s.n = int64(i)
+		ch <- s
+	}
+}
+
+func newS5(ch chan *S) {
+	s := &S{}
+	for i := 0; i < 2; i++ {
+		s.n = int64(i) // MATCH /n is accessed atomically/
+		ch <- s
+	}
+}
+
+func newS6() *S {
+	s := &S{}
+	go func() { s.inc() }()
+	s.n = 3 // MATCH /n is accessed atomically/
+	return s
+}
+
+func newS7(old *S) *S {
+	s := &S{}
+	s.n = 1
+	s = old
+	s.n = 2 // MATCH /n is accessed atomically/
+	return s
+}
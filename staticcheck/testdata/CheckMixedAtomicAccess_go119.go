@@ -0,0 +1,10 @@
+package pkg
+
+import "sync/atomic"
+
+var flag uint32
+
+func fn1() bool {
+	atomic.StoreUint32(&flag, 1)
+	return flag == 1 // MATCH /flag is accessed atomically at CheckMixedAtomicAccess_go119.go:8, but not here; consider using atomic.Uint32, which only allows atomic access/
+}
@@ -8,3 +8,27 @@ func fn1() {
 func fn2(m map[int]int) {
 	m[1] = 1
 }
+
+func fn3(m map[int]int) {
+	if m == nil {
+		m[1] = 1 // MATCH /assignment to nil map/
+	}
+	if m != nil {
+		m[1] = 1
+	}
+}
+
+func fn4(m map[int]int) {
+	if m == nil {
+		m = map[int]int{}
+	}
+	m[1] = 1
+}
+
+func fn5(b bool) {
+	var m map[int]int
+	if b {
+		m = map[int]int{}
+	}
+	m[1] = 1
+}
@@ -0,0 +1,23 @@
+package pkg
+
+func fn1(b bool) {
+	x := 300
+	if b {
+		x = 400
+	}
+	_ = byte(x) // MATCH /conversion of value in range \[300, 400\] to byte always overflows/
+
+	y := -1
+	_ = uint(y) // MATCH /always overflows/
+
+	z := 200
+	_ = int8(z) // MATCH /always overflows/
+	_ = uint8(z)
+}
+
+func fn2(x int) {
+	_ = byte(x)
+	if x > 1000 {
+		_ = int16(x)
+	}
+}
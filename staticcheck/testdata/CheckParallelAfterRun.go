@@ -0,0 +1,17 @@
+package pkg
+
+import "testing"
+
+func TestFoo(t *testing.T) {
+	t.Run("sub", func(t *testing.T) {
+		t.Parallel()
+	})
+	t.Parallel() // MATCH /t.Parallel is called after subtests have been started with t.Run/
+}
+
+func TestBar(t *testing.T) {
+	t.Parallel()
+	t.Run("sub", func(t *testing.T) {
+		t.Parallel()
+	})
+}
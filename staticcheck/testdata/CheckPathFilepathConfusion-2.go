@@ -0,0 +1,17 @@
+package pkg
+
+import (
+	"os"
+	pth "path"
+)
+
+func fn3() string {
+	wd, _ := os.Getwd()
+	return pth.Base(wd) // MATCH /path.Base is passed the operating system path returned by os.Getwd on line 9, but only understands slashes; use filepath.Base instead/
+}
+
+func fn4(filepath string) string {
+	// filepath is taken, the call can't be rewritten.
+	wd, _ := os.Getwd()
+	return pth.Dir(wd) + filepath // MATCH /use filepath.Dir instead/
+}
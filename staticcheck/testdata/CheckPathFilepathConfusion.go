@@ -0,0 +1,27 @@
+package pkg
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+func fn1() {
+	wd, _ := os.Getwd()
+	path.Join(wd, "foo") // MATCH /path.Join is passed the operating system path returned by os.Getwd on line 12, but only understands slashes; use filepath.Join instead/
+	dir := filepath.Join(wd, "foo")
+	_ = path.Dir(dir + "/bar") // MATCH /path.Dir is passed the operating system path returned by path\/filepath.Join on line 14/
+	_ = path.Join("a", filepath.ToSlash(dir))
+	_ = path.Join("a", filepath.Base(dir))
+	_ = path.Join("a", "b")
+}
+
+func fn2(r *http.Request, u url.URL) {
+	_ = filepath.Clean(r.URL.Path) // MATCH /filepath.Clean is passed the URL path url.URL.Path, which is separated by slashes on all systems; use path.Clean instead/
+	_ = filepath.Base(u.Path)      // MATCH /use path.Base instead/
+	_ = filepath.Join("/srv", r.URL.Path)
+	_ = filepath.FromSlash(r.URL.Path)
+	_ = path.Clean(r.URL.Path)
+}
@@ -9,7 +9,7 @@ func TestFoo(t *testing.T) {
 	strings.Replace("", "", "", 1) // MATCH /is a pure function but its return value is ignored/
 }
 
-func BenchmarkFoo(b *testing.B) {
+func BenchmarkFoo(b *testing.B) { // MATCH /doesn't use b.N/
 	strings.Replace("", "", "", 1)
 }
 
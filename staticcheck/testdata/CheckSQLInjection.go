@@ -0,0 +1,28 @@
+package pkg
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+func fn1(db *sql.DB, r *http.Request) {
+	name := r.FormValue("name")
+	db.Query("SELECT * FROM users WHERE name = '" + name + "'") // MATCH /possible SQL injection: untrusted data from \(\*net\/http.Request\).FormValue on line 11 is passed to \(\*database\/sql.DB\).Query/
+	db.Query("SELECT * FROM users WHERE name = ?", name)
+
+	q := fmt.Sprintf("DELETE FROM users WHERE id = %s", r.URL.Query().Get("id"))
+	db.Exec(q) // MATCH /possible SQL injection/
+
+	id, _ := strconv.Atoi(r.FormValue("id"))
+	db.Exec(fmt.Sprintf("DELETE FROM users WHERE id = %d", id))
+}
+
+func fn2(tx *sql.Tx, r *http.Request, b bool) {
+	table := "users"
+	if b {
+		table = r.PostFormValue("table")
+	}
+	tx.QueryRow("SELECT count(*) FROM " + table) // MATCH /possible SQL injection/
+}
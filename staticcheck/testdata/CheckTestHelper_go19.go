@@ -0,0 +1,47 @@
+package pkg
+
+import "testing"
+
+func assertEqual(t *testing.T, a, b int) { // MATCH /assertEqual calls t.Errorf but not t.Helper/
+	if a != b {
+		t.Errorf("%d != %d", a, b)
+	}
+}
+
+func mustBeTrue(tb testing.TB, v bool) {
+	tb.Helper()
+	if !v {
+		tb.Fatal("not true")
+	}
+}
+
+func logOnly(t *testing.T) {
+	t.Log("foo")
+}
+
+func TestFoo(t *testing.T) {
+	assertEqual(t, 1, 1)
+	mustBeTrue(t, true)
+	logOnly(t)
+	t.Fatal("foo")
+}
+
+func testSubtest(t *testing.T) {
+	t.Fatal("subtests report their own failures")
+}
+
+func TestRun(t *testing.T) {
+	t.Run("sub", testSubtest)
+}
+
+func check(t *testing.T) {
+	t.Error("check")
+}
+
+func TestCheckAsValue(t *testing.T) {
+	fns := []func(*testing.T){check}
+	for _, fn := range fns {
+		fn(t)
+	}
+	check(t)
+}
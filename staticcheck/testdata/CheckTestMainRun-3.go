@@ -0,0 +1,17 @@
+package pkg
+
+import (
+	"flag"
+	"os"
+	"testing"
+)
+
+var short = flag.Bool("short-setup", false, "")
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if *short {
+		os.Exit(0) // MATCH /TestMain doesn't call m.Run on all paths/
+	}
+	os.Exit(m.Run())
+}
@@ -0,0 +1,17 @@
+package pkg
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(run(m))
+}
+
+func run(m *testing.M) int {
+	setup()
+	return m.Run()
+}
+
+func setup() {}
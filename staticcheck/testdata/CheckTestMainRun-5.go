@@ -0,0 +1,20 @@
+package pkg
+
+import (
+	"os"
+	"testing"
+)
+
+type suite struct {
+	m *testing.M
+}
+
+func (s *suite) run() int { return s.m.Run() }
+
+func TestMain(m *testing.M) {
+	s := &suite{m: m}
+	if len(os.Args) > 5 {
+		os.Exit(2)
+	}
+	os.Exit(s.run())
+}
@@ -0,0 +1,13 @@
+package pkg
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) { // MATCH /TestMain calls os.Exit but never calls m.Run/
+	setup()
+	os.Exit(0)
+}
+
+func setup() {}
@@ -0,0 +1,39 @@
+package pkg
+
+import "time"
+
+type event struct {
+	name string
+	at   time.Time
+}
+
+type ref struct {
+	at *time.Time
+}
+
+func fn(a, b time.Time, e1, e2 event, r1, r2 ref, i interface{}) {
+	_ = a == b           // MATCH "should use a.Equal(b) instead of ==, which also compares locations and monotonic clock readings"
+	_ = a != b           // MATCH "should use !a.Equal(b) instead of !="
+	_ = a == time.Time{} // MATCH "should use a.IsZero() instead of comparing with the zero time.Time using =="
+	_ = time.Time{} != b // MATCH "should use !b.IsZero() instead"
+	_ = e1 == e2         // MATCH "comparing values of type event compares their time.Time field at with =="
+	_ = r1 == r2
+	_ = i == a
+	_ = a.Equal(b)
+
+	var m map[time.Time]int // MATCH "time.Time as map key"
+	_ = m
+	var m2 map[event]bool // MATCH "map key contains time.Time in field at"
+	_ = m2
+	var m3 map[int64]time.Time
+	_ = m3
+}
+
+func fn2(p *time.Time, ts []time.Time, f func() time.Time, e event) {
+	_ = *p == ts[0]         // MATCH "should use (*p).Equal(ts[0]) instead of =="
+	_ = f() != *p           // MATCH "should use !f().Equal(*p) instead of !="
+	_ = *p == time.Time{}   // MATCH "should use (*p).IsZero() instead"
+	_ = e.at == time.Time{} // MATCH "should use e.at.IsZero() instead"
+	_ = time.Time{} == e.at // MATCH "should use e.at.IsZero() instead"
+	_ = (*p) == ts[1]       // MATCH "should use (*p).Equal(ts[1]) instead of =="
+}
@@ -14,5 +14,16 @@ func fn1(i3 interface{}) {
 	json.Unmarshal([]byte(`{}`), i3)
 	json.Unmarshal([]byte(`{}`), p)
 
-	json.NewDecoder(nil).Decode(v) // MATCH /Decode expects to unmarshal into a pointer/
+	json.NewDecoder(nil).Decode(v)    // MATCH /Decode expects to unmarshal into a pointer/
+	json.Unmarshal([]byte(`{}`), nil) // MATCH /json.Unmarshal expects to unmarshal into a pointer, but the provided value is nil/
+	var np *map[string]interface{}
+	json.Unmarshal([]byte(`{}`), np) // MATCH /provided value is nil/
+}
+
+func fn2(v interface{}, data []byte) {
+	json.Unmarshal(data, &v) // MATCH /json.Unmarshal is passed a pointer to the interface parameter v and will replace v instead of unmarshaling into the value it holds; pass v instead/
+	json.Unmarshal(data, v)
+
+	var generic interface{}
+	json.Unmarshal(data, &generic)
 }
@@ -0,0 +1,61 @@
+package pkg
+
+import (
+	"encoding/json"
+	"os"
+)
+
+type T1 struct {
+	A  int
+	Ch chan int
+}
+
+type T2 struct {
+	A  int
+	Ch chan int `json:"-"`
+	fn func()
+}
+
+type T3 struct {
+	Fn func()
+}
+
+func (T3) MarshalJSON() ([]byte, error) { return nil, nil }
+
+type key struct{ a, b int }
+
+type T4 struct {
+	Points []map[key]int
+}
+
+type textKey struct{ a, b int }
+
+func (textKey) MarshalText() ([]byte, error) { return nil, nil }
+
+type T5 struct {
+	*T5
+	M map[textKey]int
+	N map[int]string
+}
+
+type inner struct {
+	C complex128
+}
+
+type T6 struct {
+	inner
+}
+
+func fn(i interface{}) {
+	json.Marshal(T1{})  // MATCH /json.Marshal cannot marshal values of type .*T1: its field Ch is a channel/
+	json.Marshal(&T1{}) // MATCH /its field Ch is a channel/
+	json.Marshal(T2{})
+	json.Marshal(T3{})
+	json.MarshalIndent(T4{}, "", "\t") // MATCH /json.MarshalIndent cannot marshal values of type .*T4: its field Points\[0\] is a map with keys of type .*key, which are neither strings, integers nor encoding.TextMarshalers/
+	json.Marshal(T5{})
+	json.Marshal(T6{})                      // MATCH /its field inner.C is a complex number/
+	json.Marshal(func() {})                 // MATCH /it is a function/
+	json.NewEncoder(os.Stdout).Encode(T1{}) // MATCH /Encode cannot marshal/
+	json.Marshal(i)
+	json.Marshal([]interface{}{1})
+}
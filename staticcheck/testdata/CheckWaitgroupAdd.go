@@ -19,7 +19,7 @@ func fn() {
 	wg.Add(1)
 	go func(wg sync.WaitGroup) {
 		wg.Done()
-	}(wg)
+	}(wg) // MATCH /wg \(sync.WaitGroup\) is copied by passing it to a function literal after its first use on line 9/
 
 	wg.Add(1)
 	go func(wg *sync.WaitGroup) {
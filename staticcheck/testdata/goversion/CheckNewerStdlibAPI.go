@@ -0,0 +1,31 @@
+package pkg
+
+import (
+	"errors"
+	"io/fs" // MATCH "package io/fs was added in Go 1.16, but the module declares Go 1.12"
+	"io/ioutil"
+	"math/bits"
+	. "os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func fn(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("")
+	_ = b.Cap()
+	_ = strings.ReplaceAll("", "", "")
+	_ = bits.UintSize
+	var _ fs.FileInfo
+
+	err := errors.New("")
+	_ = errors.Is(err, err)             // MATCH "errors.Is was added in Go 1.13, but the module declares Go 1.12"
+	_, _ = ioutil.ReadAll(nil)          // deprecated as of Go 1.16, after the declared version
+	_ = time.Duration(0).Milliseconds() // MATCH "time.Duration.Milliseconds was added in Go 1.13"
+	t.Cleanup(func() {})                // MATCH "testing.T.Cleanup was added in Go 1.14"
+	var tb testing.TB = t
+	tb.Helper()
+	_, _ = ReadFile("") // MATCH "os.ReadFile was added in Go 1.16"
+	_, _ = UserHomeDir()
+}
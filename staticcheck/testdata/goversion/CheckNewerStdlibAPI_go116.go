@@ -0,0 +1,15 @@
+//go:build go1.16 && !go1.99
+
+package pkg
+
+import (
+	"io/fs"
+	"os"
+	"strings"
+)
+
+func fn2() {
+	var _ fs.FileInfo
+	_, _ = os.ReadFile("")
+	_, _, _ = strings.Cut("", "") // MATCH "strings.Cut was added in Go 1.18, but the file's build constraints require Go 1.16"
+}
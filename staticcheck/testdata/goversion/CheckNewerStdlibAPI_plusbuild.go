@@ -0,0 +1,14 @@
+// +build linux,go1.16 darwin,go1.17
+// +build !windows
+
+package pkg
+
+import (
+	"io/fs"
+	"math"
+)
+
+func fn3() {
+	var _ fs.FileInfo
+	_ = math.MaxInt // MATCH "math.MaxInt was added in Go 1.17, but the file's build constraints require Go 1.16"
+}
@@ -0,0 +1,11 @@
+package pkg
+
+import (
+	"net/http"
+	"os/exec"
+)
+
+func fn(r *http.Request) {
+	exec.Command("sh", "-c", "ls "+r.FormValue("dir")) // MATCH /possible command injection/
+	exec.Command("ls", r.FormValue("dir"))             // MATCH /possible command injection/
+}
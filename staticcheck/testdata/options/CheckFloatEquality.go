@@ -0,0 +1,5 @@
+package pkg
+
+func fn(a, b float64) bool {
+	return a == b // MATCH "floating-point values shouldn't be compared"
+}
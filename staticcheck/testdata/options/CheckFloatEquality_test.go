@@ -0,0 +1,5 @@
+package pkg
+
+func fn2(got, want float64) bool {
+	return got == want
+}
@@ -0,0 +1,13 @@
+package pkg
+
+import "encoding/json"
+
+func decode(data []byte, v interface{}) error { return nil }
+
+func fn() {
+	var m map[string]int
+	decode(nil, m) // MATCH /decode expects to unmarshal into a pointer, but the provided value is not a pointer/
+	decode(nil, &m)
+	json.Unmarshal(nil, m)         // MATCH /json.Unmarshal expects to unmarshal into a pointer/
+	json.NewDecoder(nil).Decode(m) // not in the configured functions
+}
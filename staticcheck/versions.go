@@ -0,0 +1,374 @@
+package staticcheck
+
+import (
+	"go/ast"
+	"strconv"
+	"strings"
+)
+
+// stdlibVersions maps packages and identifiers of the standard library
+// to the minor Go versions that added them. Packages are keyed by
+// their import paths, package-level identifiers as in strings.Builder
+// and methods and fields by their types, as in
+// net/http.Request.Context. Identifiers that were added at the same
+// time as their packages or types aren't listed.
+var stdlibVersions = map[string]int{
+	"context":            7,
+	"net/http/httptrace": 7,
+	"plugin":             8,
+	"math/bits":          9,
+	"hash/maphash":       14,
+	"time/tzdata":        15,
+	"embed":              16,
+	"io/fs":              16,
+	"runtime/metrics":    16,
+	"debug/buildinfo":    18,
+	"net/netip":          18,
+	"crypto/ecdh":        20,
+	"cmp":                21,
+	"log/slog":           21,
+	"maps":               21,
+	"slices":             21,
+	"testing/slogtest":   21,
+	"go/version":         22,
+	"math/rand/v2":       22,
+
+	"net/http.Request.Context":     7,
+	"net/http.Request.WithContext": 7,
+	"testing.B.Run":                7,
+	"testing.T.Run":                7,
+
+	"database/sql.DB.BeginTx":         8,
+	"database/sql.DB.ExecContext":     8,
+	"database/sql.DB.PingContext":     8,
+	"database/sql.DB.PrepareContext":  8,
+	"database/sql.DB.QueryContext":    8,
+	"database/sql.DB.QueryRowContext": 8,
+	"database/sql.Named":              8,
+	"net/http.Pusher":                 8,
+	"net/http.Server.Shutdown":        8,
+	"os.Executable":                   8,
+	"sort.Slice":                      8,
+	"sort.SliceIsSorted":              8,
+	"sort.SliceStable":                8,
+	"time.Until":                      8,
+
+	"database/sql.DB.Conn": 9,
+	"sync.Map":             9,
+	"testing.B.Helper":     9,
+	"testing.T.Helper":     9,
+	"testing.TB.Helper":    9,
+
+	"encoding/json.Decoder.DisallowUnknownFields": 10,
+	"math.Round":                  10,
+	"math.RoundToEven":            10,
+	"os.IsTimeout":                10,
+	"strings.Builder":             10,
+	"time.LoadLocationFromTZData": 10,
+
+	"net/http.SameSite": 11,
+	"os.UserCacheDir":   11,
+
+	"bytes.ReplaceAll":            12,
+	"io.StringWriter":             12,
+	"os.ProcessState.ExitCode":    12,
+	"os.UserHomeDir":              12,
+	"reflect.MapIter":             12,
+	"reflect.Value.MapRange":      12,
+	"runtime/debug.BuildInfo":     12,
+	"runtime/debug.ReadBuildInfo": 12,
+	"strings.Builder.Cap":         12,
+	"strings.ReplaceAll":          12,
+
+	"bytes.ToValidUTF8":          13,
+	"database/sql.NullInt32":     13,
+	"database/sql.NullTime":      13,
+	"errors.As":                  13,
+	"errors.Is":                  13,
+	"errors.Unwrap":              13,
+	"net/http.Request.Clone":     13,
+	"os.UserConfigDir":           13,
+	"reflect.Value.IsZero":       13,
+	"strings.ToValidUTF8":        13,
+	"testing.B.ReportMetric":     13,
+	"time.Duration.Microseconds": 13,
+	"time.Duration.Milliseconds": 13,
+
+	"math.FMA":                14,
+	"net/http.Header.Values":  14,
+	"strconv.NumError.Unwrap": 14,
+	"testing.B.Cleanup":       14,
+	"testing.T.Cleanup":       14,
+	"testing.TB.Cleanup":      14,
+
+	"database/sql.DB.SetConnMaxIdleTime": 15,
+	"net/url.URL.Redacted":               15,
+	"strconv.FormatComplex":              15,
+	"strconv.ParseComplex":               15,
+	"testing.B.TempDir":                  15,
+	"testing.T.Deadline":                 15,
+	"testing.T.TempDir":                  15,
+	"testing.TB.TempDir":                 15,
+	"time.Ticker.Reset":                  15,
+
+	"html/template.ParseFS":   16,
+	"io.Discard":              16,
+	"io.NopCloser":            16,
+	"io.ReadAll":              16,
+	"io.ReadSeekCloser":       16,
+	"net.ErrClosed":           16,
+	"net/http.FS":             16,
+	"os.CreateTemp":           16,
+	"os.DirEntry":             16,
+	"os.DirFS":                16,
+	"os.MkdirTemp":            16,
+	"os.ReadDir":              16,
+	"os.ReadFile":             16,
+	"os.WriteFile":            16,
+	"os/signal.NotifyContext": 16,
+	"path/filepath.WalkDir":   16,
+	"text/template.ParseFS":   16,
+
+	"math.MaxInt":                      17,
+	"math.MaxUint":                     17,
+	"math.MinInt":                      17,
+	"net/url.Values.Has":               17,
+	"reflect.Value.CanConvert":         17,
+	"reflect.VisibleFields":            17,
+	"runtime/cgo.Handle":               17,
+	"runtime/cgo.NewHandle":            17,
+	"strconv.QuotedPrefix":             17,
+	"sync/atomic.Value.CompareAndSwap": 17,
+	"sync/atomic.Value.Swap":           17,
+	"testing.B.Setenv":                 17,
+	"testing.T.Setenv":                 17,
+	"testing.TB.Setenv":                17,
+	"time.Time.IsDST":                  17,
+	"time.Time.UnixMicro":              17,
+	"time.Time.UnixMilli":              17,
+	"time.UnixMicro":                   17,
+	"time.UnixMilli":                   17,
+
+	"bytes.Cut":                18,
+	"net/http.MaxBytesHandler": 18,
+	"reflect.Pointer":          18,
+	"reflect.PointerTo":        18,
+	"strings.Clone":            18,
+	"strings.Cut":              18,
+	"sync.Mutex.TryLock":       18,
+	"sync.RWMutex.TryLock":     18,
+	"sync.RWMutex.TryRLock":    18,
+	"testing.F":                18,
+
+	"fmt.Append":                   19,
+	"fmt.Appendf":                  19,
+	"fmt.Appendln":                 19,
+	"net/http.MaxBytesError":       19,
+	"net/url.JoinPath":             19,
+	"net/url.URL.JoinPath":         19,
+	"runtime/debug.SetMemoryLimit": 19,
+	"sort.Find":                    19,
+	"sync/atomic.Bool":             19,
+	"sync/atomic.Int32":            19,
+	"sync/atomic.Int64":            19,
+	"sync/atomic.Pointer":          19,
+	"sync/atomic.Uint32":           19,
+	"sync/atomic.Uint64":           19,
+	"sync/atomic.Uintptr":          19,
+	"time.Duration.Abs":            19,
+	"time.Time.ZoneBounds":         19,
+
+	"bytes.Clone":                    20,
+	"bytes.CutPrefix":                20,
+	"bytes.CutSuffix":                20,
+	"context.CancelCauseFunc":        20,
+	"context.Cause":                  20,
+	"context.WithCancelCause":        20,
+	"errors.Join":                    20,
+	"io.NewOffsetWriter":             20,
+	"io.OffsetWriter":                20,
+	"net/http.NewResponseController": 20,
+	"net/http.ResponseController":    20,
+	"strings.CutPrefix":              20,
+	"strings.CutSuffix":              20,
+	"sync.Map.CompareAndDelete":      20,
+	"sync.Map.CompareAndSwap":        20,
+	"sync.Map.Swap":                  20,
+	"testing.B.Elapsed":              20,
+	"time.DateOnly":                  20,
+	"time.DateTime":                  20,
+	"time.Time.Compare":              20,
+	"time.TimeOnly":                  20,
+	"unsafe.SliceData":               20,
+	"unsafe.String":                  20,
+	"unsafe.StringData":              20,
+
+	"bytes.ContainsFunc":        21,
+	"context.AfterFunc":         21,
+	"context.WithDeadlineCause": 21,
+	"context.WithTimeoutCause":  21,
+	"context.WithoutCancel":     21,
+	"errors.ErrUnsupported":     21,
+	"strings.ContainsFunc":      21,
+	"sync.OnceFunc":             21,
+	"sync.OnceValue":            21,
+	"sync.OnceValues":           21,
+	"testing.Testing":           21,
+
+	"cmp.Or":                        22,
+	"database/sql.Null":             22,
+	"net/http.Request.PathValue":    22,
+	"net/http.Request.SetPathValue": 22,
+	"reflect.TypeFor":               22,
+	"slices.Concat":                 22,
+}
+
+// constraintVersion returns the minor Go version that the build
+// constraints of f require, or 0 if they don't require any. A file
+// constrained to go1.16 only builds with Go 1.16 and later and may
+// use its APIs, regardless of the module's go directive. Both the
+// //go:build and the // +build forms are understood.
+func constraintVersion(f *ast.File) int {
+	version := 0
+	for _, cg := range f.Comments {
+		if cg.Pos() >= f.Package {
+			break
+		}
+		for _, c := range cg.List {
+			var v int
+			switch {
+			case strings.HasPrefix(c.Text, "//go:build "):
+				p := &constraintParser{toks: constraintTokens(c.Text[len("//go:build "):])}
+				v = p.or()
+			case strings.HasPrefix(c.Text, "// +build "):
+				v = plusBuildVersion(strings.Fields(c.Text[len("// +build "):]))
+			}
+			if v > version {
+				version = v
+			}
+		}
+	}
+	return version
+}
+
+// tagVersion returns N for the build tag go1.N and 0 for any other
+// tag.
+func tagVersion(tag string) int {
+	if !strings.HasPrefix(tag, "go1.") {
+		return 0
+	}
+	v, err := strconv.Atoi(tag[len("go1."):])
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// plusBuildVersion returns the version required by the options of a
+// // +build line. The options are ORed, their comma-separated terms
+// ANDed.
+func plusBuildVersion(options []string) int {
+	version := -1
+	for _, opt := range options {
+		v := 0
+		for _, term := range strings.Split(opt, ",") {
+			if tv := tagVersion(term); tv > v {
+				v = tv
+			}
+		}
+		if version == -1 || v < version {
+			version = v
+		}
+	}
+	if version == -1 {
+		return 0
+	}
+	return version
+}
+
+func constraintTokens(expr string) []string {
+	var toks []string
+	for i := 0; i < len(expr); {
+		switch c := expr[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')' || c == '!':
+			toks = append(toks, expr[i:i+1])
+			i++
+		case strings.HasPrefix(expr[i:], "&&") || strings.HasPrefix(expr[i:], "||"):
+			toks = append(toks, expr[i:i+2])
+			i += 2
+		default:
+			j := i
+			for j < len(expr) && strings.IndexByte(" \t()!&|", expr[j]) == -1 {
+				j++
+			}
+			if j == i {
+				// A lone & or |; the constraint is malformed.
+				return nil
+			}
+			toks = append(toks, expr[i:j])
+			i = j
+		}
+	}
+	return toks
+}
+
+// constraintParser computes the version required by a //go:build
+// expression: the highest version of ANDed operands and the lowest of
+// ORed ones. Negated operands don't require any version.
+type constraintParser struct {
+	toks []string
+}
+
+func (p *constraintParser) next() string {
+	if len(p.toks) == 0 {
+		return ""
+	}
+	tok := p.toks[0]
+	p.toks = p.toks[1:]
+	return tok
+}
+
+func (p *constraintParser) peek() string {
+	if len(p.toks) == 0 {
+		return ""
+	}
+	return p.toks[0]
+}
+
+func (p *constraintParser) or() int {
+	v := p.and()
+	for p.peek() == "||" {
+		p.next()
+		if w := p.and(); w < v {
+			v = w
+		}
+	}
+	return v
+}
+
+func (p *constraintParser) and() int {
+	v := p.not()
+	for p.peek() == "&&" {
+		p.next()
+		if w := p.not(); w > v {
+			v = w
+		}
+	}
+	return v
+}
+
+func (p *constraintParser) not() int {
+	switch tok := p.next(); tok {
+	case "!":
+		p.not()
+		return 0
+	case "(":
+		v := p.or()
+		p.next()
+		return v
+	default:
+		return tagVersion(tok)
+	}
+}
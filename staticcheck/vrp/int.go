@@ -193,6 +193,27 @@ func InfinityFor(v ssa.Value) IntInterval {
 	return NewIntInterval(NInfinity, PInfinity)
 }
 
+// IntervalForType returns the interval of values that the integer type
+// T can represent. int, uint and uintptr are assumed to be 64 bits
+// wide.
+func IntervalForType(T types.Type) (IntInterval, bool) {
+	b, ok := T.Underlying().(*types.Basic)
+	if !ok || (b.Info()&types.IsInteger) == 0 || (b.Info()&types.IsUntyped) != 0 {
+		return IntInterval{}, false
+	}
+	s := &types.StdSizes{WordSize: 8, MaxAlign: 1}
+	bits := uint(s.Sizeof(b) * 8)
+	if (b.Info() & types.IsUnsigned) != 0 {
+		max := new(big.Int).Lsh(big.NewInt(1), bits)
+		max.Sub(max, big.NewInt(1))
+		return NewIntInterval(NewZ(0), NewBigZ(max)), true
+	}
+	max := new(big.Int).Lsh(big.NewInt(1), bits-1)
+	min := new(big.Int).Neg(max)
+	max.Sub(max, big.NewInt(1))
+	return NewIntInterval(NewBigZ(min), NewBigZ(max)), true
+}
+
 type IntInterval struct {
 	known bool
 	Lower Z
@@ -665,6 +665,36 @@ func (r Ranges) Get(x ssa.Value) Range {
 	return i
 }
 
+// Int returns the range of the integer value x, if it is known and
+// not empty.
+func (r Ranges) Int(x ssa.Value) (IntInterval, bool) {
+	i, ok := r.Get(x).(IntInterval)
+	if !ok || !i.IsKnown() || i.Empty() {
+		return IntInterval{}, false
+	}
+	return i, true
+}
+
+// String returns the range of the lengths of the string value x, if it
+// is known and not empty.
+func (r Ranges) String(x ssa.Value) (StringInterval, bool) {
+	i, ok := r.Get(x).(StringInterval)
+	if !ok || !i.IsKnown() || i.Length.Empty() {
+		return StringInterval{}, false
+	}
+	return i, true
+}
+
+// Slice returns the range of the lengths of the slice value x, if it
+// is known and not empty.
+func (r Ranges) Slice(x ssa.Value) (SliceInterval, bool) {
+	i, ok := r.Get(x).(SliceInterval)
+	if !ok || !i.IsKnown() || i.Length.Empty() {
+		return SliceInterval{}, false
+	}
+	return i, true
+}
+
 type Graph struct {
 	Vertices map[interface{}]*Vertex
 	Edges    []Edge
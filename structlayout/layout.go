@@ -10,6 +10,16 @@ type Field struct {
 	Size      int64  `json:"size"`
 	Align     int64  `json:"align"`
 	IsPadding bool   `json:"is_padding"`
+
+	// Group identifies the group of related fields that the field
+	// belongs to, such as fields separated from other fields by
+	// blank lines in the source. Zero means that the group is unknown.
+	Group int `json:"group,omitempty"`
+
+	// Pos is the position of the field's declaration, in the form
+	// file:line:column. Fields of nested structs have the position of
+	// the top-level field.
+	Pos string `json:"pos,omitempty"`
 }
 
 func (f Field) String() string {
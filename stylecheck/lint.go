@@ -0,0 +1,1060 @@
+// Copyright (c) 2013 The Go Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file or at
+// https://developers.google.com/open-source/licenses/bsd.
+
+// Package stylecheck contains a linter that enforces style rules.
+package stylecheck // import "honnef.co/go/tools/stylecheck"
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"honnef.co/go/tools/lint"
+)
+
+type Checker struct {
+	CheckGenerated bool
+}
+
+func NewChecker() *Checker {
+	return &Checker{}
+}
+
+func (c *Checker) Init(prog *lint.Program) {}
+
+func (c *Checker) Funcs() map[string]lint.Func {
+	return map[string]lint.Func{
+		"ST1003": c.CheckNames,
+		"ST1006": c.CheckReceiverNames,
+		"ST1012": c.CheckErrorVarNames,
+		"ST1013": c.CheckErrorTypeNames,
+		"ST1014": c.CheckLocalSentinelErrors,
+		"ST1016": c.CheckReceiverNamesIdentical,
+		"ST1017": c.CheckPackageNames,
+		"ST1018": c.CheckPackageNameStutter,
+		"ST1020": c.CheckExportedDocs,
+		"ST1021": c.CheckDocCommentForm,
+		"ST1022": c.CheckNarrowInterfaceParams,
+	}
+}
+
+func (c *Checker) Options() map[string][]lint.Option {
+	return map[string][]lint.Option{
+		"ST1006": {
+			{Name: "max_receiver_name_length", Default: 5, Doc: "Receiver names longer than this are reported"},
+		},
+	}
+}
+
+func (c *Checker) filterGenerated(files []*ast.File) []*ast.File {
+	if c.CheckGenerated {
+		return files
+	}
+	var out []*ast.File
+	for _, f := range files {
+		if !lint.IsGenerated(f) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// lintName returns the name that name should be spelled as, splitting
+// it at underscores and case changes and spelling the words that are
+// initialisms in a consistent case.
+func lintName(name string, initialisms map[string]bool) string {
+	if name == "_" {
+		return name
+	}
+	allLower := true
+	for _, r := range name {
+		if !unicode.IsLower(r) {
+			allLower = false
+			break
+		}
+	}
+	if allLower {
+		return name
+	}
+
+	runes := []rune(name)
+	// w is the start of the current word, i the scan position
+	w, i := 0, 0
+	for i+1 <= len(runes) {
+		eow := false
+		if i+1 == len(runes) {
+			eow = true
+		} else if runes[i+1] == '_' {
+			// Remove the run of underscores, but leave one between
+			// two digits.
+			eow = true
+			n := 1
+			for i+n+1 < len(runes) && runes[i+n+1] == '_' {
+				n++
+			}
+			if i+n+1 < len(runes) && unicode.IsDigit(runes[i]) && unicode.IsDigit(runes[i+n+1]) {
+				n--
+			}
+			copy(runes[i+1:], runes[i+n+1:])
+			runes = runes[:len(runes)-n]
+		} else if unicode.IsLower(runes[i]) && !unicode.IsLower(runes[i+1]) {
+			eow = true
+		}
+		i++
+		if !eow {
+			continue
+		}
+
+		word := string(runes[w:i])
+		if u := strings.ToUpper(word); initialisms[u] {
+			// Keep the case consistent, which is lowercase only at
+			// the start of the name.
+			if w == 0 && unicode.IsLower(runes[w]) {
+				u = strings.ToLower(u)
+			}
+			copy(runes[w:], []rune(u))
+		} else if w > 0 && strings.ToLower(word) == word {
+			runes[w] = unicode.ToUpper(runes[w])
+		}
+		w = i
+	}
+	return string(runes)
+}
+
+func isAllCaps(name string) bool {
+	hasLetter := false
+	for _, r := range name {
+		switch {
+		case unicode.IsUpper(r):
+			hasLetter = true
+		case r == '_' || unicode.IsDigit(r):
+		default:
+			return false
+		}
+	}
+	return hasLetter
+}
+
+func isCgoExported(fn *ast.FuncDecl) bool {
+	if fn.Recv != nil || fn.Doc == nil {
+		return false
+	}
+	for _, c := range fn.Doc.List {
+		if strings.HasPrefix(c.Text, "//export ") {
+			return true
+		}
+	}
+	return false
+}
+
+func isTestFunc(name string) bool {
+	for _, prefix := range []string{"Test", "Benchmark", "Example"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Checker) CheckNames(j *lint.Job) {
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		pkg := j.NodePackage(f)
+		initialisms := map[string]bool{}
+		for _, s := range pkg.Config.Initialisms {
+			initialisms[s] = true
+		}
+
+		check := func(id *ast.Ident, thing string) {
+			if id == nil || id.Name == "_" {
+				return
+			}
+			if len(id.Name) >= 5 && isAllCaps(id.Name) && strings.Contains(id.Name, "_") {
+				j.Errorf(id, "should not use ALL_CAPS in Go names; use CamelCase instead")
+				return
+			}
+			should := lintName(id.Name, initialisms)
+			if id.Name == should {
+				return
+			}
+			if len(id.Name) > 2 && strings.Contains(id.Name[1:len(id.Name)-1], "_") {
+				j.Errorf(id, "should not use underscores in Go names; %s %s should be %s", thing, id.Name, should)
+				return
+			}
+			j.Errorf(id, "%s %s should be %s", thing, id.Name, should)
+		}
+		checkList := func(fl *ast.FieldList, thing string) {
+			if fl == nil {
+				return
+			}
+			for _, field := range fl.List {
+				for _, name := range field.Names {
+					check(name, thing)
+				}
+			}
+		}
+
+		fn := func(node ast.Node) bool {
+			switch v := node.(type) {
+			case *ast.AssignStmt:
+				if v.Tok != token.DEFINE {
+					return true
+				}
+				for _, expr := range v.Lhs {
+					if id, ok := expr.(*ast.Ident); ok {
+						check(id, "var")
+					}
+				}
+			case *ast.FuncDecl:
+				if j.IsInTest(v) && isTestFunc(v.Name.Name) {
+					return true
+				}
+				if isCgoExported(v) {
+					return true
+				}
+				thing := "func"
+				if v.Recv != nil {
+					thing = "method"
+				}
+				check(v.Name, thing)
+				checkList(v.Recv, "receiver")
+				checkList(v.Type.Params, "func parameter")
+				checkList(v.Type.Results, "func result")
+			case *ast.FuncLit:
+				checkList(v.Type.Params, "func parameter")
+				checkList(v.Type.Results, "func result")
+			case *ast.GenDecl:
+				if v.Tok == token.IMPORT {
+					return true
+				}
+				thing := "var"
+				switch v.Tok {
+				case token.CONST:
+					thing = "const"
+				case token.TYPE:
+					thing = "type"
+				}
+				for _, spec := range v.Specs {
+					switch s := spec.(type) {
+					case *ast.TypeSpec:
+						check(s.Name, thing)
+					case *ast.ValueSpec:
+						for _, id := range s.Names {
+							check(id, thing)
+						}
+					}
+				}
+			case *ast.InterfaceType:
+				for _, m := range v.Methods.List {
+					// Embedded interfaces have no names
+					for _, name := range m.Names {
+						check(name, "interface method")
+					}
+				}
+			case *ast.RangeStmt:
+				if v.Tok != token.DEFINE {
+					return true
+				}
+				if id, ok := v.Key.(*ast.Ident); ok {
+					check(id, "range var")
+				}
+				if id, ok := v.Value.(*ast.Ident); ok {
+					check(id, "range var")
+				}
+			case *ast.StructType:
+				checkList(v.Fields, "struct field")
+			}
+			return true
+		}
+		ast.Inspect(f, fn)
+	}
+}
+
+// receiver returns the name of the receiver of fn and the type it
+// belongs to, if fn is a method with a named receiver.
+func receiver(j *lint.Job, fn *ast.FuncDecl) (*ast.Ident, *types.TypeName, bool) {
+	if fn.Recv == nil || len(fn.Recv.List) != 1 || len(fn.Recv.List[0].Names) != 1 {
+		return nil, nil, false
+	}
+	T := j.Program.Info.TypeOf(fn.Recv.List[0].Type)
+	if ptr, ok := T.(*types.Pointer); ok {
+		T = ptr.Elem()
+	}
+	named, ok := T.(*types.Named)
+	if !ok {
+		return nil, nil, false
+	}
+	return fn.Recv.List[0].Names[0], named.Obj(), true
+}
+
+// receiverAbbreviation returns a suggested receiver name for the type
+// named name.
+func receiverAbbreviation(name string) string {
+	for i, r := range name {
+		if i > 0 && unicode.IsUpper(r) {
+			return strings.ToLower(name[:1] + string(r))
+		}
+	}
+	return strings.ToLower(name[:1])
+}
+
+func (c *Checker) CheckReceiverNames(j *lint.Job) {
+	fn := func(node ast.Node) bool {
+		decl, ok := node.(*ast.FuncDecl)
+		if !ok {
+			return true
+		}
+		recv, tname, ok := receiver(j, decl)
+		if !ok || recv.Name == "_" {
+			return false
+		}
+		switch {
+		case recv.Name == "this" || recv.Name == "self":
+			j.Errorf(recv, "receiver name should be a reflection of its identity; don't use generic names such as \"this\" or \"self\"")
+		case len(recv.Name) > j.IntOption(decl, "max_receiver_name_length"):
+			j.Errorf(recv, "receiver name %s is too long, it should be a short abbreviation of %s such as %s",
+				recv.Name, tname.Name(), receiverAbbreviation(tname.Name()))
+		}
+		return false
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		ast.Inspect(f, fn)
+	}
+}
+
+// renameReceiver returns a fix that renames the receiver of decl to
+// name, or nil if name would conflict with another identifier used in
+// the method.
+func renameReceiver(j *lint.Job, decl *ast.FuncDecl, recv *ast.Ident, name string) *lint.Fix {
+	obj := j.Program.Info.ObjectOf(recv)
+	fix := &lint.Fix{
+		Edits: []lint.Edit{{Pos: recv.Pos(), End: recv.End(), NewText: name}},
+	}
+	conflict := false
+	fn := func(node ast.Node) bool {
+		id, ok := node.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		use := j.Program.Info.ObjectOf(id)
+		if use == obj && id != recv {
+			fix.Edits = append(fix.Edits, lint.Edit{Pos: id.Pos(), End: id.End(), NewText: name})
+		} else if id.Name == name && use != nil && use != obj {
+			// Fields and methods are always accessed through a
+			// selector and can't conflict with the receiver.
+			if v, ok := use.(*types.Var); ok && v.IsField() {
+				return true
+			}
+			if fn, ok := use.(*types.Func); ok && fn.Type().(*types.Signature).Recv() != nil {
+				return true
+			}
+			conflict = true
+		}
+		return true
+	}
+	ast.Inspect(decl.Type, fn)
+	if decl.Body != nil {
+		ast.Inspect(decl.Body, fn)
+	}
+	if conflict {
+		return nil
+	}
+	return fix
+}
+
+func (c *Checker) CheckReceiverNamesIdentical(j *lint.Job) {
+	type method struct {
+		decl *ast.FuncDecl
+		recv *ast.Ident
+	}
+	methods := map[*types.TypeName][]method{}
+	var tnames []*types.TypeName
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		for _, d := range f.Decls {
+			decl, ok := d.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			recv, tname, ok := receiver(j, decl)
+			if !ok || recv.Name == "_" {
+				continue
+			}
+			if _, ok := methods[tname]; !ok {
+				tnames = append(tnames, tname)
+			}
+			methods[tname] = append(methods[tname], method{decl, recv})
+		}
+	}
+
+	for _, tname := range tnames {
+		ms := methods[tname]
+		counts := map[string]int{}
+		var names []string
+		for _, m := range ms {
+			if counts[m.recv.Name] == 0 {
+				names = append(names, m.recv.Name)
+			}
+			counts[m.recv.Name]++
+		}
+		if len(names) < 2 {
+			continue
+		}
+		// The most common name wins. Ties go to the name that is
+		// used first.
+		sort.SliceStable(names, func(i, j int) bool {
+			return counts[names[i]] > counts[names[j]]
+		})
+		want := names[0]
+		var seen []string
+		for _, name := range names {
+			seen = append(seen, fmt.Sprintf("%dx %q", counts[name], name))
+		}
+		for _, m := range ms {
+			if m.recv.Name == want {
+				continue
+			}
+			p := j.Errorf(m.recv, "methods on the same type should have the same receiver name (seen %s), should be %s",
+				strings.Join(seen, ", "), want)
+			p.Fix = renameReceiver(j, m.decl, m.recv, want)
+		}
+	}
+}
+
+// isErrorConstructor reports whether expr creates a new error from a
+// constant message, using errors.New or fmt.Errorf.
+func isErrorConstructor(j *lint.Job, expr ast.Expr) bool {
+	if !j.IsCallToAnyAST(expr, "errors.New", "fmt.Errorf") {
+		return false
+	}
+	call := expr.(*ast.CallExpr)
+	if len(call.Args) != 1 {
+		// fmt.Errorf with arguments creates errors from dynamic
+		// data, which can't be sentinels.
+		return false
+	}
+	_, ok := j.ExprToString(call.Args[0])
+	return ok
+}
+
+// errorName returns the name that the error variable name should be
+// spelled as.
+func errorName(name string) string {
+	prefix := "err"
+	if ast.IsExported(name) {
+		prefix = "Err"
+	}
+	if len(name) > 3 && strings.EqualFold(name[:3], "err") {
+		name = name[3:]
+	}
+	name = strings.TrimLeft(name, "_")
+	if name == "" {
+		return prefix
+	}
+	return prefix + strings.ToUpper(name[:1]) + name[1:]
+}
+
+func (c *Checker) CheckErrorVarNames(j *lint.Job) {
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				spec := spec.(*ast.ValueSpec)
+				if len(spec.Names) != len(spec.Values) {
+					continue
+				}
+				for i, name := range spec.Names {
+					if name.Name == "_" || !isErrorConstructor(j, spec.Values[i]) {
+						continue
+					}
+					prefix := "err"
+					if ast.IsExported(name.Name) {
+						prefix = "Err"
+					}
+					if strings.HasPrefix(name.Name, prefix) && len(name.Name) > len(prefix) {
+						continue
+					}
+					j.Errorf(name, "error var %s should have name of the form %sFoo, such as %s",
+						name.Name, prefix, errorName(name.Name))
+				}
+			}
+		}
+	}
+}
+
+// implementsError reports whether T or *T has an Error() string method.
+func implementsError(T types.Type) bool {
+	iface := types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+	return types.Implements(T, iface) || types.Implements(types.NewPointer(T), iface)
+}
+
+func (c *Checker) CheckErrorTypeNames(j *lint.Job) {
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				spec := spec.(*ast.TypeSpec)
+				obj := j.Program.Info.ObjectOf(spec.Name)
+				if obj == nil {
+					continue
+				}
+				if _, ok := obj.Type().Underlying().(*types.Interface); ok {
+					continue
+				}
+				if !implementsError(obj.Type()) {
+					continue
+				}
+				name := spec.Name.Name
+				if strings.HasSuffix(name, "Error") || name == "error" {
+					continue
+				}
+				j.Errorf(spec.Name, "error type %s should be of the form %sError", name, strings.TrimSuffix(name, "Err"))
+			}
+		}
+	}
+}
+
+func (c *Checker) CheckLocalSentinelErrors(j *lint.Job) {
+	check := func(name *ast.Ident, value ast.Expr) {
+		if name.Name == "_" || name.Name == "err" {
+			return
+		}
+		if !strings.HasPrefix(name.Name, "err") && !strings.HasPrefix(name.Name, "Err") {
+			return
+		}
+		if !isErrorConstructor(j, value) {
+			return
+		}
+		j.Errorf(name, "sentinel error %s is created anew on every call and can't be compared against, declare it at package level", name.Name)
+	}
+	fn := func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.AssignStmt:
+			if node.Tok != token.DEFINE || len(node.Lhs) != len(node.Rhs) {
+				return true
+			}
+			for i, lhs := range node.Lhs {
+				if name, ok := lhs.(*ast.Ident); ok {
+					check(name, node.Rhs[i])
+				}
+			}
+		case *ast.DeclStmt:
+			gen, ok := node.Decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				return true
+			}
+			for _, spec := range gen.Specs {
+				spec := spec.(*ast.ValueSpec)
+				if len(spec.Names) != len(spec.Values) {
+					continue
+				}
+				for i, name := range spec.Names {
+					check(name, spec.Values[i])
+				}
+			}
+		}
+		return true
+	}
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		if j.IsInTest(f) {
+			// Tests create errors to return from fakes and
+			// compare them by identity within the test.
+			continue
+		}
+		for _, decl := range f.Decls {
+			if fdecl, ok := decl.(*ast.FuncDecl); !ok || fdecl.Body == nil {
+				continue
+			}
+			ast.Inspect(decl, fn)
+		}
+	}
+}
+
+// packageClause returns the name in the package clause of the first
+// checked file of pkg, or nil if all files are generated.
+func (c *Checker) packageClause(pkg *lint.Pkg) *ast.Ident {
+	files := c.filterGenerated(pkg.Info.Files)
+	if len(files) == 0 {
+		return nil
+	}
+	return files[0].Name
+}
+
+func (c *Checker) CheckPackageNames(j *lint.Job) {
+	for _, pkg := range j.Program.Packages {
+		name := pkg.Pkg.Name()
+		if name == "main" {
+			continue
+		}
+		clause := c.packageClause(pkg)
+		if clause == nil {
+			continue
+		}
+		// External test packages are named after the package they
+		// test.
+		name = strings.TrimSuffix(name, "_test")
+
+		if strings.Contains(name, "_") {
+			j.Errorf(clause, "package name %s should not contain underscores", name)
+			continue
+		}
+		if strings.ToLower(name) != name {
+			j.Errorf(clause, "package name %s should not use mixed caps, should be %s", name, strings.ToLower(name))
+			continue
+		}
+		for _, denied := range pkg.Config.PackageNameDenylist {
+			if name == denied {
+				j.Errorf(clause, "package name %s is too generic, packages should be named after what they provide", name)
+				break
+			}
+		}
+	}
+}
+
+// stutters reports whether the exported name starts with the package
+// name pkg, as in http.HTTPServer or bytes.BytesBuffer.
+func stutters(pkg, name string) bool {
+	if len(name) <= len(pkg) || !strings.EqualFold(name[:len(pkg)], pkg) {
+		return false
+	}
+	r := name[len(pkg)]
+	return r >= 'A' && r <= 'Z' || r == '_'
+}
+
+func (c *Checker) CheckPackageNameStutter(j *lint.Job) {
+	for _, pkg := range j.Program.Packages {
+		name := pkg.Pkg.Name()
+		if name == "main" || strings.HasSuffix(name, "_test") {
+			continue
+		}
+		clause := c.packageClause(pkg)
+		if clause == nil {
+			continue
+		}
+		var exported, stuttering []string
+		for _, f := range c.filterGenerated(pkg.Info.Files) {
+			for _, decl := range f.Decls {
+				var ids []*ast.Ident
+				switch decl := decl.(type) {
+				case *ast.FuncDecl:
+					if decl.Recv == nil {
+						ids = append(ids, decl.Name)
+					}
+				case *ast.GenDecl:
+					for _, spec := range decl.Specs {
+						switch spec := spec.(type) {
+						case *ast.TypeSpec:
+							ids = append(ids, spec.Name)
+						case *ast.ValueSpec:
+							ids = append(ids, spec.Names...)
+						}
+					}
+				}
+				for _, id := range ids {
+					if !id.IsExported() {
+						continue
+					}
+					exported = append(exported, id.Name)
+					if stutters(name, id.Name) {
+						stuttering = append(stuttering, id.Name)
+					}
+				}
+			}
+		}
+		// A single stuttering name may well be intentional, such as
+		// a constructor or the package's main type.
+		if len(stuttering) < 2 || len(stuttering)*2 <= len(exported) {
+			continue
+		}
+		j.Errorf(clause, "package name %s prefixes %d of its %d exported identifiers, which stutter when used from other packages, such as %s.%s",
+			name, len(stuttering), len(exported), name, stuttering[0])
+	}
+}
+
+// A documentable is an exported, package-level declaration that
+// should be documented.
+type documentable struct {
+	name *ast.Ident
+	kind string
+	doc  *ast.CommentGroup
+	// whether the declaration may be documented by the comment of
+	// the enclosing declaration group
+	grouped bool
+}
+
+func exportedDecls(f *ast.File) []documentable {
+	var out []documentable
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if !decl.Name.IsExported() {
+				continue
+			}
+			kind := "function"
+			if decl.Recv != nil {
+				if len(decl.Recv.List) != 1 {
+					continue
+				}
+				T := decl.Recv.List[0].Type
+				if star, ok := T.(*ast.StarExpr); ok {
+					T = star.X
+				}
+				if id, ok := T.(*ast.Ident); !ok || !id.IsExported() {
+					continue
+				}
+				kind = "method"
+			}
+			out = append(out, documentable{decl.Name, kind, decl.Doc, false})
+		case *ast.GenDecl:
+			if decl.Tok == token.IMPORT {
+				continue
+			}
+			grouped := decl.Lparen.IsValid() && decl.Doc != nil
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					doc := spec.Doc
+					if doc == nil && !decl.Lparen.IsValid() {
+						doc = decl.Doc
+					}
+					if spec.Name.IsExported() {
+						out = append(out, documentable{spec.Name, "type", doc, grouped})
+					}
+				case *ast.ValueSpec:
+					doc := spec.Doc
+					if doc == nil && !decl.Lparen.IsValid() {
+						doc = decl.Doc
+					}
+					for _, name := range spec.Names {
+						if name.IsExported() {
+							out = append(out, documentable{name, decl.Tok.String(), doc, grouped})
+							// One comment documents all names of a spec
+							break
+						}
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// docExempt reports whether f is exempt from requiring doc comments.
+func docExempt(j *lint.Job, pkg *lint.Pkg, f *ast.File) bool {
+	path := strings.TrimSuffix(pkg.Pkg.Path(), "_test")
+	name := path + "/" + filepath.Base(j.Program.Prog.Fset.File(f.Pos()).Name())
+	for _, pattern := range pkg.Config.DocCommentsExempt {
+		if m, _ := filepath.Match(pattern, name); m {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Checker) CheckExportedDocs(j *lint.Job) {
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		pkg := j.NodePackage(f)
+		if pkg.Pkg.Name() == "main" || j.IsInTest(f) || docExempt(j, pkg, f) {
+			continue
+		}
+		for _, d := range exportedDecls(f) {
+			if d.doc != nil || d.grouped {
+				continue
+			}
+			j.Errorf(d.name, "exported %s %s should have a doc comment or be unexported", d.kind, d.name.Name)
+		}
+	}
+}
+
+// docCommentFix returns a fix that makes the doc comment doc start
+// with name, if the comment looks like it merely lacks the name, as
+// in "returns the foo".
+func docCommentFix(doc *ast.CommentGroup, name string) *lint.Fix {
+	c := doc.List[0]
+	if !strings.HasPrefix(c.Text, "//") {
+		return nil
+	}
+	body := c.Text[2:]
+	lead := len(body) - len(strings.TrimLeft(body, " \t"))
+	body = body[lead:]
+	word := body
+	if i := strings.IndexAny(body, " \t"); i != -1 {
+		word = body[:i]
+	}
+	pos := c.Slash + token.Pos(2+lead)
+	if strings.EqualFold(word, name) {
+		return lint.ReplaceRange(pos, pos+token.Pos(len(word)), name)
+	}
+	// Only verbs in the third person, such as "returns" or "reports",
+	// can be prefixed with the name.
+	if !docCommentVerbs[word] {
+		return nil
+	}
+	return lint.ReplaceRange(pos, pos, name+" ")
+}
+
+// docCommentVerbs are the verbs that a doc comment lacking the name
+// of the documented identifier commonly starts with.
+var docCommentVerbs = map[string]bool{
+	"adds":        true,
+	"allocates":   true,
+	"appends":     true,
+	"applies":     true,
+	"builds":      true,
+	"calls":       true,
+	"checks":      true,
+	"closes":      true,
+	"compares":    true,
+	"computes":    true,
+	"contains":    true,
+	"converts":    true,
+	"copies":      true,
+	"counts":      true,
+	"creates":     true,
+	"decodes":     true,
+	"defines":     true,
+	"deletes":     true,
+	"describes":   true,
+	"encodes":     true,
+	"executes":    true,
+	"finds":       true,
+	"formats":     true,
+	"generates":   true,
+	"gets":        true,
+	"handles":     true,
+	"holds":       true,
+	"implements":  true,
+	"initializes": true,
+	"loads":       true,
+	"marshals":    true,
+	"opens":       true,
+	"parses":      true,
+	"performs":    true,
+	"prints":      true,
+	"provides":    true,
+	"reads":       true,
+	"registers":   true,
+	"removes":     true,
+	"renders":     true,
+	"replaces":    true,
+	"reports":     true,
+	"represents":  true,
+	"resets":      true,
+	"resolves":    true,
+	"returns":     true,
+	"runs":        true,
+	"sends":       true,
+	"sets":        true,
+	"sorts":       true,
+	"starts":      true,
+	"stops":       true,
+	"stores":      true,
+	"unmarshals":  true,
+	"updates":     true,
+	"validates":   true,
+	"waits":       true,
+	"walks":       true,
+	"wraps":       true,
+	"writes":      true,
+}
+
+func (c *Checker) CheckDocCommentForm(j *lint.Job) {
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		for _, d := range exportedDecls(f) {
+			if d.doc == nil {
+				continue
+			}
+			text := d.doc.Text()
+			if strings.HasPrefix(text, "Deprecated: ") {
+				continue
+			}
+			name := d.name.Name
+			ok := strings.HasPrefix(text, name+" ") || strings.TrimSpace(text) == name
+			if d.kind == "type" {
+				for _, article := range []string{"A ", "An ", "The "} {
+					ok = ok || strings.HasPrefix(text, article+name+" ")
+				}
+			}
+			if ok {
+				continue
+			}
+			p := j.Errorf(d.doc, "comment on exported %s %s should be of the form \"%s ...\"", d.kind, name, name)
+			p.Fix = docCommentFix(d.doc, name)
+		}
+	}
+}
+
+// wellKnownInterfaces are the interfaces that CheckNarrowInterfaceParams
+// suggests in place of broader ones.
+var wellKnownInterfaces = []string{
+	"error",
+	"fmt.Stringer",
+	"io.ByteReader",
+	"io.ByteScanner",
+	"io.ByteWriter",
+	"io.Closer",
+	"io.ReadCloser",
+	"io.ReadSeeker",
+	"io.ReadWriteCloser",
+	"io.ReadWriter",
+	"io.Reader",
+	"io.ReaderAt",
+	"io.ReaderFrom",
+	"io.RuneReader",
+	"io.RuneScanner",
+	"io.Seeker",
+	"io.WriteCloser",
+	"io.Writer",
+	"io.WriterAt",
+	"io.WriterTo",
+	"sort.Interface",
+}
+
+// lookupWellKnown returns the well-known interface that consists of
+// exactly the methods of T named in methods, if the package declaring
+// it has been loaded.
+func lookupWellKnown(j *lint.Job, T types.Type, methods map[string]bool) string {
+	pkgs := map[string]*types.Package{}
+	for pkg := range j.Program.Prog.AllPackages {
+		pkgs[pkg.Path()] = pkg
+	}
+	for _, name := range wellKnownInterfaces {
+		var obj types.Object
+		if i := strings.LastIndex(name, "."); i == -1 {
+			obj = types.Universe.Lookup(name)
+		} else if pkg := pkgs[name[:i]]; pkg != nil {
+			obj = pkg.Scope().Lookup(name[i+1:])
+		}
+		if obj == nil {
+			continue
+		}
+		iface, ok := obj.Type().Underlying().(*types.Interface)
+		if !ok || iface.NumMethods() != len(methods) {
+			continue
+		}
+		match := true
+		for i := 0; i < iface.NumMethods(); i++ {
+			if !methods[iface.Method(i).Name()] {
+				match = false
+				break
+			}
+		}
+		if match && types.Implements(T, iface) {
+			return name
+		}
+	}
+	return ""
+}
+
+// funcValues returns the functions that are used other than by
+// calling them. Their signatures may have to match function types.
+func funcValues(j *lint.Job) map[types.Object]bool {
+	called := map[*ast.Ident]bool{}
+	for _, f := range j.Program.Files {
+		ast.Inspect(f, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			switch fun := call.Fun.(type) {
+			case *ast.Ident:
+				called[fun] = true
+			case *ast.SelectorExpr:
+				called[fun.Sel] = true
+			}
+			return true
+		})
+	}
+	out := map[types.Object]bool{}
+	for id, obj := range j.Program.Info.Uses {
+		if _, ok := obj.(*types.Func); ok && !called[id] {
+			out[obj] = true
+		}
+	}
+	return out
+}
+
+func (c *Checker) CheckNarrowInterfaceParams(j *lint.Job) {
+	values := funcValues(j)
+	for _, f := range c.filterGenerated(j.Program.Files) {
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			// Methods may have to implement interfaces, whose
+			// signatures they can't deviate from.
+			if !ok || fn.Recv != nil || fn.Body == nil {
+				continue
+			}
+			if values[j.Program.Info.ObjectOf(fn.Name)] {
+				continue
+			}
+			for _, field := range fn.Type.Params.List {
+				for _, name := range field.Names {
+					checkNarrowInterfaceParam(j, fn, name)
+				}
+			}
+		}
+	}
+}
+
+func checkNarrowInterfaceParam(j *lint.Job, fn *ast.FuncDecl, name *ast.Ident) {
+	obj := j.Program.Info.ObjectOf(name)
+	if obj == nil || name.Name == "_" {
+		return
+	}
+	iface, ok := obj.Type().Underlying().(*types.Interface)
+	if !ok || iface.NumMethods() < 2 {
+		return
+	}
+	// The parameter can only be narrowed if all it's used for is
+	// calling methods.
+	methods := map[string]bool{}
+	selected := 0
+	uses := 0
+	ast.Inspect(fn.Body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.SelectorExpr:
+			if id, ok := node.X.(*ast.Ident); ok && j.Program.Info.ObjectOf(id) == obj {
+				methods[node.Sel.Name] = true
+				selected++
+			}
+		case *ast.Ident:
+			if j.Program.Info.ObjectOf(node) == obj {
+				uses++
+			}
+		}
+		return true
+	})
+	if uses == 0 || uses != selected || len(methods) == iface.NumMethods() {
+		return
+	}
+	var names []string
+	for m := range methods {
+		names = append(names, m)
+	}
+	sort.Strings(names)
+	T := types.TypeString(obj.Type(), types.RelativeTo(obj.Pkg()))
+	if alt := lookupWellKnown(j, obj.Type(), methods); alt != "" {
+		j.Errorf(name, "parameter %s only uses %s of %s, it could be %s", name.Name, methodList(names), T, alt)
+		return
+	}
+	j.Errorf(name, "parameter %s only uses %s of %s, it could be a narrower interface", name.Name, methodList(names), T)
+}
+
+func methodList(names []string) string {
+	if len(names) == 1 {
+		return "method " + names[0]
+	}
+	return "methods " + strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
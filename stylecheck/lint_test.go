@@ -0,0 +1,31 @@
+package stylecheck
+
+import (
+	"testing"
+
+	"honnef.co/go/tools/lint/testutil"
+)
+
+func TestAll(t *testing.T) {
+	testutil.TestAll(t, NewChecker(), "")
+}
+
+func TestInitialismsConfig(t *testing.T) {
+	testutil.TestAll(t, NewChecker(), "initialisms")
+}
+
+func TestDocComments(t *testing.T) {
+	testutil.TestAll(t, NewChecker(), "docs")
+}
+
+func TestOptions(t *testing.T) {
+	testutil.TestAll(t, NewChecker(), "options")
+}
+
+func TestOverrides(t *testing.T) {
+	testutil.TestAll(t, NewChecker(), "overrides")
+}
+
+func TestDirectives(t *testing.T) {
+	testutil.TestAll(t, NewChecker(), "directives")
+}
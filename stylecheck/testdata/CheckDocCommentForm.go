@@ -0,0 +1,38 @@
+package pkg
+
+// returns the foo
+func Foo() int { return 0 }
+
+// foo2 returns the foo
+func Foo2() int { return 0 }
+
+// Bar returns the bar.
+func Bar() int { return 0 }
+
+// A Widget is a widget.
+type Widget struct{}
+
+// Deprecated: use Bar.
+func Baz() {}
+
+// Config
+type Config struct{}
+
+// This is the config.
+type Config2 struct{}
+
+// MATCH:3 "comment on exported function Foo should be of the form "Foo ...""
+
+// MATCH:6 "comment on exported function Foo2 should be of the form "Foo2 ...""
+
+// MATCH:21 "comment on exported type Config2 should be of the form "Config2 ...""
+
+// this is the thing
+func Thing() {}
+
+// has a bug
+func Bug() {}
+
+// MATCH:30 "comment on exported function Thing should be of the form "Thing ...""
+
+// MATCH:33 "comment on exported function Bug should be of the form "Bug ...""
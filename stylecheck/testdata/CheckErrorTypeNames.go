@@ -0,0 +1,23 @@
+package pkg
+
+type FooError struct{}
+
+func (FooError) Error() string { return "" }
+
+type barError struct{}
+
+func (*barError) Error() string { return "" }
+
+type Failure struct{} // MATCH "error type Failure should be of the form FailureError"
+
+func (*Failure) Error() string { return "" }
+
+type parseErr int // MATCH "error type parseErr should be of the form parseError"
+
+func (parseErr) Error() string { return "" }
+
+type Errorer interface {
+	Error() string
+}
+
+type NotAnError struct{}
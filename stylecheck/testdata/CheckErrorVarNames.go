@@ -0,0 +1,23 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrFoo     = errors.New("foo")
+	errBar     = fmt.Errorf("bar")
+	FooFailed  = errors.New("foo failed") // MATCH "error var FooFailed should have name of the form ErrFoo, such as ErrFooFailed"
+	barFailed  = errors.New("bar failed") // MATCH "error var barFailed should have name of the form errFoo, such as errBarFailed"
+	Err        = errors.New("err")        // MATCH "error var Err should have name of the form ErrFoo"
+	errorsSeen = 0
+	dynamic    = fmt.Errorf("%d", 1)
+)
+
+var ErrorTimeout = errors.New("timeout")
+
+func fn() {
+	var notAnError = errors.New("local")
+	_ = notAnError
+}
@@ -0,0 +1,19 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errGlobal = errors.New("global")
+
+func fn1() error {
+	errNotFound := errors.New("not found") // MATCH "sentinel error errNotFound is created anew on every call and can't be compared against, declare it at package level"
+	var ErrGone = fmt.Errorf("gone")       // MATCH "sentinel error ErrGone"
+	_ = ErrGone
+	err := errors.New("plain")
+	_ = err
+	errWrapped := fmt.Errorf("wrapped: %v", err)
+	_ = errWrapped
+	return errNotFound
+}
@@ -0,0 +1,15 @@
+package pkg
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFn(t *testing.T) {
+	errBoom := errors.New("boom")
+	if err := fake(errBoom); err != errBoom {
+		t.Fatal(err)
+	}
+}
+
+func fake(err error) error { return err }
@@ -0,0 +1,37 @@
+package pkg
+
+var v_1 int // MATCH "should not use underscores in Go names; var v_1 should be v1"
+
+var httpUrl string // MATCH "var httpUrl should be httpURL"
+
+const MAX_SIZE = 1 // MATCH "should not use ALL_CAPS in Go names; use CamelCase instead"
+
+type jsonApi struct { // MATCH "type jsonApi should be jsonAPI"
+	UserId int // MATCH "struct field UserId should be UserID"
+	Name   string
+}
+
+func (api *jsonApi) ServeHttp( // MATCH "method ServeHttp should be ServeHTTP"
+	reqId int, // MATCH "func parameter reqId should be reqID"
+) (
+	respId int, // MATCH "func result respId should be respID"
+) {
+	return 0
+}
+
+func fn() {
+	for _, userId := range []int{1} { // MATCH "range var userId should be userID"
+		_ = userId
+	}
+	skuCount := 0
+	_ = skuCount
+	x_1_2 := 0 // MATCH "should not use underscores in Go names; var x_1_2 should be x1_2"
+	_ = x_1_2
+}
+
+type Fooer interface {
+	GetUrl() string // MATCH "interface method GetUrl should be GetURL"
+}
+
+//export cgo_function
+func cgo_function() {}
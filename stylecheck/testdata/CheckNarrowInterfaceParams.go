@@ -0,0 +1,56 @@
+package pkg
+
+import "io"
+
+type Store interface {
+	Get(key string) string
+	Set(key, value string)
+	Delete(key string)
+}
+
+func readAll(r io.ReadCloser) { // MATCH "parameter r only uses method Read of io.ReadCloser, it could be io.Reader"
+	var buf [8]byte
+	r.Read(buf[:])
+	r.Read(buf[:])
+}
+
+func lookup(s Store, key string) string { // MATCH "parameter s only uses method Get of Store, it could be a narrower interface"
+	return s.Get(key)
+}
+
+func copyKey(s Store, from, to string) { // MATCH "parameter s only uses methods Get and Set of Store, it could be a narrower interface"
+	s.Set(to, s.Get(from))
+}
+
+func move(s Store, from, to string) {
+	s.Set(to, s.Get(from))
+	s.Delete(from)
+}
+
+func closeAll(r io.ReadCloser) {
+	var buf [8]byte
+	r.Read(buf[:])
+	r.Close()
+}
+
+func passOn(r io.ReadCloser) {
+	readAll(r)
+}
+
+func compare(r, w io.ReadCloser) bool {
+	return r == w
+}
+
+func unused(r io.ReadCloser) {}
+
+func callback(r io.ReadCloser) {
+	r.Close()
+}
+
+var _ = callback
+
+type T struct{}
+
+func (T) method(r io.ReadCloser) {
+	r.Close()
+}
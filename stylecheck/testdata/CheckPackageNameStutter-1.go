@@ -0,0 +1,9 @@
+package widget // MATCH "package name widget prefixes 2 of its 3 exported identifiers, which stutter when used from other packages, such as widget.WidgetConfig"
+
+type WidgetConfig struct{}
+
+func WidgetNew() {}
+
+func Render() {}
+
+func (WidgetConfig) WidgetMethod() {}
@@ -0,0 +1,9 @@
+package gadget
+
+type Gadget struct{}
+
+func NewGadget() *Gadget { return nil }
+
+func GadgetFromString(string) *Gadget { return nil }
+
+func Parse() {}
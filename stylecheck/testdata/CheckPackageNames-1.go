@@ -0,0 +1 @@
+package my_pkg // MATCH "package name my_pkg should not contain underscores"
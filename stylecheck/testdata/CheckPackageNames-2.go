@@ -0,0 +1 @@
+package myPkg // MATCH "package name myPkg should not use mixed caps, should be mypkg"
@@ -0,0 +1 @@
+package util // MATCH "package name util is too generic, packages should be named after what they provide"
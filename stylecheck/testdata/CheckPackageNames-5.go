@@ -0,0 +1 @@
+package util_test // MATCH "package name util is too generic"
@@ -0,0 +1,23 @@
+package pkg
+
+type T1 int
+
+func (this T1) a() {} // MATCH "don't use generic names such as "this" or "self""
+
+type T2 int
+
+func (self T2) a() {} // MATCH "don't use generic names such as "this" or "self""
+
+type T3 int
+
+func (t T3) a() {}
+func (_ T3) b() {}
+func (T3) c()   {}
+
+type T4 int
+
+func (number T4) a() {} // MATCH "receiver name number is too long, it should be a short abbreviation of T4 such as t"
+
+type fooBar int
+
+func (fooBarValue *fooBar) a() {} // MATCH "receiver name fooBarValue is too long, it should be a short abbreviation of fooBar such as fb"
@@ -0,0 +1,15 @@
+package pkg
+
+type T1 int
+
+func (t T1) a() {}
+func (t T1) b() {}
+func (x T1) c() { // MATCH "methods on the same type should have the same receiver name (seen 2x "t", 1x "x"), should be t"
+	_ = x
+}
+
+type T2 int
+
+func (a T2) a() {}
+func (b T2) b() {} // MATCH "(seen 1x "a", 1x "b"), should be a"
+func (_ T2) c() {}
@@ -0,0 +1,26 @@
+package pkg
+
+//lint:ignore ST1003 kept for compatibility
+var foo_bar int
+
+var bar_baz int // MATCH "should not use underscores"
+
+func fn() {
+	//lint:ignore ST1003 kept for compatibility until=2999-01-01
+	var x_y int
+	_ = x_y
+}
+
+//lint:ignore ST1003
+var baz_qux int // MATCH "should not use underscores"
+
+// MATCH:14 "missing the required reason field"
+
+//lint:ignore ST1003 the name used to be bad
+var fine int
+
+//lint:ignore ST9999 no such check
+var alsoFine int
+
+// MATCH:19 "didn't match anything"
+// MATCH:22 "unknown check ST9999"
@@ -0,0 +1,6 @@
+// Package pkg is generated.
+package pkg
+
+//lint:file-ignore ST1003 generated code
+
+var qux_quux int
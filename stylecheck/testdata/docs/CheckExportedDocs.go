@@ -0,0 +1,31 @@
+package pkg
+
+func Undocumented() {} // MATCH "exported function Undocumented should have a doc comment or be unexported"
+
+// Documented does things.
+func Documented() {}
+
+func unexported() {}
+
+type T struct{} // MATCH "exported type T should have a doc comment or be unexported"
+
+func (T) Method() {} // MATCH "exported method Method should have a doc comment or be unexported"
+
+type t struct{}
+
+func (t) Method() {}
+
+// Constants of the package.
+const (
+	A = 1
+	B = 2
+)
+
+const (
+	C = 1 // MATCH "exported const C should have a doc comment or be unexported"
+
+	// D is documented.
+	D = 2
+)
+
+var V, W int // MATCH "exported var V should have a doc comment or be unexported"
@@ -0,0 +1,3 @@
+package pkg
+
+func Undocumented() {} // MATCH "exported function Undocumented should have a doc comment or be unexported"
@@ -0,0 +1,6 @@
+package pkg
+
+var skuCount int
+var SkuCount int // MATCH "var SkuCount should be SKUCount"
+var userId int   // MATCH "var userId should be userID"
+var maxQps int   // MATCH "var maxQps should be maxQPS"
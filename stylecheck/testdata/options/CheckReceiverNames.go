@@ -0,0 +1,9 @@
+package pkg
+
+type T1 int
+
+func (t T1) a() {}
+
+type T2 int
+
+func (foo T2) a() {} // MATCH "receiver name foo is too long, it should be a short abbreviation of T2 such as t"
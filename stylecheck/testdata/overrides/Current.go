@@ -0,0 +1,3 @@
+package pkg
+
+var httpUrl string // MATCH "var httpUrl should be httpURL"
@@ -0,0 +1,310 @@
+// Package taint tracks the flow of untrusted data through SSA
+// functions.
+//
+// Data is tainted when it is returned by a source, such as a function
+// returning the value of an HTTP form field, and flows into a sink,
+// such as the query argument of a SQL function, unless it passes
+// through a sanitizer on the way. Taint propagates through operations
+// that derive new data from old, such as string concatenation,
+// conversions, formatting and storing into and loading from memory.
+// Numeric and boolean values never carry taint.
+//
+// The analysis is intraprocedural: data returned by other functions
+// is only tainted if they are sources, or if they are passed tainted
+// arguments.
+package taint // import "honnef.co/go/tools/taint"
+
+import (
+	"go/types"
+	"strconv"
+	"strings"
+
+	"honnef.co/go/tools/ssa"
+)
+
+// A Config describes the sources, sinks and sanitizers of an
+// analysis. Functions are named as in (*net/http.Request).FormValue
+// or os/exec.Command.
+type Config struct {
+	// Sources are the functions whose results are tainted.
+	Sources []string
+	// Sinks are the functions whose arguments must not be tainted.
+	// A name may be followed by a colon and the index of the only
+	// argument to check, not counting receivers, as in
+	// (*database/sql.DB).Query:0.
+	Sinks []string
+	// Sanitizers are the functions whose results are never tainted.
+	Sanitizers []string
+}
+
+// A Flow is tainted data reaching a sink.
+type Flow struct {
+	// Source is the call to the source the data came from.
+	Source *ssa.Call
+	// Sink is the call to the sink.
+	Sink ssa.CallInstruction
+	// Arg is the index of the tainted argument of the sink, not
+	// counting receivers.
+	Arg int
+}
+
+// SourceName returns the name of the source function of f.
+func (f Flow) SourceName() string {
+	return Callee(f.Source.Common())
+}
+
+// SinkName returns the name of the sink function of f.
+func (f Flow) SinkName() string {
+	return Callee(f.Sink.Common())
+}
+
+// Callee returns the name of the function called by call, or the
+// empty string for dynamic calls of function values.
+func Callee(call *ssa.CallCommon) string {
+	if call.IsInvoke() {
+		return call.Method.FullName()
+	}
+	if fn := call.StaticCallee(); fn != nil {
+		return fn.RelString(nil)
+	}
+	return ""
+}
+
+type sink struct {
+	all bool
+	arg int
+}
+
+func parseSinks(specs []string) map[string][]sink {
+	out := map[string][]sink{}
+	for _, spec := range specs {
+		if i := strings.LastIndex(spec, ":"); i >= 0 {
+			if n, err := strconv.Atoi(spec[i+1:]); err == nil {
+				out[spec[:i]] = append(out[spec[:i]], sink{arg: n})
+				continue
+			}
+		}
+		out[spec] = append(out[spec], sink{all: true})
+	}
+	return out
+}
+
+func set(names []string) map[string]bool {
+	out := map[string]bool{}
+	for _, name := range names {
+		out[name] = true
+	}
+	return out
+}
+
+// carriesData reports whether values of type T can carry taint.
+func carriesData(T types.Type) bool {
+	if b, ok := T.Underlying().(*types.Basic); ok {
+		return b.Info()&(types.IsNumeric|types.IsBoolean) == 0
+	}
+	return true
+}
+
+// An access is the path from a variable to the memory that an
+// address points to, consisting of field and index steps. Indices
+// that aren't constant are written as [?] and may be any index.
+type access struct {
+	root  ssa.Value
+	steps []string
+}
+
+type storedAccess struct {
+	acc access
+	src *ssa.Call
+}
+
+func accessOf(addr ssa.Value) access {
+	var steps []string
+	for {
+		switch v := addr.(type) {
+		case *ssa.FieldAddr:
+			steps = append(steps, "."+strconv.Itoa(v.Field))
+			addr = v.X
+		case *ssa.IndexAddr:
+			step := "[?]"
+			if c, ok := v.Index.(*ssa.Const); ok && c.Value != nil {
+				step = "[" + c.Value.ExactString() + "]"
+			}
+			steps = append(steps, step)
+			addr = v.X
+		default:
+			for i, j := 0, len(steps)-1; i < j; i, j = i+1, j-1 {
+				steps[i], steps[j] = steps[j], steps[i]
+			}
+			return access{root: addr, steps: steps}
+		}
+	}
+}
+
+func (a access) equals(b access) bool {
+	if a.root != b.root || len(a.steps) != len(b.steps) {
+		return false
+	}
+	for i := range a.steps {
+		if a.steps[i] != b.steps[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// overlaps reports whether a and b may refer to overlapping memory,
+// i.e. whether one of them is within the other.
+func (a access) overlaps(b access) bool {
+	if a.root != b.root {
+		return false
+	}
+	for i := 0; i < len(a.steps) && i < len(b.steps); i++ {
+		if a.steps[i] != b.steps[i] && a.steps[i] != "[?]" && b.steps[i] != "[?]" {
+			return false
+		}
+	}
+	return true
+}
+
+// Analyze returns the flows of tainted data into sinks in fn, in the
+// order of the sinks' appearance.
+func Analyze(fn *ssa.Function, cfg Config) []Flow {
+	sources := set(cfg.Sources)
+	sanitizers := set(cfg.Sanitizers)
+	sinks := parseSinks(cfg.Sinks)
+
+	// tainted maps tainted values to the calls of sources they
+	// derive from.
+	tainted := map[ssa.Value]*ssa.Call{}
+	changed := false
+	taint := func(v ssa.Value, src *ssa.Call) {
+		if src == nil || !carriesData(v.Type()) {
+			return
+		}
+		if _, ok := tainted[v]; !ok {
+			tainted[v] = src
+			changed = true
+		}
+	}
+	// stored maps variables to the accesses within them that tainted
+	// data was stored through.
+	stored := map[ssa.Value][]storedAccess{}
+	store := func(acc access, src *ssa.Call) {
+		for _, s := range stored[acc.root] {
+			if s.acc.equals(acc) {
+				return
+			}
+		}
+		stored[acc.root] = append(stored[acc.root], storedAccess{acc, src})
+		changed = true
+	}
+	// anyOf returns the source of the first tainted value in vs. An
+	// address is tainted if tainted data was stored into the memory
+	// it points to.
+	anyOf := func(vs ...ssa.Value) *ssa.Call {
+		for _, v := range vs {
+			if src, ok := tainted[v]; ok {
+				return src
+			}
+			acc := accessOf(v)
+			for _, s := range stored[acc.root] {
+				if s.acc.overlaps(acc) {
+					return s.src
+				}
+			}
+		}
+		return nil
+	}
+
+	for changed = true; changed; {
+		changed = false
+		for _, b := range fn.Blocks {
+			for _, ins := range b.Instrs {
+				switch ins := ins.(type) {
+				case *ssa.Call:
+					name := Callee(ins.Common())
+					switch {
+					case sources[name]:
+						taint(ins, ins)
+					case sanitizers[name]:
+					default:
+						args := ins.Common().Args
+						if ins.Common().IsInvoke() {
+							args = append([]ssa.Value{ins.Common().Value}, args...)
+						}
+						taint(ins, anyOf(args...))
+					}
+				case *ssa.Store:
+					if src := anyOf(ins.Val); src != nil {
+						store(accessOf(ins.Addr), src)
+					}
+				case *ssa.BinOp:
+					taint(ins, anyOf(ins.X, ins.Y))
+				case *ssa.UnOp:
+					taint(ins, anyOf(ins.X))
+				case *ssa.Phi:
+					taint(ins, anyOf(ins.Edges...))
+				case *ssa.Convert:
+					taint(ins, anyOf(ins.X))
+				case *ssa.ChangeType:
+					taint(ins, anyOf(ins.X))
+				case *ssa.ChangeInterface:
+					taint(ins, anyOf(ins.X))
+				case *ssa.MakeInterface:
+					taint(ins, anyOf(ins.X))
+				case *ssa.TypeAssert:
+					taint(ins, anyOf(ins.X))
+				case *ssa.Extract:
+					taint(ins, anyOf(ins.Tuple))
+				case *ssa.Slice:
+					taint(ins, anyOf(ins.X))
+				case *ssa.Field:
+					taint(ins, anyOf(ins.X))
+				case *ssa.FieldAddr:
+					// Only the address itself; data stored into
+					// other fields doesn't taint this one.
+					taint(ins, tainted[ins.X])
+				case *ssa.Index:
+					taint(ins, anyOf(ins.X))
+				case *ssa.IndexAddr:
+					taint(ins, tainted[ins.X])
+				case *ssa.Lookup:
+					taint(ins, anyOf(ins.X))
+				}
+			}
+		}
+	}
+
+	var flows []Flow
+	for _, b := range fn.Blocks {
+		for _, ins := range b.Instrs {
+			call, ok := ins.(ssa.CallInstruction)
+			if !ok {
+				continue
+			}
+			common := call.Common()
+			specs := sinks[Callee(common)]
+			if len(specs) == 0 {
+				continue
+			}
+			args := common.Args
+			if !common.IsInvoke() && common.Signature().Recv() != nil {
+				args = args[1:]
+			}
+			for i, arg := range args {
+				src, ok := tainted[arg]
+				if !ok {
+					continue
+				}
+				for _, s := range specs {
+					if s.all || s.arg == i {
+						flows = append(flows, Flow{Source: src, Sink: call, Arg: i})
+						break
+					}
+				}
+			}
+		}
+	}
+	return flows
+}
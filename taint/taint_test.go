@@ -0,0 +1,145 @@
+package taint
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/go/loader"
+	"honnef.co/go/tools/ssa"
+	"honnef.co/go/tools/ssa/ssautil"
+)
+
+const src = `package pkg
+
+func source() string
+func sink(a, b string)
+func sanitize(s string) string
+func pass(s string) string
+
+type T struct{ a, b string }
+
+func direct()    { sink(source(), "") }
+func second()    { sink("", source()) }
+func concat()    { sink("x"+source(), "") }
+func sanitized() { sink(sanitize(source()), "") }
+func through()   { sink(pass(source()), "") }
+
+func length() {
+	n := len(source())
+	sink(string(rune(n)), "")
+}
+
+func field() {
+	var t T
+	t.a = source()
+	sink(t.a, t.b)
+}
+
+func otherField() {
+	t := &T{}
+	t.b = source()
+	sink(t.a, "")
+}
+
+func wholeStruct(t *T, u T) {
+	u.a = source()
+	*t = u
+	sink(t.b, "")
+}
+
+func element() {
+	var arr [2]string
+	arr[1] = source()
+	sink(arr[0], arr[1])
+}
+
+func variableIndex(i int) {
+	var arr [2]string
+	arr[i] = source()
+	sink(arr[0], "")
+}
+
+func sliceLiteral() {
+	s := []string{"", source()}
+	sink(s[1], "")
+}
+
+func loop() {
+	var s string
+	for i := 0; i < 3; i++ {
+		s += source()
+	}
+	sink(s, "")
+}
+`
+
+func build(t *testing.T) *ssa.Package {
+	conf := loader.Config{}
+	f, err := conf.ParseFile("pkg.go", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.CreateFromFiles("pkg", f)
+	lprog, err := conf.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prog := ssautil.CreateProgram(lprog, 0)
+	prog.Build()
+	pkg := prog.Package(lprog.Created[0].Pkg)
+	return pkg
+}
+
+func TestAnalyze(t *testing.T) {
+	pkg := build(t)
+
+	cfg := Config{
+		Sources:    []string{"pkg.source"},
+		Sinks:      []string{"pkg.sink"},
+		Sanitizers: []string{"pkg.sanitize"},
+	}
+	// the tainted arguments of the sink in each function
+	tests := map[string][]int{
+		"direct":        {0},
+		"second":        {1},
+		"concat":        {0},
+		"sanitized":     nil,
+		"through":       {0},
+		"length":        nil,
+		"field":         {0},
+		"otherField":    nil,
+		"wholeStruct":   {0},
+		"element":       {1},
+		"variableIndex": {0},
+		"sliceLiteral":  {0},
+		"loop":          {0},
+	}
+	for name, want := range tests {
+		fn := pkg.Func(name)
+		var got []int
+		for _, flow := range Analyze(fn, cfg) {
+			if flow.SourceName() != "pkg.source" || flow.SinkName() != "pkg.sink" {
+				t.Errorf("%s: unexpected flow from %s to %s", name, flow.SourceName(), flow.SinkName())
+			}
+			got = append(got, flow.Arg)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got tainted arguments %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestAnalyzeSinkArgument(t *testing.T) {
+	pkg := build(t)
+
+	cfg := Config{
+		Sources: []string{"pkg.source"},
+		Sinks:   []string{"pkg.sink:1"},
+	}
+	if flows := Analyze(pkg.Func("direct"), cfg); len(flows) != 0 {
+		t.Errorf("got %d flows into the unchecked argument, want none", len(flows))
+	}
+	if flows := Analyze(pkg.Func("second"), cfg); len(flows) != 1 {
+		t.Errorf("got %d flows into the checked argument, want one", len(flows))
+	}
+}
@@ -0,0 +1,178 @@
+package unused
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"io"
+	"sort"
+)
+
+// A declNode is a declaration in the object graph.
+type declNode struct {
+	obj  types.Object
+	root bool
+	used bool
+	uses []*declNode
+	id   int
+}
+
+// isDecl reports whether obj is a declaration of one of the initial
+// packages, as opposed to an implementation detail of the graph.
+func isDecl(obj types.Object, initial map[*types.Package]bool) bool {
+	if _, ok := obj.(*types.PkgName); ok {
+		return false
+	}
+	return obj.Pkg() != nil && initial[obj.Pkg()] && !isIntermediate(obj)
+}
+
+// declGraph returns the declarations of the initial packages, sorted
+// by position, and the declarations that they use, looking through
+// nodes that aren't declarations, such as types and scopes.
+func (c *Checker) declGraph() []*declNode {
+	initial := map[*types.Package]bool{}
+	for _, pkg := range c.lprog.InitialPackages() {
+		initial[pkg.Pkg] = true
+	}
+	decls := map[*graphNode]*declNode{}
+	for _, node := range c.graph.nodes {
+		obj, ok := node.obj.(types.Object)
+		if !ok || !isDecl(obj, initial) {
+			continue
+		}
+		decls[node] = &declNode{obj: obj, used: node.used}
+	}
+	for _, root := range c.graph.roots {
+		if d, ok := decls[root]; ok {
+			d.root = true
+		}
+	}
+	for _, e := range c.escapes {
+		if d, ok := decls[e.node]; ok {
+			d.root = true
+		}
+	}
+
+	for start, d := range decls {
+		seen := map[*graphNode]bool{start: true}
+		var queue []*graphNode
+		for node := range start.uses {
+			queue = append(queue, node)
+		}
+		for len(queue) > 0 {
+			node := queue[0]
+			queue = queue[1:]
+			if seen[node] {
+				continue
+			}
+			seen[node] = true
+			if used, ok := decls[node]; ok {
+				d.uses = append(d.uses, used)
+				continue
+			}
+			if obj, ok := node.obj.(types.Object); ok && !isIntermediate(obj) {
+				// declarations of other packages
+				continue
+			}
+			for used := range node.uses {
+				// Fields refer to their struct, but don't depend on
+				// its other fields.
+				if st, ok := used.obj.(*types.Struct); ok {
+					if field, ok := node.obj.(*types.Var); ok && isFieldOf(field, st) {
+						continue
+					}
+				}
+				queue = append(queue, used)
+			}
+		}
+	}
+
+	out := make([]*declNode, 0, len(decls))
+	for _, d := range decls {
+		out = append(out, d)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].obj.Pos() < out[j].obj.Pos()
+	})
+	for i, d := range out {
+		d.id = i + 1
+	}
+	for _, d := range out {
+		sort.Slice(d.uses, func(i, j int) bool {
+			return d.uses[i].id < d.uses[j].id
+		})
+	}
+	return out
+}
+
+// writeGraphDOT writes the declaration graph in the DOT language of
+// Graphviz. Unused declarations are red, roots are drawn in bold.
+func (c *Checker) writeGraphDOT(w io.Writer, decls []*declNode) {
+	fmt.Fprintln(w, "digraph {")
+	for _, d := range decls {
+		pos := c.lprog.Fset.Position(d.obj.Pos())
+		label := fmt.Sprintf("%s %s\n%s", typString(d.obj), objName(d.obj), pos)
+		color := "black"
+		if !d.used {
+			color = "red"
+		}
+		style := "solid"
+		if d.root {
+			style = "bold"
+		}
+		fmt.Fprintf(w, "n%d [label = %q, color = %s, style = %s]\n", d.id, label, color, style)
+	}
+	for _, d := range decls {
+		for _, used := range d.uses {
+			fmt.Fprintf(w, "n%d -> n%d\n", d.id, used.id)
+		}
+	}
+	fmt.Fprintln(w, "}")
+}
+
+type jsonDecl struct {
+	ID        int          `json:"id"`
+	Kind      string       `json:"kind"`
+	Name      string       `json:"name"`
+	Qualified string       `json:"qualified"`
+	Position  jsonPosition `json:"position"`
+	Root      bool         `json:"root"`
+	Used      bool         `json:"used"`
+	Uses      []int        `json:"uses"`
+}
+
+// writeGraphJSON writes the declaration graph as a JSON array of
+// declarations, each listing the IDs of the declarations it uses.
+func (c *Checker) writeGraphJSON(w io.Writer, decls []*declNode) {
+	out := make([]jsonDecl, 0, len(decls))
+	for _, d := range decls {
+		jd := jsonDecl{
+			ID:        d.id,
+			Kind:      typString(d.obj),
+			Name:      objName(d.obj),
+			Qualified: qualifiedName(d.obj),
+			Position:  newJSONPosition(c.lprog.Fset.Position(d.obj.Pos())),
+			Root:      d.root,
+			Used:      d.used,
+			Uses:      []int{},
+		}
+		for _, used := range d.uses {
+			jd.Uses = append(jd.Uses, used.id)
+		}
+		out = append(out, jd)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	enc.Encode(out)
+}
+
+// writeGraph writes the declaration graph to c.Graph in the format
+// named by c.GraphFormat.
+func (c *Checker) writeGraph() {
+	decls := c.declGraph()
+	if c.GraphFormat == "json" {
+		c.writeGraphJSON(c.Graph, decls)
+	} else {
+		c.writeGraphDOT(c.Graph, decls)
+	}
+}
@@ -0,0 +1,74 @@
+package unused
+
+import (
+	"encoding/json"
+	"go/token"
+	"go/types"
+	"io"
+
+	"honnef.co/go/tools/lint"
+)
+
+type jsonPosition struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+type jsonObject struct {
+	Kind     string       `json:"kind"`
+	Name     string       `json:"name"`
+	Position jsonPosition `json:"position"`
+}
+
+type jsonProblem struct {
+	Position jsonPosition `json:"position"`
+	Message  string       `json:"message"`
+	Check    string       `json:"check,omitempty"`
+	Severity string       `json:"severity,omitempty"`
+
+	// only set for unused objects
+	Object *jsonObject  `json:"object,omitempty"`
+	Reason string       `json:"reason,omitempty"`
+	Chain  []jsonObject `json:"chain,omitempty"`
+}
+
+func newJSONPosition(pos token.Position) jsonPosition {
+	return jsonPosition{File: pos.Filename, Line: pos.Line, Column: pos.Column}
+}
+
+func newJSONObject(fset *token.FileSet, obj types.Object) jsonObject {
+	return jsonObject{
+		Kind:     typString(obj),
+		Name:     objName(obj),
+		Position: newJSONPosition(fset.Position(obj.Pos())),
+	}
+}
+
+// WriteJSON writes ps, the problems found by l, to w as a stream of
+// JSON objects, one per line. Problems about unused objects include
+// the object, the reason it is unused and the chain of unused objects
+// that refer to it.
+func (l *LintChecker) WriteJSON(w io.Writer, fset *token.FileSet, ps []lint.Problem) error {
+	enc := json.NewEncoder(w)
+	for _, p := range ps {
+		jp := jsonProblem{
+			Position: newJSONPosition(fset.Position(p.Position)),
+			Message:  p.Text,
+			Check:    p.Check,
+			Severity: p.Severity,
+		}
+		if u, ok := l.results[p.Position]; ok && p.Check == "U1000" {
+			obj := newJSONObject(fset, u.Obj)
+			jp.Object = &obj
+			jp.Reason = u.Reason
+			for _, o := range u.Chain {
+				jp.Chain = append(jp.Chain, newJSONObject(fset, o))
+			}
+		}
+		if err := enc.Encode(jp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
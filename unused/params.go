@@ -0,0 +1,153 @@
+package unused
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"honnef.co/go/tools/lint"
+
+	"golang.org/x/tools/go/loader"
+)
+
+func (l *LintChecker) LintParams(j *lint.Job) {
+	if !l.c.checkParams() {
+		return
+	}
+	interfaces := allInterfaces(j.Program.Prog)
+	values := funcValues(j.Program.Prog)
+	for _, pkg := range j.Program.Prog.InitialPackages() {
+		for _, f := range pkg.Files {
+			if len(f.Comments) > 0 && isGenerated(f.Comments[0].Text()) {
+				continue
+			}
+			for _, decl := range f.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Body == nil || len(fn.Body.List) == 0 {
+					continue
+				}
+				obj, ok := pkg.Defs[fn.Name].(*types.Func)
+				if !ok || values[obj] || hasSignatureConstraint(j, fn, obj, interfaces) {
+					continue
+				}
+				if obj.Exported() && pkg.Pkg.Name() != "main" && !l.c.WholeProgram {
+					// Packages we don't know about may use it as a
+					// value.
+					continue
+				}
+				lintParams(j, pkg, fn)
+			}
+		}
+	}
+}
+
+func lintParams(j *lint.Job, pkg *loader.PackageInfo, fn *ast.FuncDecl) {
+	used := map[types.Object]bool{}
+	ast.Inspect(fn.Body, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok {
+			used[pkg.Uses[ident]] = true
+		}
+		return true
+	})
+	check := func(fields *ast.FieldList, msg string) {
+		if fields == nil {
+			return
+		}
+		for _, field := range fields.List {
+			for _, name := range field.Names {
+				if name.Name == "_" || used[pkg.Defs[name]] {
+					continue
+				}
+				p := j.Errorf(name, msg, name.Name)
+				p.Fix = lint.Replace(name, "_")
+			}
+		}
+	}
+	check(fn.Type.Params, "parameter %s is unused")
+	check(fn.Type.Results, "named result %s is never assigned")
+}
+
+// allInterfaces returns all non-empty interfaces in lprog.
+func allInterfaces(lprog *loader.Program) []*types.Interface {
+	ifaces := []*types.Interface{types.Universe.Lookup("error").Type().Underlying().(*types.Interface)}
+	for _, pkg := range lprog.AllPackages {
+		for _, tv := range pkg.Types {
+			if iface, ok := tv.Type.(*types.Interface); ok && iface.NumMethods() > 0 {
+				ifaces = append(ifaces, iface)
+			}
+		}
+	}
+	return ifaces
+}
+
+// funcValues returns the functions that the initial packages of lprog
+// use as values instead of calling them, in any of the packages.
+// Their signatures may be dictated by the functions that they're
+// passed to.
+func funcValues(lprog *loader.Program) map[*types.Func]bool {
+	values := map[*types.Func]bool{}
+	for _, pkg := range lprog.InitialPackages() {
+		called := map[*ast.Ident]bool{}
+		for _, f := range pkg.Files {
+			ast.Inspect(f, func(node ast.Node) bool {
+				call, ok := node.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				switch fun := unparen(call.Fun).(type) {
+				case *ast.Ident:
+					called[fun] = true
+				case *ast.SelectorExpr:
+					called[fun.Sel] = true
+				}
+				return true
+			})
+		}
+		for ident, obj := range pkg.Uses {
+			if fn, ok := obj.(*types.Func); ok && !called[ident] {
+				values[fn] = true
+			}
+		}
+	}
+	return values
+}
+
+// hasSignatureConstraint reports whether the signature of fn is
+// dictated by something other than the function itself, such as an
+// interface that it implements or the testing package.
+func hasSignatureConstraint(j *lint.Job, fn *ast.FuncDecl, obj *types.Func, interfaces []*types.Interface) bool {
+	if fn.Doc != nil {
+		for _, cmt := range fn.Doc.List {
+			if strings.HasPrefix(cmt.Text, "//export ") || strings.HasPrefix(cmt.Text, "//go:") {
+				return true
+			}
+		}
+	}
+	if strings.HasSuffix(j.Program.Prog.Fset.Position(fn.Pos()).Filename, "_test.go") {
+		for _, prefix := range []string{"Test", "Benchmark", "Example"} {
+			if strings.HasPrefix(obj.Name(), prefix) {
+				return true
+			}
+		}
+	}
+	recv := obj.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return false
+	}
+	typ := recv.Type()
+	ptr, ok := typ.(*types.Pointer)
+	if !ok {
+		ptr = types.NewPointer(typ)
+	}
+	for _, iface := range interfaces {
+		if !types.Implements(ptr, iface) {
+			continue
+		}
+		for i := 0; i < iface.NumMethods(); i++ {
+			if iface.Method(i).Name() == obj.Name() {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package pkg
+
+//lint:used called by plugins via the registry
+func plugin() {}
+
+//lint:used kept for the next release
+func used() {} // MATCH "func used is used, the //lint:used directive is no longer needed"
+
+//lint:used
+type (
+	t1 struct{}
+	t2 struct{}
+)
+
+type t3 struct {
+	//lint:used read via unsafe
+	f1 int
+	f2 int // MATCH "field f2 is unused"
+}
+
+func fn() {} // MATCH "func fn is unused"
+
+func init() {
+	used()
+	_ = t3{}
+}
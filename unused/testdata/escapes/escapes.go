@@ -0,0 +1,19 @@
+package pkg
+
+import _ "unsafe"
+
+//go:linkname linked runtime.nanotime
+func linked() int64 // MATCH "func linked is assumed to be used because of //go:linkname"
+
+func reflected() { helper() } // MATCH "func reflected is assumed to be used because of assume_used"
+
+func helper() {}
+
+type t struct{}
+
+var _ = t{}
+
+func (t) viaReflect()   {} // MATCH "func t.viaReflect is assumed to be used because of assume_used"
+func (t) unusedMethod() {} // MATCH "func t.unusedMethod is unused"
+
+func fn() {} // MATCH "func fn is unused"
@@ -0,0 +1,31 @@
+package pkg
+
+type shape interface {
+	area() float64
+}
+
+type square struct{}
+
+func (square) area() float64 { return 0 }
+
+type storage interface { // MATCH "type storage is unused"
+	load() string // MATCH "func storage.load is unused"
+}
+
+type disk struct{}
+
+func (disk) load() string { return "" } // MATCH "func disk.load is unused"
+
+type memory struct{}
+
+func (*memory) load() string { return "" } // MATCH "func (*memory).load is unused"
+
+func (square) String() string { return "" }
+
+func fn(s shape) float64 { return s.area() }
+
+func init() {
+	fn(square{})
+	_ = disk{}
+	_ = &memory{}
+}
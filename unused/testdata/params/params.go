@@ -0,0 +1,43 @@
+package pkg
+
+import (
+	"io"
+	"net/http"
+)
+
+func fn1(a int, b int) int { // MATCH "parameter b is unused"
+	return a
+}
+
+func fn2(_ int, a int) (n int, err error) { // MATCH "named result err is never assigned"
+	n = a
+	return n, nil
+}
+
+func fn3(a int) {}
+
+type T struct{}
+
+// implements io.Writer
+func (T) Write(b []byte) (int, error) { return 0, nil }
+
+func (T) method(x int) { // MATCH "parameter x is unused"
+	println()
+}
+
+func handler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(200)
+}
+
+func fn4() {
+	http.HandleFunc("/", handler)
+	var _ io.Writer = T{}
+}
+
+func init() {
+	fn1(0, 0)
+	fn2(0, 1)
+	fn3(0)
+	T{}.method(0)
+	fn4()
+}
@@ -0,0 +1,16 @@
+package pkg
+
+import "encoding/json"
+
+type t1 struct {
+	name  string `json:"name"`
+	other string `xml:"other"` // MATCH "field other is unused"
+	plain string // MATCH "field plain is unused"
+}
+
+func fn() {
+	b, _ := json.Marshal(t1{})
+	_ = b
+}
+
+func init() { fn() }
@@ -0,0 +1,20 @@
+package pkg
+
+var (
+	count    int // MATCH "var count is written to but never read"
+	last     string
+	lastErr  error // MATCH "var lastErr is written to but never read"
+	items    []int
+	Exported int
+)
+
+func record(s string, err error) {
+	count++
+	last = s
+	lastErr = err
+	items[0] = 1
+	Exported = 1
+	println(last)
+}
+
+func init() { record("", nil) }
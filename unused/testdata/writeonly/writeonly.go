@@ -0,0 +1,37 @@
+package pkg
+
+type t1 struct {
+	read    int
+	written int // MATCH "field written is written to but never read"
+	counter int // MATCH "field counter is written to but never read"
+	keyed   int // MATCH "field keyed is written to but never read"
+	addr    int
+	Public  int
+}
+
+type t2 struct {
+	a int
+	b int
+}
+
+type t3 struct {
+	a int
+	b int
+}
+
+func fn() {
+	x := t1{keyed: 1}
+	x.written = 1
+	x.counter++
+	x.read = 2
+	_ = x.read
+	p := &x.addr
+	*p = 1
+	x.Public = 1
+
+	// conversions depend on all fields
+	y := t2{a: 1, b: 2}
+	_ = t3(y)
+}
+
+func init() { fn() }
@@ -6,7 +6,10 @@ import (
 	"go/token"
 	"go/types"
 	"io"
+	"path"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 
 	"honnef.co/go/tools/lint"
@@ -24,12 +27,40 @@ func NewLintChecker(c *Checker) *LintChecker {
 
 type LintChecker struct {
 	c *Checker
+
+	// the unused objects found by the last run, keyed by position
+	results map[token.Pos]Unused
 }
 
 func (l *LintChecker) Init(*lint.Program) {}
+
+func (l *LintChecker) Options() map[string][]lint.Option {
+	return map[string][]lint.Option{
+		"U1000": {
+			{
+				Name:    "assume_used",
+				Default: []string{},
+				Doc: "Patterns of identifiers that are used in ways that aren't visible in the source, such as via reflection, " +
+					"matched against names like example.com/pkg.Type.Method",
+			},
+			{
+				Name:    "used_struct_tags",
+				Default: []string{},
+				Doc:     "Keys of struct tags, such as json or db, that mark fields as used",
+			},
+			{
+				Name:    "report_escapes",
+				Default: false,
+				Doc:     "Report identifiers that are only considered used because of assume_used, //go:linkname or cgo exports",
+			},
+		},
+	}
+}
 func (l *LintChecker) Funcs() map[string]lint.Func {
 	return map[string]lint.Func{
 		"U1000": l.Lint,
+		"U1001": l.LintWriteOnly,
+		"U1002": l.LintParams,
 	}
 }
 
@@ -52,22 +83,92 @@ func typString(obj types.Object) string {
 	}
 }
 
-func (l *LintChecker) Lint(j *lint.Job) {
-	unused := l.c.Check(j.Program.Prog)
-	for _, u := range unused {
-		name := u.Obj.Name()
-		if sig, ok := u.Obj.Type().(*types.Signature); ok && sig.Recv() != nil {
-			switch sig.Recv().Type().(type) {
-			case *types.Named, *types.Pointer:
-				typ := types.TypeString(sig.Recv().Type(), func(*types.Package) string { return "" })
-				if len(typ) > 0 && typ[0] == '*' {
-					name = fmt.Sprintf("(%s).%s", typ, u.Obj.Name())
-				} else if len(typ) > 0 {
-					name = fmt.Sprintf("%s.%s", typ, u.Obj.Name())
+// objName returns the name of obj, qualified with its receiver type
+// for methods.
+func objName(obj types.Object) string {
+	name := obj.Name()
+	if sig, ok := obj.Type().(*types.Signature); ok && sig.Recv() != nil {
+		switch sig.Recv().Type().(type) {
+		case *types.Named, *types.Pointer:
+			typ := types.TypeString(sig.Recv().Type(), func(*types.Package) string { return "" })
+			if len(typ) > 0 && typ[0] == '*' {
+				name = fmt.Sprintf("(%s).%s", typ, obj.Name())
+			} else if len(typ) > 0 {
+				name = fmt.Sprintf("%s.%s", typ, obj.Name())
+			}
+		}
+	}
+	return name
+}
+
+// qualifiedName returns the name of obj that assume_used patterns are
+// matched against: the import path of its package, followed by its
+// name, qualified with the receiver type for methods and the struct
+// type for fields.
+func qualifiedName(obj types.Object) string {
+	name := obj.Name()
+	switch obj := obj.(type) {
+	case *types.Func:
+		if recv := obj.Type().(*types.Signature).Recv(); recv != nil {
+			if named, ok := dereferenceType(recv.Type()).(*types.Named); ok {
+				name = named.Obj().Name() + "." + name
+			}
+		}
+	case *types.Var:
+		if obj.IsField() {
+			scope := obj.Pkg().Scope()
+			for _, n := range scope.Names() {
+				tn, ok := scope.Lookup(n).(*types.TypeName)
+				if !ok {
+					continue
+				}
+				if st, ok := tn.Type().Underlying().(*types.Struct); ok && isFieldOf(obj, st) {
+					name = tn.Name() + "." + name
+					break
 				}
 			}
 		}
-		j.Errorf(u.Obj, "%s %s is unused", typString(u.Obj), name)
+	}
+	return obj.Pkg().Path() + "." + name
+}
+
+// usedStructTags returns the value of the used_struct_tags option of
+// U1000 for pkg.
+func usedStructTags(j *lint.Job, pkg *types.Package) []string {
+	for _, p := range j.Program.Packages {
+		if p.Info.Pkg == pkg && len(p.Info.Files) > 0 {
+			return j.CheckOption("U1000", p.Info.Files[0], "used_struct_tags").([]string)
+		}
+	}
+	return nil
+}
+
+func (l *LintChecker) Lint(j *lint.Job) {
+	l.c.AssumeUsed = func(obj types.Object) bool {
+		name := qualifiedName(obj)
+		for _, pattern := range j.StringsOption(obj, "assume_used") {
+			if m, _ := path.Match(pattern, name); m {
+				return true
+			}
+		}
+		return false
+	}
+	l.c.UsedStructTags = func(pkg *types.Package) []string {
+		return usedStructTags(j, pkg)
+	}
+	unused, escapes := l.c.CheckEscapes(j.Program.Prog)
+	l.results = map[token.Pos]Unused{}
+	for _, u := range unused {
+		l.results[u.Obj.Pos()] = u
+		j.Errorf(u.Obj, "%s %s is unused", typString(u.Obj), objName(u.Obj))
+	}
+	for _, e := range escapes {
+		switch {
+		case e.Via == "//lint:used" && !e.Needed:
+			j.Errorf(e.Obj, "%s %s is used, the //lint:used directive is no longer needed", typString(e.Obj), objName(e.Obj))
+		case e.Needed && j.BoolOption(e.Obj, "report_escapes"):
+			j.Errorf(e.Obj, "%s %s is assumed to be used because of %s", typString(e.Obj), objName(e.Obj), e.Via)
+		}
 	}
 }
 
@@ -136,13 +237,56 @@ const (
 	CheckFunctions
 	CheckTypes
 	CheckVariables
+	// CheckWriteOnly reports fields and package-level variables that
+	// are written to but never read. It isn't part of CheckAll.
+	CheckWriteOnly
+	// CheckParams reports unused function parameters and named
+	// results that are never assigned. It isn't part of CheckAll.
+	CheckParams
 
 	CheckAll = CheckConstants | CheckFields | CheckFunctions | CheckTypes | CheckVariables
 )
 
+// An escape is an object that is assumed to be used because it may be
+// accessed in ways that aren't visible in the source.
+type escape struct {
+	node *graphNode
+	via  string
+}
+
+// An Escape is an object that is assumed to be used, such as because
+// of its //go:linkname directive.
+type Escape struct {
+	Obj      types.Object
+	Position token.Position
+	// Via describes the reason for the assumption.
+	Via string
+	// Needed is true if the object would be unused without the
+	// assumption.
+	Needed bool
+}
+
+// Reasons why an object is unused.
+const (
+	// Nothing refers to the object.
+	ReasonUnreferenced = "unreferenced"
+	// The object is only referred to by other unused objects.
+	ReasonUsedByUnused = "used-by-unused"
+	// The object is only referred to by unused objects that it
+	// refers to itself, directly or indirectly.
+	ReasonCycle = "cycle"
+)
+
 type Unused struct {
 	Obj      types.Object
 	Position token.Position
+
+	// Reason is one of the Reason constants.
+	Reason string
+	// Chain is the chain of unused objects that was considered:
+	// Chain[0] refers to Obj and each further object refers to its
+	// predecessor. It is empty for unreferenced objects.
+	Chain []types.Object
 }
 
 type Checker struct {
@@ -151,12 +295,37 @@ type Checker struct {
 	ConsiderReflection bool
 	Debug              io.Writer
 
-	graph *graph
+	// Graph, if not nil, receives the graph of declarations in the
+	// initial packages and the declarations that they use, in the
+	// format named by GraphFormat, either "dot" (the default) or
+	// "json".
+	Graph       io.Writer
+	GraphFormat string
+
+	// StrictInterfaces, if true, only considers methods used for
+	// implementing interfaces of the initial packages if the
+	// interfaces are used themselves. By default, all methods that
+	// implement interfaces are considered used.
+	StrictInterfaces bool
+
+	// AssumeUsed, if not nil, reports whether obj is used in ways
+	// that aren't visible in the source, such as via reflection.
+	AssumeUsed func(obj types.Object) bool
+
+	// UsedStructTags, if not nil, returns the keys of struct tags,
+	// such as json, that mark fields of structs in pkg as used.
+	UsedStructTags func(pkg *types.Package) []string
+
+	graph   *graph
+	escapes []escape
 
 	msCache      typeutil.MethodSetCache
 	lprog        *loader.Program
 	topmostCache map[*types.Scope]*types.Scope
 	interfaces   []*types.Interface
+	// interfaces declared in the initial packages; only used with
+	// StrictInterfaces
+	localInterfaces map[*types.Interface]bool
 }
 
 func NewChecker(mode CheckMode) *Checker {
@@ -174,6 +343,8 @@ func (c *Checker) checkFields() bool    { return (c.Mode & CheckFields) > 0 }
 func (c *Checker) checkFunctions() bool { return (c.Mode & CheckFunctions) > 0 }
 func (c *Checker) checkTypes() bool     { return (c.Mode & CheckTypes) > 0 }
 func (c *Checker) checkVariables() bool { return (c.Mode & CheckVariables) > 0 }
+func (c *Checker) checkWriteOnly() bool { return (c.Mode & CheckWriteOnly) > 0 }
+func (c *Checker) checkParams() bool    { return (c.Mode & CheckParams) > 0 }
 
 func (c *Checker) markFields(typ types.Type) {
 	structType, ok := typ.Underlying().(*types.Struct)
@@ -196,11 +367,21 @@ func (e Error) Error() string {
 }
 
 func (c *Checker) Check(lprog *loader.Program) []Unused {
+	unused, _ := c.CheckEscapes(lprog)
+	return unused
+}
+
+// CheckEscapes is like Check, but also returns the objects that are
+// assumed to be used.
+func (c *Checker) CheckEscapes(lprog *loader.Program) ([]Unused, []Escape) {
 	var unused []Unused
 	c.lprog = lprog
 	if c.WholeProgram {
 		c.findExportedInterfaces()
 	}
+	if c.StrictInterfaces {
+		c.findLocalInterfaces()
+	}
 	for _, pkg := range c.lprog.InitialPackages() {
 		c.processDefs(pkg)
 		c.processUses(pkg)
@@ -226,11 +407,35 @@ func (c *Checker) Check(lprog *loader.Program) []Unused {
 		roots[root] = struct{}{}
 	}
 	markNodesUsed(roots)
+
+	var escapes []Escape
+	seen := map[escape]bool{}
+	for _, e := range c.escapes {
+		if seen[e] {
+			continue
+		}
+		seen[e] = true
+		obj := e.node.obj.(types.Object)
+		escapes = append(escapes, Escape{
+			Obj:      obj,
+			Position: c.lprog.Fset.Position(obj.Pos()),
+			Via:      e.via,
+			Needed:   !e.node.used,
+		})
+	}
+	roots = map[*graphNode]struct{}{}
+	for _, e := range c.escapes {
+		roots[e.node] = struct{}{}
+	}
+	markNodesUsed(roots)
 	c.markNodesQuiet()
 
 	if c.Debug != nil {
 		c.printDebugGraph(c.Debug)
 	}
+	if c.Graph != nil {
+		c.writeGraph()
+	}
 
 	for _, node := range c.graph.nodes {
 		if node.used || node.quiet {
@@ -272,7 +477,108 @@ func (c *Checker) Check(lprog *loader.Program) []Unused {
 		}
 		unused = append(unused, Unused{Obj: obj, Position: pos})
 	}
-	return unused
+
+	if len(unused) > 0 {
+		users := c.graph.users()
+		for i := range unused {
+			u := &unused[i]
+			u.Reason, u.Chain = c.explain(users, u.Obj)
+		}
+	}
+	return unused, escapes
+}
+
+// users returns the inverse of the uses relation of the graph.
+func (g *graph) users() map[*graphNode][]*graphNode {
+	users := map[*graphNode][]*graphNode{}
+	for _, node := range g.nodes {
+		for used := range node.uses {
+			users[used] = append(users[used], node)
+		}
+	}
+	return users
+}
+
+// isIntermediate reports whether obj is only an implementation detail
+// of the graph, such as a parameter, that doesn't make sense as the
+// user of an object.
+func isIntermediate(obj types.Object) bool {
+	v, ok := obj.(*types.Var)
+	return ok && !v.IsField() && v.Parent() != nil && v.Parent() != v.Pkg().Scope()
+}
+
+// objectUsers returns the objects that refer to obj, looking through
+// nodes that aren't objects, such as types and scopes.
+func (c *Checker) objectUsers(users map[*graphNode][]*graphNode, obj types.Object) []types.Object {
+	start := c.graph.nodes[obj]
+	seen := map[*graphNode]bool{start: true}
+	queue := append([]*graphNode(nil), users[start]...)
+	var out []types.Object
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		if seen[node] {
+			continue
+		}
+		seen[node] = true
+		if obj, ok := node.obj.(types.Object); ok && !isIntermediate(obj) {
+			out = append(out, obj)
+			continue
+		}
+		for _, user := range users[node] {
+			// Fields refer to their struct, but the struct
+			// doesn't depend on them.
+			if st, ok := node.obj.(*types.Struct); ok {
+				if field, ok := user.obj.(*types.Var); ok && isFieldOf(field, st) {
+					continue
+				}
+			}
+			queue = append(queue, user)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Pos() < out[j].Pos()
+	})
+	return out
+}
+
+func isFieldOf(field *types.Var, st *types.Struct) bool {
+	for i := 0; i < st.NumFields(); i++ {
+		if st.Field(i) == field {
+			return true
+		}
+	}
+	return false
+}
+
+// explain classifies why obj is unused and returns the chain of
+// unused objects that refer to it.
+func (c *Checker) explain(users map[*graphNode][]*graphNode, obj types.Object) (string, []types.Object) {
+	seen := map[types.Object]bool{obj: true}
+	var chain []types.Object
+	cur := obj
+	for {
+		objs := c.objectUsers(users, cur)
+		if len(objs) == 0 {
+			if len(chain) == 0 {
+				return ReasonUnreferenced, nil
+			}
+			return ReasonUsedByUnused, chain
+		}
+		var next types.Object
+		for _, o := range objs {
+			if !seen[o] {
+				next = o
+				break
+			}
+		}
+		if next == nil {
+			return ReasonCycle, chain
+		}
+		seen[next] = true
+		chain = append(chain, next)
+		cur = next
+	}
 }
 
 func (c *Checker) useExportedFields(typ types.Type) {
@@ -287,6 +593,29 @@ func (c *Checker) useExportedFields(typ types.Type) {
 	}
 }
 
+// hasUsedTag reports whether field i of st has a struct tag that
+// marks it as used, such as a json tag.
+func (c *Checker) hasUsedTag(pkg *types.Package, st *types.Struct, i int) bool {
+	if c.UsedStructTags == nil {
+		return false
+	}
+	tag := reflect.StructTag(st.Tag(i))
+	for _, key := range c.UsedStructTags(pkg) {
+		if _, ok := tag.Lookup(key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Checker) useTaggedFields(pkg *types.Package, st *types.Struct) {
+	for i := 0; i < st.NumFields(); i++ {
+		if c.hasUsedTag(pkg, st, i) {
+			c.graph.markUsedBy(st.Field(i), st)
+		}
+	}
+}
+
 func (c *Checker) useExportedMethods(typ types.Type) {
 	named, ok := typ.(*types.Named)
 	if !ok {
@@ -410,6 +739,10 @@ func (c *Checker) processDefs(pkg *loader.PackageInfo) {
 			c.graph.markUsedBy(c.topmostScope(scope, obj.Pkg()), obj)
 		}
 
+		if c.AssumeUsed != nil && obj.Pkg() != nil && c.AssumeUsed(obj) {
+			c.addEscape(obj, "assume_used")
+		}
+
 		if c.isRoot(obj) {
 			node := c.graph.getNode(obj)
 			c.graph.roots = append(c.graph.roots, node)
@@ -465,6 +798,17 @@ func (c *Checker) findExportedInterfaces() {
 	}
 }
 
+func (c *Checker) findLocalInterfaces() {
+	c.localInterfaces = map[*types.Interface]bool{}
+	for _, pkg := range c.lprog.InitialPackages() {
+		for _, tv := range pkg.Types {
+			if iface, ok := tv.Type.(*types.Interface); ok {
+				c.localInterfaces[iface] = true
+			}
+		}
+	}
+}
+
 func (c *Checker) processTypes(pkg *loader.PackageInfo) {
 	named := map[*types.Named]*types.Pointer{}
 	var interfaces []*types.Interface
@@ -488,6 +832,7 @@ func (c *Checker) processTypes(pkg *loader.PackageInfo) {
 			if pkg.Pkg.Name() != "main" && !c.WholeProgram {
 				c.useExportedFields(obj)
 			}
+			c.useTaggedFields(pkg.Pkg, obj)
 		}
 	}
 
@@ -501,6 +846,9 @@ func (c *Checker) processTypes(pkg *loader.PackageInfo) {
 	// 2) Use SSA and flow analysis and determine the exact set of
 	// interfaces that is relevant.
 	fn := func(iface *types.Interface) {
+		// In strict mode, methods implementing local interfaces are
+		// only used if the interface is.
+		strict := c.StrictInterfaces && c.localInterfaces[iface]
 		for obj, objPtr := range named {
 			if !types.Implements(obj, iface) && !types.Implements(objPtr, iface) {
 				continue
@@ -512,6 +860,10 @@ func (c *Checker) processTypes(pkg *loader.PackageInfo) {
 				ifaceMethods[meth.Name()] = struct{}{}
 			}
 			for _, obj := range []types.Type{obj, objPtr} {
+				var user interface{} = obj
+				if strict {
+					user = iface
+				}
 				ms := c.msCache.MethodSet(obj)
 				n := ms.Len()
 				for i := 0; i < n; i++ {
@@ -521,12 +873,12 @@ func (c *Checker) processTypes(pkg *loader.PackageInfo) {
 					if !found {
 						continue
 					}
-					c.graph.markUsedBy(meth.Type().(*types.Signature).Recv().Type(), obj) // embedded receiver
+					c.graph.markUsedBy(meth.Type().(*types.Signature).Recv().Type(), user) // embedded receiver
 					if len(sel.Index()) > 1 {
 						f := getField(obj, sel.Index()[0])
-						c.graph.markUsedBy(f, obj) // embedded receiver
+						c.graph.markUsedBy(f, user) // embedded receiver
 					}
-					c.graph.markUsedBy(meth, obj)
+					c.graph.markUsedBy(meth, user)
 				}
 			}
 		}
@@ -661,11 +1013,87 @@ func (c *Checker) processCgoExported(pkg *loader.PackageInfo, node ast.Node) {
 				return
 			}
 			obj := pkg.ObjectOf(node.Name)
-			c.graph.roots = append(c.graph.roots, c.graph.getNode(obj))
+			c.addEscape(obj, "cgo export")
 		}
 	}
 }
 
+// processLinknames marks objects as used if they're the local side of
+// a //go:linkname directive. The linked symbol may be used by code
+// that the type checker can't see.
+func (c *Checker) processLinknames(pkg *loader.PackageInfo, file *ast.File) {
+	for _, cg := range file.Comments {
+		for _, cmt := range cg.List {
+			if !strings.HasPrefix(cmt.Text, "//go:linkname ") {
+				continue
+			}
+			fields := strings.Fields(cmt.Text)
+			if len(fields) < 2 {
+				continue
+			}
+			obj := pkg.Pkg.Scope().Lookup(fields[1])
+			if obj == nil {
+				continue
+			}
+			c.addEscape(obj, "//go:linkname")
+		}
+	}
+}
+
+// processUsedDirectives marks declarations as used if they're
+// documented with a //lint:used directive, which marks them as
+// intentionally kept.
+func (c *Checker) processUsedDirectives(pkg *loader.PackageInfo, file *ast.File) {
+	hasDirective := func(doc *ast.CommentGroup) bool {
+		if doc == nil {
+			return false
+		}
+		for _, cmt := range doc.List {
+			if cmt.Text == "//lint:used" || strings.HasPrefix(cmt.Text, "//lint:used ") {
+				return true
+			}
+		}
+		return false
+	}
+	mark := func(names ...*ast.Ident) {
+		for _, name := range names {
+			if obj := pkg.Defs[name]; obj != nil {
+				c.addEscape(obj, "//lint:used")
+			}
+		}
+	}
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FuncDecl:
+			if hasDirective(node.Doc) {
+				mark(node.Name)
+			}
+		case *ast.GenDecl:
+			for _, spec := range node.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					if hasDirective(node.Doc) || hasDirective(spec.Doc) {
+						mark(spec.Name)
+					}
+				case *ast.ValueSpec:
+					if hasDirective(node.Doc) || hasDirective(spec.Doc) {
+						mark(spec.Names...)
+					}
+				}
+			}
+		case *ast.Field:
+			if hasDirective(node.Doc) {
+				mark(node.Names...)
+			}
+		}
+		return true
+	})
+}
+
+func (c *Checker) addEscape(obj types.Object, via string) {
+	c.escapes = append(c.escapes, escape{node: c.graph.getNode(obj), via: via})
+}
+
 func (c *Checker) processVariableDeclaration(pkg *loader.PackageInfo, node ast.Node) {
 	if decl, ok := node.(*ast.GenDecl); ok {
 		for _, spec := range decl.Specs {
@@ -759,6 +1187,8 @@ func (c *Checker) processAST(pkg *loader.PackageInfo) {
 	}
 	for _, file := range pkg.Files {
 		ast.Inspect(file, fn)
+		c.processLinknames(pkg, file)
+		c.processUsedDirectives(pkg, file)
 	}
 }
 
@@ -7,12 +7,21 @@ package unused
 // https://developers.google.com/open-source/licenses/bsd.
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"go/parser"
 	"go/token"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
+	"honnef.co/go/tools/lint"
 	"honnef.co/go/tools/lint/testutil"
+
+	"golang.org/x/tools/go/buildutil"
+	"golang.org/x/tools/go/loader"
 )
 
 func TestAll(t *testing.T) {
@@ -60,3 +69,187 @@ func extractIDs(line string) []string {
 	line = line[idx+len(marker):]
 	return strings.Split(line, ", ")
 }
+
+func TestReasons(t *testing.T) {
+	const src = `package pkg
+
+func unref() { helper() }
+func helper() { leaf() }
+func leaf() {}
+
+func ping() { pong() }
+func pong() { ping() }
+`
+	conf := &loader.Config{}
+	f, err := conf.ParseFile("pkg.go", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.CreateFromFiles("pkg", f)
+	lprog, err := conf.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]struct {
+		reason string
+		chain  string
+	}{
+		"unref":  {ReasonUnreferenced, ""},
+		"helper": {ReasonUsedByUnused, "unref"},
+		"leaf":   {ReasonUsedByUnused, "helper unref"},
+		"ping":   {ReasonCycle, "pong"},
+		"pong":   {ReasonCycle, "ping"},
+	}
+	unused := NewChecker(CheckAll).Check(lprog)
+	if len(unused) != len(want) {
+		t.Fatalf("got %d unused objects, want %d", len(unused), len(want))
+	}
+	for _, u := range unused {
+		var chain []string
+		for _, obj := range u.Chain {
+			chain = append(chain, obj.Name())
+		}
+		w := want[u.Obj.Name()]
+		if u.Reason != w.reason || strings.Join(chain, " ") != w.chain {
+			t.Errorf("%s: got reason %q, chain %v; want reason %q, chain %q",
+				u.Obj.Name(), u.Reason, chain, w.reason, w.chain)
+		}
+	}
+}
+
+func TestGraph(t *testing.T) {
+	const src = `package main
+
+type T struct{ f int }
+
+func main() { helper() }
+func helper() { _ = T{}.f }
+func unref() {}
+`
+	conf := &loader.Config{}
+	f, err := conf.ParseFile("pkg.go", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.CreateFromFiles("main", f)
+	lprog, err := conf.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checker := NewChecker(CheckAll)
+	checker.Graph = &bytes.Buffer{}
+	checker.GraphFormat = "json"
+	checker.Check(lprog)
+	var decls []jsonDecl
+	if err := json.Unmarshal(checker.Graph.(*bytes.Buffer).Bytes(), &decls); err != nil {
+		t.Fatal(err)
+	}
+	names := map[int]string{}
+	for _, d := range decls {
+		names[d.ID] = d.Name
+	}
+	var got []string
+	for _, d := range decls {
+		var uses []string
+		for _, id := range d.Uses {
+			uses = append(uses, names[id])
+		}
+		got = append(got, fmt.Sprintf("%s root=%t used=%t uses=%s", d.Name, d.Root, d.Used, strings.Join(uses, ",")))
+	}
+	want := []string{
+		"T root=false used=true uses=",
+		"f root=false used=true uses=T",
+		"main root=true used=true uses=helper",
+		"helper root=false used=true uses=T,f",
+		"unref root=false used=false uses=",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got graph\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestEscapes(t *testing.T) {
+	checker := NewChecker(CheckAll)
+	l := NewLintChecker(checker)
+	testutil.TestAll(t, l, "escapes")
+}
+
+func TestWriteOnly(t *testing.T) {
+	checker := NewChecker(CheckAll | CheckWriteOnly)
+	l := NewLintChecker(checker)
+	testutil.TestAll(t, l, "writeonly")
+}
+
+func TestStructTags(t *testing.T) {
+	checker := NewChecker(CheckAll | CheckWriteOnly)
+	l := NewLintChecker(checker)
+	testutil.TestAll(t, l, "tags")
+}
+
+func TestParams(t *testing.T) {
+	checker := NewChecker(CheckAll | CheckParams)
+	l := NewLintChecker(checker)
+	testutil.TestAll(t, l, "params")
+}
+
+func TestParamsAcrossPackages(t *testing.T) {
+	ctx := buildutil.FakeContext(map[string]map[string]string{
+		"a": {"a.go": `package a
+
+func Handler(x int, unused string) int { return x }
+func Helper(x int, unused string) int  { return x }
+`},
+		"b": {"b.go": `package b
+
+import "a"
+
+var h = a.Handler
+
+func fn() { a.Helper(0, "") }
+`},
+	})
+	for _, whole := range []bool{false, true} {
+		conf := &loader.Config{Build: ctx}
+		conf.Import("a")
+		conf.Import("b")
+		lprog, err := conf.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		checker := NewChecker(CheckAll | CheckParams)
+		checker.WholeProgram = whole
+		l := &lint.Linter{Checker: NewLintChecker(checker)}
+		var got []string
+		for _, p := range l.Lint(lprog) {
+			if p.Check == "U1002" {
+				pos := lprog.Fset.Position(p.Position)
+				got = append(got, fmt.Sprintf("%s:%d:%d", filepath.Base(pos.Filename), pos.Line, pos.Column))
+			}
+		}
+		// b uses Handler as a value, so its signature may be
+		// dictated by whatever it is passed to. Without the whole
+		// program, other packages may do the same with Helper.
+		var want []string
+		if whole {
+			want = []string{"a.go:4:20"}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("WholeProgram = %t: got %q, want %q", whole, got, want)
+		}
+	}
+}
+
+func TestUsedDirectives(t *testing.T) {
+	checker := NewChecker(CheckAll)
+	l := NewLintChecker(checker)
+	testutil.TestAll(t, l, "directives")
+}
+
+func TestStrictInterfaces(t *testing.T) {
+	checker := NewChecker(CheckAll)
+	checker.StrictInterfaces = true
+	l := NewLintChecker(checker)
+	testutil.TestAll(t, l, "interfaces")
+}
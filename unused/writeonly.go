@@ -0,0 +1,221 @@
+package unused
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+
+	"honnef.co/go/tools/lint"
+
+	"golang.org/x/tools/go/loader"
+)
+
+func (l *LintChecker) LintWriteOnly(j *lint.Job) {
+	if !l.c.checkWriteOnly() {
+		return
+	}
+	c := &Checker{
+		Mode:               l.c.Mode,
+		WholeProgram:       l.c.WholeProgram,
+		ConsiderReflection: l.c.ConsiderReflection,
+		UsedStructTags: func(pkg *types.Package) []string {
+			return usedStructTags(j, pkg)
+		},
+	}
+	for _, v := range c.WriteOnly(j.Program.Prog) {
+		if v.IsField() {
+			j.Errorf(v, "field %s is written to but never read", v.Name())
+		} else {
+			j.Errorf(v, "var %s is written to but never read", v.Name())
+		}
+	}
+}
+
+// varAccesses records how fields and package-level variables are
+// accessed.
+type varAccesses struct {
+	reads  map[*types.Var]bool
+	writes map[*types.Var]bool
+}
+
+func (fa varAccesses) readAll(st *types.Struct) {
+	for i := 0; i < st.NumFields(); i++ {
+		fa.reads[st.Field(i)] = true
+	}
+}
+
+// WriteOnly returns the struct fields and package-level variables in
+// the initial packages of lprog that are assigned to but whose values
+// are never read. Objects that may be read by code outside of lprog,
+// or via reflection, are not considered.
+func (c *Checker) WriteOnly(lprog *loader.Program) []*types.Var {
+	fa := varAccesses{
+		reads:  map[*types.Var]bool{},
+		writes: map[*types.Var]bool{},
+	}
+	initial := map[*types.Package]bool{}
+	for _, pkg := range lprog.InitialPackages() {
+		initial[pkg.Pkg] = true
+		c.processVarAccesses(pkg, fa)
+	}
+
+	var out []*types.Var
+	for v := range fa.writes {
+		if fa.reads[v] || !initial[v.Pkg()] || v.Name() == "_" {
+			continue
+		}
+		if v.Exported() && (!c.WholeProgram || c.ConsiderReflection) {
+			continue
+		}
+		pos := lprog.Fset.Position(v.Pos())
+		if pos.Filename == "" || isGeneratedFile(lprog, v.Pkg(), pos.Filename) {
+			continue
+		}
+		out = append(out, v)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Pos() < out[j].Pos()
+	})
+	return out
+}
+
+func isGeneratedFile(lprog *loader.Program, pkg *types.Package, filename string) bool {
+	for _, file := range lprog.Package(pkg.Path()).Files {
+		if lprog.Fset.Position(file.Pos()).Filename != filename {
+			continue
+		}
+		return len(file.Comments) > 0 && isGenerated(file.Comments[0].Text())
+	}
+	return false
+}
+
+// isPackageVar reports whether obj is a package-level variable.
+func isPackageVar(obj types.Object) bool {
+	v, ok := obj.(*types.Var)
+	return ok && !v.IsField() && v.Pkg() != nil && v.Parent() == v.Pkg().Scope()
+}
+
+func (c *Checker) processVarAccesses(pkg *loader.PackageInfo, fa varAccesses) {
+	for _, tv := range pkg.Types {
+		st, ok := tv.Type.(*types.Struct)
+		if !ok {
+			continue
+		}
+		// Fields with tags such as json are read by encoders.
+		for i := 0; i < st.NumFields(); i++ {
+			if c.hasUsedTag(pkg.Pkg, st, i) {
+				fa.reads[st.Field(i)] = true
+			}
+		}
+	}
+
+	written := map[ast.Expr]bool{}
+	write := func(expr ast.Expr) {
+		switch expr := unparen(expr).(type) {
+		case *ast.SelectorExpr:
+			written[expr] = true
+			// Assigning to pkg.Var writes to the variable.
+			written[expr.Sel] = true
+		case *ast.Ident:
+			written[expr] = true
+		}
+	}
+	fn := func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range node.Lhs {
+				write(lhs)
+			}
+		case *ast.IncDecStmt:
+			write(node.X)
+		case *ast.RangeStmt:
+			if node.Tok == token.ASSIGN {
+				if node.Key != nil {
+					write(node.Key)
+				}
+				if node.Value != nil {
+					write(node.Value)
+				}
+			}
+
+		case *ast.CompositeLit:
+			st, ok := dereferenceType(pkg.TypeOf(node)).Underlying().(*types.Struct)
+			if !ok {
+				return true
+			}
+			for i, elt := range node.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok {
+					if field, ok := pkg.ObjectOf(kv.Key.(*ast.Ident)).(*types.Var); ok {
+						fa.writes[field] = true
+					}
+				} else if i < st.NumFields() {
+					fa.writes[st.Field(i)] = true
+				}
+			}
+		case *ast.CallExpr:
+			// Conversions between struct types depend on all
+			// fields, and unsafe conversions may access them in
+			// any way.
+			if len(node.Args) != 1 {
+				return true
+			}
+			tv, ok := pkg.Types[node.Fun]
+			if !ok || !tv.IsType() {
+				return true
+			}
+			if st, ok := dereferenceType(tv.Type).Underlying().(*types.Struct); ok {
+				fa.readAll(st)
+			}
+			if st, ok := dereferenceType(pkg.TypeOf(node.Args[0])).Underlying().(*types.Struct); ok {
+				fa.readAll(st)
+			}
+		}
+		return true
+	}
+	for _, file := range pkg.Files {
+		ast.Inspect(file, fn)
+	}
+
+	for expr, sel := range pkg.Selections {
+		if sel.Kind() != types.FieldVal {
+			continue
+		}
+		// Fields that the selected field is promoted through are
+		// read.
+		typ := sel.Recv()
+		indices := sel.Index()
+		for _, idx := range indices[:len(indices)-1] {
+			field := getField(typ, idx)
+			fa.reads[field] = true
+			typ = field.Type()
+		}
+		field := sel.Obj().(*types.Var)
+		if written[expr] {
+			fa.writes[field] = true
+		} else {
+			fa.reads[field] = true
+		}
+	}
+
+	for ident, obj := range pkg.Uses {
+		if !isPackageVar(obj) {
+			continue
+		}
+		if written[ident] {
+			fa.writes[obj.(*types.Var)] = true
+		} else {
+			fa.reads[obj.(*types.Var)] = true
+		}
+	}
+}
+
+func unparen(expr ast.Expr) ast.Expr {
+	for {
+		paren, ok := expr.(*ast.ParenExpr)
+		if !ok {
+			return expr
+		}
+		expr = paren.X
+	}
+}